@@ -0,0 +1,155 @@
+package systemdmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	otelattr "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AuditRecord describes a mutating unit operation.
+type AuditRecord struct {
+	Time      time.Time         `json:"time"`
+	Operation Operation         `json:"operation"`
+	Unit      string            `json:"unit"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Duration  time.Duration     `json:"duration"`
+	// Error is empty when the operation succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink receives a record for every mutating unit operation.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// WithAuditSink sends a record of every mutating unit operation to sink.
+// Failing to record doesn't fail the operation, but is recorded in its span.
+func WithAuditSink(sink AuditSink) Option {
+	return func(o *options) {
+		o.auditSink = sink
+	}
+}
+
+// WithMetadata attaches caller metadata, such as who requested an operation,
+// to the operation span and audit record. See also ContextWithMetadata.
+func WithMetadata(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]string)
+		}
+		o.metadata[key] = value
+	}
+}
+
+// metadataKey is the context key of caller metadata.
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying caller metadata, such as
+// a request ID or who requested an operation, which is added to the spans
+// and audit records of every operation called with it, along with metadata
+// given with WithMetadata. Metadata carried by ctx is kept, unless set again
+// under the same key.
+func ContextWithMetadata(ctx context.Context, key, value string) context.Context {
+	metadata := MetadataFromContext(ctx)
+	if metadata == nil {
+		metadata = make(map[string]string, 1)
+	}
+	metadata[key] = value
+
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns a copy of the caller metadata carried by ctx,
+// or nil if none.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+
+	return maps.Clone(metadata)
+}
+
+// withContextMetadata adds the caller metadata carried by ctx to the
+// metadata of an operation, which takes precedence, and to its span.
+func withContextMetadata(ctx context.Context, o callOptions) callOptions {
+	if metadata := MetadataFromContext(ctx); metadata != nil {
+		maps.Copy(metadata, o.metadata)
+		o.metadata = metadata
+	}
+	span := trace.SpanFromContext(ctx)
+	for _, key := range slices.Sorted(maps.Keys(o.metadata)) {
+		span.SetAttributes(otelattr.String("metadata."+key, o.metadata[key]))
+	}
+
+	return o
+}
+
+// audit sends the record of an operation to the audit sink.
+func (m *manager) audit(ctx context.Context, op Operation, unit string, o callOptions, started time.Time, opErr error) {
+	record := AuditRecord{
+		Time:      started.UTC(),
+		Operation: op,
+		Unit:      unit,
+		Metadata:  o.metadata,
+		Duration:  time.Since(started),
+	}
+	if opErr != nil {
+		record.Error = opErr.Error()
+	}
+
+	if err := m.options.auditSink.Record(ctx, record); err != nil {
+		trace.SpanFromContext(ctx).RecordError(fmt.Errorf("failed to record audit of %s unit %q: %w", op, unit, err))
+	}
+}
+
+// JSONAuditSink writes audit records as JSON lines.
+type JSONAuditSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// Assert JSONAuditSink fulfills the AuditSink interface.
+var _ AuditSink = (*JSONAuditSink)(nil)
+
+// NewJSONAuditSink returns a JSONAuditSink writing to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{encoder: json.NewEncoder(w)}
+}
+
+// OpenJSONAuditFile returns a JSONAuditSink appending to the named file,
+// which is created if needed. The sink must be closed when done.
+func OpenJSONAuditFile(path string) (*JSONAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	sink := NewJSONAuditSink(f)
+	sink.closer = f
+
+	return sink, nil
+}
+
+// Record writes record as a single JSON line.
+func (s *JSONAuditSink) Record(_ context.Context, record AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.encoder.Encode(record)
+}
+
+// Close closes the underlying file, if any.
+func (s *JSONAuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
@@ -0,0 +1,81 @@
+package systemdmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_JSONAuditSink(t *testing.T) {
+	ctx := context.Background()
+	record := AuditRecord{
+		Time:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Operation: OperationRestart,
+		Unit:      "a.service",
+		Metadata:  map[string]string{"operator": "alice"},
+		Duration:  time.Second,
+		Error:     "boom",
+	}
+
+	t.Run("Writes JSON lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewJSONAuditSink(&buf)
+		require.NoError(t, sink.Record(ctx, record))
+		require.NoError(t, sink.Record(ctx, record))
+		require.NoError(t, sink.Close())
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		require.Len(t, lines, 2)
+		require.JSONEq(t, `{"time":"2025-01-02T03:04:05Z","operation":"restart","unit":"a.service","metadata":{"operator":"alice"},"duration":1000000000,"error":"boom"}`, string(lines[0]))
+	})
+
+	t.Run("Appends to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		for range 2 {
+			sink, err := OpenJSONAuditFile(path)
+			require.NoError(t, err)
+			require.NoError(t, sink.Record(ctx, record))
+			require.NoError(t, sink.Close())
+		}
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, 2, bytes.Count(b, []byte("\n")))
+	})
+}
+
+func Test_Unit_manager_audit(t *testing.T) {
+	var buf bytes.Buffer
+	m := &manager{options: newOptions([]Option{WithAuditSink(NewJSONAuditSink(&buf))})}
+	o := newCallOptions([]CallOption{WithMetadata("request_id", "42")})
+
+	m.audit(context.Background(), OperationStop, "a.service", o, time.Now(), errors.New("failed"))
+
+	var record AuditRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, OperationStop, record.Operation)
+	require.Equal(t, "a.service", record.Unit)
+	require.Equal(t, map[string]string{"request_id": "42"}, record.Metadata)
+	require.Equal(t, "failed", record.Error)
+}
+
+func Test_Unit_ContextWithMetadata(t *testing.T) {
+	require.Nil(t, MetadataFromContext(context.Background()))
+
+	parent := ContextWithMetadata(context.Background(), "request_id", "42")
+	ctx := ContextWithMetadata(parent, "operator", "alice")
+	require.Equal(t, map[string]string{"request_id": "42"}, MetadataFromContext(parent))
+	require.Equal(t, map[string]string{"request_id": "42", "operator": "alice"}, MetadataFromContext(ctx))
+
+	// Call options take precedence over the context.
+	o := withContextMetadata(ctx, newCallOptions([]CallOption{WithMetadata("operator", "bob")}))
+	require.Equal(t, map[string]string{"request_id": "42", "operator": "bob"}, o.metadata)
+	require.Equal(t, map[string]string{"request_id": "42", "operator": "alice"}, MetadataFromContext(ctx))
+}
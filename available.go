@@ -0,0 +1,85 @@
+package systemdmanager
+
+import (
+	"context"
+	"os"
+	"runtime"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Reason explains why systemd isn't available.
+type Reason string
+
+const (
+	// ReasonNone means systemd is available.
+	ReasonNone Reason = ""
+	// ReasonUnsupportedOS means the operating system isn't Linux, e.g.
+	// macOS.
+	ReasonUnsupportedOS Reason = "unsupported operating system"
+	// ReasonNotBooted means systemd isn't the init system, e.g. in
+	// containers without systemd, or WSL1.
+	ReasonNotBooted Reason = "system not booted with systemd"
+	// ReasonNoBus means the D-Bus bus couldn't be connected to.
+	ReasonNoBus Reason = "D-Bus unreachable"
+	// ReasonNoSystemd means systemd doesn't answer over D-Bus.
+	ReasonNoSystemd Reason = "systemd unreachable over D-Bus"
+)
+
+// bootedPath exists when the system was booted with systemd. See
+// sd_booted(3).
+const bootedPath = "/run/systemd/system"
+
+// Available reports whether a usable systemd D-Bus endpoint exists and, if
+// not, why, so tests and programs can skip or fall back to a fake instead
+// of failing to create a Manager. opts are those New would be given, e.g.
+// WithBusAddress, in which case systemd needn't be the host's init system.
+func Available(ctx context.Context, opts ...Option) (bool, Reason) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "Available")
+	defer span.End()
+
+	reason := available(ctx, runtime.GOOS, booted, newOptions(opts).busAddress)
+	if reason != ReasonNone {
+		span.SetAttributes(otelattr.String("reason", string(reason)))
+		span.SetStatus(otelcodes.Error, string(reason))
+
+		return false, reason
+	}
+	span.SetStatus(otelcodes.Ok, "systemd is available")
+
+	return true, ReasonNone
+}
+
+// available implements Available for the operating system goos, using
+// booted to tell whether the system was booted with systemd.
+func available(ctx context.Context, goos string, booted func() bool, address string) Reason {
+	if address == "" {
+		if goos != "linux" {
+			return ReasonUnsupportedOS
+		}
+		if !booted() {
+			return ReasonNotBooted
+		}
+	}
+
+	c, err := connect(ctx, address)
+	if err != nil {
+		return ReasonNoBus
+	}
+	defer c.Close()
+	if _, err := c.version(ctx); err != nil {
+		return ReasonNoSystemd
+	}
+
+	return ReasonNone
+}
+
+// booted reports whether the system was booted with systemd.
+func booted() bool {
+	fi, err := os.Lstat(bootedPath)
+
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,29 @@
+package systemdmanager
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_available(t *testing.T) {
+	ctx := context.Background()
+	yes := func() bool { return true }
+	no := func() bool { return false }
+
+	require.Equal(t, ReasonUnsupportedOS, available(ctx, "darwin", yes, ""))
+	require.Equal(t, ReasonNotBooted, available(ctx, "linux", no, ""))
+
+	// Buses at an address needn't be the host's.
+	address := "unix:path=" + filepath.Join(t.TempDir(), "nonexisting")
+	require.Equal(t, ReasonNoBus, available(ctx, "darwin", no, address))
+}
+
+func Test_Unit_Available(t *testing.T) {
+	address := "unix:path=" + filepath.Join(t.TempDir(), "nonexisting")
+	ok, reason := Available(context.Background(), WithBusAddress(address))
+	require.False(t, ok)
+	require.Equal(t, ReasonNoBus, reason)
+}
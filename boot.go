@@ -0,0 +1,179 @@
+package systemdmanager
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// BootTimes are the durations of the boot phases, as reported by
+// systemd-analyze. Phases which didn't happen, e.g. firmware and loader in
+// containers, or initrd without one, are zero.
+type BootTimes struct {
+	Firmware  time.Duration `json:"firmware" yaml:"firmware"`
+	Loader    time.Duration `json:"loader" yaml:"loader"`
+	Kernel    time.Duration `json:"kernel" yaml:"kernel"`
+	InitRD    time.Duration `json:"initrd" yaml:"initrd"`
+	Userspace time.Duration `json:"userspace" yaml:"userspace"`
+	// Finished reports whether boot finished. Userspace is zero until it
+	// does.
+	Finished bool `json:"finished" yaml:"finished"`
+}
+
+// Total returns the duration of the whole boot.
+func (b BootTimes) Total() time.Duration {
+	return b.Firmware + b.Loader + b.Kernel + b.InitRD + b.Userspace
+}
+
+// UnitStartup is how long a unit took to activate.
+type UnitStartup struct {
+	Unit     string        `json:"unit" yaml:"unit"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+// BootTimes returns the durations of the boot phases.
+func (m *manager) BootTimes(parentCtx context.Context) (BootTimes, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "BootTimes")
+	defer span.End()
+
+	props, err := m.managerProperties(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve boot times: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return BootTimes{}, err
+	}
+	b := bootTimes(props)
+	span.SetAttributes(otelattr.Bool("finished", b.Finished), otelattr.String("total", b.Total().String()))
+	span.SetStatus(otelcodes.Ok, "retrieved boot times")
+
+	return b, nil
+}
+
+// bootTimes computes the boot phases from the monotonic timestamps of the
+// systemd manager, like systemd-analyze does. Firmware and loader
+// timestamps count backwards from the kernel start.
+func bootTimes(props map[string]godbus.Variant) BootTimes {
+	usec := func(key string) uint64 {
+		v, _ := props[key+"TimestampMonotonic"].Value().(uint64)
+		return v
+	}
+	firmware, loader := usec("Firmware"), usec("Loader")
+	initrd, userspace, finish := usec("InitRD"), usec("Userspace"), usec("Finish")
+
+	var b BootTimes
+	if firmware > loader {
+		b.Firmware = usecToDuration(firmware - loader)
+	}
+	b.Loader = usecToDuration(loader)
+	if initrd > 0 {
+		b.Kernel = usecToDuration(initrd)
+		b.InitRD = usecToDuration(userspace - initrd)
+	} else {
+		b.Kernel = usecToDuration(userspace)
+	}
+	if finish > 0 {
+		b.Finished = true
+		b.Userspace = usecToDuration(finish - userspace)
+	}
+
+	return b
+}
+
+// UnitStartupBlame returns how long units took to activate, slowest first,
+// like systemd-analyze blame. Units which didn't activate since systemd
+// started are omitted.
+func (m *manager) UnitStartupBlame(parentCtx context.Context) ([]UnitStartup, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "UnitStartupBlame")
+	defer span.End()
+
+	blame, err := m.unitStartupBlame(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to compute unit startup times: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetAttributes(otelattr.Int("units", len(blame)))
+	span.SetStatus(otelcodes.Ok, "computed unit startup times")
+
+	return blame, nil
+}
+
+// unitStartupBlame implements UnitStartupBlame.
+func (m *manager) unitStartupBlame(ctx context.Context) ([]UnitStartup, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var statuses []dbus.UnitStatus
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		statuses, err = m.dbusConn.Load().ListUnitsContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var blame []UnitStartup
+	// Leave out units the manager isn't allowed to touch.
+	for _, s := range m.allowedStatuses(statuses) {
+		var props map[string]godbus.Variant
+		err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+			return m.dbusConn.Load().busConn.Object(systemdDest, s.Path).
+				CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, systemdDest+".Unit").
+				Store(&props)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve timestamps of unit %q: %w", s.Name, err)
+		}
+		if d, ok := startupDuration(props); ok {
+			blame = append(blame, UnitStartup{Unit: s.Name, Duration: d})
+		}
+	}
+	slices.SortStableFunc(blame, func(a, b UnitStartup) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+
+	return blame, nil
+}
+
+// startupDuration returns how long a unit took from leaving the inactive
+// state to entering the active one, if it did both.
+func startupDuration(props map[string]godbus.Variant) (time.Duration, bool) {
+	activating, _ := props["InactiveExitTimestampMonotonic"].Value().(uint64)
+	activated, _ := props["ActiveEnterTimestampMonotonic"].Value().(uint64)
+	if activating == 0 || activated < activating {
+		return 0, false
+	}
+
+	return usecToDuration(activated - activating), true
+}
+
+// managerProperties returns all properties of the systemd manager.
+func (m *manager) managerProperties(ctx context.Context) (map[string]godbus.Variant, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var props map[string]godbus.Variant
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().systemd().CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, managerInterface).Store(&props)
+	})
+
+	return props, err
+}
@@ -0,0 +1,59 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_bootTimes(t *testing.T) {
+	usec := func(d time.Duration) godbus.Variant {
+		return godbus.MakeVariant(uint64(d.Microseconds()))
+	}
+
+	// Firmware and loader count backwards from the kernel start.
+	b := bootTimes(map[string]godbus.Variant{
+		"FirmwareTimestampMonotonic":  usec(5 * time.Second),
+		"LoaderTimestampMonotonic":    usec(2 * time.Second),
+		"KernelTimestampMonotonic":    usec(0),
+		"InitRDTimestampMonotonic":    usec(1 * time.Second),
+		"UserspaceTimestampMonotonic": usec(3 * time.Second),
+		"FinishTimestampMonotonic":    usec(10 * time.Second),
+	})
+	require.Equal(t, BootTimes{
+		Firmware:  3 * time.Second,
+		Loader:    2 * time.Second,
+		Kernel:    1 * time.Second,
+		InitRD:    2 * time.Second,
+		Userspace: 7 * time.Second,
+		Finished:  true,
+	}, b)
+	require.Equal(t, 15*time.Second, b.Total())
+
+	// Containers have neither firmware, loader, nor initrd, and boot may not
+	// be finished.
+	b = bootTimes(map[string]godbus.Variant{
+		"UserspaceTimestampMonotonic": usec(time.Second),
+	})
+	require.Equal(t, BootTimes{Kernel: time.Second}, b)
+}
+
+func Test_Unit_startupDuration(t *testing.T) {
+	d, ok := startupDuration(map[string]godbus.Variant{
+		"InactiveExitTimestampMonotonic": godbus.MakeVariant(uint64(1_000_000)),
+		"ActiveEnterTimestampMonotonic":  godbus.MakeVariant(uint64(1_500_000)),
+	})
+	require.True(t, ok)
+	require.Equal(t, 500*time.Millisecond, d)
+
+	// Units which never activated, or are still activating.
+	_, ok = startupDuration(map[string]godbus.Variant{})
+	require.False(t, ok)
+	_, ok = startupDuration(map[string]godbus.Variant{
+		"InactiveExitTimestampMonotonic": godbus.MakeVariant(uint64(2_000_000)),
+		"ActiveEnterTimestampMonotonic":  godbus.MakeVariant(uint64(1_000_000)),
+	})
+	require.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package systemdmanager
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// maxCachedProperties is how many cached values are kept before expired ones
+// are dropped.
+const maxCachedProperties = 4096
+
+// WithPropertyCache caches unit statuses and properties read by Status,
+// Uptime, and GetProperty for ttl, which saves D-Bus round-trips when they
+// are read often, e.g. by metrics scrapers. Cached values of a unit are
+// invalidated when an operation on it completes, and when a watch observes
+// it changing. Zero, the default, disables caching.
+func WithPropertyCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+// cacheKey identifies a cached value. The status of a unit has an empty
+// interface and property.
+type cacheKey struct {
+	unit  string
+	iface string
+	prop  string
+}
+
+// cachedValue is a cached value and when it expires.
+type cachedValue struct {
+	value   any
+	expires time.Time
+}
+
+// propertyCache caches unit statuses and properties.
+type propertyCache struct {
+	mutex   sync.Mutex
+	entries map[cacheKey]cachedValue
+	now     func() time.Time
+}
+
+// get returns the cached value of key, if any and not expired.
+func (c *propertyCache) get(key cacheKey) (any, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.time().Before(entry.expires) {
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// put caches value for ttl. It does nothing if ttl isn't positive.
+func (c *propertyCache) put(key cacheKey, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.time()
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]cachedValue)
+	}
+	// Bound memory by dropping expired values, or all of them if none are.
+	if len(c.entries) >= maxCachedProperties {
+		maps.DeleteFunc(c.entries, func(_ cacheKey, entry cachedValue) bool {
+			return !now.Before(entry.expires)
+		})
+		if len(c.entries) >= maxCachedProperties {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = cachedValue{value: value, expires: now.Add(ttl)}
+}
+
+// invalidate drops the cached values of the named unit.
+func (c *propertyCache) invalidate(unit string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	maps.DeleteFunc(c.entries, func(key cacheKey, _ cachedValue) bool {
+		return key.unit == unit
+	})
+}
+
+// time returns the current time. The mutex must be held.
+func (c *propertyCache) time() time.Time {
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	return c.now()
+}
@@ -0,0 +1,54 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_propertyCache(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := propertyCache{now: func() time.Time { return now }}
+	status := cacheKey{unit: "a.service"}
+	uptime := cacheKey{unit: "a.service", iface: "org.freedesktop.systemd1.Service", prop: "ExecMainStartTimestamp"}
+	other := cacheKey{unit: "b.service"}
+
+	t.Run("Expires", func(t *testing.T) {
+		c.put(status, "active", time.Second)
+		v, ok := c.get(status)
+		require.True(t, ok)
+		require.Equal(t, "active", v)
+
+		now = now.Add(time.Second)
+		_, ok = c.get(status)
+		require.False(t, ok)
+	})
+
+	t.Run("Invalidates unit", func(t *testing.T) {
+		c.put(status, "active", time.Minute)
+		c.put(uptime, uint64(1), time.Minute)
+		c.put(other, "active", time.Minute)
+		c.invalidate("a.service")
+
+		_, ok := c.get(status)
+		require.False(t, ok)
+		_, ok = c.get(uptime)
+		require.False(t, ok)
+		_, ok = c.get(other)
+		require.True(t, ok)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		c.put(status, "active", 0)
+		_, ok := c.get(status)
+		require.False(t, ok)
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		for i := range maxCachedProperties + 1 {
+			c.put(cacheKey{unit: "a.service", prop: string(rune(i))}, i, time.Minute)
+		}
+		require.LessOrEqual(t, len(c.entries), maxCachedProperties)
+	})
+}
@@ -0,0 +1,211 @@
+package systemdmanager
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrCanaryFailed means canaries failed or were restarted by systemd more
+// often than tolerated while observed.
+var ErrCanaryFailed = errors.New("canary failed")
+
+// CanaryOptions configures CanaryRestart.
+type CanaryOptions struct {
+	// MaxFailures is how many canaries may fail, or be restarted by
+	// systemd, during the observation window before the restart is aborted.
+	// Defaults to zero.
+	MaxFailures int
+	// Rollback, if not nil, is called with the canaries when aborting, to
+	// restore the previous configuration of the units, e.g. by removing a
+	// drop-in. The canaries are then restarted again, to run with it.
+	Rollback func(ctx context.Context, canaries []string) error
+}
+
+// CanaryResult describes how far a canary restart went.
+type CanaryResult struct {
+	// Canaries lists the units restarted first.
+	Canaries []string
+	// Failed lists the canaries that failed to restart, or failed or were
+	// restarted by systemd during the observation window.
+	Failed []string
+	// Restarted lists the units that were restarted and are active.
+	Restarted []string
+	// Skipped lists the units that weren't restarted because the restart
+	// was aborted.
+	Skipped []string
+	// RolledBack is whether Rollback was called and the canaries restarted
+	// again.
+	RolledBack bool
+}
+
+// CanaryRestart restarts the first canaryCount units, and observes them for
+// observationWindow. If no more than MaxFailures of them fail, or are
+// restarted by systemd, e.g. due to Restart=on-failure, the other units are
+// restarted one at a time. Otherwise, the restart is aborted with
+// ErrCanaryFailed, after rolling back the canaries if a Rollback hook is
+// set.
+func (m *manager) CanaryRestart(parentCtx context.Context, units []string, canaryCount int, observationWindow time.Duration, opts CanaryOptions) (*CanaryResult, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "CanaryRestart")
+	span.SetAttributes(
+		otelattr.StringSlice("units", units),
+		otelattr.Int("canaries", canaryCount),
+		otelattr.String("observation_window", observationWindow.String()),
+	)
+	defer span.End()
+
+	result, err := m.canaryRestart(ctx, units, canaryCount, observationWindow, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return result, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully restarted %d units", len(result.Restarted)))
+
+	return result, nil
+}
+
+// canaryRestart implements CanaryRestart.
+func (m *manager) canaryRestart(ctx context.Context, units []string, canaryCount int, observationWindow time.Duration, opts CanaryOptions) (*CanaryResult, error) {
+	if len(units) == 0 {
+		return nil, fmt.Errorf("at least one unit is required for a canary restart")
+	}
+	for _, unit := range units {
+		if err := m.validateUnit(unit); err != nil {
+			return nil, err
+		}
+	}
+	canaryCount = min(max(canaryCount, 1), len(units))
+	canaries, rest := units[:canaryCount], units[canaryCount:]
+	result := &CanaryResult{Canaries: canaries}
+
+	// abort skips the other units, rolling the canaries back if possible.
+	abort := func(err error) (*CanaryResult, error) {
+		result.Skipped = append(result.Skipped, rest...)
+		err = fmt.Errorf("aborted canary restart: %w", err)
+		if opts.Rollback == nil {
+			return result, err
+		}
+		if rollbackErr := opts.Rollback(ctx, canaries); rollbackErr != nil {
+			return result, errors.Join(err, fmt.Errorf("failed to roll back canaries: %w", rollbackErr))
+		}
+		var errs []error
+		for _, unit := range canaries {
+			if restartErr := m.Restart(ctx, unit); restartErr != nil {
+				errs = append(errs, restartErr)
+			}
+		}
+		result.RolledBack = len(errs) == 0
+
+		return result, errors.Join(append([]error{err}, errs...)...)
+	}
+
+	var errs []error
+	invocations := make(map[string]string, len(canaries))
+	for _, unit := range canaries {
+		if err := m.restartAndWaitHealthy(ctx, unit, RollingOptions{}); err != nil {
+			result.Failed = append(result.Failed, unit)
+			errs = append(errs, err)
+			continue
+		}
+		id, err := m.invocationID(ctx, unit)
+		if err != nil {
+			return abort(err)
+		}
+		invocations[unit] = id
+	}
+	failed, err := m.observeCanaries(ctx, invocations, observationWindow)
+	if err != nil {
+		return abort(err)
+	}
+	result.Failed = append(result.Failed, failed...)
+	if len(result.Failed) > opts.MaxFailures {
+		errs = append([]error{fmt.Errorf("%w: %d of %d canaries failed", ErrCanaryFailed, len(result.Failed), len(canaries))}, errs...)
+		return abort(errors.Join(errs...))
+	}
+	for _, unit := range canaries {
+		if _, ok := invocations[unit]; ok && !slices.Contains(failed, unit) {
+			result.Restarted = append(result.Restarted, unit)
+		}
+	}
+
+	for i, unit := range rest {
+		if err := m.restartAndWaitHealthy(ctx, unit, RollingOptions{}); err != nil {
+			result.Failed = append(result.Failed, unit)
+			result.Skipped = append(result.Skipped, rest[i+1:]...)
+
+			return result, fmt.Errorf("aborted canary restart: %w", err)
+		}
+		result.Restarted = append(result.Restarted, unit)
+	}
+
+	return result, nil
+}
+
+// observeCanaries polls the canaries for window, and returns those that
+// failed, or were restarted by systemd, which changes their invocation ID.
+func (m *manager) observeCanaries(ctx context.Context, invocations map[string]string, window time.Duration) ([]string, error) {
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	ticker := time.NewTicker(activePollInterval)
+	defer ticker.Stop()
+
+	var failed []string
+	for {
+		select {
+		case <-ctx.Done():
+			return failed, ctx.Err()
+		case <-timer.C:
+			return failed, nil
+		case <-ticker.C:
+		}
+
+		for unit, id := range invocations {
+			if slices.Contains(failed, unit) {
+				continue
+			}
+			state, err := m.activeState(ctx, unit)
+			if err != nil {
+				return failed, err
+			}
+			current, err := m.invocationID(ctx, unit)
+			if err != nil {
+				return failed, err
+			}
+			if state == "failed" || current != id {
+				failed = append(failed, unit)
+			}
+		}
+	}
+}
+
+// invocationID returns the InvocationID property of the named unit, which
+// changes every time the unit is started, as a hex string.
+func (m *manager) invocationID(ctx context.Context, unit string) (string, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return "", ErrDisconnected
+	}
+
+	var p *dbus.Property
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		p, err = m.dbusConn.Load().GetUnitPropertyContext(ctx, unit, "InvocationID")
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve property %q of unit %q: %w", "InvocationID", unit, err)
+	}
+	id, _ := p.Value.Value().([]byte)
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,32 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_CanaryRestart_InvalidArguments(t *testing.T) {
+	m := &manager{}
+
+	_, err := m.CanaryRestart(context.Background(), nil, 1, time.Second, CanaryOptions{})
+	require.Error(t, err)
+	_, err = m.CanaryRestart(context.Background(), []string{"a.service", "b"}, 1, time.Second, CanaryOptions{})
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
+
+func Test_Unit_observeCanaries_Window(t *testing.T) {
+	// Without canaries, observing only waits for the window.
+	start := time.Now()
+	failed, err := (&manager{}).observeCanaries(context.Background(), nil, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, failed)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&manager{}).observeCanaries(ctx, nil, time.Hour)
+	require.ErrorIs(t, err, context.Canceled)
+}
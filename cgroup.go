@@ -0,0 +1,87 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrNoControlGroup means a unit has no control group, because it isn't
+// running.
+var ErrNoControlGroup = errors.New("unit has no control group")
+
+// cgroupRoot is where the unified cgroup hierarchy is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupTypes are the unit types with control groups.
+var cgroupTypes = []string{"service", "slice", "scope", "socket", "mount", "swap"}
+
+// ControlGroupPath returns the control group of the named unit, relative to
+// the cgroup hierarchy, e.g. "/system.slice/foo.service". It's empty if the
+// unit isn't running. Only services, slices, scopes, sockets, mounts, and
+// swaps have control groups; it fails with ErrWrongUnitType for other units.
+func (m *manager) ControlGroupPath(parentCtx context.Context, unit string) (string, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ControlGroupPath")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	path, err := m.controlGroupPath(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	span.SetAttributes(otelattr.String("path", path))
+	span.SetStatus(otelcodes.Ok, "retrieved unit control group")
+
+	return path, nil
+}
+
+// controlGroupPath implements ControlGroupPath.
+func (m *manager) controlGroupPath(ctx context.Context, unit string) (string, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return "", err
+	}
+	if !slices.Contains(cgroupTypes, unitType(unit)) {
+		return "", fmt.Errorf("%w: unit %q is a %s, which has no control group", ErrWrongUnitType, unit, unitType(unit))
+	}
+
+	return getProperty[string](ctx, m, unit, unitTypeInterface(unit), "ControlGroup")
+}
+
+// OpenControlGroup opens the cgroupfs directory of the control group of the
+// named unit, to read cgroup files systemd doesn't expose as properties,
+// such as io.stat or cpu.stat. It fails with ErrNoControlGroup if the unit
+// isn't running. Only the unified cgroup hierarchy, cgroup v2, is
+// supported. The caller must close the returned root.
+func OpenControlGroup(ctx context.Context, m Manager, unit string) (*os.Root, error) {
+	path, err := m.ControlGroupPath(ctx, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	return openControlGroup(cgroupRoot, unit, path)
+}
+
+// openControlGroup opens the control group at path in the hierarchy mounted
+// at root.
+func openControlGroup(root, unit, path string) (*os.Root, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: %q", ErrNoControlGroup, unit)
+	}
+	dir, err := os.OpenRoot(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control group of unit %q: %w", unit, err)
+	}
+
+	return dir, nil
+}
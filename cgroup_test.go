@@ -0,0 +1,37 @@
+package systemdmanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_controlGroupPath_WrongUnitType(t *testing.T) {
+	_, err := (&manager{}).ControlGroupPath(context.Background(), "a.timer")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
+
+func Test_Unit_openControlGroup(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "system.slice", "a.service")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 42\n"), 0o644))
+
+	cgroup, err := openControlGroup(root, "a.service", "/system.slice/a.service")
+	require.NoError(t, err)
+	defer cgroup.Close()
+	b, err := cgroup.ReadFile("cpu.stat")
+	require.NoError(t, err)
+	require.Equal(t, "usage_usec 42\n", string(b))
+	// Files outside the control group can't be reached.
+	_, err = cgroup.ReadFile("../../cpu.stat")
+	require.Error(t, err)
+
+	_, err = openControlGroup(root, "a.service", "")
+	require.ErrorIs(t, err, ErrNoControlGroup)
+	_, err = openControlGroup(root, "b.service", "/system.slice/b.service")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
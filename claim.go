@@ -0,0 +1,371 @@
+package systemdmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrUnitClaimed means a unit is claimed by another owner.
+var ErrUnitClaimed = errors.New("unit claimed by another owner")
+
+// ClaimStorage is where claims are kept.
+type ClaimStorage int
+
+const (
+	// ClaimStorageMemory keeps claims in the manager, which only it sees,
+	// until it's gone. This is the default.
+	ClaimStorageMemory ClaimStorage = iota
+	// ClaimStorageDropIn keeps claims as a comment in a drop-in of the
+	// claimed unit, which all managers on the host installing units to the
+	// same directory see.
+	ClaimStorageDropIn
+	// ClaimStorageFile keeps claims in the JSON state file of the policy,
+	// which all managers configured with it see.
+	ClaimStorageFile
+)
+
+// claimDropIn is the name of the drop-in holding the claim of a unit, with
+// ClaimStorageDropIn.
+const claimDropIn = "50-systemdmanager-claim"
+
+// claimComment prefixes the owner in the claim drop-in.
+const claimComment = "# systemdmanager-owner: "
+
+// ClaimPolicy controls how operations on units claimed by another owner are
+// handled, which keeps automation agents sharing a host from stepping on
+// each other.
+type ClaimPolicy struct {
+	// Owner is who operations of the manager run as. Operations on units
+	// claimed by other owners are refused with ErrUnitClaimed.
+	Owner string
+	// Warn, if not nil, lets operations on units claimed by other owners
+	// run, calling Warn instead of failing with ErrUnitClaimed.
+	Warn func(op Operation, unit, owner string)
+	// Storage is where claims are kept. Defaults to ClaimStorageMemory.
+	Storage ClaimStorage
+	// StateFile is the path of the file claims are kept in with
+	// ClaimStorageFile.
+	StateFile string
+}
+
+// WithClaimPolicy sets who operations of the manager run as, and how
+// operations on units claimed by others are handled.
+func WithClaimPolicy(policy ClaimPolicy) Option {
+	return func(o *options) {
+		o.claimPolicy = policy
+	}
+}
+
+// Claim records owner as the owner of the named unit, after which
+// operations of managers running as other owners are refused, or warned
+// about, as set by their ClaimPolicy. Claiming a unit already claimed by
+// owner is a no-op. It fails with ErrUnitClaimed if the unit is claimed by
+// another owner, which has to Release it first.
+func (m *manager) Claim(parentCtx context.Context, unit, owner string) error {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Claim")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("owner", owner))
+	defer span.End()
+
+	if owner == "" {
+		err := fmt.Errorf("an owner is required to claim unit %q", unit)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if err := m.setClaim(unit, "", owner); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("claimed unit %q", unit))
+
+	return nil
+}
+
+// Release removes the claim of owner on the named unit. Releasing an
+// unclaimed unit is a no-op. It fails with ErrUnitClaimed if the unit is
+// claimed by another owner.
+func (m *manager) Release(parentCtx context.Context, unit, owner string) error {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Release")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("owner", owner))
+	defer span.End()
+
+	if err := m.setClaim(unit, owner, ""); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("released unit %q", unit))
+
+	return nil
+}
+
+// ClaimedBy returns the owner of the named unit, which is empty if the unit
+// isn't claimed.
+func (m *manager) ClaimedBy(parentCtx context.Context, unit string) (string, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "ClaimedBy")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	if err := m.validateUnit(unit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	m.claims.mutex.Lock()
+	owner, err := m.claimOwner(unit)
+	m.claims.mutex.Unlock()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	span.SetAttributes(otelattr.String("owner", owner))
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("retrieved owner of unit %q", unit))
+
+	return owner, nil
+}
+
+// checkClaim admits an operation on the named unit if it isn't claimed by
+// another owner than the one of the policy, or if the policy only warns.
+func (m *manager) checkClaim(op Operation, unit string) error {
+	m.claims.mutex.Lock()
+	owner, err := m.claimOwner(unit)
+	m.claims.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	policy := m.options.claimPolicy
+	if owner == "" || owner == policy.Owner {
+		return nil
+	}
+	if policy.Warn != nil {
+		policy.Warn(op, unit, owner)
+		return nil
+	}
+
+	return fmt.Errorf("unit %q is claimed by %q: %w", unit, owner, ErrUnitClaimed)
+}
+
+// checkClaims admits a change to the named units made outside of jobs, such
+// as writing their unit files, if none is claimed by another owner than the
+// one of the policy, or if the policy only warns.
+func (m *manager) checkClaims(units ...string) error {
+	for _, unit := range units {
+		if err := m.checkClaim(OperationConfigure, unit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claims holds the claims kept with ClaimStorageMemory, and serializes
+// changes to claims of all storages.
+type claims struct {
+	mutex sync.Mutex
+	// owners holds the owners of claimed units, by unit.
+	owners map[string]string
+}
+
+// setClaim replaces the owner of the named unit, which must be from, with
+// to. An empty owner means the unit isn't claimed.
+func (m *manager) setClaim(unit, from, to string) error {
+	if err := m.validateUnit(unit); err != nil {
+		return err
+	}
+
+	m.claims.mutex.Lock()
+	defer m.claims.mutex.Unlock()
+	// Other processes share the state file, and must not change it between
+	// checking and recording the claim.
+	if m.options.claimPolicy.Storage == ClaimStorageFile {
+		unlock, err := m.lockClaimFile()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	owner, err := m.claimOwner(unit)
+	if err != nil {
+		return err
+	}
+	if owner == to {
+		return nil
+	}
+	if owner != from {
+		return fmt.Errorf("unit %q is claimed by %q: %w", unit, owner, ErrUnitClaimed)
+	}
+
+	switch m.options.claimPolicy.Storage {
+	case ClaimStorageDropIn:
+		return m.writeClaimDropIn(unit, to)
+	case ClaimStorageFile:
+		return m.writeClaimFile(unit, to)
+	default:
+		if m.claims.owners == nil {
+			m.claims.owners = make(map[string]string)
+		}
+		if to == "" {
+			delete(m.claims.owners, unit)
+		} else {
+			m.claims.owners[unit] = to
+		}
+
+		return nil
+	}
+}
+
+// claimOwner returns the owner of the named unit, which is empty if the
+// unit isn't claimed. The caller must hold the claims mutex.
+func (m *manager) claimOwner(unit string) (string, error) {
+	switch m.options.claimPolicy.Storage {
+	case ClaimStorageDropIn:
+		return m.readClaimDropIn(unit)
+	case ClaimStorageFile:
+		owners, err := m.readClaimFile()
+		return owners[unit], err
+	default:
+		return m.claims.owners[unit], nil
+	}
+}
+
+// readClaimDropIn returns the owner recorded in the claim drop-in of the
+// named unit, if any.
+func (m *manager) readClaimDropIn(unit string) (string, error) {
+	content, err := os.ReadFile(m.dropInPath(unit, claimDropIn))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read claim of unit %q: %w", unit, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if owner, ok := strings.CutPrefix(scanner.Text(), claimComment); ok {
+			return owner, nil
+		}
+	}
+
+	return "", nil
+}
+
+// writeClaimDropIn records owner in the claim drop-in of the named unit, or
+// removes the drop-in if owner is empty. The drop-in only holds a comment,
+// so systemd doesn't need to be reloaded.
+func (m *manager) writeClaimDropIn(unit, owner string) error {
+	if owner == "" {
+		return m.removeDropIn(unit, claimDropIn)
+	}
+	if strings.ContainsAny(owner, "\r\n") {
+		return fmt.Errorf("invalid owner %q: must be a single line", owner)
+	}
+
+	path := m.dropInPath(unit, claimDropIn)
+	content := []byte(claimComment + owner + "\n")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to claim unit %q: %w", unit, err)
+	}
+	if err := writeFileAtomic(path, content); err != nil {
+		return fmt.Errorf("failed to claim unit %q: %w", unit, err)
+	}
+	m.writes.record(path, content)
+
+	return nil
+}
+
+// readClaimFile returns the owners recorded in the state file of the
+// policy, by unit.
+func (m *manager) readClaimFile() (map[string]string, error) {
+	path := m.options.claimPolicy.StateFile
+	if path == "" {
+		return nil, fmt.Errorf("a state file is required to keep claims in")
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read claims: %w", err)
+	}
+	owners := map[string]string{}
+	if err := json.Unmarshal(content, &owners); err != nil {
+		return nil, fmt.Errorf("failed to read claims from %q: %w", path, err)
+	}
+
+	return owners, nil
+}
+
+// lockClaimFile blocks until it holds an exclusive lock on the state file of
+// the policy, shared with other processes, returning the function releasing
+// it. The lock is taken on a sibling file, as the state file is replaced on
+// every write.
+func (m *manager) lockClaimFile() (func(), error) {
+	path := m.options.claimPolicy.StateFile
+	if path == "" {
+		return nil, fmt.Errorf("a state file is required to keep claims in")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to lock claims in %q: %w", path, err)
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock claims in %q: %w", path, err)
+	}
+	if err := flock(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock claims in %q: %w", path, err)
+	}
+
+	return func() { f.Close() }, nil
+}
+
+// writeClaimFile records owner as the owner of the named unit in the state
+// file of the policy, or removes the claim if owner is empty.
+func (m *manager) writeClaimFile(unit, owner string) error {
+	owners, err := m.readClaimFile()
+	if err != nil {
+		return err
+	}
+	if owner == "" {
+		delete(owners, unit)
+	} else {
+		owners[unit] = owner
+	}
+	content, err := json.MarshalIndent(owners, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := m.options.claimPolicy.StateFile
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write claims to %q: %w", path, err)
+	}
+	if err := writeFileAtomic(path, append(content, '\n')); err != nil {
+		return fmt.Errorf("failed to write claims to %q: %w", path, err)
+	}
+
+	return nil
+}
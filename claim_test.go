@@ -0,0 +1,139 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Claim(t *testing.T) {
+	dir := t.TempDir()
+	for _, policy := range []ClaimPolicy{
+		{Owner: "agent-a"},
+		{Owner: "agent-a", Storage: ClaimStorageDropIn},
+		{Owner: "agent-a", Storage: ClaimStorageFile, StateFile: filepath.Join(dir, "state", "claims.json")},
+	} {
+		ctx := context.Background()
+		m := &manager{options: options{unitDirectory: dir, claimPolicy: policy}}
+
+		owner, err := m.ClaimedBy(ctx, "a.service")
+		require.NoError(t, err)
+		require.Empty(t, owner)
+		require.NoError(t, m.checkClaim(OperationStart, "a.service"))
+
+		// Claiming is idempotent, but claims can't be stolen.
+		require.NoError(t, m.Claim(ctx, "a.service", "agent-b"))
+		require.NoError(t, m.Claim(ctx, "a.service", "agent-b"))
+		require.ErrorIs(t, m.Claim(ctx, "a.service", "agent-a"), ErrUnitClaimed)
+		owner, err = m.ClaimedBy(ctx, "a.service")
+		require.NoError(t, err)
+		require.Equal(t, "agent-b", owner)
+
+		// Operations of other owners are refused.
+		require.ErrorIs(t, m.checkClaim(OperationStart, "a.service"), ErrUnitClaimed)
+		require.NoError(t, m.checkClaim(OperationStart, "b.service"))
+		require.NoError(t, m.Claim(ctx, "b.service", "agent-a"))
+		require.NoError(t, m.checkClaim(OperationStart, "b.service"))
+
+		require.ErrorIs(t, m.Release(ctx, "a.service", "agent-a"), ErrUnitClaimed)
+		require.NoError(t, m.Release(ctx, "a.service", "agent-b"))
+		require.NoError(t, m.Release(ctx, "a.service", "agent-b"))
+		require.NoError(t, m.checkClaim(OperationStart, "a.service"))
+		require.NoError(t, m.Release(ctx, "b.service", "agent-a"))
+	}
+}
+
+func Test_Unit_Claim_Persisted(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	policy := ClaimPolicy{Owner: "agent-a", Storage: ClaimStorageDropIn}
+	m := &manager{options: options{unitDirectory: dir, claimPolicy: policy}}
+	require.NoError(t, m.Claim(ctx, "a.service", "agent-b"))
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.service.d", claimDropIn+".conf"))
+	require.NoError(t, err)
+	require.Equal(t, "# systemdmanager-owner: agent-b\n", string(content))
+	require.Error(t, m.Claim(ctx, "b.service", "agent\nb"))
+
+	// Other managers see the claim.
+	other := &manager{options: options{unitDirectory: dir, claimPolicy: policy}}
+	owner, err := other.ClaimedBy(ctx, "a.service")
+	require.NoError(t, err)
+	require.Equal(t, "agent-b", owner)
+}
+
+func Test_Unit_checkClaim_Warn(t *testing.T) {
+	var warned []string
+	m := &manager{options: options{claimPolicy: ClaimPolicy{
+		Owner: "agent-a",
+		Warn: func(op Operation, unit, owner string) {
+			warned = append(warned, string(op)+" "+unit+" "+owner)
+		},
+	}}}
+	require.NoError(t, m.Claim(context.Background(), "a.service", "agent-b"))
+
+	require.NoError(t, m.checkClaim(OperationRestart, "a.service"))
+	require.Equal(t, []string{"restart a.service agent-b"}, warned)
+}
+
+func Test_Unit_Claim_InvalidArguments(t *testing.T) {
+	m := &manager{}
+	require.ErrorIs(t, m.Claim(context.Background(), "a", "agent-a"), ErrInvalidUnitName)
+	require.Error(t, m.Claim(context.Background(), "a.service", ""))
+	_, err := m.ClaimedBy(context.Background(), "a")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+
+	// The state file is required to keep claims in one.
+	m.options.claimPolicy.Storage = ClaimStorageFile
+	require.Error(t, m.Claim(context.Background(), "a.service", "agent-a"))
+}
+
+func Test_Unit_Claim_ConcurrentStateFile(t *testing.T) {
+	ctx := context.Background()
+	policy := ClaimPolicy{Owner: "agent-a", Storage: ClaimStorageFile, StateFile: filepath.Join(t.TempDir(), "claims.json")}
+
+	// Managers sharing the state file, as separate processes would, don't
+	// overwrite each other's claims.
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			m := &manager{options: options{claimPolicy: policy}}
+			require.NoError(t, m.Claim(ctx, fmt.Sprintf("unit-%d.service", i), "agent-b"))
+		})
+	}
+	wg.Wait()
+
+	m := &manager{options: options{claimPolicy: policy}}
+	owners, err := m.readClaimFile()
+	require.NoError(t, err)
+	require.Len(t, owners, 20)
+}
+
+func Test_Unit_Claim_UnitFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	m := &manager{options: options{unitDirectory: dir, claimPolicy: ClaimPolicy{Owner: "agent-a"}}}
+	require.NoError(t, m.Claim(ctx, "a.service", "agent-b"))
+	original := []byte("[Service]\nExecStart=/bin/true\n")
+	require.NoError(t, os.WriteFile(m.unitPath("a.service"), original, 0o644))
+
+	// Files of units claimed by other owners are left untouched.
+	fsys := fstest.MapFS{"a.service": {Data: []byte("[Service]\nExecStart=/bin/false\n")}}
+	require.ErrorIs(t, m.InstallFromFS(ctx, fsys), ErrUnitClaimed)
+	content, err := os.ReadFile(m.unitPath("a.service"))
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+
+	require.ErrorIs(t, m.SetUnitEnvironment(ctx, "a.service", map[string]string{"FOO": "bar"}, false), ErrUnitClaimed)
+	require.NoFileExists(t, m.dropInPath("a.service", environmentDropIn))
+	require.ErrorIs(t, m.SetFailureHandler(ctx, "a.service", "alert.service"), ErrUnitClaimed)
+	require.NoFileExists(t, m.dropInPath("a.service", failureHandlerDropIn))
+	_, err = m.restoreUnitFileState(ctx, "a.service", UnitFileDisabled)
+	require.ErrorIs(t, err, ErrUnitClaimed)
+}
@@ -0,0 +1,64 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Unit_manager_concurrency exercises the state shared by operations
+// concurrently, for the race detector to catch unguarded accesses.
+func Test_Unit_manager_concurrency(t *testing.T) {
+	ctx := context.Background()
+	notifications := make(chan Notification)
+	m := &manager{options: newOptions([]Option{
+		WithEventSink(ChannelSink(notifications)),
+		WithHistory(8),
+		WithPropertyCache(time.Minute),
+		WithRateLimits(RateLimits{PerUnit: Limit{Every: time.Microsecond, Burst: 1}, Wait: true}),
+	})}
+	go func() {
+		for range notifications {
+		}
+	}()
+	defer close(notifications)
+
+	const goroutines, iterations = 16, 100
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		unit := fmt.Sprintf("unit-%d.service", g%4)
+		wg.Go(func() {
+			for i := range iterations {
+				// Register hooks while others run them.
+				m.OnBeforeStart(func(context.Context, Operation, string) error { return nil })
+				require.NoError(t, m.runHooksBefore(ctx, OperationRestart, unit))
+				require.NoError(t, m.runHooksAfter(ctx, OperationRestart, unit))
+
+				// Serialize on the unit, as operations do.
+				require.NoError(t, m.rateLimiter.take(ctx, m.options.rateLimits, unit))
+				unlock, err := m.unitLocks.lock(ctx, unit)
+				require.NoError(t, err)
+				m.cache.invalidate(unit)
+				unlock()
+
+				// Watch events and reads.
+				status := &dbus.UnitStatus{Name: unit, ActiveState: []string{"active", "inactive"}[i%2]}
+				m.cache.put(cacheKey{unit: unit}, *status, m.options.cacheTTL)
+				m.history.record(unit, status, m.options.historySize)
+				m.publishStatus(ctx, NotificationStatus, unit, status)
+				m.cache.get(cacheKey{unit: unit})
+				m.History(unit)
+			}
+		})
+	}
+	wg.Wait()
+
+	for g := range 4 {
+		require.Len(t, m.History(fmt.Sprintf("unit-%d.service", g)), 8)
+	}
+}
@@ -0,0 +1,114 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Condition is a condition or assertion of a unit, such as
+// ConditionPathExists=, as last checked by systemd.
+type Condition struct {
+	// Type is the setting, e.g. "ConditionPathExists".
+	Type string `json:"type" yaml:"type"`
+	// Trigger is set for triggering conditions, prefixed with "|", of
+	// which only one needs to pass.
+	Trigger bool `json:"trigger" yaml:"trigger"`
+	// Negate is set for negated conditions, prefixed with "!".
+	Negate    bool   `json:"negate" yaml:"negate"`
+	Parameter string `json:"parameter" yaml:"parameter"`
+	// State is positive when the condition passed, negative when it
+	// failed, and zero when it wasn't checked.
+	State int32 `json:"state" yaml:"state"`
+}
+
+// Passed returns whether the condition was checked and passed.
+func (c Condition) Passed() bool {
+	return c.State > 0
+}
+
+// Failed returns whether the condition was checked and failed.
+func (c Condition) Failed() bool {
+	return c.State < 0
+}
+
+// UnitConditions are the results of the last check of a unit's conditions
+// and assertions, which happens when the unit is started. A unit whose
+// conditions fail is skipped without error, and one whose assertions fail
+// fails to start.
+type UnitConditions struct {
+	ConditionResult    bool        `json:"condition_result" yaml:"condition_result"`
+	ConditionTimestamp time.Time   `json:"condition_timestamp" yaml:"condition_timestamp"`
+	Conditions         []Condition `json:"conditions" yaml:"conditions"`
+	AssertResult       bool        `json:"assert_result" yaml:"assert_result"`
+	AssertTimestamp    time.Time   `json:"assert_timestamp" yaml:"assert_timestamp"`
+	Asserts            []Condition `json:"asserts" yaml:"asserts"`
+}
+
+// Failed returns the conditions and assertions that failed.
+func (c UnitConditions) Failed() []Condition {
+	var failed []Condition
+	for _, condition := range append(c.Conditions[:len(c.Conditions):len(c.Conditions)], c.Asserts...) {
+		if condition.Failed() {
+			failed = append(failed, condition)
+		}
+	}
+
+	return failed
+}
+
+// Conditions returns the results of the last check of the named unit's
+// conditions and assertions, which explain why a unit that was started
+// successfully isn't active.
+func (m *manager) Conditions(parentCtx context.Context, unit string) (UnitConditions, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Conditions")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.unitProperties(ctx, unit, "Unit")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitConditions{}, err
+	}
+	c, err := decodeConditions(props)
+	if err != nil {
+		err = fmt.Errorf("failed to decode conditions of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitConditions{}, err
+	}
+	span.SetAttributes(otelattr.Bool("condition_result", c.ConditionResult), otelattr.Bool("assert_result", c.AssertResult))
+	span.SetStatus(otelcodes.Ok, "retrieved unit conditions")
+
+	return c, nil
+}
+
+// decodeConditions decodes the condition properties of a unit.
+func decodeConditions(props map[string]godbus.Variant) (UnitConditions, error) {
+	var (
+		c    UnitConditions
+		errs = make([]error, 6)
+	)
+	c.ConditionResult, errs[0] = decodeProperty[bool](props["ConditionResult"])
+	c.ConditionTimestamp, errs[1] = decodeProperty[time.Time](props["ConditionTimestamp"])
+	c.Conditions, errs[2] = decodeProperty[[]Condition](props["Conditions"])
+	c.AssertResult, errs[3] = decodeProperty[bool](props["AssertResult"])
+	c.AssertTimestamp, errs[4] = decodeProperty[time.Time](props["AssertTimestamp"])
+	c.Asserts, errs[5] = decodeProperty[[]Condition](props["Asserts"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitConditions{}, err
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,46 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeConditions(t *testing.T) {
+	props := map[string]godbus.Variant{
+		"ConditionResult":    godbus.MakeVariant(false),
+		"ConditionTimestamp": godbus.MakeVariant(uint64(1700000000000000)),
+		"Conditions": godbus.MakeVariant([][]any{
+			{"ConditionPathExists", false, false, "/etc/foo", int32(-1)},
+			{"ConditionUser", false, true, "root", int32(1)},
+			{"ConditionHost", true, false, "bar", int32(0)},
+		}),
+		"AssertResult":    godbus.MakeVariant(true),
+		"AssertTimestamp": godbus.MakeVariant(uint64(0)),
+		"Asserts":         godbus.MakeVariant([][]any{}),
+	}
+
+	c, err := decodeConditions(props)
+	require.NoError(t, err)
+	require.False(t, c.ConditionResult)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), c.ConditionTimestamp)
+	require.True(t, c.AssertResult)
+	require.True(t, c.AssertTimestamp.IsZero())
+	require.Empty(t, c.Asserts)
+	require.Equal(t, []Condition{
+		{Type: "ConditionPathExists", Parameter: "/etc/foo", State: -1},
+		{Type: "ConditionUser", Negate: true, Parameter: "root", State: 1},
+		{Type: "ConditionHost", Trigger: true, Parameter: "bar"},
+	}, c.Conditions)
+
+	require.Equal(t, []Condition{c.Conditions[0]}, c.Failed())
+	require.True(t, c.Conditions[1].Passed())
+	require.False(t, c.Conditions[2].Passed())
+	require.False(t, c.Conditions[2].Failed())
+
+	delete(props, "Conditions")
+	_, err = decodeConditions(props)
+	require.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+)
+
+const (
+	// systemdDest is the D-Bus destination of the systemd manager.
+	systemdDest = "org.freedesktop.systemd1"
+	// systemdPath is the D-Bus object path of the systemd manager.
+	systemdPath godbus.ObjectPath = "/org/freedesktop/systemd1"
+	// managerInterface is the D-Bus interface of the systemd manager.
+	managerInterface = "org.freedesktop.systemd1.Manager"
+)
+
+// conn is a connection to the systemd D-Bus API. It wraps the go-systemd
+// client and keeps hold of the underlying bus connections, so methods the
+// client doesn't wrap can still be called.
+type conn struct {
+	*dbus.Conn
+
+	// busConn is used for method calls.
+	busConn *godbus.Conn
+	// sigConn is used for receiving signals.
+	sigConn *godbus.Conn
+}
+
+// connect establishes a connection to the bus at address, if set. Otherwise,
+// it connects to the system bus or, failing that and running as root,
+// directly to systemd, which mirrors dbus.NewWithContext.
+func connect(ctx context.Context, address string) (*conn, error) {
+	if address != "" {
+		return connectWith(func() (*godbus.Conn, error) {
+			return authConnection(ctx, func(opts ...godbus.ConnOption) (*godbus.Conn, error) {
+				return godbus.Dial(address, opts...)
+			}, true)
+		})
+	}
+
+	c, err := connectWith(func() (*godbus.Conn, error) {
+		return authConnection(ctx, godbus.SystemBusPrivate, true)
+	})
+	if err != nil && os.Geteuid() == 0 {
+		return connectWith(func() (*godbus.Conn, error) {
+			// Hello is skipped when talking directly to systemd.
+			return authConnection(ctx, func(opts ...godbus.ConnOption) (*godbus.Conn, error) {
+				return godbus.Dial("unix:path=/run/systemd/private", opts...)
+			}, false)
+		})
+	}
+
+	return c, err
+}
+
+// connectWith establishes a connection using dialBus, which is called once
+// for method calls and once for signals.
+func connectWith(dialBus func() (*godbus.Conn, error)) (*conn, error) {
+	var conns []*godbus.Conn
+	dbusConn, err := dbus.NewConnection(func() (*godbus.Conn, error) {
+		c, err := dialBus()
+		if err == nil {
+			conns = append(conns, c)
+		}
+
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{
+		Conn:    dbusConn,
+		busConn: conns[0],
+		sigConn: conns[1],
+	}, nil
+}
+
+// authConnection creates and authenticates a bus connection.
+func authConnection(ctx context.Context, createBus func(opts ...godbus.ConnOption) (*godbus.Conn, error), hello bool) (*godbus.Conn, error) {
+	c, err := createBus(godbus.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Only use EXTERNAL method, and hardcode the uid (not username) to avoid
+	// a username lookup.
+	if err := c.Auth([]godbus.Auth{godbus.AuthExternal(strconv.Itoa(os.Getuid()))}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if hello {
+		if err := c.Hello(); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// systemd returns the systemd manager object.
+func (c *conn) systemd() godbus.BusObject {
+	return c.busConn.Object(systemdDest, systemdPath)
+}
+
+// unit returns the D-Bus object of the named unit.
+func (c *conn) unit(name string) godbus.BusObject {
+	return c.busConn.Object(systemdDest, systemdPath+"/unit/"+godbus.ObjectPath(dbus.PathBusEscape(name)))
+}
+
+// cancelJob cancels a queued or running job.
+func (c *conn) cancelJob(ctx context.Context, id int) error {
+	return c.systemd().CallWithContext(ctx, managerInterface+".CancelJob", 0, uint32(id)).Err
+}
+
+// alreadySubscribedError is the name of the D-Bus error systemd fails
+// subscribing twice with.
+const alreadySubscribedError = "org.freedesktop.systemd1.AlreadySubscribed"
+
+// subscribe delivers the signals named members of the systemd manager, such
+// as "JobNew", until the returned function is called. Other signals are
+// delivered too, and must be filtered by the caller. The channel is closed
+// if the connection is lost.
+func (c *conn) subscribe(ctx context.Context, members ...string) (<-chan *godbus.Signal, func(), error) {
+	matches := make([][]godbus.MatchOption, 0, len(members))
+	for _, member := range members {
+		matches = append(matches, []godbus.MatchOption{
+			godbus.WithMatchObjectPath(systemdPath),
+			godbus.WithMatchInterface(managerInterface),
+			godbus.WithMatchMember(member),
+		})
+	}
+	for _, match := range matches {
+		// Direct connections to systemd have no bus to add matches to, and
+		// get all signals of subscribed clients anyway.
+		_ = c.sigConn.AddMatchSignal(match...)
+	}
+	removeMatches := func() {
+		for _, match := range matches {
+			_ = c.sigConn.RemoveMatchSignal(match...)
+		}
+	}
+
+	// systemd only signals subscribed clients of most changes. The
+	// subscription lasts until the connection is closed, as other watches
+	// may rely on it.
+	err := c.sigConn.Object(systemdDest, systemdPath).CallWithContext(ctx, managerInterface+".Subscribe", 0).Err
+	var dbusErr godbus.Error
+	if err != nil && !(errors.As(err, &dbusErr) && dbusErr.Name == alreadySubscribedError) {
+		removeMatches()
+
+		return nil, nil, fmt.Errorf("failed to subscribe to systemd signals: %w", err)
+	}
+
+	signals := make(chan *godbus.Signal, 64)
+	c.sigConn.Signal(signals)
+	unsubscribe := func() {
+		c.sigConn.RemoveSignal(signals)
+		removeMatches()
+	}
+
+	return signals, unsubscribe, nil
+}
+
+// WithBusAddress connects to the D-Bus bus at address, e.g.
+// "unix:path=/run/dbus/system_bus_socket", instead of the system bus, such
+// as the bus of a container.
+func WithBusAddress(address string) Option {
+	return func(o *options) {
+		o.busAddress = address
+	}
+}
+
+// defaultReconnectPolicy is how reconnecting to systemd is retried by
+// default, for about a minute.
+var defaultReconnectPolicy = RetryPolicy{
+	Attempts: 10,
+	Backoff:  Backoff{Initial: 100 * time.Millisecond, Max: 10 * time.Second},
+}
+
+// WithReconnectPolicy sets how reconnecting to systemd is retried once the
+// D-Bus connection is lost, e.g. when dbus-daemon restarts. All errors are
+// retried, unless the policy classifies errors itself. By default,
+// reconnecting is attempted 10 times over about a minute.
+func WithReconnectPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.reconnectPolicy = p
+	}
+}
+
+// reconnect replaces the connection to systemd if it was lost, retrying
+// according to the reconnect policy. It fails with ErrDisconnected once the
+// manager is done.
+func (m *manager) reconnect(ctx context.Context) error {
+	m.reconnectMutex.Lock()
+	defer m.reconnectMutex.Unlock()
+
+	// Another caller may have reconnected already.
+	if m.dbusConn.Load().Connected() {
+		return nil
+	}
+	policy := m.options.reconnectPolicy
+	retryable := policy.Retryable
+	policy.Retryable = func(err error) bool {
+		return m.lifetime.Err() == nil && (retryable == nil || retryable(err))
+	}
+
+	return policy.Do(ctx, func(context.Context) error {
+		if m.lifetime.Err() != nil {
+			return ErrDisconnected
+		}
+		c, err := connect(m.lifetime, m.options.busAddress)
+		if err != nil {
+			return fmt.Errorf("failed to reconnect to systemd: %w: %w", ErrDisconnected, err)
+		}
+		m.dbusConn.Swap(c).Close()
+
+		return nil
+	})
+}
+
+// Raw returns the go-systemd client of the manager, for calling APIs this
+// package doesn't wrap without opening another connection. Calls made with
+// it aren't traced, retried, audited, nor serialized with the manager's own
+// operations on the same units. It must not be closed, nor kept across
+// reconnects, after which it is disconnected.
+func (m *manager) Raw() *dbus.Conn {
+	return m.dbusConn.Load().Conn
+}
@@ -0,0 +1,128 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// coredumpMessageID is the journal MESSAGE_ID of messages logged by
+// systemd-coredump when a process dumps core.
+const coredumpMessageID = "fc2e22bc6ee647b6b90729ab34a250b1"
+
+// coredumpFields are the journal fields Coredumps reads, which leaves out
+// the core itself when stored in the journal.
+var coredumpFields = []string{
+	"__REALTIME_TIMESTAMP", "COREDUMP_TIMESTAMP", "COREDUMP_UNIT", "COREDUMP_PID",
+	"COREDUMP_SIGNAL", "COREDUMP_SIGNAL_NAME", "COREDUMP_EXE", "COREDUMP_COMM",
+	"COREDUMP_FILENAME",
+}
+
+// CoredumpInfo is a core dump of a process of a unit, as recorded by
+// systemd-coredump.
+type CoredumpInfo struct {
+	// Time is when the process crashed.
+	Time time.Time `json:"time" yaml:"time"`
+	Unit string    `json:"unit" yaml:"unit"`
+	PID  int       `json:"pid" yaml:"pid"`
+	// Signal is the signal the process was killed by, e.g. 11, and
+	// SignalName its name, e.g. "SIGSEGV", which is empty on systemd
+	// versions older than 245.
+	Signal     int    `json:"signal" yaml:"signal"`
+	SignalName string `json:"signal_name,omitempty" yaml:"signal_name,omitempty"`
+	// Executable is the path of the executable, and Command the process
+	// name.
+	Executable string `json:"executable" yaml:"executable"`
+	Command    string `json:"command" yaml:"command"`
+	// Path is where the core was stored, e.g. in /var/lib/systemd/coredump.
+	// It's empty when the core wasn't stored as a file, e.g. with
+	// Storage=journal or Storage=none in coredump.conf, and the file may
+	// have been removed since.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Coredumps returns the core dumps of processes of the named unit since the
+// given time, oldest first, as recorded in the journal by systemd-coredump,
+// e.g. to collect crash reports after a failure. A zero since returns all
+// the core dumps still in the journal. This requires journalctl and access
+// to the system journal.
+func (m *manager) Coredumps(parentCtx context.Context, unit string, since time.Time) ([]CoredumpInfo, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Coredumps")
+	span.SetAttributes(otelattr.String("unit", unit))
+	if !since.IsZero() {
+		span.SetAttributes(otelattr.String("since", since.Format(time.RFC3339)))
+	}
+	defer span.End()
+
+	if err := m.validateUnit(unit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	dumps, err := readJournalAs(ctx, parseCoredump, coredumpArgs(unit, since)...)
+	if err != nil {
+		err = fmt.Errorf("failed to read core dumps of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetAttributes(otelattr.Int("coredumps", len(dumps)))
+	span.SetStatus(otelcodes.Ok, "retrieved core dumps")
+
+	return dumps, nil
+}
+
+// coredumpArgs returns the journalctl arguments selecting the core dumps of
+// unit since the given time.
+func coredumpArgs(unit string, since time.Time) []string {
+	args := []string{
+		"--output-fields=" + strings.Join(coredumpFields, ","),
+		"MESSAGE_ID=" + coredumpMessageID,
+		"COREDUMP_UNIT=" + unit,
+	}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%d.%06d", since.Unix(), since.Nanosecond()/int(time.Microsecond)))
+	}
+
+	return args
+}
+
+// parseCoredump parses a core dump entry, in the JSON output of journalctl.
+func parseCoredump(line []byte) (CoredumpInfo, error) {
+	field, err := journalFields(line)
+	if err != nil {
+		return CoredumpInfo{}, err
+	}
+
+	dump := CoredumpInfo{
+		Unit:       field("COREDUMP_UNIT"),
+		SignalName: field("COREDUMP_SIGNAL_NAME"),
+		Executable: field("COREDUMP_EXE"),
+		Command:    field("COREDUMP_COMM"),
+		Path:       field("COREDUMP_FILENAME"),
+	}
+	if dump.PID, err = strconv.Atoi(field("COREDUMP_PID")); err != nil {
+		return CoredumpInfo{}, fmt.Errorf("invalid core dump PID: %w", err)
+	}
+	if dump.Signal, err = strconv.Atoi(field("COREDUMP_SIGNAL")); err != nil {
+		return CoredumpInfo{}, fmt.Errorf("invalid core dump signal: %w", err)
+	}
+	// The crash time is logged by the kernel, before the core is processed.
+	for _, key := range []string{"COREDUMP_TIMESTAMP", "__REALTIME_TIMESTAMP"} {
+		if usec, err := strconv.ParseInt(field(key), 10, 64); err == nil {
+			dump.Time = time.UnixMicro(usec).UTC()
+			break
+		}
+	}
+
+	return dump, nil
+}
@@ -0,0 +1,59 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_parseCoredump(t *testing.T) {
+	dump, err := parseCoredump([]byte(`{
+		"__REALTIME_TIMESTAMP": "1700000001000000",
+		"COREDUMP_TIMESTAMP": "1700000000500000",
+		"COREDUMP_UNIT": "foo.service",
+		"COREDUMP_PID": "4242",
+		"COREDUMP_SIGNAL": "11",
+		"COREDUMP_SIGNAL_NAME": "SIGSEGV",
+		"COREDUMP_EXE": "/usr/bin/foo",
+		"COREDUMP_COMM": "foo",
+		"COREDUMP_FILENAME": "/var/lib/systemd/coredump/core.foo.0.abc.4242.1700000000000000.zst"
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, CoredumpInfo{
+		Time:       time.UnixMicro(1700000000500000).UTC(),
+		Unit:       "foo.service",
+		PID:        4242,
+		Signal:     11,
+		SignalName: "SIGSEGV",
+		Executable: "/usr/bin/foo",
+		Command:    "foo",
+		Path:       "/var/lib/systemd/coredump/core.foo.0.abc.4242.1700000000000000.zst",
+	}, dump)
+
+	// Cores stored in the journal have no file, and older systemd versions
+	// neither log signal names nor crash times.
+	dump, err = parseCoredump([]byte(`{"__REALTIME_TIMESTAMP": "1700000001000000", "COREDUMP_UNIT": "foo.service", "COREDUMP_PID": "4242", "COREDUMP_SIGNAL": "6"}`))
+	require.NoError(t, err)
+	require.Equal(t, CoredumpInfo{Time: time.UnixMicro(1700000001000000).UTC(), Unit: "foo.service", PID: 4242, Signal: 6}, dump)
+
+	_, err = parseCoredump([]byte(`{"COREDUMP_SIGNAL": "6"}`))
+	require.Error(t, err)
+	_, err = parseCoredump([]byte(`{"COREDUMP_PID": "4242"}`))
+	require.Error(t, err)
+	_, err = parseCoredump([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func Test_Unit_coredumpArgs(t *testing.T) {
+	fields := "--output-fields=__REALTIME_TIMESTAMP,COREDUMP_TIMESTAMP,COREDUMP_UNIT,COREDUMP_PID,COREDUMP_SIGNAL,COREDUMP_SIGNAL_NAME,COREDUMP_EXE,COREDUMP_COMM,COREDUMP_FILENAME"
+	require.Equal(t, []string{fields, "MESSAGE_ID=" + coredumpMessageID, "COREDUMP_UNIT=foo.service"}, coredumpArgs("foo.service", time.Time{}))
+	require.Equal(t, []string{fields, "MESSAGE_ID=" + coredumpMessageID, "COREDUMP_UNIT=foo.service", "--since=@1700000000.000500"},
+		coredumpArgs("foo.service", time.Unix(1700000000, 500_000)))
+}
+
+func Test_Unit_Coredumps_InvalidUnit(t *testing.T) {
+	_, err := (&manager{}).Coredumps(context.Background(), "foo", time.Time{})
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
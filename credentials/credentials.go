@@ -0,0 +1,85 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrNoCredentials means the running process wasn't passed any credentials
+// by systemd.
+var ErrNoCredentials = errors.New("no credentials passed by systemd")
+
+// Directory returns the directory holding the credentials passed to the
+// running service, from $CREDENTIALS_DIRECTORY.
+func Directory() (string, error) {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return "", ErrNoCredentials
+	}
+
+	return dir, nil
+}
+
+// Read returns the named credential passed to the running service.
+func Read(name string) ([]byte, error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid credential name %q", name)
+	}
+	dir, err := Directory()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential %q: %w", name, err)
+	}
+
+	return content, nil
+}
+
+// List returns the names of the credentials passed to the running service,
+// sorted.
+func List() ([]string, error) {
+	dir, err := Directory()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// Encrypt encrypts a credential with systemd-creds, for use with
+// unitfile.File.SetCredentialEncrypted. The name is embedded in the
+// encrypted credential, which then only decrypts under that name.
+func Encrypt(ctx context.Context, name string, plaintext []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "systemd-creds", "encrypt", "--name="+name, "-", "-")
+	cmd.Stdin = bytes.NewReader(plaintext)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credential %q: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Read(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("s3cret"), 0o400))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cert"), []byte("pem"), 0o400))
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+
+	d, err := Directory()
+	require.NoError(t, err)
+	require.Equal(t, dir, d)
+
+	token, err := Read("token")
+	require.NoError(t, err)
+	require.Equal(t, []byte("s3cret"), token)
+
+	names, err := List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cert", "token"}, names)
+
+	_, err = Read("missing")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = Read("../token")
+	require.Error(t, err)
+}
+
+func Test_Unit_Read_NoCredentials(t *testing.T) {
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+
+	_, err := Read("token")
+	require.ErrorIs(t, err, ErrNoCredentials)
+	_, err = List()
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
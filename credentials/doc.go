@@ -0,0 +1,3 @@
+// Package credentials reads the systemd credentials passed to the running
+// service, and encrypts credentials to be passed to services.
+package credentials
@@ -0,0 +1,182 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotDelegated means a unit doesn't delegate its control group subtree,
+// i.e. it doesn't have Delegate=yes.
+var ErrNotDelegated = errors.New("unit control group isn't delegated")
+
+// delegateTypes are the unit types which may delegate their control group.
+var delegateTypes = []string{"service", "scope"}
+
+// ControlGroup is a control group delegated to a unit, or one of its
+// descendants, within which processes may be partitioned with sub-cgroups.
+//
+// Under cgroup v2, processes only live in leaf control groups once
+// controllers are enabled, so processes of the unit should be moved to a
+// child before enabling controllers for children.
+type ControlGroup struct {
+	root *os.Root
+}
+
+// OpenDelegatedControlGroup opens the control group of a running unit with
+// Delegate=yes, which the unit's processes may manage. It fails with
+// ErrNotDelegated for other units. The caller must close the returned
+// control group.
+func OpenDelegatedControlGroup(ctx context.Context, m Manager, unit string) (*ControlGroup, error) {
+	if err := ValidateUnitName(unit); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(delegateTypes, unitType(unit)) {
+		return nil, fmt.Errorf("%w: %q", ErrNotDelegated, unit)
+	}
+	delegated, err := GetProperty[bool](ctx, m, unit, unitTypeInterface(unit), "Delegate")
+	if err != nil {
+		return nil, err
+	}
+	if !delegated {
+		return nil, fmt.Errorf("%w: %q", ErrNotDelegated, unit)
+	}
+
+	root, err := OpenControlGroup(ctx, m, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControlGroup{root: root}, nil
+}
+
+// Name returns the cgroupfs directory of the control group.
+func (g *ControlGroup) Name() string {
+	return g.root.Name()
+}
+
+// Close closes the control group, leaving it in place.
+func (g *ControlGroup) Close() error {
+	return g.root.Close()
+}
+
+// CreateChild creates a child control group, unless it exists, and opens
+// it.
+func (g *ControlGroup) CreateChild(name string) (*ControlGroup, error) {
+	if err := validateChildName(name); err != nil {
+		return nil, err
+	}
+	if err := g.root.Mkdir(name, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("failed to create control group %q: %w", name, err)
+	}
+
+	return g.Child(name)
+}
+
+// Child opens an existing child control group.
+func (g *ControlGroup) Child(name string) (*ControlGroup, error) {
+	if err := validateChildName(name); err != nil {
+		return nil, err
+	}
+	root, err := g.root.OpenRoot(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control group %q: %w", name, err)
+	}
+
+	return &ControlGroup{root: root}, nil
+}
+
+// RemoveChild removes a child control group, which must have neither
+// processes nor children.
+func (g *ControlGroup) RemoveChild(name string) error {
+	if err := validateChildName(name); err != nil {
+		return err
+	}
+	if err := g.root.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove control group %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Processes returns the IDs of the processes in the control group.
+func (g *ControlGroup) Processes() ([]int, error) {
+	procs, err := g.Get("cgroup.procs")
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, field := range strings.Fields(procs) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse process ID %q: %w", field, err)
+		}
+		pids = append(pids, pid)
+	}
+
+	return pids, nil
+}
+
+// AddProcess moves a process into the control group, from wherever it was
+// within the delegated subtree.
+func (g *ControlGroup) AddProcess(pid int) error {
+	return g.Set("cgroup.procs", strconv.Itoa(pid))
+}
+
+// EnableControllers enables controllers, e.g. "cpu" or "memory", for the
+// children of the control group, so their attributes can be set.
+func (g *ControlGroup) EnableControllers(controllers ...string) error {
+	enable := make([]string, len(controllers))
+	for i, controller := range controllers {
+		enable[i] = "+" + controller
+	}
+
+	return g.Set("cgroup.subtree_control", strings.Join(enable, " "))
+}
+
+// Get returns the value of an attribute of the control group, e.g.
+// "memory.max", without the trailing newline.
+func (g *ControlGroup) Get(attr string) (string, error) {
+	b, err := g.root.ReadFile(attr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read control group attribute %q: %w", attr, err)
+	}
+
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// Set sets an attribute of the control group, e.g. "memory.max" or
+// "cpu.weight".
+func (g *ControlGroup) Set(attr, value string) error {
+	// cgroupfs files exist for as long as the control group does, and
+	// aren't truncated.
+	f, err := g.root.OpenFile(attr, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to set control group attribute %q: %w", attr, err)
+	}
+	_, err = f.WriteString(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set control group attribute %q: %w", attr, err)
+	}
+
+	return nil
+}
+
+// validateChildName ensures name is that of a child control group, rather
+// than a path.
+func validateChildName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsRune(name, '/') {
+		return fmt.Errorf("invalid control group name %q", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package systemdmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_ControlGroup(t *testing.T) {
+	dir := t.TempDir()
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	g := &ControlGroup{root: root}
+	defer g.Close()
+
+	child, err := g.CreateChild("workers")
+	require.NoError(t, err)
+	defer child.Close()
+	require.Equal(t, filepath.Join(dir, "workers"), child.Name())
+	// Creating an existing child opens it.
+	again, err := g.CreateChild("workers")
+	require.NoError(t, err)
+	require.NoError(t, again.Close())
+
+	// cgroupfs creates the files of control groups.
+	for _, attr := range []string{"cgroup.procs", "cgroup.subtree_control", "memory.max"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "workers", attr), nil, 0o644))
+	}
+	require.NoError(t, child.AddProcess(42))
+	pids, err := child.Processes()
+	require.NoError(t, err)
+	require.Equal(t, []int{42}, pids)
+	require.NoError(t, child.EnableControllers("cpu", "memory"))
+	enabled, err := child.Get("cgroup.subtree_control")
+	require.NoError(t, err)
+	require.Equal(t, "+cpu +memory", enabled)
+	require.NoError(t, child.Set("memory.max", "1G"))
+	// Attributes aren't created.
+	require.Error(t, child.Set("memory.high", "1G"))
+
+	for _, name := range []string{"", ".", "..", "a/b"} {
+		_, err := g.CreateChild(name)
+		require.Error(t, err, name)
+	}
+
+	_, err = g.Child("missing")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	empty, err := g.CreateChild("empty")
+	require.NoError(t, err)
+	require.NoError(t, empty.Close())
+	require.NoError(t, g.RemoveChild("empty"))
+	_, err = g.Child("empty")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_Unit_OpenDelegatedControlGroup_WrongUnitType(t *testing.T) {
+	_, err := OpenDelegatedControlGroup(t.Context(), &manager{}, "a.socket")
+	require.ErrorIs(t, err, ErrNotDelegated)
+}
@@ -0,0 +1,98 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrDependencyCycle means the ordering dependencies between units can't be
+// resolved into a sequence.
+var ErrDependencyCycle = errors.New("units have cyclic ordering dependencies")
+
+// unitDependencies returns the After= and Before= ordering dependencies of
+// the named unit.
+func (m *manager) unitDependencies(ctx context.Context, unit string) (after []string, before []string, err error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, nil, ErrDisconnected
+	}
+
+	var props map[string]any
+	err = m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		props, err = m.dbusConn.Load().GetUnitPropertiesContext(ctx, unit)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve dependencies for unit %q: %w", unit, err)
+	}
+	after, _ = props["After"].([]string)
+	before, _ = props["Before"].([]string)
+
+	return after, before, nil
+}
+
+// dependencyOrder returns units sorted so that every unit comes after the
+// units it is ordered after.
+func (m *manager) dependencyOrder(ctx context.Context, units []string) ([]string, error) {
+	after := make(map[string][]string, len(units))
+	for _, unit := range units {
+		unitAfter, unitBefore, err := m.unitDependencies(ctx, unit)
+		if err != nil {
+			return nil, err
+		}
+		after[unit] = append(after[unit], unitAfter...)
+		// unit Before=other is the same as other After=unit.
+		for _, other := range unitBefore {
+			after[other] = append(after[other], unit)
+		}
+	}
+
+	return topologicalOrder(units, after)
+}
+
+// topologicalOrder sorts units so that each unit comes after every unit
+// listed for it in after. Dependencies on units outside of units are ignored
+// and, among units with no ordering between them, input order is kept.
+func topologicalOrder(units []string, after map[string][]string) ([]string, error) {
+	// Count, for each unit, how many units of the group it must wait for.
+	pending := make(map[string]int, len(units))
+	dependents := make(map[string][]string, len(units))
+	for _, unit := range units {
+		pending[unit] = 0
+	}
+	for _, unit := range units {
+		seen := map[string]bool{}
+		for _, dep := range after[unit] {
+			if _, ok := pending[dep]; !ok || dep == unit || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			pending[unit]++
+			dependents[dep] = append(dependents[dep], unit)
+		}
+	}
+
+	order := make([]string, 0, len(units))
+	for len(order) < len(pending) {
+		progressed := false
+		for _, unit := range units {
+			if pending[unit] != 0 || slices.Contains(order, unit) {
+				continue
+			}
+			order = append(order, unit)
+			for _, dependent := range dependents[unit] {
+				pending[dependent]--
+			}
+			progressed = true
+
+			break
+		}
+		if !progressed {
+			return nil, ErrDependencyCycle
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,58 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_topologicalOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		units    []string
+		after    map[string][]string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "no dependencies keeps input order",
+			units:    []string{"a", "b", "c"},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "chain of dependencies",
+			units:    []string{"c", "b", "a"},
+			after:    map[string][]string{"c": {"b"}, "b": {"a"}},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "dependencies outside of the group are ignored",
+			units:    []string{"b", "a"},
+			after:    map[string][]string{"b": {"a", "network.target"}, "a": {"sysinit.target"}},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "duplicate dependencies",
+			units:    []string{"b", "a"},
+			after:    map[string][]string{"b": {"a", "a"}},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:  "cyclic dependencies",
+			units: []string{"a", "b"},
+			after: map[string][]string{"a": {"b"}, "b": {"a"}},
+			err:   ErrDependencyCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := topologicalOrder(tt.units, tt.after)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, order)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitDevice is the state of a device unit.
+type UnitDevice struct {
+	// Unit is the name of the device unit, e.g. "dev-sdb.device".
+	Unit string `json:"unit" yaml:"unit"`
+	// SysFSPath is the path of the device in /sys, empty while unplugged.
+	SysFSPath string `json:"sysfs_path" yaml:"sysfs_path"`
+	// ActiveState is "active" while the device is plugged.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+}
+
+// DeviceUnitName returns the name of the device unit of device, which is a
+// path in /dev or /sys, e.g. "dev-disk-by\x2dlabel-data.device" for
+// "/dev/disk/by-label/data".
+func DeviceUnitName(device string) string {
+	return EscapePath(device) + ".device"
+}
+
+// DeviceStatus returns the state of the device unit of device, which is a
+// path in /dev or /sys.
+func (m *manager) DeviceStatus(parentCtx context.Context, device string) (UnitDevice, error) {
+	unit := DeviceUnitName(device)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "DeviceStatus")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("device", device))
+	defer span.End()
+
+	dev, err := m.deviceStatus(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitDevice{}, err
+	}
+	span.SetAttributes(otelattr.String("active_state", dev.ActiveState))
+	span.SetStatus(otelcodes.Ok, "retrieved device status")
+
+	return dev, nil
+}
+
+// deviceStatus implements DeviceStatus.
+func (m *manager) deviceStatus(ctx context.Context, unit string) (UnitDevice, error) {
+	props, err := m.unitProperties(ctx, unit, "Device")
+	if err != nil {
+		return UnitDevice{}, err
+	}
+	dev := UnitDevice{Unit: unit}
+	dev.SysFSPath, err = decodeProperty[string](props["SysFSPath"])
+	if err != nil {
+		return UnitDevice{}, fmt.Errorf("failed to decode device properties of unit %q: %w", unit, err)
+	}
+	dev.ActiveState, err = m.activeState(ctx, unit)
+	if err != nil {
+		return UnitDevice{}, fmt.Errorf("failed to retrieve state of unit %q: %w", unit, err)
+	}
+
+	return dev, nil
+}
+
+// WaitForDevice blocks until the device unit of device, which is a path in
+// /dev or /sys, is active. systemd only activates device units once udev has
+// processed the device, so its symlinks, e.g. in /dev/disk/by-label, exist
+// and units depending on it may start. This is how services depending on
+// hotplugged hardware are orchestrated. Bound ctx to give up waiting, in
+// which case a LastStatusError is returned.
+func (m *manager) WaitForDevice(parentCtx context.Context, device string) error {
+	unit := DeviceUnitName(device)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "WaitForDevice")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("device", device))
+	defer span.End()
+
+	if err := m.waitForDevice(ctx, unit, device); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("device %q is plugged", device))
+
+	return nil
+}
+
+// waitForDevice implements WaitForDevice.
+func (m *manager) waitForDevice(ctx context.Context, unit, device string) error {
+	if !path.IsAbs(device) {
+		return fmt.Errorf("failed to wait for device %q: path must be absolute", device)
+	}
+	if err := m.validateUnit(unit); err != nil {
+		return err
+	}
+
+	return m.waitActive(ctx, unit)
+}
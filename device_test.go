@@ -0,0 +1,23 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_DeviceUnitName(t *testing.T) {
+	require.Equal(t, "dev-sdb.device", DeviceUnitName("/dev/sdb"))
+	require.Equal(t, `dev-disk-by\x2dlabel-data.device`, DeviceUnitName("/dev/disk/by-label/data"))
+	require.Equal(t, "sys-subsystem-net-devices-lo.device", DeviceUnitName("/sys/subsystem/net/devices/lo"))
+}
+
+func Test_Unit_WaitForDevice_Relative(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := (&manager{}).WaitForDevice(ctx, "sdb")
+	require.ErrorContains(t, err, "must be absolute")
+}
@@ -0,0 +1,145 @@
+package systemdmanager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Change is a setting that differs between an installed unit file and the
+// desired content.
+type Change struct {
+	Section string
+	Key     string
+	// Current and Desired are the values in effect, empty when the setting
+	// isn't set.
+	Current []string
+	Desired []string
+}
+
+// String returns a human-readable description of the change.
+func (c Change) String() string {
+	return fmt.Sprintf("[%s] %s: %q -> %q", c.Section, c.Key, c.Current, c.Desired)
+}
+
+// Diff describes how an installed unit file differs from desired content.
+type Diff struct {
+	// Path is the installed unit file, empty when the unit isn't installed.
+	Path string
+	// DropIns lists the drop-in files extending the installed unit file.
+	// They aren't part of the comparison, but do apply on top of it.
+	DropIns []string
+	// NeedDaemonReload is set when the unit file changed on disk since
+	// systemd last loaded it.
+	NeedDaemonReload bool
+	// Changes lists the settings that differ.
+	Changes []Change
+}
+
+// DiffUnit compares the installed unit file of the named unit with the
+// desired content. It returns whether the desired content needs to be
+// installed, followed by a daemon reload and a restart, for the unit to run
+// with it. Settings are compared rather than text, so formatting and
+// comments don't matter.
+func (m *manager) DiffUnit(parentCtx context.Context, unit string, desired []byte) (Diff, bool, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "DiffUnit")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	diff, err := m.diffUnit(ctx, unit, desired)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return Diff{}, false, err
+	}
+	needed := diff.Path == "" || diff.NeedDaemonReload || len(diff.Changes) > 0
+	span.SetAttributes(otelattr.Bool("needed", needed))
+	span.SetStatus(otelcodes.Ok, "compared unit file")
+
+	return diff, needed, nil
+}
+
+// diffUnit implements DiffUnit.
+func (m *manager) diffUnit(ctx context.Context, unit string, desired []byte) (Diff, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return Diff{}, err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return Diff{}, ErrDisconnected
+	}
+
+	desiredFile, err := unitfile.Parse(bytes.NewReader(desired))
+	if err != nil {
+		return Diff{}, fmt.Errorf("failed to parse desired content of unit %q: %w", unit, err)
+	}
+
+	var props map[string]any
+	err = m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		props, err = m.dbusConn.Load().GetUnitPropertiesContext(ctx, unit)
+		return err
+	})
+	if err != nil {
+		return Diff{}, fmt.Errorf("failed to retrieve unit file of %q: %w", unit, err)
+	}
+
+	var diff Diff
+	diff.Path, _ = props["FragmentPath"].(string)
+	diff.DropIns, _ = props["DropInPaths"].([]string)
+	diff.NeedDaemonReload, _ = props["NeedDaemonReload"].(bool)
+
+	current := &unitfile.File{}
+	if diff.Path != "" {
+		content, err := os.ReadFile(diff.Path)
+		if err != nil {
+			return Diff{}, fmt.Errorf("failed to read unit file of %q: %w", unit, err)
+		}
+		if current, err = unitfile.Parse(bytes.NewReader(content)); err != nil {
+			return Diff{}, fmt.Errorf("failed to parse unit file of %q: %w", unit, err)
+		}
+	}
+	diff.Changes = diffUnitFiles(current, desiredFile)
+
+	return diff, nil
+}
+
+// diffUnitFiles returns the settings that differ between two unit files, in
+// order of appearance.
+func diffUnitFiles(current, desired *unitfile.File) []Change {
+	type setting struct{ section, key string }
+	var settings []setting
+	for _, f := range []*unitfile.File{desired, current} {
+		for _, s := range f.Sections {
+			for _, e := range s.Entries {
+				if st := (setting{s.Name, e.Key}); !slices.Contains(settings, st) {
+					settings = append(settings, st)
+				}
+			}
+		}
+	}
+
+	var changes []Change
+	for _, st := range settings {
+		currentValues := current.Values(st.section, st.key)
+		desiredValues := desired.Values(st.section, st.key)
+		if !slices.Equal(currentValues, desiredValues) {
+			changes = append(changes, Change{
+				Section: st.section,
+				Key:     st.key,
+				Current: currentValues,
+				Desired: desiredValues,
+			})
+		}
+	}
+
+	return changes
+}
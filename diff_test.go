@@ -0,0 +1,56 @@
+package systemdmanager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_diffUnitFiles(t *testing.T) {
+	parse := func(s string) *unitfile.File {
+		f, err := unitfile.Parse(strings.NewReader(s))
+		require.NoError(t, err)
+
+		return f
+	}
+
+	current := parse(`[Unit]
+Description=Test
+After=a.target
+[Service]
+ExecStart=/bin/old
+Restart=always
+`)
+
+	// Comments, formatting and ordering between settings don't matter.
+	same := parse(`# Comment
+[Unit]
+Description = Test
+After=a.target
+[Service]
+Restart=always
+ExecStart=/bin/old
+`)
+	require.Empty(t, diffUnitFiles(current, same))
+
+	desired := parse(`[Unit]
+Description=Test
+After=a.target
+After=b.target
+[Service]
+ExecStart=/bin/new
+[Install]
+WantedBy=multi-user.target
+`)
+	require.Equal(t, []Change{
+		{Section: "Unit", Key: "After", Current: []string{"a.target"}, Desired: []string{"a.target", "b.target"}},
+		{Section: "Service", Key: "ExecStart", Current: []string{"/bin/old"}, Desired: []string{"/bin/new"}},
+		{Section: "Install", Key: "WantedBy", Desired: []string{"multi-user.target"}},
+		{Section: "Service", Key: "Restart", Current: []string{"always"}},
+	}, diffUnitFiles(current, desired))
+
+	// Not installed.
+	require.Len(t, diffUnitFiles(&unitfile.File{}, desired), 4)
+}
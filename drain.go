@@ -0,0 +1,85 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DrainFunc prepares a unit to stop, e.g. by deregistering it from a load
+// balancer and waiting for in-flight requests to complete.
+type DrainFunc func(ctx context.Context) error
+
+// WithDrainTimeout bounds how long StopWithDrain waits for the drain
+// function, after which the unit is stopped anyway. Zero, the default, means
+// draining is only bound by the operation timeout.
+func WithDrainTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.drainTimeout = d
+	}
+}
+
+// StopWithDrain synchronously stops the named unit, once drain returns. The
+// drain function runs after the hooks registered to run before stopping, and
+// is bound by the operation timeout and cancelled along with ctx. If it
+// fails, the unit isn't stopped, unless it failed for exceeding the timeout
+// set with WithDrainTimeout.
+func (m *manager) StopWithDrain(parentCtx context.Context, unit string, drain DrainFunc, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "StopWithDrain")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	o := newCallOptions(opts)
+	o.drain = drain
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationStop, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StopUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully drained and stopped unit %q", unit))
+
+	return nil
+}
+
+// runDrain runs the drain function of an operation, if any.
+func runDrain(ctx context.Context, unit string, o callOptions) error {
+	if o.drain == nil {
+		return nil
+	}
+
+	drainCtx := ctx
+	if o.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(ctx, o.drainTimeout)
+		defer cancel()
+	}
+	started := time.Now()
+	err := o.drain(drainCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(drainCtx.Err(), context.DeadlineExceeded) {
+		// Draining took too long, which doesn't prevent stopping.
+		trace.SpanFromContext(ctx).AddEvent("drain timed out", trace.WithAttributes(
+			otelattr.String("unit", unit),
+			otelattr.String("error", err.Error()),
+		))
+
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to drain unit %q: %w", unit, err)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(otelattr.String("drain_duration", time.Since(started).String()))
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_runDrain(t *testing.T) {
+	t.Run("No drain", func(t *testing.T) {
+		require.NoError(t, runDrain(t.Context(), "a.service", newCallOptions(nil)))
+	})
+
+	t.Run("Drain fails", func(t *testing.T) {
+		failed := errors.New("deregistration failed")
+		o := newCallOptions(nil)
+		o.drain = func(context.Context) error { return failed }
+		require.ErrorIs(t, runDrain(t.Context(), "a.service", o), failed)
+	})
+
+	t.Run("Drain times out", func(t *testing.T) {
+		o := newCallOptions([]CallOption{WithDrainTimeout(time.Millisecond)})
+		o.drain = func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		require.NoError(t, runDrain(t.Context(), "a.service", o))
+	})
+
+	t.Run("Operation cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		o := newCallOptions([]CallOption{WithDrainTimeout(time.Minute)})
+		o.drain = func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		require.ErrorIs(t, runDrain(ctx, "a.service", o), context.Canceled)
+	})
+}
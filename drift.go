@@ -0,0 +1,342 @@
+package systemdmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// DriftChange is the kind of a DriftEvent.
+type DriftChange string
+
+const (
+	// DriftCreated is a unit file or drop-in being created.
+	DriftCreated DriftChange = "created"
+	// DriftModified is a unit file or drop-in being modified.
+	DriftModified DriftChange = "modified"
+	// DriftRemoved is a unit file or drop-in being removed.
+	DriftRemoved DriftChange = "removed"
+)
+
+// DriftEvent is a file of a unit changed by something other than the
+// manager, as observed by WatchDrift.
+type DriftEvent struct {
+	Time   time.Time   `json:"time" yaml:"time"`
+	Unit   string      `json:"unit" yaml:"unit"`
+	Path   string      `json:"path" yaml:"path"`
+	Change DriftChange `json:"change" yaml:"change"`
+}
+
+// WatchDrift sends to driftChan every change to the unit files and drop-ins
+// of the named units which wasn't made by this manager, such as an
+// administrator editing them or a package upgrade replacing them, so agents
+// reconciling hosts against a desired state know to act. Files are checked
+// every interval set with WithWatchInterval, and whenever systemd reloads or
+// unit files change. Changes made while disconnected are reported once
+// reconnected. This is a blocking function. It fails with ErrDisconnected if
+// reconnecting fails.
+func (m *manager) WatchDrift(parentCtx context.Context, units []string, driftChan chan<- DriftEvent, opts ...WatchOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "WatchDrift")
+	span.SetAttributes(otelattr.StringSlice("units", units))
+	defer span.End()
+
+	// Ensure a non-nil channel is provided.
+	if driftChan == nil {
+		err := fmt.Errorf("a chan is required for WatchDrift to write drift events to")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if len(units) == 0 {
+		err := fmt.Errorf("at least one unit is required for WatchDrift to watch")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	for _, unit := range units {
+		if err := m.validateUnit(unit); err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+
+			return err
+		}
+	}
+
+	o := newWatchOptions(opts)
+	span.SetAttributes(otelattr.String("interval", o.interval.String()))
+	err := m.watchDrift(ctx, units, driftChan, o.interval)
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+
+	return err
+}
+
+// watchDrift follows drift, reconnecting as needed, until ctx is done or
+// reconnecting fails. The fingerprints of the units are kept across
+// connections.
+func (m *manager) watchDrift(ctx context.Context, units []string, driftChan chan<- DriftEvent, interval time.Duration) error {
+	fingerprints := make(map[string]map[string]fileFingerprint, len(units))
+	for {
+		err := m.followDrift(ctx, units, fingerprints, driftChan, interval)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, ErrDisconnected) {
+			return err
+		}
+		if err := m.reconnect(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// followDrift checks the files of units every interval and whenever
+// signaled over the current connection, sending drift to driftChan, until
+// ctx is done or the connection is lost. Units without fingerprints are
+// fingerprinted without reporting drift.
+func (m *manager) followDrift(ctx context.Context, units []string, fingerprints map[string]map[string]fileFingerprint, driftChan chan<- DriftEvent, interval time.Duration) error {
+	c := m.dbusConn.Load()
+	// Ensure connection to D-Bus API.
+	if !c.Connected() {
+		return ErrDisconnected
+	}
+	signals, unsubscribe, err := c.subscribe(ctx, "UnitFilesChanged", "Reloading")
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, unit := range units {
+			current, err := m.fingerprintUnit(ctx, unit, fingerprints[unit])
+			if err != nil {
+				if !c.Connected() {
+					return ErrDisconnected
+				}
+				return err
+			}
+			previous, ok := fingerprints[unit]
+			fingerprints[unit] = current
+			if !ok {
+				continue
+			}
+			for _, event := range m.driftEvents(unit, previous, current, time.Now()) {
+				select {
+				case driftChan <- event:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		signaled, err := waitDriftCheck(ctx, signals, ticker.C)
+		if err != nil {
+			return err
+		}
+		// Reloading may have changed which files make up the units, which
+		// are cached otherwise.
+		if signaled {
+			for _, unit := range units {
+				m.cache.invalidate(unit)
+			}
+		}
+	}
+}
+
+// waitDriftCheck blocks until files are to be checked again, which is when
+// tick fires, or when systemd signals reloading or unit files changing. It
+// returns whether systemd signaled.
+func waitDriftCheck(ctx context.Context, signals <-chan *godbus.Signal, tick <-chan time.Time) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-tick:
+			return false, nil
+		case s, ok := <-signals:
+			if !ok {
+				return false, ErrDisconnected
+			}
+			if s.Name == managerInterface+".UnitFilesChanged" || s.Name == managerInterface+".Reloading" {
+				return true, nil
+			}
+		}
+	}
+}
+
+// fileFingerprint identifies the content of a file.
+type fileFingerprint struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// fingerprintUnit returns the fingerprints of the existing files making up
+// the named unit, keyed by path. Files found in previous are checked too, so
+// their removal is noticed, and are only hashed again if their modification
+// time or size changed.
+func (m *manager) fingerprintUnit(ctx context.Context, unit string, previous map[string]fileFingerprint) (map[string]fileFingerprint, error) {
+	paths, err := m.unitFilePaths(ctx, unit)
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, slices.Collect(maps.Keys(previous))...)
+
+	fingerprints := make(map[string]fileFingerprint, len(paths))
+	for _, path := range paths {
+		if _, ok := fingerprints[path]; ok {
+			continue
+		}
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check file %q of unit %q: %w", path, unit, err)
+		}
+		if p, ok := previous[path]; ok && p.modTime.Equal(info.ModTime()) && p.size == info.Size() {
+			fingerprints[path] = p
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check file %q of unit %q: %w", path, unit, err)
+		}
+		fingerprints[path] = fileFingerprint{modTime: info.ModTime(), size: info.Size(), hash: contentHash(content)}
+	}
+
+	return fingerprints, nil
+}
+
+// unitFilePaths returns the paths of the unit file and drop-ins systemd
+// loaded the named unit from, along with those the manager installs, which
+// may not be loaded yet.
+func (m *manager) unitFilePaths(ctx context.Context, unit string) ([]string, error) {
+	variant, err := m.unitProperty(ctx, unit, "Unit", "FragmentPath")
+	if err != nil {
+		return nil, err
+	}
+	fragmentPath, err := decodeProperty[string](variant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode property %q of unit %q: %w", "FragmentPath", unit, err)
+	}
+	variant, err = m.unitProperty(ctx, unit, "Unit", "DropInPaths")
+	if err != nil {
+		return nil, err
+	}
+	dropInPaths, err := decodeProperty[[]string](variant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode property %q of unit %q: %w", "DropInPaths", unit, err)
+	}
+	dropIns, err := filepath.Glob(filepath.Join(m.options.unitDirectory, unit+".d", "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{m.unitPath(unit)}
+	if fragmentPath != "" {
+		paths = append(paths, fragmentPath)
+	}
+	paths = append(paths, dropInPaths...)
+
+	return append(paths, dropIns...), nil
+}
+
+// driftEvents returns the changes between two fingerprints of the named
+// unit which weren't written by the manager, sorted by path.
+func (m *manager) driftEvents(unit string, previous, current map[string]fileFingerprint, now time.Time) []DriftEvent {
+	paths := slices.Sorted(maps.Keys(previous))
+	for path := range current {
+		if _, ok := previous[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var events []DriftEvent
+	for _, path := range paths {
+		p, existed := previous[path]
+		c, exists := current[path]
+		var change DriftChange
+		switch {
+		case !existed:
+			change = DriftCreated
+		case !exists:
+			change = DriftRemoved
+		case p.hash != c.hash:
+			change = DriftModified
+		default:
+			continue
+		}
+		if m.writes.wrote(path, c.hash) {
+			continue
+		}
+		events = append(events, DriftEvent{Time: now, Unit: unit, Path: path, Change: change})
+	}
+
+	return events
+}
+
+// contentHash returns the hash of the content of a file, as recorded by
+// fileWrites.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileWrites records the last content the manager wrote to files, so that
+// changes made by others can be told apart.
+type fileWrites struct {
+	mutex sync.Mutex
+	// hashes holds the hash of the content of files by path, or an empty
+	// hash for removed files.
+	hashes map[string]string
+}
+
+// record stores that the manager wrote content to path, or removed it if
+// content is nil.
+func (w *fileWrites) record(path string, content []byte) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.hashes == nil {
+		w.hashes = make(map[string]string)
+	}
+	w.hashes[path] = ""
+	if content != nil {
+		w.hashes[path] = contentHash(content)
+	}
+}
+
+// wrote returns whether the last write of the manager to path left content
+// with the given hash, or removed it if hash is empty.
+func (w *fileWrites) wrote(path, hash string) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	recorded, ok := w.hashes[path]
+	return ok && recorded == hash
+}
@@ -0,0 +1,93 @@
+package systemdmanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_driftEvents(t *testing.T) {
+	m := &manager{}
+	now := time.Now()
+	fingerprint := func(content string) fileFingerprint {
+		return fileFingerprint{hash: contentHash([]byte(content))}
+	}
+
+	previous := map[string]fileFingerprint{
+		"/etc/systemd/system/a.service":            fingerprint("a"),
+		"/etc/systemd/system/a.service.d/b.conf":   fingerprint("b"),
+		"/etc/systemd/system/a.service.d/c.conf":   fingerprint("c"),
+		"/etc/systemd/system/a.service.d/own.conf": fingerprint("own"),
+	}
+	current := map[string]fileFingerprint{
+		"/etc/systemd/system/a.service":            fingerprint("edited"),
+		"/etc/systemd/system/a.service.d/c.conf":   fingerprint("c"),
+		"/etc/systemd/system/a.service.d/d.conf":   fingerprint("d"),
+		"/etc/systemd/system/a.service.d/own.conf": fingerprint("own edited"),
+	}
+	// Changes written by the manager aren't drift.
+	m.writes.record("/etc/systemd/system/a.service.d/own.conf", []byte("own edited"))
+
+	require.Equal(t, []DriftEvent{
+		{Time: now, Unit: "a.service", Path: "/etc/systemd/system/a.service", Change: DriftModified},
+		{Time: now, Unit: "a.service", Path: "/etc/systemd/system/a.service.d/b.conf", Change: DriftRemoved},
+		{Time: now, Unit: "a.service", Path: "/etc/systemd/system/a.service.d/d.conf", Change: DriftCreated},
+	}, m.driftEvents("a.service", previous, current, now))
+	require.Empty(t, m.driftEvents("a.service", current, current, now))
+}
+
+func Test_Unit_fileWrites(t *testing.T) {
+	dir := t.TempDir()
+	m := &manager{options: options{unitDirectory: dir}}
+	f := &unitfile.File{}
+	f.Set("Unit", "Description", "foo")
+	path := filepath.Join(dir, "a.service.d", "foo.conf")
+
+	require.NoError(t, m.writeDropIn("a.service", "foo", f))
+	require.True(t, m.writes.wrote(path, contentHash([]byte(f.String()))))
+
+	// A later edit by someone else doesn't match the recorded write.
+	require.NoError(t, os.WriteFile(path, []byte("[Unit]\nDescription=bar\n"), 0o644))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.False(t, m.writes.wrote(path, contentHash(content)))
+
+	require.NoError(t, m.removeDropIn("a.service", "foo"))
+	require.True(t, m.writes.wrote(path, ""))
+	require.False(t, m.writes.wrote(filepath.Join(dir, "b.service"), ""))
+}
+
+func Test_Unit_WatchDrift_InvalidArguments(t *testing.T) {
+	m := &manager{}
+	require.Error(t, m.WatchDrift(context.Background(), []string{"a.service"}, nil))
+	require.Error(t, m.WatchDrift(context.Background(), nil, make(chan DriftEvent)))
+	require.ErrorIs(t, m.WatchDrift(context.Background(), []string{"a"}, make(chan DriftEvent)), ErrInvalidUnitName)
+}
+
+func Test_Unit_waitDriftCheck(t *testing.T) {
+	signals := make(chan *godbus.Signal, 2)
+	tick := make(chan time.Time, 1)
+
+	// Ticks check files without systemd signaling.
+	tick <- time.Now()
+	signaled, err := waitDriftCheck(t.Context(), signals, tick)
+	require.NoError(t, err)
+	require.False(t, signaled)
+
+	// Other signals are ignored.
+	signals <- &godbus.Signal{Name: managerInterface + ".JobNew"}
+	signals <- &godbus.Signal{Name: managerInterface + ".Reloading"}
+	signaled, err = waitDriftCheck(t.Context(), signals, tick)
+	require.NoError(t, err)
+	require.True(t, signaled)
+
+	close(signals)
+	_, err = waitDriftCheck(t.Context(), signals, tick)
+	require.ErrorIs(t, err, ErrDisconnected)
+}
@@ -0,0 +1,45 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+)
+
+// dropInPath returns where the named drop-in of a unit is installed.
+func (m *manager) dropInPath(unit, dropIn string) string {
+	return filepath.Join(m.options.unitDirectory, unit+".d", dropIn+".conf")
+}
+
+// writeDropIn installs a drop-in extending the named unit. systemd needs to
+// be reloaded for it to apply.
+func (m *manager) writeDropIn(unit, dropIn string, f *unitfile.File) error {
+	path := m.dropInPath(unit, dropIn)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to install drop-in %q of unit %q: %w", dropIn, unit, err)
+	}
+	content := []byte(f.String())
+	if err := writeFileAtomic(path, content); err != nil {
+		return fmt.Errorf("failed to install drop-in %q of unit %q: %w", dropIn, unit, err)
+	}
+	m.writes.record(path, content)
+
+	return nil
+}
+
+// removeDropIn removes the named drop-in of a unit, if installed. systemd
+// needs to be reloaded for it to apply.
+func (m *manager) removeDropIn(unit, dropIn string) error {
+	path := m.dropInPath(unit, dropIn)
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove drop-in %q of unit %q: %w", dropIn, unit, err)
+	}
+	m.writes.record(path, nil)
+
+	return nil
+}
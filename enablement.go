@@ -0,0 +1,93 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitFileState is the enablement state of a unit file.
+type UnitFileState string
+
+const (
+	UnitFileEnabled        UnitFileState = "enabled"
+	UnitFileEnabledRuntime UnitFileState = "enabled-runtime"
+	UnitFileLinked         UnitFileState = "linked"
+	UnitFileLinkedRuntime  UnitFileState = "linked-runtime"
+	UnitFileAlias          UnitFileState = "alias"
+	UnitFileMasked         UnitFileState = "masked"
+	UnitFileMaskedRuntime  UnitFileState = "masked-runtime"
+	UnitFileStatic         UnitFileState = "static"
+	UnitFileIndirect       UnitFileState = "indirect"
+	UnitFileDisabled       UnitFileState = "disabled"
+	UnitFileGenerated      UnitFileState = "generated"
+	UnitFileTransient      UnitFileState = "transient"
+	UnitFileBad            UnitFileState = "bad"
+)
+
+// Enabled returns whether the unit file is enabled, persistently or at
+// runtime.
+func (s UnitFileState) Enabled() bool {
+	return s == UnitFileEnabled || s == UnitFileEnabledRuntime
+}
+
+// Masked returns whether the unit file is masked, persistently or at runtime.
+func (s UnitFileState) Masked() bool {
+	return s == UnitFileMasked || s == UnitFileMaskedRuntime
+}
+
+// EnablementState returns the enablement state of the named unit file.
+func (m *manager) EnablementState(parentCtx context.Context, unit string) (UnitFileState, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "EnablementState")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	state, err := m.unitFileState(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	span.SetAttributes(otelattr.String("state", string(state)))
+	span.SetStatus(otelcodes.Ok, "retrieved unit file state")
+
+	return state, nil
+}
+
+// IsEnabled returns whether the named unit file is enabled, persistently or
+// at runtime.
+func (m *manager) IsEnabled(ctx context.Context, unit string) (bool, error) {
+	state, err := m.EnablementState(ctx, unit)
+	if err != nil {
+		return false, err
+	}
+
+	return state.Enabled(), nil
+}
+
+// unitFileState calls the GetUnitFileState D-Bus method, which go-systemd
+// doesn't wrap.
+func (m *manager) unitFileState(ctx context.Context, unit string) (UnitFileState, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return "", err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return "", ErrDisconnected
+	}
+
+	var state string
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().systemd().CallWithContext(ctx, managerInterface+".GetUnitFileState", 0, unit).Store(&state)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve unit file state of %q: %w", unit, err)
+	}
+
+	return UnitFileState(state), nil
+}
@@ -0,0 +1,18 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_UnitFileState(t *testing.T) {
+	require.True(t, UnitFileEnabled.Enabled())
+	require.True(t, UnitFileEnabledRuntime.Enabled())
+	require.False(t, UnitFileStatic.Enabled())
+	require.False(t, UnitFileDisabled.Enabled())
+
+	require.True(t, UnitFileMasked.Masked())
+	require.True(t, UnitFileMaskedRuntime.Masked())
+	require.False(t, UnitFileLinked.Masked())
+}
@@ -0,0 +1,235 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// environmentName matches valid environment variable names.
+var environmentName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SetEnvironment sets variables in the systemd manager environment, which
+// units started afterwards inherit.
+func (m *manager) SetEnvironment(parentCtx context.Context, vars map[string]string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SetEnvironment")
+	names := slices.Sorted(maps.Keys(vars))
+	span.SetAttributes(otelattr.StringSlice("names", names))
+	defer span.End()
+
+	assignments, err := environmentAssignments(vars)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if err := m.callEnvironment(ctx, "SetEnvironment", assignments); err != nil {
+		err := fmt.Errorf("failed to set environment variables %q: %w", names, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "set environment variables")
+
+	return nil
+}
+
+// UnsetEnvironment removes variables from the systemd manager environment.
+func (m *manager) UnsetEnvironment(parentCtx context.Context, names []string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "UnsetEnvironment")
+	span.SetAttributes(otelattr.StringSlice("names", names))
+	defer span.End()
+
+	for _, n := range names {
+		if !environmentName.MatchString(n) {
+			err := fmt.Errorf("invalid environment variable name %q", n)
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+
+			return err
+		}
+	}
+	if err := m.callEnvironment(ctx, "UnsetEnvironment", names); err != nil {
+		err := fmt.Errorf("failed to unset environment variables %q: %w", names, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "unset environment variables")
+
+	return nil
+}
+
+// ShowEnvironment returns the systemd manager environment.
+func (m *manager) ShowEnvironment(parentCtx context.Context) (map[string]string, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ShowEnvironment")
+	defer span.End()
+
+	vars, err := m.showEnvironment(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve environment: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved environment")
+
+	return vars, nil
+}
+
+// showEnvironment reads the Environment property of the systemd manager.
+func (m *manager) showEnvironment(ctx context.Context) (map[string]string, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var variant godbus.Variant
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().systemd().CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, managerInterface, "Environment").Store(&variant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := decodeProperty[[]string](variant)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEnvironment(assignments), nil
+}
+
+// callEnvironment calls one of the environment methods of the systemd
+// manager, which take a list of strings.
+func (m *manager) callEnvironment(ctx context.Context, method string, args []string) error {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return ErrDisconnected
+	}
+
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().systemd().CallWithContext(ctx, managerInterface+"."+method, 0, args).Store()
+	})
+	if IsAccessDenied(err) {
+		return fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+
+	return err
+}
+
+// environmentAssignments turns variables into sorted NAME=value
+// assignments.
+func environmentAssignments(vars map[string]string) ([]string, error) {
+	assignments := make([]string, 0, len(vars))
+	for _, n := range slices.Sorted(maps.Keys(vars)) {
+		if !environmentName.MatchString(n) {
+			return nil, fmt.Errorf("invalid environment variable name %q", n)
+		}
+		assignments = append(assignments, n+"="+vars[n])
+	}
+
+	return assignments, nil
+}
+
+// parseEnvironment turns NAME=value assignments into variables.
+func parseEnvironment(assignments []string) map[string]string {
+	vars := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		if n, v, ok := strings.Cut(a, "="); ok {
+			vars[n] = v
+		}
+	}
+
+	return vars
+}
+
+// environmentDropIn is the name of the drop-in holding the environment set
+// by SetUnitEnvironment.
+const environmentDropIn = "50-systemdmanager-environment"
+
+// SetUnitEnvironment sets the environment of the named unit with a drop-in,
+// replacing the variables set by a previous call. Empty vars removes the
+// drop-in. systemd is reloaded for the environment to apply, and the unit
+// restarted if restart is set, which is needed for running processes to see
+// it.
+func (m *manager) SetUnitEnvironment(parentCtx context.Context, unit string, vars map[string]string, restart bool) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SetUnitEnvironment")
+	names := slices.Sorted(maps.Keys(vars))
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.StringSlice("names", names), otelattr.Bool("restart", restart))
+	defer span.End()
+
+	if err := m.setUnitEnvironment(ctx, unit, vars, restart); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("set environment of unit %q", unit))
+
+	return nil
+}
+
+// setUnitEnvironment implements SetUnitEnvironment.
+func (m *manager) setUnitEnvironment(ctx context.Context, unit string, vars map[string]string, restart bool) error {
+	if err := m.validateUnit(unit); err != nil {
+		return err
+	}
+	if err := m.admitChange(ctx, unit); err != nil {
+		return err
+	}
+
+	if len(vars) == 0 {
+		if err := m.removeDropIn(unit, environmentDropIn); err != nil {
+			return err
+		}
+	} else {
+		f, err := environmentFile(vars)
+		if err != nil {
+			return err
+		}
+		if err := m.writeDropIn(unit, environmentDropIn, f); err != nil {
+			return err
+		}
+	}
+	if err := m.daemonReload(ctx); err != nil {
+		return err
+	}
+	if restart {
+		return m.Restart(ctx, unit)
+	}
+
+	return nil
+}
+
+// environmentFile returns a unit file setting variables with Environment=.
+func environmentFile(vars map[string]string) (*unitfile.File, error) {
+	assignments, err := environmentAssignments(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &unitfile.File{}
+	for _, a := range assignments {
+		// % starts a specifier, which isn't wanted in values.
+		f.Add("Service", "Environment", unitfile.QuoteWord(strings.ReplaceAll(a, "%", "%%")))
+	}
+
+	return f, nil
+}
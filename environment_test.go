@@ -0,0 +1,72 @@
+package systemdmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_environmentAssignments(t *testing.T) {
+	assignments, err := environmentAssignments(map[string]string{
+		"HTTPS_PROXY": "http://proxy:3128",
+		"NO_PROXY":    "localhost,127.0.0.1",
+		"EMPTY":       "",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"EMPTY=", "HTTPS_PROXY=http://proxy:3128", "NO_PROXY=localhost,127.0.0.1"}, assignments)
+
+	for _, n := range []string{"", "1ABC", "A-B", "A=B"} {
+		_, err := environmentAssignments(map[string]string{n: "value"})
+		require.Error(t, err, n)
+	}
+}
+
+func Test_Unit_parseEnvironment(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"LANG": "C.UTF-8",
+		"PATH": "/usr/bin:/bin",
+		"OPTS": "a=b",
+	}, parseEnvironment([]string{"LANG=C.UTF-8", "PATH=/usr/bin:/bin", "OPTS=a=b", "garbage"}))
+}
+
+func Test_Unit_environmentFile(t *testing.T) {
+	f, err := environmentFile(map[string]string{
+		"TOKEN": "s3cr%t",
+		"OPTS":  `--name "my app"`,
+		"PLAIN": "value",
+	})
+	require.NoError(t, err)
+	require.Equal(t, `[Service]
+Environment="OPTS=--name \"my app\""
+Environment=PLAIN=value
+Environment=TOKEN=s3cr%%t
+`, f.String())
+
+	// Values survive systemd's unquoting.
+	words, err := unitfile.SplitWords(f.Values("Service", "Environment")[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{`OPTS=--name "my app"`}, words)
+
+	_, err = environmentFile(map[string]string{"A B": "value"})
+	require.Error(t, err)
+}
+
+func Test_Unit_SetUnitEnvironment_DropIn(t *testing.T) {
+	m := &manager{options: newOptions([]Option{WithUnitDirectory(t.TempDir())})}
+	f, err := environmentFile(map[string]string{"FOO": "bar"})
+	require.NoError(t, err)
+
+	require.NoError(t, m.writeDropIn("foo.service", environmentDropIn, f))
+	content, err := os.ReadFile(m.dropInPath("foo.service", environmentDropIn))
+	require.NoError(t, err)
+	require.Equal(t, "[Service]\nEnvironment=FOO=bar\n", string(content))
+	require.Equal(t, "foo.service.d", filepath.Base(filepath.Dir(m.dropInPath("foo.service", environmentDropIn))))
+
+	require.NoError(t, m.removeDropIn("foo.service", environmentDropIn))
+	require.NoFileExists(t, m.dropInPath("foo.service", environmentDropIn))
+	// Removing a missing drop-in is fine.
+	require.NoError(t, m.removeDropIn("foo.service", environmentDropIn))
+}
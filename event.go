@@ -0,0 +1,110 @@
+package systemdmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// UnitStatus is a dbus.UnitStatus with stable JSON and YAML field names, for
+// shipping statuses to logs and APIs.
+type UnitStatus struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	LoadState   string `json:"load_state" yaml:"load_state"`
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	SubState    string `json:"sub_state" yaml:"sub_state"`
+	Followed    string `json:"followed,omitempty" yaml:"followed,omitempty"`
+	Path        string `json:"path" yaml:"path"`
+	JobID       uint32 `json:"job_id,omitempty" yaml:"job_id,omitempty"`
+	JobType     string `json:"job_type,omitempty" yaml:"job_type,omitempty"`
+	JobPath     string `json:"job_path,omitempty" yaml:"job_path,omitempty"`
+}
+
+// NewUnitStatus converts a status as returned by Status and Watch. It
+// returns nil for a nil status.
+func NewUnitStatus(status *dbus.UnitStatus) *UnitStatus {
+	if status == nil {
+		return nil
+	}
+
+	return &UnitStatus{
+		Name:        status.Name,
+		Description: status.Description,
+		LoadState:   status.LoadState,
+		ActiveState: status.ActiveState,
+		SubState:    status.SubState,
+		Followed:    status.Followed,
+		Path:        string(status.Path),
+		JobID:       status.JobId,
+		JobType:     status.JobType,
+		JobPath:     string(status.JobPath),
+	}
+}
+
+// Event is a unit status change observed by a watch.
+type Event struct {
+	Time time.Time `json:"time" yaml:"time"`
+	Unit string    `json:"unit" yaml:"unit"`
+	// Status is nil when the unit was unloaded.
+	Status *UnitStatus `json:"status" yaml:"status"`
+}
+
+// NewEvent returns an event for a status received from Watch, timestamped
+// now.
+func NewEvent(unit string, status *dbus.UnitStatus) Event {
+	return Event{
+		Time:   time.Now(),
+		Unit:   unit,
+		Status: NewUnitStatus(status),
+	}
+}
+
+// supervisorEventJSON is the encoding of a SupervisorEvent.
+type supervisorEventJSON struct {
+	Unit    string `json:"unit" yaml:"unit"`
+	Attempt int    `json:"attempt" yaml:"attempt"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	GaveUp  bool   `json:"gave_up,omitempty" yaml:"gave_up,omitempty"`
+}
+
+// marshaled returns the encoding of e, which has the error as a string.
+func (e SupervisorEvent) marshaled() supervisorEventJSON {
+	j := supervisorEventJSON{
+		Unit:    e.Unit,
+		Attempt: e.Attempt,
+		GaveUp:  e.GaveUp,
+	}
+	if e.Err != nil {
+		j.Error = e.Err.Error()
+	}
+
+	return j
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e SupervisorEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.marshaled())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The error is restored as an
+// opaque error with the same message.
+func (e *SupervisorEvent) UnmarshalJSON(b []byte) error {
+	var j supervisorEventJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*e = SupervisorEvent{Unit: j.Unit, Attempt: j.Attempt, GaveUp: j.GaveUp}
+	if j.Error != "" {
+		e.Err = errors.New(j.Error)
+	}
+
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e SupervisorEvent) MarshalYAML() (any, error) {
+	return e.marshaled(), nil
+}
@@ -0,0 +1,93 @@
+package systemdmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Unit_Event_Marshal(t *testing.T) {
+	event := NewEvent("foo.service", &dbus.UnitStatus{
+		Name:        "foo.service",
+		Description: "Foo",
+		LoadState:   "loaded",
+		ActiveState: "active",
+		SubState:    "running",
+		Path:        "/org/freedesktop/systemd1/unit/foo_2eservice",
+	})
+	event.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	b, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"time": "2024-01-02T03:04:05Z",
+		"unit": "foo.service",
+		"status": {
+			"name": "foo.service",
+			"description": "Foo",
+			"load_state": "loaded",
+			"active_state": "active",
+			"sub_state": "running",
+			"path": "/org/freedesktop/systemd1/unit/foo_2eservice"
+		}
+	}`, string(b))
+
+	var decoded Event
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, event, decoded)
+
+	b, err = yaml.Marshal(event)
+	require.NoError(t, err)
+	require.YAMLEq(t, `
+time: 2024-01-02T03:04:05Z
+unit: foo.service
+status:
+  name: foo.service
+  description: Foo
+  load_state: loaded
+  active_state: active
+  sub_state: running
+  path: /org/freedesktop/systemd1/unit/foo_2eservice
+`, string(b))
+
+	// Unloaded units have no status.
+	b, err = json.Marshal(NewEvent("foo.service", nil))
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"status":null`)
+}
+
+func Test_Unit_SupervisorEvent_Marshal(t *testing.T) {
+	event := SupervisorEvent{Unit: "foo.service", Attempt: 2, Err: errors.New("boom")}
+
+	b, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"unit": "foo.service", "attempt": 2, "error": "boom"}`, string(b))
+
+	b, err = yaml.Marshal(event)
+	require.NoError(t, err)
+	require.YAMLEq(t, "unit: foo.service\nattempt: 2\nerror: boom\n", string(b))
+}
+
+func Test_Unit_StateSnapshot_Marshal(t *testing.T) {
+	snap := StateSnapshot{
+		Units:   []UnitSnapshot{{Name: "foo.service", ActiveState: "active", SubState: "running", UnitFileState: UnitFileEnabled}},
+		TakenAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := json.Marshal(snap)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"units": [{"name": "foo.service", "active_state": "active", "sub_state": "running", "unit_file_state": "enabled"}],
+		"taken_at": "2024-01-02T03:04:05Z"
+	}`, string(b))
+
+	// Snapshots can be saved and restored later.
+	var decoded StateSnapshot
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, snap, decoded)
+}
@@ -0,0 +1,247 @@
+package systemdmanager
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+const (
+	// defaultJournalMaxSize is the size at which journal files are rotated
+	// by default.
+	defaultJournalMaxSize = 10 << 20
+	// defaultJournalMaxFiles is how many rotated journal files are kept by
+	// default.
+	defaultJournalMaxFiles = 3
+)
+
+// EventJournal is an EventSink appending notifications as JSON lines to a
+// local file, so the history of units survives restarts. Once the file
+// reaches its maximum size, it is rotated to "<path>.1", previous rotations
+// are shifted to "<path>.2" and so on, and the oldest are removed.
+type EventJournal struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+}
+
+// Assert EventJournal fulfills the EventSink interface.
+var _ EventSink = (*EventJournal)(nil)
+
+// EventJournalOption configures an EventJournal.
+type EventJournalOption func(*EventJournal)
+
+// WithJournalMaxSize sets the size in bytes at which the journal file is
+// rotated. Defaults to 10 MiB.
+func WithJournalMaxSize(size int64) EventJournalOption {
+	return func(j *EventJournal) {
+		j.maxSize = size
+	}
+}
+
+// WithJournalMaxFiles sets how many rotated journal files are kept, besides
+// the current one. Defaults to 3.
+func WithJournalMaxFiles(n int) EventJournalOption {
+	return func(j *EventJournal) {
+		j.maxFiles = n
+	}
+}
+
+// OpenEventJournal returns an EventJournal appending to the named file,
+// which is created if needed. The journal must be closed when done.
+func OpenEventJournal(path string, opts ...EventJournalOption) (*EventJournal, error) {
+	j := &EventJournal{
+		path:     path,
+		maxSize:  defaultJournalMaxSize,
+		maxFiles: defaultJournalMaxFiles,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// open opens the current journal file for appending.
+func (j *EventJournal) open() error {
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open event journal %q: %w", j.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return fmt.Errorf("failed to open event journal %q: %w", j.path, err)
+	}
+	j.file, j.size = f, info.Size()
+	if err := j.terminate(); err != nil {
+		f.Close()
+		j.file = nil
+
+		return err
+	}
+
+	return nil
+}
+
+// terminate ends a line left partially written by a crash, so that it
+// doesn't corrupt the next one.
+func (j *EventJournal) terminate() error {
+	if j.size == 0 {
+		return nil
+	}
+	f, err := os.Open(j.path)
+	if err != nil {
+		return fmt.Errorf("failed to open event journal %q: %w", j.path, err)
+	}
+	defer f.Close()
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, j.size-1); err != nil {
+		return fmt.Errorf("failed to open event journal %q: %w", j.path, err)
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	written, err := j.file.Write([]byte{'\n'})
+	j.size += int64(written)
+
+	return err
+}
+
+// Publish appends n as a single JSON line, rotating the journal first if it
+// is full.
+func (j *EventJournal) Publish(_ context.Context, n Notification) error {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return fs.ErrClosed
+	}
+	if j.size > 0 && j.size+int64(len(b)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := j.file.Write(b)
+	j.size += int64(written)
+
+	return err
+}
+
+// rotate moves the current journal file to the first rotation, shifting
+// older ones, and opens a new one.
+func (j *EventJournal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	j.file = nil
+	if j.maxFiles <= 0 {
+		if err := os.Remove(j.path); err != nil {
+			return fmt.Errorf("failed to rotate event journal %q: %w", j.path, err)
+		}
+
+		return j.open()
+	}
+	for i := j.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(rotatedJournal(j.path, i), rotatedJournal(j.path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to rotate event journal %q: %w", j.path, err)
+		}
+	}
+	if err := os.Rename(j.path, rotatedJournal(j.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate event journal %q: %w", j.path, err)
+	}
+
+	return j.open()
+}
+
+// Close closes the journal file.
+func (j *EventJournal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+// rotatedJournal returns the path of the i-th rotation of a journal file.
+func rotatedJournal(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// ReadEventJournal returns the notifications of the named journal file and
+// its rotations, oldest first. Lines left partially written by a crash are
+// skipped.
+func ReadEventJournal(path string) ([]Notification, error) {
+	var rotations int
+	for {
+		if _, err := os.Stat(rotatedJournal(path, rotations+1)); err != nil {
+			break
+		}
+		rotations++
+	}
+
+	var notifications []Notification
+	for i := rotations; i >= 0; i-- {
+		name := path
+		if i > 0 {
+			name = rotatedJournal(path, i)
+		}
+		n, err := readJournalFile(name)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n...)
+	}
+
+	return notifications, nil
+}
+
+// readJournalFile returns the notifications of a single journal file.
+func readJournalFile(name string) ([]Notification, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event journal %q: %w", name, err)
+	}
+	defer f.Close()
+
+	var notifications []Notification
+	reader := bufio.NewReader(f)
+	for {
+		b, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Whatever follows the last newline wasn't fully written.
+			return notifications, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event journal %q: %w", name, err)
+		}
+		var n Notification
+		if json.Unmarshal(b, &n) == nil {
+			notifications = append(notifications, n)
+		}
+	}
+}
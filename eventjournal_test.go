@@ -0,0 +1,78 @@
+package systemdmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_EventJournal(t *testing.T) {
+	notification := func(i int) Notification {
+		return Notification{
+			Type:   NotificationStatus,
+			Status: &Event{Time: time.Unix(int64(i), 0).UTC(), Unit: "a.service", Status: &UnitStatus{ActiveState: "active"}},
+		}
+	}
+
+	t.Run("Survives reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		supervisor := Notification{
+			Type:       NotificationSupervisor,
+			Supervisor: &SupervisorEvent{Unit: "a.service", Attempt: 2, Err: errors.New("boom")},
+		}
+		for _, n := range []Notification{notification(1), supervisor} {
+			j, err := OpenEventJournal(path)
+			require.NoError(t, err)
+			require.NoError(t, j.Publish(t.Context(), n))
+			require.NoError(t, j.Close())
+		}
+
+		got, err := ReadEventJournal(path)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		require.Equal(t, notification(1), got[0])
+		require.Equal(t, "boom", got[1].Supervisor.Err.Error())
+		got[1].Supervisor.Err = supervisor.Supervisor.Err
+		require.Equal(t, supervisor, got[1])
+	})
+
+	t.Run("Rotates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		// Each file holds a single notification, and two rotations are kept.
+		j, err := OpenEventJournal(path, WithJournalMaxSize(1), WithJournalMaxFiles(2))
+		require.NoError(t, err)
+		defer j.Close()
+		for i := range 5 {
+			require.NoError(t, j.Publish(t.Context(), notification(i)))
+		}
+
+		got, err := ReadEventJournal(path)
+		require.NoError(t, err)
+		require.Equal(t, []Notification{notification(2), notification(3), notification(4)}, got)
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("Skips partial lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(`{"type":"unit.sta`), 0o600))
+		j, err := OpenEventJournal(path)
+		require.NoError(t, err)
+		require.NoError(t, j.Publish(t.Context(), notification(1)))
+		require.NoError(t, j.Close())
+
+		got, err := ReadEventJournal(path)
+		require.NoError(t, err)
+		require.Equal(t, []Notification{notification(1)}, got)
+	})
+
+	t.Run("Fails once closed", func(t *testing.T) {
+		j, err := OpenEventJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+		require.NoError(t, err)
+		require.NoError(t, j.Close())
+		require.ErrorIs(t, j.Publish(t.Context(), notification(1)), os.ErrClosed)
+	})
+}
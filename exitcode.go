@@ -0,0 +1,91 @@
+package systemdmanager
+
+import (
+	"errors"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// ExitCode is a process exit status, as returned by systemctl, following the
+// LSB init script conventions, so tools built on a Manager can stand in for
+// systemctl in scripts checking it.
+type ExitCode int
+
+// Exit codes of actions, such as starting a unit.
+const (
+	// ExitSuccess means the action succeeded.
+	ExitSuccess ExitCode = 0
+	// ExitFailure means the action failed for any other reason.
+	ExitFailure ExitCode = 1
+	// ExitInvalidArgument means the action was given an invalid unit name
+	// or setting.
+	ExitInvalidArgument ExitCode = 2
+	// ExitNotImplemented means the action isn't supported.
+	ExitNotImplemented ExitCode = 3
+	// ExitNoPermission means the caller isn't allowed to run the action.
+	ExitNoPermission ExitCode = 4
+	// ExitNotInstalled means the unit doesn't exist.
+	ExitNotInstalled ExitCode = 5
+)
+
+// Exit codes of status queries, such as systemctl is-active and status.
+const (
+	// ExitStatusActive means the unit is active.
+	ExitStatusActive ExitCode = 0
+	// ExitStatusNotActive means the unit is inactive or failed. systemctl
+	// is-active exits with it for units that don't exist too.
+	ExitStatusNotActive ExitCode = 3
+	// ExitStatusNoSuchUnit means the unit doesn't exist, as systemctl status
+	// reports it.
+	ExitStatusNoSuchUnit ExitCode = 4
+	// ExitStatusUnknown means the status of the unit couldn't be retrieved.
+	ExitStatusUnknown ExitCode = 4
+)
+
+// ActionExitCode returns the exit code systemctl exits with when an action
+// fails with err, which is ExitSuccess if err is nil.
+func ActionExitCode(err error) ExitCode {
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, ErrInvalidUnitName), errors.Is(err, ErrWrongUnitType), errors.Is(err, ErrUnsupportedSetting), errors.Is(err, ErrInvalidJobMode):
+		return ExitInvalidArgument
+	case errors.Is(err, ErrUnsupported):
+		return ExitNotImplemented
+	case errors.Is(err, ErrPermissionDenied):
+		return ExitNoPermission
+	case errors.Is(err, ErrUnitNotFound):
+		return ExitNotInstalled
+	default:
+		return ExitFailure
+	}
+}
+
+// StatusExitCode returns the exit code systemctl status exits with for a unit
+// status, as returned by Status, or the error retrieving it. Units that don't
+// exist get ExitStatusNoSuchUnit. Use IsActiveExitCode to check whether a
+// unit is active instead.
+func StatusExitCode(status *dbus.UnitStatus, err error) ExitCode {
+	switch {
+	case errors.Is(err, ErrUnitNotFound), err == nil && status != nil && status.LoadState == "not-found":
+		return ExitStatusNoSuchUnit
+	default:
+		return IsActiveExitCode(status, err)
+	}
+}
+
+// IsActiveExitCode returns the exit code systemctl is-active exits with for a
+// unit status, as returned by Status, or the error retrieving it. Units that
+// don't exist aren't active, so they get ExitStatusNotActive.
+func IsActiveExitCode(status *dbus.UnitStatus, err error) ExitCode {
+	switch {
+	case errors.Is(err, ErrUnitNotFound):
+		return ExitStatusNotActive
+	case err != nil, status == nil:
+		return ExitStatusUnknown
+	case status.ActiveState == "active", status.ActiveState == "reloading", status.ActiveState == "refreshing":
+		return ExitStatusActive
+	default:
+		return ExitStatusNotActive
+	}
+}
@@ -0,0 +1,50 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_ActionExitCode(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want ExitCode
+	}{
+		{nil, ExitSuccess},
+		{fmt.Errorf("%w %q", ErrInvalidUnitName, "foo"), ExitInvalidArgument},
+		{fmt.Errorf("%w %q", ErrInvalidJobMode, "flush"), ExitInvalidArgument},
+		{ErrWrongUnitType, ExitInvalidArgument},
+		{ErrUnsupported, ExitNotImplemented},
+		{ErrPermissionDenied, ExitNoPermission},
+		{fmt.Errorf("%w: %q", ErrUnitNotFound, "foo.service"), ExitNotInstalled},
+		{&JobError{Op: "start", Unit: "foo.service", Result: "failed"}, ExitFailure},
+		{ErrDisconnected, ExitFailure},
+		{errors.New("boom"), ExitFailure},
+	} {
+		require.Equal(t, tt.want, ActionExitCode(tt.err), "%v", tt.err)
+	}
+}
+
+func Test_Unit_StatusExitCode(t *testing.T) {
+	require.Equal(t, ExitStatusActive, StatusExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "active"}, nil))
+	require.Equal(t, ExitStatusNotActive, StatusExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "failed"}, nil))
+	// systemctl status reports units that don't exist as such.
+	require.Equal(t, ExitStatusNoSuchUnit, StatusExitCode(&dbus.UnitStatus{LoadState: "not-found", ActiveState: "inactive"}, nil))
+	require.Equal(t, ExitStatusNoSuchUnit, StatusExitCode(nil, fmt.Errorf("%w: %q", ErrUnitNotFound, "foo.service")))
+	require.Equal(t, ExitStatusUnknown, StatusExitCode(nil, ErrDisconnected))
+}
+
+func Test_Unit_IsActiveExitCode(t *testing.T) {
+	require.Equal(t, ExitStatusActive, IsActiveExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "active"}, nil))
+	require.Equal(t, ExitStatusActive, IsActiveExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "reloading"}, nil))
+	require.Equal(t, ExitStatusNotActive, IsActiveExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "inactive"}, nil))
+	require.Equal(t, ExitStatusNotActive, IsActiveExitCode(&dbus.UnitStatus{LoadState: "loaded", ActiveState: "failed"}, nil))
+	// systemctl is-active reports units that don't exist as inactive.
+	require.Equal(t, ExitStatusNotActive, IsActiveExitCode(&dbus.UnitStatus{LoadState: "not-found", ActiveState: "inactive"}, nil))
+	require.Equal(t, ExitStatusNotActive, IsActiveExitCode(nil, fmt.Errorf("%w: %q", ErrUnitNotFound, "foo.service")))
+	require.Equal(t, ExitStatusUnknown, IsActiveExitCode(nil, ErrDisconnected))
+}
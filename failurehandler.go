@@ -0,0 +1,128 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+	"testing/fstest"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// failureHandlerDropIn is the name of the drop-in holding the OnFailure=
+// dependency set by SetFailureHandler.
+const failureHandlerDropIn = "50-systemdmanager-on-failure"
+
+// WebhookNotifierUnit is the name of the template service returned by
+// WebhookNotifier.
+const WebhookNotifierUnit = "systemdmanager-notify-failure@.service"
+
+// SetFailureHandler makes systemd start handlerUnit whenever unit fails,
+// with an OnFailure= dependency set in a drop-in, replacing the handler set
+// by a previous call. If handlerUnit is a template, such as
+// WebhookNotifierUnit, it's instantiated with the name of the failed unit.
+// An empty handlerUnit removes the drop-in. systemd is reloaded for the
+// handler to apply.
+func (m *manager) SetFailureHandler(parentCtx context.Context, unit, handlerUnit string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SetFailureHandler")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("handler", handlerUnit))
+	defer span.End()
+
+	if err := m.setFailureHandler(ctx, unit, handlerUnit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("set failure handler of unit %q", unit))
+
+	return nil
+}
+
+// setFailureHandler implements SetFailureHandler.
+func (m *manager) setFailureHandler(ctx context.Context, unit, handlerUnit string) error {
+	if err := m.validateUnit(unit); err != nil {
+		return err
+	}
+
+	if err := m.admitChange(ctx, unit); err != nil {
+		return err
+	}
+
+	if handlerUnit == "" {
+		if err := m.removeDropIn(unit, failureHandlerDropIn); err != nil {
+			return err
+		}
+	} else {
+		if err := m.validateUnit(handlerUnit); err != nil {
+			return err
+		}
+		f := &unitfile.File{}
+		f.Set("Unit", "OnFailure", failureHandlerName(handlerUnit))
+		if err := m.writeDropIn(unit, failureHandlerDropIn, f); err != nil {
+			return err
+		}
+	}
+
+	return m.daemonReload(ctx)
+}
+
+// failureHandlerName returns the OnFailure= value starting handlerUnit,
+// instantiating templates with the name of the failed unit.
+func failureHandlerName(handlerUnit string) string {
+	if IsTemplate(handlerUnit) {
+		return strings.Replace(handlerUnit, "@.", "@%n.", 1)
+	}
+
+	return handlerUnit
+}
+
+// webhookNotifierBody is the JSON body posted by the WebhookNotifierUnit, as
+// a NotificationFailure notification. %i is the name of the failed unit.
+const webhookNotifierBody = `{"type":"unit.failure","failure":{"unit":"%i","kind":"failed"}}`
+
+// WebhookNotifier returns a file system holding the WebhookNotifierUnit,
+// which posts a NotificationFailure notification to rawURL with curl, to be
+// installed with InstallFromFS and used with SetFailureHandler:
+//
+//	notifier, err := WebhookNotifier("https://alerts.example.com/hook")
+//	...
+//	err = mgr.InstallFromFS(ctx, notifier)
+//	...
+//	err = mgr.SetFailureHandler(ctx, "foo.service", WebhookNotifierUnit)
+func WebhookNotifier(rawURL string) (fs.FS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", rawURL)
+	}
+
+	args := []string{
+		"/usr/bin/curl", "--fail", "--silent", "--show-error",
+		"--retry", "3", "--max-time", "30",
+		"--header", "Content-Type: application/json",
+		"--data", webhookNotifierBody,
+		// % starts a specifier, which isn't wanted in the URL.
+		strings.ReplaceAll(rawURL, "%", "%%"),
+	}
+	for i, arg := range args {
+		args[i] = unitfile.QuoteWord(arg)
+	}
+
+	f := &unitfile.File{}
+	f.Set("Unit", "Description", "Notify webhook of %i failure")
+	f.Set("Service", "Type", "oneshot")
+	f.Set("Service", "ExecStart", strings.Join(args, " "))
+
+	return fstest.MapFS{
+		WebhookNotifierUnit: &fstest.MapFile{Data: []byte(f.String()), Mode: 0o644},
+	}, nil
+}
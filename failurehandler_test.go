@@ -0,0 +1,47 @@
+package systemdmanager
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_failureHandlerName(t *testing.T) {
+	require.Equal(t, "alert.service", failureHandlerName("alert.service"))
+	require.Equal(t, "alert@%n.service", failureHandlerName("alert@.service"))
+	require.Equal(t, "alert@pager.service", failureHandlerName("alert@pager.service"))
+}
+
+func Test_Unit_SetFailureHandler_InvalidUnitName(t *testing.T) {
+	m := &manager{}
+	require.ErrorIs(t, m.SetFailureHandler(context.Background(), "foo", "alert.service"), ErrInvalidUnitName)
+	require.ErrorIs(t, m.SetFailureHandler(context.Background(), "foo.service", "alert"), ErrInvalidUnitName)
+}
+
+func Test_Unit_WebhookNotifier(t *testing.T) {
+	fsys, err := WebhookNotifier("https://alerts.example.com/hook?token=a%20b")
+	require.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, WebhookNotifierUnit)
+	require.NoError(t, err)
+	f, err := unitfile.Parse(bytes.NewReader(content))
+	require.NoError(t, err)
+	require.Empty(t, unitfile.Check(f, "service"))
+
+	execStart, ok := f.Get("Service", "ExecStart")
+	require.True(t, ok)
+	args, err := unitfile.SplitWords(execStart)
+	require.NoError(t, err)
+	require.Equal(t, "/usr/bin/curl", args[0])
+	require.Contains(t, args, webhookNotifierBody)
+	require.Equal(t, "https://alerts.example.com/hook?token=a%%20b", args[len(args)-1])
+
+	for _, rawURL := range []string{"", "alerts.example.com/hook", "ftp://alerts.example.com", "https://"} {
+		_, err := WebhookNotifier(rawURL)
+		require.Error(t, err, rawURL)
+	}
+}
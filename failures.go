@@ -0,0 +1,235 @@
+package systemdmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// unitFailedMessageID is the journal MESSAGE_ID of unit failure messages
+// logged by systemd.
+const unitFailedMessageID = "d9b373ed55a64feb8242e02dbe79a49c"
+
+// unitOOMMessageID is the journal MESSAGE_ID of messages logged by systemd
+// when a process of a unit is killed by the kernel OOM killer.
+const unitOOMMessageID = "fe6faa94e7774663a0da52717891d8ef"
+
+// failureLogLines is how many journal lines of a failed unit are reported.
+const failureLogLines = 10
+
+// FailureKind is the kind of a FailureEvent.
+type FailureKind string
+
+const (
+	// FailureKindFailed is a unit entering the failed state.
+	FailureKindFailed FailureKind = "failed"
+	// FailureKindOOM is a process of a unit killed by the OOM killer, which
+	// may or may not fail the unit, depending on its OOMPolicy.
+	FailureKindOOM FailureKind = "oom"
+)
+
+// FailureEvent is a unit failure observed by WatchFailures.
+type FailureEvent struct {
+	Time time.Time   `json:"time" yaml:"time"`
+	Unit string      `json:"unit" yaml:"unit"`
+	Kind FailureKind `json:"kind" yaml:"kind"`
+	// Result is why the unit failed, e.g. "exit-code", "signal", "timeout",
+	// or "oom-kill".
+	Result string `json:"result" yaml:"result"`
+	// ActiveState and SubState are the state of the unit once the failure
+	// was observed, which may have changed since, e.g. when restarted.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	SubState    string `json:"sub_state" yaml:"sub_state"`
+	// Lines are the last journal messages of the unit, oldest first.
+	Lines []string `json:"lines" yaml:"lines"`
+}
+
+// WatchFailures follows the journal for unit failures and OOM kills, which
+// are sent to failuresChan with the unit state and latest journal lines, and
+// told apart by their kind. This is a blocking function, which needs
+// journalctl and access to the system journal.
+func (m *manager) WatchFailures(parentCtx context.Context, failuresChan chan<- FailureEvent) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "WatchFailures")
+	defer span.End()
+
+	// Ensure a non-nil channel is provided.
+	if failuresChan == nil {
+		err := fmt.Errorf("a chan is required for WatchFailures to write unit failures to")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+
+	err := followJournal(ctx, func(e journalEntry) {
+		if !m.allowed(e.Unit) {
+			return
+		}
+		event := FailureEvent{Time: e.Time, Unit: e.Unit, Kind: FailureKindFailed, Result: e.Result}
+		typ := NotificationFailure
+		if e.MessageID == unitOOMMessageID {
+			event.Kind, event.Result = FailureKindOOM, oomKillResult
+			typ = NotificationOOM
+		}
+		// A failed lookup leaves the state or lines empty, rather than
+		// dropping the failure.
+		if status, err := m.Status(ctx, e.Unit); err == nil {
+			event.ActiveState, event.SubState = status.ActiveState, status.SubState
+		}
+		event.Lines, _ = journalLines(ctx, e.Unit, failureLogLines)
+		m.publish(ctx, Notification{Type: typ, Failure: &event})
+
+		select {
+		case failuresChan <- event:
+		case <-ctx.Done():
+		}
+	}, "MESSAGE_ID="+unitFailedMessageID, "MESSAGE_ID="+unitOOMMessageID)
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+
+	return err
+}
+
+// journalEntry is an entry of the journal, in the JSON output of journalctl.
+type journalEntry struct {
+	Time      time.Time
+	MessageID string
+	Unit      string
+	Result    string
+	Message   string
+}
+
+// parseJournalEntry parses a line of the JSON output of journalctl. Binary
+// fields are output as arrays of bytes, rather than strings.
+func parseJournalEntry(line []byte) (journalEntry, error) {
+	field, err := journalFields(line)
+	if err != nil {
+		return journalEntry{}, err
+	}
+
+	e := journalEntry{
+		MessageID: field("MESSAGE_ID"),
+		Unit:      field("UNIT"),
+		Result:    field("UNIT_RESULT"),
+		Message:   field("MESSAGE"),
+	}
+	if usec, err := strconv.ParseInt(field("__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		e.Time = time.UnixMicro(usec).UTC()
+	}
+
+	return e, nil
+}
+
+// journalFields decodes a line of the JSON output of journalctl, returning
+// a function looking fields up. Missing fields are empty.
+func journalFields(line []byte) (func(key string) string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode journal entry: %w", err)
+	}
+
+	return func(key string) string {
+		var s string
+		if json.Unmarshal(fields[key], &s) == nil {
+			return s
+		}
+		var b []int
+		if json.Unmarshal(fields[key], &b) == nil {
+			raw := make([]byte, len(b))
+			for i, c := range b {
+				raw[i] = byte(c)
+			}
+			return string(raw)
+		}
+		return ""
+	}, nil
+}
+
+// followJournal calls handle for every new journal entry matching matches,
+// until ctx is done or journalctl fails.
+func followJournal(ctx context.Context, handle func(journalEntry), matches ...string) error {
+	// Kill journalctl if reading its output fails, or it blocks writing.
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	args := append([]string{"--follow", "--lines=0", "--output=json", "--no-pager"}, matches...)
+	cmd := exec.CommandContext(cmdCtx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to follow journal: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if e, err := parseJournalEntry(scanner.Bytes()); err == nil {
+			handle(e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		cancel()
+		_ = cmd.Wait()
+
+		return fmt.Errorf("failed to follow journal: %w", err)
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return fmt.Errorf("journalctl stopped following the journal: %w", err)
+}
+
+// journalLines returns the last n messages logged by the named unit, oldest
+// first.
+func journalLines(ctx context.Context, unit string, n int) ([]string, error) {
+	entries, err := readJournal(ctx, "--unit="+unit, "--lines="+strconv.Itoa(n))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal of unit %q: %w", unit, err)
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, e.Message)
+	}
+
+	return lines, nil
+}
+
+// readJournal returns the journal entries selected by args, such as matches
+// and --lines, oldest first.
+func readJournal(ctx context.Context, args ...string) ([]journalEntry, error) {
+	return readJournalAs(ctx, parseJournalEntry, args...)
+}
+
+// readJournalAs returns the journal entries selected by args, oldest first,
+// decoded with parse. Entries failing to decode are skipped.
+func readJournalAs[T any](ctx context.Context, parse func(line []byte) (T, error), args ...string) ([]T, error) {
+	args = append([]string{"--output=json", "--no-pager"}, args...)
+	out, err := exec.CommandContext(ctx, "journalctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []T
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if e, err := parse(scanner.Bytes()); err == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,53 @@
+package systemdmanager
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_parseJournalEntry(t *testing.T) {
+	e, err := parseJournalEntry([]byte(`{
+		"__REALTIME_TIMESTAMP": "1714564800000000",
+		"MESSAGE_ID": "d9b373ed55a64feb8242e02dbe79a49c",
+		"UNIT": "app.service",
+		"UNIT_RESULT": "exit-code",
+		"MESSAGE": "app.service: Failed with result 'exit-code'."
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, journalEntry{
+		Time:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		MessageID: "d9b373ed55a64feb8242e02dbe79a49c",
+		Unit:      "app.service",
+		Result:    "exit-code",
+		Message:   "app.service: Failed with result 'exit-code'.",
+	}, e)
+
+	// Binary messages are arrays of bytes.
+	e, err = parseJournalEntry([]byte(`{"MESSAGE": [104, 105, 27]}`))
+	require.NoError(t, err)
+	require.Equal(t, "hi\x1b", e.Message)
+	require.True(t, e.Time.IsZero())
+
+	_, err = parseJournalEntry([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func Test_Unit_followJournal(t *testing.T) {
+	// A journalctl writing an entry too long to read, then hanging.
+	dir := t.TempDir()
+	script := "#!/bin/sh\nhead -c 2097152 /dev/zero | tr '\\0' a\nexec sleep 3600\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := followJournal(ctx, func(journalEntry) {})
+	require.ErrorIs(t, err, bufio.ErrTooLong)
+	require.NoError(t, ctx.Err())
+}
@@ -0,0 +1,156 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// unknownMethodError is the name of the D-Bus error objects fail calls to
+// methods they don't have with, e.g. on older systemd versions.
+const unknownMethodError = "org.freedesktop.DBus.Error.UnknownMethod"
+
+// UnitFileDescriptorStore is the usage of the file descriptor store of a
+// service, which keeps file descriptors, e.g. listening sockets or client
+// connections, passed by the service with FDSTORE=1 across its restarts.
+type UnitFileDescriptorStore struct {
+	// Max is FileDescriptorStoreMax=, zero when the store is disabled.
+	Max uint32 `json:"max" yaml:"max"`
+	// Count is how many file descriptors are stored.
+	Count uint32 `json:"count" yaml:"count"`
+	// Preserve is FileDescriptorStorePreserve=, "no", "yes", or "restart".
+	// It's empty on systemd versions older than 254.
+	Preserve string `json:"preserve,omitempty" yaml:"preserve,omitempty"`
+}
+
+// StoredFileDescriptor is a file descriptor held in the store of a service.
+type StoredFileDescriptor struct {
+	// Name is FDNAME= as passed by the service, "stored" by default.
+	Name string `json:"name" yaml:"name"`
+	// Mode is the file type and permissions, as st_mode of stat(2).
+	Mode uint32 `json:"mode" yaml:"mode"`
+	// DeviceMajor and DeviceMinor are the device holding the file, and
+	// Inode the file on it.
+	DeviceMajor uint32 `json:"device_major" yaml:"device_major"`
+	DeviceMinor uint32 `json:"device_minor" yaml:"device_minor"`
+	Inode       uint64 `json:"inode" yaml:"inode"`
+	// RdevMajor and RdevMinor are the device the file is, if any.
+	RdevMajor uint32 `json:"rdev_major" yaml:"rdev_major"`
+	RdevMinor uint32 `json:"rdev_minor" yaml:"rdev_minor"`
+	// Path is the file the descriptor refers to, e.g. "socket:[12345]".
+	Path string `json:"path" yaml:"path"`
+	// Flags are the file status flags, as returned by fcntl(2) F_GETFL.
+	Flags uint32 `json:"flags" yaml:"flags"`
+}
+
+// FileDescriptorStore returns the size and usage of the file descriptor
+// store of the named service.
+func (m *manager) FileDescriptorStore(parentCtx context.Context, unit string) (UnitFileDescriptorStore, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "FileDescriptorStore")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.unitProperties(ctx, unit, "Service")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitFileDescriptorStore{}, err
+	}
+	store, err := decodeFileDescriptorStore(props)
+	if err != nil {
+		err = fmt.Errorf("failed to decode file descriptor store of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitFileDescriptorStore{}, err
+	}
+	span.SetAttributes(otelattr.Int("max", int(store.Max)), otelattr.Int("count", int(store.Count)))
+	span.SetStatus(otelcodes.Ok, "retrieved file descriptor store")
+
+	return store, nil
+}
+
+// decodeFileDescriptorStore decodes the file descriptor store properties of
+// a service. FileDescriptorStorePreserve is left empty if missing.
+func decodeFileDescriptorStore(props map[string]godbus.Variant) (UnitFileDescriptorStore, error) {
+	var (
+		store UnitFileDescriptorStore
+		errs  = make([]error, 2)
+	)
+	store.Max, errs[0] = decodeProperty[uint32](props["FileDescriptorStoreMax"])
+	store.Count, errs[1] = decodeProperty[uint32](props["NFileDescriptorStore"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitFileDescriptorStore{}, err
+		}
+	}
+	store.Preserve, _ = props["FileDescriptorStorePreserve"].Value().(string)
+
+	return store, nil
+}
+
+// DumpFileDescriptorStore returns the file descriptors held in the store of
+// the named service, e.g. to check that connections survive a restart. It
+// fails with ErrUnsupported on systemd versions older than 254, which can't
+// dump stores.
+func (m *manager) DumpFileDescriptorStore(parentCtx context.Context, unit string) ([]StoredFileDescriptor, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "DumpFileDescriptorStore")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	fds, err := m.dumpFileDescriptorStore(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetAttributes(otelattr.Int("count", len(fds)))
+	span.SetStatus(otelcodes.Ok, "dumped file descriptor store")
+
+	return fds, nil
+}
+
+// dumpFileDescriptorStore implements DumpFileDescriptorStore.
+func (m *manager) dumpFileDescriptorStore(ctx context.Context, unit string) ([]StoredFileDescriptor, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return nil, err
+	}
+	if err := checkUnitType(unit, systemdDest+".Service"); err != nil {
+		return nil, err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var fds []StoredFileDescriptor
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().unit(unit).
+			CallWithContext(ctx, systemdDest+".Service.DumpFileDescriptorStore", 0).
+			Store(&fds)
+	})
+	if isUnknownMethod(err) {
+		return nil, fmt.Errorf("failed to dump file descriptor store of unit %q: %w", unit, ErrUnsupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump file descriptor store of unit %q: %w", unit, err)
+	}
+
+	return fds, nil
+}
+
+// isUnknownMethod returns whether err means a D-Bus method doesn't exist.
+func isUnknownMethod(err error) bool {
+	var dbusErr godbus.Error
+
+	return errors.As(err, &dbusErr) && dbusErr.Name == unknownMethodError
+}
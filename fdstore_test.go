@@ -0,0 +1,59 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeFileDescriptorStore(t *testing.T) {
+	store, err := decodeFileDescriptorStore(map[string]godbus.Variant{
+		"FileDescriptorStoreMax":      godbus.MakeVariant(uint32(16)),
+		"NFileDescriptorStore":        godbus.MakeVariant(uint32(2)),
+		"FileDescriptorStorePreserve": godbus.MakeVariant("restart"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitFileDescriptorStore{Max: 16, Count: 2, Preserve: "restart"}, store)
+
+	// Older systemd versions don't preserve stores.
+	store, err = decodeFileDescriptorStore(map[string]godbus.Variant{
+		"FileDescriptorStoreMax": godbus.MakeVariant(uint32(0)),
+		"NFileDescriptorStore":   godbus.MakeVariant(uint32(0)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitFileDescriptorStore{}, store)
+
+	_, err = decodeFileDescriptorStore(map[string]godbus.Variant{})
+	require.Error(t, err)
+}
+
+func Test_Unit_StoredFileDescriptor_Store(t *testing.T) {
+	// The reply of DumpFileDescriptorStore, as a(suuutuusu).
+	reply := [][]any{{"conn", uint32(0o140777), uint32(0), uint32(8), uint64(12345), uint32(0), uint32(0), "socket:[12345]", uint32(0o2)}}
+	var fds []StoredFileDescriptor
+	require.NoError(t, godbus.Store([]any{reply}, &fds))
+	require.Equal(t, []StoredFileDescriptor{{
+		Name:        "conn",
+		Mode:        0o140777,
+		DeviceMinor: 8,
+		Inode:       12345,
+		Path:        "socket:[12345]",
+		Flags:       0o2,
+	}}, fds)
+}
+
+func Test_Unit_isUnknownMethod(t *testing.T) {
+	require.True(t, isUnknownMethod(fmt.Errorf("call: %w", godbus.Error{Name: unknownMethodError})))
+	require.False(t, isUnknownMethod(godbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied"}))
+	require.False(t, isUnknownMethod(errors.New("boom")))
+	require.False(t, isUnknownMethod(nil))
+}
+
+func Test_Unit_DumpFileDescriptorStore_WrongUnitType(t *testing.T) {
+	_, err := (&manager{}).DumpFileDescriptorStore(context.Background(), "foo.socket")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
@@ -0,0 +1,8 @@
+// Package harness runs tests against systemd in a container, instead of the
+// host's, by launching a systemd-enabled container with podman or docker and
+// connecting a Manager to its system bus.
+//
+// The container's bus authenticates clients by uid, so tests must run as
+// root or with rootful docker, where the host and container root match. The
+// image must run systemd as its init and dbus-daemon on the system bus.
+package harness
@@ -0,0 +1,230 @@
+//go:build linux
+
+package harness
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pires/go-systemdmanager"
+)
+
+// ErrNoEngine means neither podman nor docker were found.
+var ErrNoEngine = errors.New("no container engine found")
+
+// DefaultImage is the systemd-enabled image containers run by default.
+const DefaultImage = "docker.io/jrei/systemd-ubuntu:24.04"
+
+// busSocket is the name of the system bus socket, in the directory mounted
+// at /run/dbus in the container.
+const busSocket = "system_bus_socket"
+
+// readyPollInterval is how often the container is checked while waiting for
+// systemd to be ready.
+const readyPollInterval = 100 * time.Millisecond
+
+// Options configures a container.
+type Options struct {
+	// Engine is the container engine command, either "podman" or "docker".
+	// Defaults to whichever is found first in PATH, in that order.
+	Engine string
+	// Image is the container image. Defaults to DefaultImage.
+	Image string
+	// ManagerOptions configure the Manager connected to the container.
+	ManagerOptions []systemdmanager.Option
+}
+
+// Container is a running systemd-enabled container.
+type Container struct {
+	// ID is the container ID.
+	ID string
+	// Manager is connected to the container's systemd.
+	Manager systemdmanager.Manager
+
+	engine string
+	busDir string
+	cancel context.CancelFunc
+}
+
+// Start launches a container and connects a Manager to it, once systemd is
+// ready. ctx only bounds starting up: the container runs until closed.
+func Start(ctx context.Context, opts Options) (_ *Container, err error) {
+	engine, err := findEngine(opts.Engine)
+	if err != nil {
+		return nil, err
+	}
+	image := opts.Image
+	if image == "" {
+		image = DefaultImage
+	}
+
+	busDir, err := os.MkdirTemp("", "systemdmanager-harness-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bus directory: %w", err)
+	}
+	c := &Container{
+		engine: engine,
+		busDir: busDir,
+		cancel: func() {},
+	}
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+
+	out, err := run(ctx, nil, engine, runArgs(engine, image, busDir)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run container from image %q: %w", image, err)
+	}
+	c.ID = strings.TrimSpace(string(out))
+
+	if err := waitFile(ctx, filepath.Join(busDir, busSocket)); err != nil {
+		return nil, fmt.Errorf("failed waiting for the bus of container %q: %w", c.ID, err)
+	}
+
+	// The Manager outlives ctx, until the container is closed.
+	mgrCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c.cancel = cancel
+	mgrOpts := append([]systemdmanager.Option{
+		systemdmanager.WithBusAddress("unix:path=" + filepath.Join(busDir, busSocket)),
+	}, opts.ManagerOptions...)
+	c.Manager, err = systemdmanager.New(mgrCtx, mgrOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to container %q: %w", c.ID, err)
+	}
+	if err := waitReady(ctx, c.Manager); err != nil {
+		return nil, fmt.Errorf("failed waiting for systemd in container %q: %w", c.ID, err)
+	}
+
+	return c, nil
+}
+
+// Exec runs a command in the container and returns its combined output.
+func (c *Container) Exec(ctx context.Context, args ...string) ([]byte, error) {
+	return run(ctx, nil, c.engine, append([]string{"exec", c.ID}, args...)...)
+}
+
+// InstallUnit writes a unit file named name with content to
+// /etc/systemd/system in the container, and reloads systemd.
+func (c *Container) InstallUnit(ctx context.Context, name, content string) error {
+	unitPath := path.Join("/etc/systemd/system", name)
+	if _, err := run(ctx, strings.NewReader(content), c.engine, "exec", "-i", c.ID, "sh", "-c", `cat > "$1"`, "sh", unitPath); err != nil {
+		return fmt.Errorf("failed to write unit file %q: %w", unitPath, err)
+	}
+	if _, err := c.Exec(ctx, "systemctl", "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd after installing %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Close disconnects the Manager and removes the container.
+func (c *Container) Close() error {
+	c.cancel()
+
+	var err error
+	if c.ID != "" {
+		_, err = run(context.Background(), nil, c.engine, "rm", "--force", c.ID)
+		if err != nil {
+			err = fmt.Errorf("failed to remove container %q: %w", c.ID, err)
+		}
+	}
+
+	return errors.Join(err, os.RemoveAll(c.busDir))
+}
+
+// findEngine returns engine, if set, or the first container engine found.
+func findEngine(engine string) (string, error) {
+	if engine != "" {
+		return engine, nil
+	}
+	for _, engine := range []string{"podman", "docker"} {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine, nil
+		}
+	}
+
+	return "", ErrNoEngine
+}
+
+// runArgs returns the engine arguments running image detached, with systemd
+// as init and busDir mounted at /run/dbus.
+func runArgs(engine, image, busDir string) []string {
+	args := []string{"run", "--detach", "--volume", busDir + ":/run/dbus"}
+	if path.Base(engine) == "podman" {
+		args = append(args, "--systemd=always")
+	} else {
+		args = append(args,
+			"--privileged",
+			"--cgroupns=host",
+			"--volume", "/sys/fs/cgroup:/sys/fs/cgroup:rw",
+			"--tmpfs", "/run",
+			"--tmpfs", "/run/lock",
+			"--tmpfs", "/tmp",
+		)
+	}
+
+	return append(args, image)
+}
+
+// run runs a command with stdin and returns its standard output, or its
+// standard error as part of the error when failing.
+func run(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// waitFile blocks until the file at path exists.
+func waitFile(ctx context.Context, path string) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitReady blocks until systemd answers on the bus.
+func waitReady(ctx context.Context, mgr systemdmanager.Manager) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := mgr.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
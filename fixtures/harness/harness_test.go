@@ -0,0 +1,54 @@
+//go:build linux
+
+package harness
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_runArgs(t *testing.T) {
+	podman := runArgs("/usr/bin/podman", DefaultImage, "/tmp/bus")
+	require.Equal(t, []string{"run", "--detach", "--volume", "/tmp/bus:/run/dbus", "--systemd=always", DefaultImage}, podman)
+
+	docker := runArgs("docker", DefaultImage, "/tmp/bus")
+	require.Subset(t, docker, []string{"--privileged", "--cgroupns=host", "/sys/fs/cgroup:/sys/fs/cgroup:rw"})
+	require.NotContains(t, docker, "--systemd=always")
+	require.Equal(t, DefaultImage, docker[len(docker)-1])
+}
+
+func Test_Unit_findEngine(t *testing.T) {
+	engine, err := findEngine("nerdctl")
+	require.NoError(t, err)
+	require.Equal(t, "nerdctl", engine)
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = findEngine("")
+	require.ErrorIs(t, err, ErrNoEngine)
+}
+
+func Test_E2E_Start(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	c, err := Start(ctx, Options{})
+	if errors.Is(err, ErrNoEngine) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c.Close())
+	}()
+
+	unit := "harness_dummy.service"
+	require.NoError(t, c.InstallUnit(ctx, unit, "[Service]\nType=oneshot\nRemainAfterExit=yes\nExecStart=/bin/true\n"))
+	require.NoError(t, c.Manager.Start(ctx, unit))
+
+	status, err := c.Manager.Status(ctx, unit)
+	require.NoError(t, err)
+	require.Equal(t, "active", status.ActiveState)
+}
@@ -4,90 +4,238 @@ package fixtures
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 )
 
-func InstallUnit(ctx context.Context, unit string) error {
-	// Find fixture.
-	if !strings.HasSuffix(unit, ".service") {
-		unit = unit + ".service"
+// Option configures where fixtures are installed.
+type Option func(*options)
+
+// options holds where fixtures are installed.
+type options struct {
+	user       bool
+	persistent bool
+}
+
+// newOptions returns the settings resulting from applying opts over the
+// defaults.
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithUserManager installs fixtures for the calling user's service manager,
+// instead of the system's, over the user bus.
+func WithUserManager() Option {
+	return func(o *options) {
+		o.user = true
+	}
+}
+
+// WithPersistent installs fixtures so they survive reboots, in /etc or
+// ~/.config, instead of in /run. This is required to enable them.
+func WithPersistent() Option {
+	return func(o *options) {
+		o.persistent = true
+	}
+}
+
+// connect sets up a systemd D-Bus API client on the system or user bus.
+func (o options) connect(ctx context.Context) (*dbus.Conn, error) {
+	if o.user {
+		return dbus.NewUserConnectionContext(ctx)
+	}
+
+	return dbus.NewWithContext(ctx)
+}
+
+// unitDirectory returns where systemd links fixtures to.
+func (o options) unitDirectory() (string, error) {
+	switch {
+	case !o.user && !o.persistent:
+		return "/run/systemd/system", nil
+	case !o.user:
+		return "/etc/systemd/system", nil
+	case !o.persistent:
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return "", errors.New("failed to determine user runtime directory: XDG_RUNTIME_DIR isn't set")
+		}
+
+		return filepath.Join(runtimeDir, "systemd/user"), nil
+	default:
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine user configuration directory: %w", err)
+		}
+
+		return filepath.Join(configDir, "systemd/user"), nil
+	}
+}
+
+// unitTypes are the suffixes of the unit types fixtures may be.
+var unitTypes = []string{
+	".service", ".socket", ".device", ".mount", ".automount", ".swap",
+	".target", ".path", ".timer", ".slice", ".scope",
+}
+
+// unitName returns the name of the fixture unit, which is a service unless
+// suffixed with another unit type.
+func unitName(unit string) string {
+	if slices.Contains(unitTypes, filepath.Ext(unit)) {
+		return unit
 	}
+
+	return unit + ".service"
+}
+
+// fixturePath returns the absolute path of the fixture unit file, whether
+// running from the fixtures directory or from its parent.
+func fixturePath(unit string) (string, error) {
 	fixtureAbsoluteFilepath, err := filepath.Abs(unit)
 	if err != nil {
-		return fmt.Errorf("failed to determine absolute path for unit %q: %w", unit, err)
+		return "", fmt.Errorf("failed to determine absolute path for unit %q: %w", unit, err)
 	}
 	if !strings.Contains(fixtureAbsoluteFilepath, "/fixtures/") {
 		fixtureAbsoluteFilepath, err = filepath.Abs("fixtures/" + unit)
 		if err != nil {
-			return fmt.Errorf("failed to determine absolute path for unit %q: %w", unit, err)
+			return "", fmt.Errorf("failed to determine absolute path for unit %q: %w", unit, err)
+		}
+	}
+
+	return fixtureAbsoluteFilepath, nil
+}
+
+func InstallUnit(ctx context.Context, unit string, opts ...Option) error {
+	return InstallUnits(ctx, []string{unit}, opts...)
+}
+
+// InstallUnits links the fixture units at once, so they may depend on each
+// other, e.g. a timer and the service it triggers. Units without a unit type
+// suffix are services. By default, units are installed in /run for the
+// system's service manager.
+func InstallUnits(ctx context.Context, units []string, opts ...Option) error {
+	o := newOptions(opts)
+	dir, err := o.unitDirectory()
+	if err != nil {
+		return err
+	}
+
+	// Find fixtures.
+	names := make([]string, len(units))
+	fixtures := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unitName(unit)
+		fixture, err := fixturePath(names[i])
+		if err != nil {
+			return err
 		}
+		fixtures[i] = fixture
 	}
 
 	// Set-up systemd D-Bus API client.
-	conn, err := dbus.NewWithContext(ctx)
+	conn, err := o.connect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to set-up systemd D-Bus connection: %w", err)
 	}
 	defer conn.Close()
 
-	// Blindly kill the unit in case it is running.
-	_ = UninstallUnit(ctx, unit)
+	// Blindly kill the units in case they are running.
+	_ = UninstallUnits(ctx, names, opts...)
 
-	// Blindly remove the symlink in case it exists.
-	runPath := filepath.Join("/run/systemd/system/", unit)
-	_ = os.Remove(runPath)
+	// Blindly remove the symlinks in case they exist.
+	for _, unit := range names {
+		_ = os.Remove(filepath.Join(dir, unit))
+	}
 
-	// Link unit.
-	changes, err := conn.LinkUnitFilesContext(ctx, []string{fixtureAbsoluteFilepath}, true, true)
+	// Link units.
+	changes, err := conn.LinkUnitFilesContext(ctx, fixtures, !o.persistent, true)
 	if err != nil {
-		return fmt.Errorf("failed to link %q: %w", fixtureAbsoluteFilepath, err)
+		return fmt.Errorf("failed to link %q: %w", fixtures, err)
 	}
-	if len(changes) < 1 {
-		return fmt.Errorf("expected one change when linking %q, got %v", fixtureAbsoluteFilepath, changes)
+	filenames := make([]string, len(changes))
+	for i, change := range changes {
+		filenames[i] = change.Filename
 	}
-	if changes[0].Filename != runPath {
-		return fmt.Errorf("expected %q, got %q", runPath, changes[0].Filename)
+	if err := checkChanges(filenames, dir, names); err != nil {
+		return fmt.Errorf("failed to link %q: %w", fixtures, err)
 	}
 
 	return nil
 }
 
-func UninstallUnit(ctx context.Context, unit string) error {
-	// Figure out running unit path.
-	if !strings.HasSuffix(unit, ".service") {
-		unit = unit + ".service"
+func UninstallUnit(ctx context.Context, unit string, opts ...Option) error {
+	return UninstallUnits(ctx, []string{unit}, opts...)
+}
+
+// UninstallUnits stops the fixture units, in reverse order, and unlinks
+// them. opts must match those the units were installed with.
+func UninstallUnits(ctx context.Context, units []string, opts ...Option) error {
+	o := newOptions(opts)
+	dir, err := o.unitDirectory()
+	if err != nil {
+		return err
+	}
+
+	// Figure out running unit paths.
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unitName(unit)
 	}
 
 	// Set-up systemd D-Bus API client.
-	conn, err := dbus.NewWithContext(ctx)
+	conn, err := o.connect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to set-up systemd D-Bus connection: %w", err)
 	}
 	defer conn.Close()
 
-	// Blindly stop the unit in case it is running.
-	_, _ = conn.StopUnitContext(ctx, unit, "replace", nil)
+	// Blindly stop the units in case they are running, dependents first.
+	for _, unit := range slices.Backward(names) {
+		_, _ = conn.StopUnitContext(ctx, unit, "replace", nil)
+	}
 
-	// Unink unit.
-	changes, err := conn.DisableUnitFilesContext(ctx, []string{unit}, true)
+	// Unink units.
+	changes, err := conn.DisableUnitFilesContext(ctx, names, !o.persistent)
 	if err != nil {
-		return fmt.Errorf("failed to disable unit %q: %w", unit, err)
+		return fmt.Errorf("failed to disable units %q: %w", names, err)
 	}
-	if len(changes) < 1 {
-		return fmt.Errorf("expected one change when unlinking %q, got %v", unit, changes)
+	filenames := make([]string, len(changes))
+	for i, change := range changes {
+		filenames[i] = change.Filename
 	}
-	runPath := filepath.Join("/run/systemd/system/", unit)
-	if changes[0].Filename != runPath {
-		return fmt.Errorf("expected %q, got %q", runPath, changes[0].Filename)
+	if err := checkChanges(filenames, dir, names); err != nil {
+		return fmt.Errorf("failed to unlink %q: %w", names, err)
 	}
 
-	// Blindly remove the symlink in case it exists.
-	_ = os.Remove(runPath)
+	// Blindly remove the symlinks in case they exist.
+	for _, unit := range names {
+		_ = os.Remove(filepath.Join(dir, unit))
+	}
 
 	return nil
 }
+
+// checkChanges verifies every unit was (un)linked in dir, given the file
+// names of the changes.
+func checkChanges(filenames []string, dir string, units []string) error {
+	var errs []error
+	for _, unit := range units {
+		runPath := filepath.Join(dir, unit)
+		if !slices.Contains(filenames, runPath) {
+			errs = append(errs, fmt.Errorf("expected a change of %q, got %q", runPath, filenames))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -100,3 +100,65 @@ func Test_E2E_UninstallUnit(t *testing.T) {
 		})
 	}
 }
+
+func Test_Unit_unitName(t *testing.T) {
+	require.Equal(t, "dummy.service", unitName("dummy"))
+	require.Equal(t, "dummy.service", unitName("dummy.service"))
+	require.Equal(t, "dummy.timer", unitName("dummy.timer"))
+	require.Equal(t, "manager_rolling@.service", unitName("manager_rolling@"))
+	// Unknown suffixes are part of service names.
+	require.Equal(t, "dummy.v2.service", unitName("dummy.v2"))
+}
+
+func Test_Unit_checkChanges(t *testing.T) {
+	require.NoError(t, checkChanges([]string{"/run/systemd/system/a.timer", "/run/systemd/system/a.service"}, "/run/systemd/system", []string{"a.service", "a.timer"}))
+	require.Error(t, checkChanges([]string{"/run/systemd/system/a.timer"}, "/run/systemd/system", []string{"a.service", "a.timer"}))
+	require.Error(t, checkChanges([]string{"/run/systemd/system/a.service"}, "/etc/systemd/system", []string{"a.service"}))
+}
+
+func Test_E2E_InstallUnits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	for _, unit := range []string{"dummy.timer", "dummy.socket", "dummy.path", "dummy.target"} {
+		t.Run(unit, func(t *testing.T) {
+			require.NoError(t, InstallUnits(ctx, []string{unitDummy, unit}))
+			require.NoError(t, UninstallUnits(ctx, []string{unitDummy, unit}))
+		})
+	}
+}
+
+func Test_Unit_unitDirectory(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.config")
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "system runtime", want: "/run/systemd/system"},
+		{name: "system persistent", opts: []Option{WithPersistent()}, want: "/etc/systemd/system"},
+		{name: "user runtime", opts: []Option{WithUserManager()}, want: "/run/user/1000/systemd/user"},
+		{name: "user persistent", opts: []Option{WithUserManager(), WithPersistent()}, want: "/home/user/.config/systemd/user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := newOptions(tt.opts).unitDirectory()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, dir)
+		})
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	_, err := newOptions([]Option{WithUserManager()}).unitDirectory()
+	require.Error(t, err)
+}
+
+func Test_E2E_InstallUnit_Persistent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	require.NoError(t, InstallUnit(ctx, unitDummy, WithPersistent()))
+	require.NoError(t, UninstallUnit(ctx, unitDummy, WithPersistent()))
+}
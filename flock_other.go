@@ -0,0 +1,10 @@
+//go:build !unix
+
+package systemdmanager
+
+import "os"
+
+// flock fails, files can't be locked across processes here.
+func flock(*os.File) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package systemdmanager
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// flock blocks until it holds an exclusive lock on f, which is released
+// when f is closed, by any process.
+func flock(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
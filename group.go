@@ -0,0 +1,115 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Stage identifies a step of a multi-unit operation.
+type Stage string
+
+const (
+	// StageStop is the step where a unit is stopped.
+	StageStop Stage = "stop"
+	// StageStart is the step where a unit is started.
+	StageStart Stage = "start"
+)
+
+// GroupOptions configures multi-unit operations.
+type GroupOptions struct {
+	// ContinueOnError keeps going through the remaining units when a unit
+	// fails to stop or start, instead of aborting right away.
+	ContinueOnError bool
+}
+
+// StageResult is the outcome of a single stage for a single unit.
+type StageResult struct {
+	Stage Stage
+	Unit  string
+	Err   error
+}
+
+// GroupResult holds the outcome of a multi-unit operation.
+type GroupResult struct {
+	// Order is the dependency order units were resolved to. Units are
+	// started in this order and stopped in reverse.
+	Order []string
+	// Stages lists the result of every stage that ran, in execution order.
+	Stages []StageResult
+}
+
+// Failed returns the stage results that ended in error.
+func (r *GroupResult) Failed() []StageResult {
+	var failed []StageResult
+	for _, res := range r.Stages {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// RestartGroup synchronously restarts a set of interdependent units. Units
+// are ordered by their After=/Before= dependencies, then stopped in reverse
+// order and started in forward order.
+func (m *manager) RestartGroup(parentCtx context.Context, units []string, opts GroupOptions) (*GroupResult, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "RestartGroup")
+	span.SetAttributes(otelattr.StringSlice("units", units))
+	defer span.End()
+
+	order, err := m.dependencyOrder(ctx, units)
+	if err != nil {
+		err = fmt.Errorf("failed to resolve dependency order for units %q: %w", units, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	result := &GroupResult{Order: order}
+
+	var errs []error
+	run := func(stage Stage, unit string, op func(context.Context, string, ...CallOption) error) bool {
+		err := op(ctx, unit)
+		result.Stages = append(result.Stages, StageResult{Stage: stage, Unit: unit, Err: err})
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		return err == nil || opts.ContinueOnError
+	}
+
+	// Stop dependents before their dependencies.
+	stopOK := true
+	for _, unit := range slices.Backward(order) {
+		if stopOK = run(StageStop, unit, m.Stop); !stopOK {
+			break
+		}
+	}
+	// Start dependencies before their dependents.
+	if stopOK {
+		for _, unit := range order {
+			if !run(StageStart, unit, m.Start) {
+				break
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		err = fmt.Errorf("failed to restart group of units %q: %w", order, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return result, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully restarted group of units %q", order))
+
+	return result, nil
+}
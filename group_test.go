@@ -0,0 +1,46 @@
+//go:build linux
+
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pires/go-systemdmanager/fixtures"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixtures
+const (
+	unitGroupFirst  = "manager_group_first.service"
+	unitGroupSecond = "manager_group_second.service"
+)
+
+func Test_E2E_Manager_RestartGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixtures.
+	for _, unit := range []string{unitGroupFirst, unitGroupSecond} {
+		require.NoError(t, fixtures.InstallUnit(ctx, unit))
+		// By the time of uninstall, ctx may be cancelled.
+		defer uninstallUnit(t, t.Context(), unit)
+	}
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Units are passed out of order on purpose.
+	res, err := mgr.RestartGroup(ctx, []string{unitGroupSecond, unitGroupFirst}, GroupOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{unitGroupFirst, unitGroupSecond}, res.Order)
+	require.Equal(t, []StageResult{
+		{Stage: StageStop, Unit: unitGroupSecond},
+		{Stage: StageStop, Unit: unitGroupFirst},
+		{Stage: StageStart, Unit: unitGroupFirst},
+		{Stage: StageStart, Unit: unitGroupSecond},
+	}, res.Stages)
+	require.Empty(t, res.Failed())
+}
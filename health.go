@@ -0,0 +1,169 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// WaitAllActive blocks until all named units are active. It fails as soon as
+// any of the units fails or, with a LastStatusError for every unit still not
+// active, when ctx is done.
+func (m *manager) WaitAllActive(parentCtx context.Context, units []string) error {
+	// Set-up tracing context.
+	spanCtx, span := otel.Tracer(name).Start(parentCtx, "WaitAllActive")
+	span.SetAttributes(otelattr.StringSlice("units", units))
+	defer span.End()
+
+	// Stop waiting on the remaining units once one of them fails.
+	ctx, cancel := context.WithCancelCause(spanCtx)
+	defer cancel(nil)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(units))
+	)
+	for i, unit := range units {
+		wg.Go(func() {
+			if errs[i] = m.waitActive(ctx, unit); errs[i] != nil {
+				cancel(errs[i])
+			}
+		})
+	}
+	wg.Wait()
+
+	// When ctx is done, report the last status of every unit not active.
+	err := context.Cause(ctx)
+	if spanCtx.Err() != nil {
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "all units are active")
+
+	return nil
+}
+
+// HealthAggregator tracks a set of units and reports whether all of them are
+// active.
+type HealthAggregator struct {
+	mgr   Manager
+	units []string
+
+	mutex   sync.RWMutex
+	active  map[string]bool
+	ready   bool
+	changes chan bool
+}
+
+// NewHealthAggregator returns a HealthAggregator for the named units. Units
+// are considered not active until Run observes otherwise.
+func NewHealthAggregator(mgr Manager, units ...string) *HealthAggregator {
+	return &HealthAggregator{
+		mgr:     mgr,
+		units:   slices.Clone(units),
+		active:  make(map[string]bool, len(units)),
+		changes: make(chan bool, 1),
+	}
+}
+
+// Run watches the tracked units and updates the aggregated state until ctx is
+// done. This is a blocking function.
+func (h *HealthAggregator) Run(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(h.units))
+	)
+	for i, unit := range h.units {
+		wg.Go(func() {
+			updatesChan := make(chan *dbus.UnitStatus)
+			watchDone := make(chan struct{})
+			go func() {
+				defer close(watchDone)
+				errs[i] = h.mgr.Watch(ctx, unit, updatesChan, WithWaitForUnit())
+			}()
+
+			// Keep reading until Watch returns so it never blocks on send.
+			for {
+				select {
+				case <-watchDone:
+					return
+				case status := <-updatesChan:
+					h.update(unit, status)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil && !errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("failed to watch units: %w", err)
+	}
+
+	return ctx.Err()
+}
+
+// update records the status of a unit, notifying of changes to the
+// aggregated state. A nil status means the unit isn't loaded.
+func (h *HealthAggregator) update(unit string, status *dbus.UnitStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.active[unit] = status != nil && status.ActiveState == "active"
+
+	ready := true
+	for _, u := range h.units {
+		ready = ready && h.active[u]
+	}
+	if ready == h.ready {
+		return
+	}
+	h.ready = ready
+
+	// Only the latest state is kept for slow readers.
+	select {
+	case <-h.changes:
+	default:
+	}
+	h.changes <- ready
+}
+
+// Ready returns whether all tracked units are active.
+func (h *HealthAggregator) Ready() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.ready
+}
+
+// NotReady returns the tracked units that aren't active.
+func (h *HealthAggregator) NotReady() []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var units []string
+	for _, unit := range h.units {
+		if !h.active[unit] {
+			units = append(units, unit)
+		}
+	}
+
+	return units
+}
+
+// Changes returns a channel receiving the aggregated state every time it
+// changes. Only the latest state is buffered.
+func (h *HealthAggregator) Changes() <-chan bool {
+	return h.changes
+}
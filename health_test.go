@@ -0,0 +1,32 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_HealthAggregator_update(t *testing.T) {
+	h := NewHealthAggregator(nil, "a.service", "b.service")
+	require.False(t, h.Ready())
+	require.Equal(t, []string{"a.service", "b.service"}, h.NotReady())
+
+	h.update("a.service", &dbus.UnitStatus{Name: "a.service", ActiveState: "active"})
+	require.False(t, h.Ready())
+	require.Equal(t, []string{"b.service"}, h.NotReady())
+	require.Empty(t, h.Changes())
+
+	h.update("b.service", &dbus.UnitStatus{Name: "b.service", ActiveState: "active"})
+	require.True(t, h.Ready())
+	require.Empty(t, h.NotReady())
+	require.True(t, <-h.Changes())
+
+	// Only the latest state is kept when nobody reads changes.
+	h.update("a.service", nil)
+	h.update("a.service", &dbus.UnitStatus{Name: "a.service", ActiveState: "active"})
+	h.update("b.service", &dbus.UnitStatus{Name: "b.service", ActiveState: "failed"})
+	require.False(t, h.Ready())
+	require.Len(t, h.Changes(), 1)
+	require.False(t, <-h.Changes())
+}
@@ -0,0 +1,277 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHealthCheckInterval is how often health checks run by default.
+	defaultHealthCheckInterval = 10 * time.Second
+	// defaultHealthCheckTimeout bounds health check runs by default.
+	defaultHealthCheckTimeout = 5 * time.Second
+	// defaultFailureThreshold is how many consecutive failures make a check
+	// unhealthy by default.
+	defaultFailureThreshold = 3
+)
+
+// HealthCheck is a probe run periodically against a unit by a
+// HealthChecker.
+type HealthCheck struct {
+	// Name identifies the check in results. Defaults to "check-<n>", n
+	// being the position of the check among those of the unit.
+	Name string
+	// Probe is the check to run, such as TCPProbe, HTTPProbe, ExecProbe, or
+	// PropertyProbe.
+	Probe ReadinessProbe
+	// Interval is the delay between runs. Defaults to ten seconds.
+	Interval time.Duration
+	// Timeout bounds every run. Defaults to five seconds.
+	Timeout time.Duration
+	// FailureThreshold is how many consecutive failed runs make the check
+	// unhealthy. Defaults to 3.
+	FailureThreshold int
+}
+
+// CheckResult is the outcome of the last runs of a HealthCheck.
+type CheckResult struct {
+	Name    string
+	Healthy bool
+	// Failures is how many runs failed in a row.
+	Failures int
+	// Err is the error of the last run, if it failed.
+	Err     error
+	LastRun time.Time
+}
+
+// UnitHealth is the health of a unit, which is healthy when all of its
+// checks are.
+type UnitHealth struct {
+	Unit    string
+	Healthy bool
+	Checks  []CheckResult
+}
+
+// HealthCheckerOptions configures a HealthChecker.
+type HealthCheckerOptions struct {
+	// Restart, if set, restarts units once they become unhealthy, with the
+	// backoff and attempts of the policy, as a Supervisor does.
+	Restart *RestartPolicy
+	// Notify, if not nil, is called for every restart attempt and when
+	// giving up on a unit.
+	Notify func(SupervisorEvent)
+}
+
+// HealthChecker periodically runs health checks registered per unit, and
+// reports their health. Like liveness probes, checks are healthy until they
+// fail FailureThreshold times in a row.
+type HealthChecker struct {
+	mgr  Manager
+	opts HealthCheckerOptions
+
+	mutex   sync.RWMutex
+	checks  map[string][]HealthCheck
+	results map[string][]CheckResult
+}
+
+// NewHealthChecker returns a HealthChecker without any checks.
+func NewHealthChecker(mgr Manager, opts HealthCheckerOptions) *HealthChecker {
+	return &HealthChecker{
+		mgr:     mgr,
+		opts:    opts,
+		checks:  make(map[string][]HealthCheck),
+		results: make(map[string][]CheckResult),
+	}
+}
+
+// Register adds health checks of a unit. Checks must be registered before
+// calling Run.
+func (h *HealthChecker) Register(unit string, checks ...HealthCheck) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, check := range checks {
+		n := len(h.checks[unit])
+		if check.Name == "" {
+			check.Name = fmt.Sprintf("check-%d", n)
+		}
+		if check.Interval <= 0 {
+			check.Interval = defaultHealthCheckInterval
+		}
+		if check.Timeout <= 0 {
+			check.Timeout = defaultHealthCheckTimeout
+		}
+		if check.FailureThreshold <= 0 {
+			check.FailureThreshold = defaultFailureThreshold
+		}
+		h.checks[unit] = append(h.checks[unit], check)
+		h.results[unit] = append(h.results[unit], CheckResult{Name: check.Name, Healthy: true})
+	}
+}
+
+// Run runs the registered checks until ctx is done, restarting unhealthy
+// units if configured to. This is a blocking function.
+func (h *HealthChecker) Run(ctx context.Context) error {
+	h.mutex.RLock()
+	checks := maps.Clone(h.checks)
+	h.mutex.RUnlock()
+
+	var wg sync.WaitGroup
+	for unit, unitChecks := range checks {
+		// Changes are coalesced, the unit health being read once notified.
+		changed := make(chan struct{}, 1)
+		for i, check := range unitChecks {
+			wg.Go(func() {
+				h.runCheck(ctx, unit, i, check, changed)
+			})
+		}
+		if h.opts.Restart != nil {
+			wg.Go(func() {
+				h.restartUnhealthy(ctx, unit, changed)
+			})
+		}
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// runCheck runs a check every interval until ctx is done, notifying changed
+// every time the unit becomes healthy, and every time the check reaches its
+// failure threshold. Notifications never block.
+func (h *HealthChecker) runCheck(ctx context.Context, unit string, i int, check HealthCheck, changed chan<- struct{}) {
+	ticker := time.NewTicker(check.Interval)
+	defer ticker.Stop()
+
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, check.Timeout)
+		err := check.Probe(probeCtx, h.mgr, unit)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if h.record(unit, i, check, err) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record stores the outcome of a check run. It returns whether the health of
+// the unit is worth reporting.
+func (h *HealthChecker) record(unit string, i int, check HealthCheck, err error) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	wasHealthy := h.healthy(unit)
+	result := &h.results[unit][i]
+	result.LastRun = time.Now()
+	result.Err = err
+	// Checks only become healthy again by passing.
+	if err == nil {
+		result.Failures, result.Healthy = 0, true
+	} else {
+		result.Failures++
+		result.Healthy = result.Healthy && result.Failures < check.FailureThreshold
+	}
+	healthy := h.healthy(unit)
+
+	return healthy && !wasHealthy || result.Failures == check.FailureThreshold
+}
+
+// healthy returns whether all checks of unit are healthy. The caller must
+// hold the mutex.
+func (h *HealthChecker) healthy(unit string) bool {
+	for _, result := range h.results[unit] {
+		if !result.Healthy {
+			return false
+		}
+	}
+
+	return true
+}
+
+// restartUnhealthy restarts unit whenever it's unhealthy once changed is
+// notified, until ctx is done. Attempts are reset once the unit is healthy
+// again.
+func (h *HealthChecker) restartUnhealthy(ctx context.Context, unit string, changed <-chan struct{}) {
+	supervisor := NewSupervisor(h.mgr, *h.opts.Restart, h.opts.Notify, unit)
+	attempts, gaveUp := 0, false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+			h.mutex.RLock()
+			healthy := h.healthy(unit)
+			h.mutex.RUnlock()
+			switch {
+			case healthy:
+				attempts, gaveUp = 0, false
+			case !gaveUp:
+				gaveUp = supervisor.recover(ctx, unit, &attempts)
+				// Give the restarted unit as many runs to recover as it
+				// took to fail.
+				h.resetFailures(unit)
+			}
+		}
+	}
+}
+
+// resetFailures clears the consecutive failures of the checks of unit,
+// without marking them healthy.
+func (h *HealthChecker) resetFailures(unit string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for i := range h.results[unit] {
+		h.results[unit][i].Failures = 0
+	}
+}
+
+// Health returns the health of a unit, and whether it has checks.
+func (h *HealthChecker) Health(unit string) (UnitHealth, bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	results, ok := h.results[unit]
+	if !ok {
+		return UnitHealth{}, false
+	}
+
+	return UnitHealth{Unit: unit, Healthy: h.healthy(unit), Checks: slices.Clone(results)}, true
+}
+
+// Healthy returns whether all units with checks are healthy.
+func (h *HealthChecker) Healthy() bool {
+	return len(h.Unhealthy()) == 0
+}
+
+// Unhealthy returns the units with checks that aren't healthy, sorted.
+func (h *HealthChecker) Unhealthy() []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var units []string
+	for unit := range h.results {
+		if !h.healthy(unit) {
+			units = append(units, unit)
+		}
+	}
+	slices.Sort(units)
+
+	return units
+}
@@ -0,0 +1,119 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_HealthChecker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		passing  atomic.Bool
+		restarts atomic.Int32
+		events   = make(chan SupervisorEvent, 10)
+	)
+	mgr := &fakeManager{
+		restart: func(context.Context, string) error {
+			restarts.Add(1)
+			return nil
+		},
+	}
+	h := NewHealthChecker(mgr, HealthCheckerOptions{
+		Restart: &RestartPolicy{Backoff: Backoff{Initial: time.Millisecond}, MaxAttempts: 2},
+		Notify: func(e SupervisorEvent) {
+			events <- e
+		},
+	})
+	h.Register("a.service", HealthCheck{
+		Probe: func(context.Context, Manager, string) error {
+			if passing.Load() {
+				return nil
+			}
+			return errors.New("unhealthy")
+		},
+		Interval:         10 * time.Millisecond,
+		FailureThreshold: 2,
+	})
+	h.Register("b.service", HealthCheck{Name: "always", Probe: FileProbe("/"), Interval: 10 * time.Millisecond})
+
+	// Checks are healthy until they fail.
+	require.True(t, h.Healthy())
+	health, ok := h.Health("a.service")
+	require.True(t, ok)
+	require.Equal(t, "check-0", health.Checks[0].Name)
+	_, ok = h.Health("c.service")
+	require.False(t, ok)
+
+	go h.Run(ctx)
+
+	// Unhealthy units are restarted until the policy gives up.
+	require.Equal(t, SupervisorEvent{Unit: "a.service", Attempt: 1}, <-events)
+	require.Equal(t, SupervisorEvent{Unit: "a.service", Attempt: 2}, <-events)
+	require.Equal(t, SupervisorEvent{Unit: "a.service", Attempt: 2, GaveUp: true}, <-events)
+	require.Equal(t, int32(2), restarts.Load())
+	require.Equal(t, []string{"a.service"}, h.Unhealthy())
+	health, _ = h.Health("a.service")
+	require.False(t, health.Healthy)
+	require.Error(t, health.Checks[0].Err)
+
+	passing.Store(true)
+	require.Eventually(t, h.Healthy, time.Second, 10*time.Millisecond)
+	health, _ = h.Health("a.service")
+	require.Zero(t, health.Checks[0].Failures)
+	require.NoError(t, health.Checks[0].Err)
+}
+
+func Test_Unit_ExecProbe(t *testing.T) {
+	require.NoError(t, ExecProbe("true")(context.Background(), nil, "a.service"))
+	require.ErrorIs(t, ExecProbe("false")(context.Background(), nil, "a.service"), ErrNotReady)
+}
+
+func Test_Unit_PropertyProbe(t *testing.T) {
+	mgr := struct {
+		Manager
+		fakePropertyGetter
+	}{fakePropertyGetter: fakePropertyGetter{"NRestarts": uint32(5)}}
+
+	low := PropertyProbe("Service", "NRestarts", func(n uint32) bool { return n < 3 })
+	require.ErrorIs(t, low(context.Background(), mgr, "a.service"), ErrNotReady)
+	some := PropertyProbe("Service", "NRestarts", func(n uint32) bool { return n < 10 })
+	require.NoError(t, some(context.Background(), mgr, "a.service"))
+}
+
+func Test_Unit_HealthChecker_RunReturns(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Checks of a unit changing its health at once never block, with or
+	// without anything restarting it.
+	h := NewHealthChecker(&fakeManager{}, HealthCheckerOptions{})
+	for range 3 {
+		h.Register("a.service", HealthCheck{
+			Probe: func(context.Context, Manager, string) error {
+				return errors.New("unhealthy")
+			},
+			Interval:         time.Millisecond,
+			FailureThreshold: 1,
+		})
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Run(ctx)
+	}()
+	require.Eventually(t, func() bool { return !h.Healthy() }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return")
+	}
+}
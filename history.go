@@ -0,0 +1,115 @@
+package systemdmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// Transition is a state change of a unit observed by a watch.
+type Transition struct {
+	Time time.Time `json:"time" yaml:"time"`
+	// ActiveState and SubState are the states the unit entered. Both are
+	// empty when the unit was unloaded.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	SubState    string `json:"sub_state" yaml:"sub_state"`
+}
+
+// WithHistory keeps the last n state transitions of each watched unit, which
+// are returned by History. Zero, the default, keeps none.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		o.historySize = n
+	}
+}
+
+// History returns the state transitions of the named unit observed by
+// watches, oldest first, if enabled by WithHistory.
+func (m *manager) History(unit string) []Transition {
+	return m.history.transitions(unit)
+}
+
+// transitionRing holds the last transitions of a unit.
+type transitionRing struct {
+	transitions []Transition
+	// next is where the next transition is written once the ring is full.
+	next int
+}
+
+// add appends t, overwriting the oldest transition once size are held.
+func (r *transitionRing) add(t Transition, size int) {
+	if len(r.transitions) < size {
+		r.transitions = append(r.transitions, t)
+
+		return
+	}
+	r.transitions[r.next] = t
+	r.next = (r.next + 1) % size
+}
+
+// last returns the latest transition, if any.
+func (r *transitionRing) last() (Transition, bool) {
+	if len(r.transitions) == 0 {
+		return Transition{}, false
+	}
+
+	return r.transitions[(r.next+len(r.transitions)-1)%len(r.transitions)], true
+}
+
+// ordered returns a copy of the transitions, oldest first.
+func (r *transitionRing) ordered() []Transition {
+	return append(append([]Transition(nil), r.transitions[r.next:]...), r.transitions[:r.next]...)
+}
+
+// history records the transitions of watched units.
+type history struct {
+	mutex sync.Mutex
+	units map[string]*transitionRing
+	now   func() time.Time
+}
+
+// record adds the transition to status, if the unit changed state, keeping
+// the last size transitions of the unit.
+func (h *history) record(unit string, status *dbus.UnitStatus, size int) {
+	if size <= 0 {
+		return
+	}
+	var t Transition
+	if status != nil {
+		t.ActiveState, t.SubState = status.ActiveState, status.SubState
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.now == nil {
+		h.now = time.Now
+	}
+	if h.units == nil {
+		h.units = make(map[string]*transitionRing)
+	}
+	r, ok := h.units[unit]
+	if !ok {
+		r = &transitionRing{}
+		h.units[unit] = r
+	}
+	// Watches report changes of any property, so only keep state changes.
+	if last, ok := r.last(); ok && last.ActiveState == t.ActiveState && last.SubState == t.SubState {
+		return
+	}
+	t.Time = h.now()
+	r.add(t, size)
+}
+
+// transitions returns the recorded transitions of the named unit, oldest
+// first.
+func (h *history) transitions(unit string) []Transition {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	r, ok := h.units[unit]
+	if !ok {
+		return nil
+	}
+
+	return r.ordered()
+}
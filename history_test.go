@@ -0,0 +1,45 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_history(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+	h := history{now: func() time.Time { return now }}
+	record := func(active, sub string) {
+		now = now.Add(time.Second)
+		h.record("a.service", &dbus.UnitStatus{ActiveState: active, SubState: sub}, 3)
+	}
+
+	t.Run("Keeps state changes only", func(t *testing.T) {
+		record("activating", "start")
+		record("activating", "start")
+		record("active", "running")
+		require.Equal(t, []Transition{
+			{Time: start.Add(time.Second), ActiveState: "activating", SubState: "start"},
+			{Time: start.Add(3 * time.Second), ActiveState: "active", SubState: "running"},
+		}, h.transitions("a.service"))
+	})
+
+	t.Run("Drops oldest transitions", func(t *testing.T) {
+		record("deactivating", "stop")
+		record("failed", "failed")
+		h.record("a.service", nil, 3)
+		require.Equal(t, []Transition{
+			{Time: start.Add(4 * time.Second), ActiveState: "deactivating", SubState: "stop"},
+			{Time: start.Add(5 * time.Second), ActiveState: "failed", SubState: "failed"},
+			{Time: start.Add(5 * time.Second)},
+		}, h.transitions("a.service"))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		h.record("b.service", &dbus.UnitStatus{ActiveState: "active"}, 0)
+		require.Nil(t, h.transitions("b.service"))
+	})
+}
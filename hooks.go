@@ -0,0 +1,140 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+)
+
+// Operation identifies a mutating unit operation.
+type Operation string
+
+const (
+	OperationStart   Operation = "start"
+	OperationStop    Operation = "stop"
+	OperationRestart Operation = "restart"
+	// OperationReload doesn't stop or start units, so it doesn't run hooks.
+	// Neither does OperationReloadOrRestart, as whether it restarts units
+	// isn't known in advance.
+	OperationReload          Operation = "reload"
+	OperationReloadOrRestart Operation = "reload-or-restart"
+	// OperationConfigure changes unit files, drop-ins, or enablement
+	// without running jobs, so it doesn't run hooks either.
+	OperationConfigure Operation = "configure"
+)
+
+// Hook runs around a unit operation. A hook returning an error fails the
+// operation, and when run before the operation, prevents it altogether.
+type Hook func(ctx context.Context, op Operation, unit string) error
+
+// FailureHook runs when a unit operation fails.
+type FailureHook func(ctx context.Context, op Operation, unit string, err error)
+
+// hooks holds the hooks registered with a manager, in registration order.
+type hooks struct {
+	beforeStart []Hook
+	afterStart  []Hook
+	beforeStop  []Hook
+	afterStop   []Hook
+	failure     []FailureHook
+}
+
+// OnBeforeStart registers a hook to run before a unit is started, including
+// as part of a restart.
+func (m *manager) OnBeforeStart(hook Hook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks.beforeStart = append(m.hooks.beforeStart, hook)
+}
+
+// OnAfterStart registers a hook to run after a unit is started, including as
+// part of a restart.
+func (m *manager) OnAfterStart(hook Hook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks.afterStart = append(m.hooks.afterStart, hook)
+}
+
+// OnBeforeStop registers a hook to run before a unit is stopped, including as
+// part of a restart.
+func (m *manager) OnBeforeStop(hook Hook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks.beforeStop = append(m.hooks.beforeStop, hook)
+}
+
+// OnAfterStop registers a hook to run after a unit is stopped.
+func (m *manager) OnAfterStop(hook Hook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks.afterStop = append(m.hooks.afterStop, hook)
+}
+
+// OnFailure registers a hook to run when a unit operation fails.
+func (m *manager) OnFailure(hook FailureHook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.hooks.failure = append(m.hooks.failure, hook)
+}
+
+// runHooksBefore runs the hooks registered to run before op. A restart stops
+// then starts the unit, so it runs both stop and start hooks.
+func (m *manager) runHooksBefore(ctx context.Context, op Operation, unit string) error {
+	m.mutex.RLock()
+	var hs []Hook
+	switch op {
+	case OperationStart:
+		hs = m.hooks.beforeStart
+	case OperationStop:
+		hs = m.hooks.beforeStop
+	case OperationRestart:
+		hs = append(append(hs, m.hooks.beforeStop...), m.hooks.beforeStart...)
+	}
+	m.mutex.RUnlock()
+
+	for _, hook := range hs {
+		if err := hook(ctx, op, unit); err != nil {
+			return fmt.Errorf("before %s hook failed for unit %q: %w", op, unit, err)
+		}
+	}
+
+	return nil
+}
+
+// runHooksAfter runs the hooks registered to run after op.
+func (m *manager) runHooksAfter(ctx context.Context, op Operation, unit string) error {
+	m.mutex.RLock()
+	var hs []Hook
+	switch op {
+	case OperationStart:
+		hs = m.hooks.afterStart
+	case OperationStop:
+		hs = m.hooks.afterStop
+	case OperationRestart:
+		hs = append(append(hs, m.hooks.afterStop...), m.hooks.afterStart...)
+	}
+	m.mutex.RUnlock()
+
+	for _, hook := range hs {
+		if err := hook(ctx, op, unit); err != nil {
+			return fmt.Errorf("after %s hook failed for unit %q: %w", op, unit, err)
+		}
+	}
+
+	return nil
+}
+
+// runHooksFailure runs the hooks registered to run when op fails.
+func (m *manager) runHooksFailure(ctx context.Context, op Operation, unit string, err error) {
+	m.mutex.RLock()
+	hs := m.hooks.failure
+	m.mutex.RUnlock()
+
+	for _, hook := range hs {
+		hook(ctx, op, unit, err)
+	}
+}
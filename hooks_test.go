@@ -0,0 +1,61 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_manager_hooks(t *testing.T) {
+	ctx := context.Background()
+	m := &manager{}
+
+	var calls []string
+	record := func(name string) Hook {
+		return func(_ context.Context, op Operation, unit string) error {
+			calls = append(calls, name+":"+string(op)+":"+unit)
+			return nil
+		}
+	}
+	m.OnBeforeStart(record("before-start"))
+	m.OnAfterStart(record("after-start"))
+	m.OnBeforeStop(record("before-stop"))
+	m.OnAfterStop(record("after-stop"))
+
+	t.Run("Restart runs stop and start hooks", func(t *testing.T) {
+		calls = nil
+		require.NoError(t, m.runHooksBefore(ctx, OperationRestart, "a.service"))
+		require.NoError(t, m.runHooksAfter(ctx, OperationRestart, "a.service"))
+		require.Equal(t, []string{
+			"before-stop:restart:a.service",
+			"before-start:restart:a.service",
+			"after-stop:restart:a.service",
+			"after-start:restart:a.service",
+		}, calls)
+	})
+
+	t.Run("Failing hook stops the chain", func(t *testing.T) {
+		calls = nil
+		hookErr := errors.New("drain failed")
+		m.OnBeforeStop(func(context.Context, Operation, string) error { return hookErr })
+		m.OnBeforeStop(record("never"))
+
+		require.ErrorIs(t, m.runHooksBefore(ctx, OperationStop, "a.service"), hookErr)
+		require.Equal(t, []string{"before-stop:stop:a.service"}, calls)
+	})
+
+	t.Run("Failure hooks receive the error", func(t *testing.T) {
+		opErr := errors.New("job failed")
+		var got error
+		m.OnFailure(func(_ context.Context, op Operation, unit string, err error) {
+			require.Equal(t, OperationStart, op)
+			require.Equal(t, "a.service", unit)
+			got = err
+		})
+
+		m.runHooksFailure(ctx, OperationStart, "a.service", opErr)
+		require.ErrorIs(t, got, opErr)
+	})
+}
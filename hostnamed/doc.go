@@ -0,0 +1,6 @@
+// Package hostnamed reads and sets the host name and machine metadata
+// through systemd-hostnamed.
+package hostnamed
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/hostnamed"
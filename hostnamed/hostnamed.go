@@ -0,0 +1,197 @@
+package hostnamed
+
+import (
+	"context"
+	"fmt"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// hostnamedDest is the D-Bus destination of hostnamed.
+	hostnamedDest = "org.freedesktop.hostname1"
+	// hostnamedPath is the D-Bus object path of hostnamed.
+	hostnamedPath godbus.ObjectPath = "/org/freedesktop/hostname1"
+	// hostnamedInterface is the D-Bus interface of hostnamed.
+	hostnamedInterface = "org.freedesktop.hostname1"
+)
+
+// Chassis types.
+const (
+	ChassisDesktop     = "desktop"
+	ChassisLaptop      = "laptop"
+	ChassisConvertible = "convertible"
+	ChassisServer      = "server"
+	ChassisTablet      = "tablet"
+	ChassisHandset     = "handset"
+	ChassisWatch       = "watch"
+	ChassisEmbedded    = "embedded"
+	ChassisVM          = "vm"
+	ChassisContainer   = "container"
+)
+
+// Info is the host name and metadata of the machine.
+type Info struct {
+	// Hostname is the current host name, which may differ from the static
+	// one, e.g. when set by DHCP.
+	Hostname       string `json:"hostname" yaml:"hostname"`
+	StaticHostname string `json:"static_hostname" yaml:"static_hostname"`
+	PrettyHostname string `json:"pretty_hostname,omitempty" yaml:"pretty_hostname,omitempty"`
+	IconName       string `json:"icon_name,omitempty" yaml:"icon_name,omitempty"`
+	Chassis        string `json:"chassis,omitempty" yaml:"chassis,omitempty"`
+	// Deployment is e.g. "development", "staging", or "production".
+	Deployment string `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	// Location is a human readable location, e.g. "Berlin, rack 3".
+	Location        string `json:"location,omitempty" yaml:"location,omitempty"`
+	KernelName      string `json:"kernel_name" yaml:"kernel_name"`
+	KernelRelease   string `json:"kernel_release" yaml:"kernel_release"`
+	OperatingSystem string `json:"operating_system" yaml:"operating_system"`
+	HardwareVendor  string `json:"hardware_vendor,omitempty" yaml:"hardware_vendor,omitempty"`
+	HardwareModel   string `json:"hardware_model,omitempty" yaml:"hardware_model,omitempty"`
+}
+
+// Manager reads and sets the host name and metadata.
+type Manager interface {
+	Info(ctx context.Context) (Info, error)
+	SetChassis(ctx context.Context, chassis string) error
+	SetDeployment(ctx context.Context, deployment string) error
+	SetHostname(ctx context.Context, hostname string) error
+	SetLocation(ctx context.Context, location string) error
+	SetPrettyHostname(ctx context.Context, hostname string) error
+	SetStaticHostname(ctx context.Context, hostname string) error
+}
+
+// manager manages the host name via a D-Bus connection to hostnamed.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to hostnamed failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to hostnamed over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, hostnamedDest, hostnamedPath, hostnamedInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up hostname manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// Info returns the host name and metadata of the machine.
+func (m *manager) Info(parentCtx context.Context) (Info, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Info")
+	defer span.End()
+
+	props, err := m.conn.Properties(ctx, hostnamedPath, "")
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve host information: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return Info{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved host information")
+
+	return decodeInfo(props), nil
+}
+
+// decodeInfo converts the D-Bus properties of hostnamed. Missing or
+// mistyped properties are left empty.
+func decodeInfo(props map[string]godbus.Variant) Info {
+	str := func(key string) string {
+		s, _ := props[key].Value().(string)
+		return s
+	}
+
+	return Info{
+		Hostname:        str("Hostname"),
+		StaticHostname:  str("StaticHostname"),
+		PrettyHostname:  str("PrettyHostname"),
+		IconName:        str("IconName"),
+		Chassis:         str("Chassis"),
+		Deployment:      str("Deployment"),
+		Location:        str("Location"),
+		KernelName:      str("KernelName"),
+		KernelRelease:   str("KernelRelease"),
+		OperatingSystem: str("OperatingSystemPrettyName"),
+		HardwareVendor:  str("HardwareVendor"),
+		HardwareModel:   str("HardwareModel"),
+	}
+}
+
+// SetHostname sets the transient host name, which is lost on reboot.
+func (m *manager) SetHostname(ctx context.Context, hostname string) error {
+	return m.set(ctx, "Hostname", hostname)
+}
+
+// SetStaticHostname sets the host name stored in /etc/hostname. An empty
+// name resets it to the default.
+func (m *manager) SetStaticHostname(ctx context.Context, hostname string) error {
+	return m.set(ctx, "StaticHostname", hostname)
+}
+
+// SetPrettyHostname sets the free-form, human readable host name.
+func (m *manager) SetPrettyHostname(ctx context.Context, hostname string) error {
+	return m.set(ctx, "PrettyHostname", hostname)
+}
+
+// SetChassis sets the chassis type, one of the Chassis constants. An empty
+// chassis resets it to the detected one.
+func (m *manager) SetChassis(ctx context.Context, chassis string) error {
+	return m.set(ctx, "Chassis", chassis)
+}
+
+// SetDeployment sets the deployment environment, e.g. "production".
+func (m *manager) SetDeployment(ctx context.Context, deployment string) error {
+	return m.set(ctx, "Deployment", deployment)
+}
+
+// SetLocation sets the human readable location of the machine.
+func (m *manager) SetLocation(ctx context.Context, location string) error {
+	return m.set(ctx, "Location", location)
+}
+
+// set calls the Set method of a property, without interactive
+// authorization.
+func (m *manager) set(parentCtx context.Context, prop, value string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Set"+prop)
+	span.SetAttributes(otelattr.String("value", value))
+	defer span.End()
+
+	if err := m.conn.Call(ctx, "Set"+prop, []any{value, false}); err != nil {
+		err = fmt.Errorf("failed to set %s to %q: %w", prop, value, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("set %s", prop))
+
+	return nil
+}
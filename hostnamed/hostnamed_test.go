@@ -0,0 +1,35 @@
+package hostnamed
+
+import (
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeInfo(t *testing.T) {
+	props := map[string]godbus.Variant{
+		"Hostname":                  godbus.MakeVariant("web-1"),
+		"StaticHostname":            godbus.MakeVariant("web-1"),
+		"PrettyHostname":            godbus.MakeVariant("Web server #1"),
+		"Chassis":                   godbus.MakeVariant(ChassisServer),
+		"Deployment":                godbus.MakeVariant("production"),
+		"Location":                  godbus.MakeVariant("rack 3"),
+		"KernelName":                godbus.MakeVariant("Linux"),
+		"KernelRelease":             godbus.MakeVariant("6.8.0"),
+		"OperatingSystemPrettyName": godbus.MakeVariant("Debian GNU/Linux 12 (bookworm)"),
+		// Mistyped properties are ignored.
+		"HardwareVendor": godbus.MakeVariant(uint32(1)),
+	}
+	require.Equal(t, Info{
+		Hostname:        "web-1",
+		StaticHostname:  "web-1",
+		PrettyHostname:  "Web server #1",
+		Chassis:         ChassisServer,
+		Deployment:      "production",
+		Location:        "rack 3",
+		KernelName:      "Linux",
+		KernelRelease:   "6.8.0",
+		OperatingSystem: "Debian GNU/Linux 12 (bookworm)",
+	}, decodeInfo(props))
+}
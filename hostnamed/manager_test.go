@@ -0,0 +1,48 @@
+//go:build linux
+
+package hostnamed
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_Info(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	info, err := mgr.Info(ctx)
+	require.NoError(t, err)
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, hostname, info.Hostname)
+	require.Equal(t, "Linux", info.KernelName)
+}
+
+func Test_E2E_Manager_SetLocation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	info, err := mgr.Info(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = mgr.SetLocation(t.Context(), info.Location)
+	}()
+
+	require.NoError(t, mgr.SetLocation(ctx, "systemdmanager e2e test"))
+	updated, err := mgr.Info(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "systemdmanager e2e test", updated.Location)
+}
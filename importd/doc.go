@@ -0,0 +1,6 @@
+// Package importd downloads container and VM images through
+// systemd-importd, for use with machined or portabled.
+package importd
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/importd"
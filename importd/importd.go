@@ -0,0 +1,221 @@
+package importd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// importdDest is the D-Bus destination of importd.
+	importdDest = "org.freedesktop.import1"
+	// importdPath is the D-Bus object path of importd.
+	importdPath godbus.ObjectPath = "/org/freedesktop/import1"
+	// managerInterface is the D-Bus interface of importd.
+	managerInterface = "org.freedesktop.import1.Manager"
+	// transferInterface is the D-Bus interface of transfers.
+	transferInterface = "org.freedesktop.import1.Transfer"
+)
+
+// progressPollInterval is how often the progress of a transfer is checked.
+const progressPollInterval = time.Second
+
+// cancelTransferTimeout bounds cancelling a transfer whose context is done.
+const cancelTransferTimeout = 5 * time.Second
+
+// ErrTransferFailed means a transfer didn't complete.
+var ErrTransferFailed = errors.New("transfer failed")
+
+// Verification modes of downloaded images.
+const (
+	// VerifyNo doesn't verify images.
+	VerifyNo = "no"
+	// VerifyChecksum verifies images against a SHA256SUMS file.
+	VerifyChecksum = "checksum"
+	// VerifySignature verifies images against a signed SHA256SUMS file.
+	VerifySignature = "signature"
+)
+
+// PullOptions configures pulling an image.
+type PullOptions struct {
+	// Verify is one of the Verify constants. Defaults to VerifySignature.
+	Verify string
+	// Force replaces an existing image with the same local name.
+	Force bool
+}
+
+// Progress is the progress of a transfer.
+type Progress struct {
+	// Transfer is the ID of the transfer.
+	Transfer uint32 `json:"transfer" yaml:"transfer"`
+	// Fraction is how much is done, from 0 to 1.
+	Fraction float64 `json:"fraction" yaml:"fraction"`
+}
+
+// Manager downloads images.
+type Manager interface {
+	PullRaw(ctx context.Context, url, localName string, opts PullOptions, progressChan chan<- Progress) error
+	PullTar(ctx context.Context, url, localName string, opts PullOptions, progressChan chan<- Progress) error
+}
+
+// manager downloads images via a D-Bus connection to importd.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to importd failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to importd over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, importdDest, importdPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up import manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// PullTar downloads a container image packaged as a tarball from url, and
+// stores it as localName in /var/lib/machines. It blocks until the
+// transfer ends, sending its progress to progressChan unless nil. The
+// transfer is cancelled when ctx is done.
+func (m *manager) PullTar(ctx context.Context, url, localName string, opts PullOptions, progressChan chan<- Progress) error {
+	return m.pull(ctx, "PullTar", url, localName, opts, progressChan)
+}
+
+// PullRaw downloads a disk image from url like PullTar.
+func (m *manager) PullRaw(ctx context.Context, url, localName string, opts PullOptions, progressChan chan<- Progress) error {
+	return m.pull(ctx, "PullRaw", url, localName, opts, progressChan)
+}
+
+// pull starts a transfer with one of the pull methods and waits for it to
+// end.
+func (m *manager) pull(parentCtx context.Context, method, url, localName string, opts PullOptions, progressChan chan<- Progress) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, method)
+	span.SetAttributes(otelattr.String("url", url), otelattr.String("local_name", localName))
+	defer span.End()
+
+	if err := m.doPull(ctx, method, url, localName, opts, progressChan); err != nil {
+		err = fmt.Errorf("failed to pull %q: %w", url, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("pulled %q", localName))
+
+	return nil
+}
+
+// doPull implements pull.
+func (m *manager) doPull(ctx context.Context, method, url, localName string, opts PullOptions, progressChan chan<- Progress) error {
+	verify := opts.Verify
+	if verify == "" {
+		verify = VerifySignature
+	}
+
+	// Subscribe before starting the transfer, not to miss its end.
+	removed, unsubscribe, err := m.conn.Subscribe(managerInterface, "TransferRemoved")
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	var (
+		id   uint32
+		path godbus.ObjectPath
+	)
+	if err := m.conn.Call(ctx, method, []any{url, localName, verify, opts.Force}, &id, &path); err != nil {
+		return err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(otelattr.Int("transfer", int(id)))
+
+	ticker := time.NewTicker(progressPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case s, ok := <-removed:
+			if !ok {
+				return systemdmanager.ErrDisconnected
+			}
+			if result, ok := transferResult(s, id); ok {
+				if result != "done" {
+					return fmt.Errorf("%w: transfer %d %s", ErrTransferFailed, id, result)
+				}
+				sendProgress(ctx, progressChan, Progress{Transfer: id, Fraction: 1})
+
+				return nil
+			}
+		case <-ticker.C:
+			// The transfer may end in between, which is then reported by
+			// the signal.
+			variant, err := m.conn.Property(ctx, path, transferInterface, "Progress")
+			if err != nil {
+				continue
+			}
+			if fraction, ok := variant.Value().(float64); ok {
+				sendProgress(ctx, progressChan, Progress{Transfer: id, Fraction: fraction})
+			}
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTransferTimeout)
+			defer cancel()
+			_ = m.conn.Call(cancelCtx, "CancelTransfer", []any{id})
+
+			return ctx.Err()
+		}
+	}
+}
+
+// transferResult returns the result of the transfer with the given ID if s
+// is its TransferRemoved signal.
+func transferResult(s *godbus.Signal, id uint32) (string, bool) {
+	if len(s.Body) != 3 {
+		return "", false
+	}
+	removedID, _ := s.Body[0].(uint32)
+	result, ok := s.Body[2].(string)
+	if !ok || removedID != id {
+		return "", false
+	}
+
+	return result, true
+}
+
+// sendProgress sends p to progressChan, unless nil or ctx is done.
+func sendProgress(ctx context.Context, progressChan chan<- Progress, p Progress) {
+	if progressChan == nil {
+		return
+	}
+	select {
+	case progressChan <- p:
+	case <-ctx.Done():
+	}
+}
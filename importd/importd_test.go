@@ -0,0 +1,36 @@
+package importd
+
+import (
+	"context"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_transferResult(t *testing.T) {
+	signal := &godbus.Signal{Body: []any{uint32(7), godbus.ObjectPath("/org/freedesktop/import1/transfer/_7"), "done"}}
+	result, ok := transferResult(signal, 7)
+	require.True(t, ok)
+	require.Equal(t, "done", result)
+
+	// Other transfers are ignored.
+	_, ok = transferResult(signal, 8)
+	require.False(t, ok)
+	_, ok = transferResult(&godbus.Signal{Body: []any{uint32(7)}}, 7)
+	require.False(t, ok)
+}
+
+func Test_Unit_sendProgress(t *testing.T) {
+	// A nil channel is ignored.
+	sendProgress(t.Context(), nil, Progress{})
+
+	progressChan := make(chan Progress, 1)
+	sendProgress(t.Context(), progressChan, Progress{Transfer: 1, Fraction: 0.5})
+	require.Equal(t, Progress{Transfer: 1, Fraction: 0.5}, <-progressChan)
+
+	// Sending doesn't block once ctx is done.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	sendProgress(ctx, make(chan Progress), Progress{})
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package importd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_PullTar(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Pulling from a missing server fails, rather than hang.
+	err = mgr.PullTar(ctx, "http://127.0.0.1:1/image.tar.xz", "systemdmanager-e2e", PullOptions{Verify: VerifyNo}, nil)
+	require.ErrorIs(t, err, ErrTransferFailed)
+}
@@ -0,0 +1,262 @@
+package systemdmanager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// embeddedUnit is a unit file read from a file system.
+type embeddedUnit struct {
+	name    string
+	content []byte
+	file    *unitfile.File
+	changed bool
+}
+
+// InstallFromFS installs the named unit files from fsys, e.g. an embed.FS,
+// into the unit directory, then enables and starts them. Without names, all
+// unit files at the root of fsys are installed. This lets a daemon ship and
+// install its own units on first run.
+//
+// It is idempotent: unit files already installed with the same settings
+// aren't rewritten, and units already active aren't restarted. Changed units
+// are restarted once systemd is reloaded. Units are only enabled if they have
+// an [Install] section, and templates are enabled but not started. Units
+// triggered by timer, socket, or path units installed along with them, such
+// as the service of a timer, aren't started either, but are restarted if
+// changed while active.
+func (m *manager) InstallFromFS(parentCtx context.Context, fsys fs.FS, names ...string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "InstallFromFS")
+	defer span.End()
+
+	units, err := m.installFromFS(ctx, fsys, names)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully installed %d units", len(units)))
+
+	return nil
+}
+
+// installFromFS implements InstallFromFS, returning the installed units.
+func (m *manager) installFromFS(ctx context.Context, fsys fs.FS, names []string) ([]*embeddedUnit, error) {
+	if len(names) == 0 {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list unit files: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && ValidateUnitName(entry.Name()) == nil {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	trace.SpanFromContext(ctx).SetAttributes(otelattr.StringSlice("units", names))
+
+	// Read and compare every unit file before touching anything.
+	units := make([]*embeddedUnit, 0, len(names))
+	for _, n := range names {
+		u := &embeddedUnit{name: path.Base(n)}
+		if err := m.validateUnit(u.name); err != nil {
+			return nil, err
+		}
+		// Don't touch units outside maintenance windows or claimed by other
+		// owners, not even their files.
+		if err := m.admitChange(ctx, u.name); err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read unit file %q: %w", n, err)
+		}
+		u.content = content
+		if u.file, err = unitfile.Parse(bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse unit file %q: %w", n, err)
+		}
+		diff, err := m.diffUnit(ctx, u.name, content)
+		if err != nil {
+			return nil, err
+		}
+		u.changed = diff.Path != m.unitPath(u.name) || diff.NeedDaemonReload || len(diff.Changes) > 0
+		units = append(units, u)
+	}
+
+	reload := false
+	for _, u := range units {
+		if !u.changed {
+			continue
+		}
+		if err := writeFileAtomic(m.unitPath(u.name), u.content); err != nil {
+			return nil, fmt.Errorf("failed to install unit file %q: %w", u.name, err)
+		}
+		m.writes.record(m.unitPath(u.name), u.content)
+		reload = true
+	}
+	if reload {
+		if err := m.daemonReload(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var toEnable []string
+	for _, u := range units {
+		if u.file.Section("Install") != nil {
+			toEnable = append(toEnable, u.name)
+		}
+	}
+	if len(toEnable) > 0 {
+		err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+			_, _, err := m.dbusConn.Load().EnableUnitFilesContext(ctx, toEnable, false, true)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable units %q: %w", toEnable, err)
+		}
+	}
+
+	// Triggered units are left for the units triggering them to start.
+	triggered := triggeredUnits(units)
+	for _, u := range units {
+		if IsTemplate(u.name) {
+			continue
+		}
+		if triggered[u.name] {
+			if err := m.restartIfActive(ctx, u); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var err error
+		if u.changed {
+			err = m.Restart(ctx, u.name)
+		} else {
+			err = m.Start(ctx, u.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return units, nil
+}
+
+// triggeredUnits returns the units triggered by the timer, socket, and path
+// units among units.
+func triggeredUnits(units []*embeddedUnit) map[string]bool {
+	triggered := make(map[string]bool)
+	for _, u := range units {
+		if t := triggeredUnit(u.name, u.file); t != "" {
+			triggered[t] = true
+		}
+	}
+
+	return triggered
+}
+
+// triggeredUnit returns the unit triggered by the named timer, socket, or
+// path unit, as set by its unit file f, or the service of the same name. It
+// returns an empty string for units of other types.
+func triggeredUnit(unit string, f *unitfile.File) string {
+	base := strings.TrimSuffix(unit, "."+unitType(unit))
+	switch unitType(unit) {
+	case "path":
+		return pathActivatedUnit(unit, f)
+	case "timer":
+		if activated, ok := f.Get("Timer", "Unit"); ok && activated != "" {
+			return activated
+		}
+	case "socket":
+		if service, ok := f.Get("Socket", "Service"); ok && service != "" {
+			return service
+		}
+		// Sockets accepting connections start an instance per connection.
+		if accept, ok := f.Get("Socket", "Accept"); ok {
+			if yes, _ := unitfile.ParseBool(accept); yes {
+				return base + "@.service"
+			}
+		}
+	default:
+		return ""
+	}
+
+	return base + ".service"
+}
+
+// restartIfActive restarts a changed unit if it's active, so it runs with
+// its new unit file.
+func (m *manager) restartIfActive(ctx context.Context, u *embeddedUnit) error {
+	if !u.changed {
+		return nil
+	}
+	state, err := m.activeState(ctx, u.name)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve state of unit %q: %w", u.name, err)
+	}
+	if state != "active" && state != "reloading" {
+		return nil
+	}
+
+	return m.Restart(ctx, u.name)
+}
+
+// unitPath returns where the named unit file is installed.
+func (m *manager) unitPath(unit string) string {
+	return filepath.Join(m.options.unitDirectory, unit)
+}
+
+// daemonReload makes systemd reload all unit files.
+func (m *manager) daemonReload(ctx context.Context) error {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return ErrDisconnected
+	}
+
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().ReloadContext(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes a file so that readers see either the previous or
+// the new content, never a partial write.
+func writeFileAtomic(name string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
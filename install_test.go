@@ -0,0 +1,37 @@
+package systemdmanager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_triggeredUnits(t *testing.T) {
+	unit := func(name, content string) *embeddedUnit {
+		f, err := unitfile.Parse(strings.NewReader(content))
+		require.NoError(t, err)
+
+		return &embeddedUnit{name: name, file: f}
+	}
+	units := []*embeddedUnit{
+		unit("backup.timer", "[Timer]\nOnCalendar=daily\n\n[Install]\nWantedBy=timers.target\n"),
+		unit("backup.service", "[Service]\nType=oneshot\nExecStart=/usr/bin/backup\n"),
+		unit("cleanup.timer", "[Timer]\nOnCalendar=weekly\nUnit=purge.service\n"),
+		unit("api.socket", "[Socket]\nListenStream=8080\n"),
+		unit("echo.socket", "[Socket]\nListenStream=7\nAccept=yes\n"),
+		unit("web.socket", "[Socket]\nListenStream=80\nService=httpd.service\n"),
+		unit("upload.path", "[Path]\nPathExists=/run/upload\n"),
+		unit("daemon.service", "[Service]\nExecStart=/usr/bin/daemon\n"),
+	}
+
+	require.Equal(t, map[string]bool{
+		"backup.service": true,
+		"purge.service":  true,
+		"api.service":    true,
+		"echo@.service":  true,
+		"httpd.service":  true,
+		"upload.service": true,
+	}, triggeredUnits(units))
+}
@@ -0,0 +1,90 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ListInstances returns the names of the loaded instances of a templated
+// unit, such as "foo@.service", sorted by name.
+func (m *manager) ListInstances(parentCtx context.Context, template string) ([]string, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListInstances")
+	span.SetAttributes(otelattr.String("template", template))
+	defer span.End()
+
+	instances, err := m.listInstances(ctx, template)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetAttributes(otelattr.Int("instances", len(instances)))
+	span.SetStatus(otelcodes.Ok, "listed template instances")
+
+	return instances, nil
+}
+
+// listInstances returns the names of the loaded instances of a templated
+// unit.
+func (m *manager) listInstances(ctx context.Context, template string) ([]string, error) {
+	statuses, err := m.instanceStatuses(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		instances = append(instances, status.Name)
+	}
+
+	return instances, nil
+}
+
+// instanceStatuses returns the statuses of the loaded instances of a
+// templated unit, sorted by name.
+func (m *manager) instanceStatuses(ctx context.Context, template string) ([]dbus.UnitStatus, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	pattern, err := instancePattern(template)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []dbus.UnitStatus
+	err = m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		statuses, err = m.dbusConn.Load().ListUnitsByPatternsContext(ctx, nil, []string{pattern})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances of %q: %w", template, err)
+	}
+	// Leave out instances the manager isn't allowed to touch.
+	statuses = m.allowedStatuses(statuses)
+	slices.SortFunc(statuses, func(a, b dbus.UnitStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses, nil
+}
+
+// instancePattern turns a template name such as "foo@.service" into a glob
+// matching all of its instances, such as "foo@*.service".
+func instancePattern(template string) (string, error) {
+	prefix, suffix, ok := strings.Cut(template, "@")
+	if !ok || prefix == "" || !strings.HasPrefix(suffix, ".") {
+		return "", fmt.Errorf("%w %q: not a template", ErrInvalidUnitName, template)
+	}
+
+	return prefix + "@*" + suffix, nil
+}
@@ -0,0 +1,32 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_instancePattern(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+		failed   bool
+	}{
+		{template: "foo@.service", expected: "foo@*.service"},
+		{template: "foo@.socket", expected: "foo@*.socket"},
+		{template: "foo.service", failed: true},
+		{template: "@.service", failed: true},
+		{template: "foo@", failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			pattern, err := instancePattern(tt.template)
+			if tt.failed {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, pattern)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package bus holds the D-Bus plumbing shared by the packages integrating
+// with systemd services other than the manager.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+)
+
+// Conn is a connection to a service on the system bus.
+type Conn struct {
+	busConn     *godbus.Conn
+	dest        string
+	path        godbus.ObjectPath
+	iface       string
+	retryPolicy systemdmanager.RetryPolicy
+}
+
+// Option configures a Conn, and the Manager of the packages using it.
+type Option func(*options)
+
+// options holds the settings of a Conn.
+type options struct {
+	retryPolicy systemdmanager.RetryPolicy
+}
+
+// newOptions returns the settings set by opts.
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithRetryPolicy retries D-Bus calls failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = p
+	}
+}
+
+// Connect connects to the service named dest on the system bus, whose
+// manager object is at path and implements iface. The connection is closed
+// when ctx is done.
+func Connect(ctx context.Context, dest string, path godbus.ObjectPath, iface string, opts ...Option) (*Conn, error) {
+	o := newOptions(opts)
+	busConn, err := godbus.ConnectSystemBus(godbus.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the D-Bus connection is closed when done.
+	go func(c *godbus.Conn) {
+		<-ctx.Done()
+		c.Close()
+	}(busConn)
+
+	return &Conn{
+		busConn:     busConn,
+		dest:        dest,
+		path:        path,
+		iface:       iface,
+		retryPolicy: o.retryPolicy,
+	}, nil
+}
+
+// Call calls a method of the manager object and stores its results in ret.
+func (c *Conn) Call(ctx context.Context, method string, args []any, ret ...any) error {
+	return c.CallObject(ctx, c.path, c.iface+"."+method, args, ret...)
+}
+
+// CallObject calls a method, given with its interface, of the object at path
+// and stores its results in ret. Access denied errors wrap
+// systemdmanager.ErrPermissionDenied.
+func (c *Conn) CallObject(ctx context.Context, path godbus.ObjectPath, method string, args []any, ret ...any) error {
+	// Ensure connection to D-Bus API.
+	if !c.busConn.Connected() {
+		return systemdmanager.ErrDisconnected
+	}
+
+	err := c.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return c.busConn.Object(c.dest, path).CallWithContext(ctx, method, 0, args...).Store(ret...)
+	})
+	if systemdmanager.IsAccessDenied(err) {
+		return fmt.Errorf("%w: %w", systemdmanager.ErrPermissionDenied, err)
+	}
+
+	return err
+}
+
+// Property returns a property of the object at path. An empty iface means
+// the manager interface.
+func (c *Conn) Property(ctx context.Context, path godbus.ObjectPath, iface, prop string) (godbus.Variant, error) {
+	if iface == "" {
+		iface = c.iface
+	}
+
+	var variant godbus.Variant
+	err := c.CallObject(ctx, path, "org.freedesktop.DBus.Properties.Get", []any{iface, prop}, &variant)
+
+	return variant, err
+}
+
+// Properties returns all properties of the object at path on an interface.
+// An empty iface means the manager interface.
+func (c *Conn) Properties(ctx context.Context, path godbus.ObjectPath, iface string) (map[string]godbus.Variant, error) {
+	if iface == "" {
+		iface = c.iface
+	}
+
+	var props map[string]godbus.Variant
+	err := c.CallObject(ctx, path, "org.freedesktop.DBus.Properties.GetAll", []any{iface}, &props)
+
+	return props, err
+}
+
+// Path returns the path of the manager object.
+func (c *Conn) Path() godbus.ObjectPath {
+	return c.path
+}
+
+// Subscribe delivers the signals named member of iface, emitted by the
+// manager object, until the returned function is called.
+func (c *Conn) Subscribe(iface, member string) (<-chan *godbus.Signal, func(), error) {
+	matches := []godbus.MatchOption{
+		godbus.WithMatchSender(c.dest),
+		godbus.WithMatchObjectPath(c.path),
+		godbus.WithMatchInterface(iface),
+		godbus.WithMatchMember(member),
+	}
+	if err := c.busConn.AddMatchSignal(matches...); err != nil {
+		return nil, nil, err
+	}
+
+	// Signals of other subscriptions are delivered too, so they're
+	// filtered here.
+	all := make(chan *godbus.Signal, 16)
+	signals := make(chan *godbus.Signal, 16)
+	c.busConn.Signal(all)
+	done := make(chan struct{})
+	go func() {
+		defer close(signals)
+		for {
+			select {
+			case s, ok := <-all:
+				if !ok {
+					return
+				}
+				if s.Path == c.path && s.Name == iface+"."+member {
+					select {
+					case signals <- s:
+					case <-done:
+						return
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			c.busConn.RemoveSignal(all)
+			_ = c.busConn.RemoveMatchSignal(matches...)
+			close(done)
+		})
+	}
+
+	return signals, unsubscribe, nil
+}
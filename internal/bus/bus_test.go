@@ -0,0 +1,17 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pires/go-systemdmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_newOptions(t *testing.T) {
+	// Calls aren't retried by default.
+	require.Zero(t, newOptions(nil).retryPolicy.Attempts)
+
+	policy := systemdmanager.RetryPolicy{Attempts: 3, Backoff: systemdmanager.Backoff{Initial: time.Millisecond}}
+	require.Equal(t, policy, newOptions([]Option{WithRetryPolicy(policy)}).retryPolicy)
+}
@@ -0,0 +1,95 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+)
+
+var (
+	// ErrJobCanceled means a job was cancelled before completing, e.g. by
+	// a conflicting job.
+	ErrJobCanceled = errors.New("job canceled")
+	// ErrJobTimeout means a job reached its systemd timeout.
+	ErrJobTimeout = errors.New("job timed out")
+	// ErrJobFailed means a job failed, such as a service exiting with an
+	// error. See JobError.UnitResult for why.
+	ErrJobFailed = errors.New("job failed")
+	// ErrJobDependency means a job failed because a dependency failed.
+	ErrJobDependency = errors.New("job dependency failed")
+	// ErrJobSkipped means a job was skipped because it didn't apply to the
+	// unit's current state.
+	ErrJobSkipped = errors.New("job skipped")
+)
+
+// jobResultErrors are the errors of job results other than done.
+var jobResultErrors = map[string]error{
+	"canceled":   ErrJobCanceled,
+	"timeout":    ErrJobTimeout,
+	"failed":     ErrJobFailed,
+	"dependency": ErrJobDependency,
+	"skipped":    ErrJobSkipped,
+}
+
+// unitResultTypes are the unit types with a Result property, which tells
+// why a unit failed.
+var unitResultTypes = []string{"service", "socket", "mount", "automount", "swap", "timer", "path", "scope"}
+
+// unitResultTimeout bounds retrieving why a unit failed.
+const unitResultTimeout = 5 * time.Second
+
+// JobError means a job completed with a result other than "done". It matches
+// the error of its result with errors.Is, e.g. ErrJobFailed.
+type JobError struct {
+	Op   Operation
+	Unit string
+	// Path is the D-Bus object path of the job.
+	Path godbus.ObjectPath
+	// Result is the result of the job, e.g. "failed" or "dependency".
+	Result string
+	// UnitResult is why the unit failed, e.g. "exit-code", "signal", or
+	// "oom-kill", when the job failed and the unit type tells.
+	UnitResult string
+}
+
+func (e *JobError) Error() string {
+	msg := fmt.Sprintf("failed to %s unit %q with result %q", e.Op, e.Unit, e.Result)
+	if e.UnitResult != "" {
+		msg += fmt.Sprintf(", unit result %q", e.UnitResult)
+	}
+
+	return msg
+}
+
+func (e *JobError) Unwrap() error {
+	return jobResultErrors[e.Result]
+}
+
+// jobPath returns the D-Bus object path of a job.
+func jobPath(jobID int) godbus.ObjectPath {
+	return systemdPath + "/job/" + godbus.ObjectPath(strconv.Itoa(jobID))
+}
+
+// jobError returns the error of a job of op on the named unit completing
+// with result, which for failed jobs tells why the unit failed, if known.
+func (m *manager) jobError(ctx context.Context, op Operation, unit string, jobID int, result string) error {
+	err := &JobError{Op: op, Unit: unit, Path: jobPath(jobID), Result: result}
+	if result != "failed" || !slices.Contains(unitResultTypes, unitType(unit)) {
+		return err
+	}
+
+	// The job is over, so don't fail to tell why if the operation is.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), unitResultTimeout)
+	defer cancel()
+	iface := unitTypeInterface(unit)
+	if unitResult, resultErr := getProperty[string](ctx, m, unit, iface, "Result"); resultErr == nil {
+		err.UnitResult = unitResult
+	}
+
+	return err
+}
@@ -0,0 +1,31 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_JobError(t *testing.T) {
+	for result, sentinel := range jobResultErrors {
+		err := error(&JobError{Op: OperationStart, Unit: "a.service", Result: result})
+		require.ErrorIs(t, err, sentinel, result)
+	}
+
+	err := &JobError{Op: OperationRestart, Unit: "a.service", Path: jobPath(42), Result: "failed", UnitResult: "oom-kill"}
+	require.Equal(t, godbus.ObjectPath("/org/freedesktop/systemd1/job/42"), err.Path)
+	require.EqualError(t, err, `failed to restart unit "a.service" with result "failed", unit result "oom-kill"`)
+	require.NotErrorIs(t, err, ErrJobDependency)
+}
+
+func Test_Unit_jobError(t *testing.T) {
+	// Unit results are only retrieved for failed jobs of units having one,
+	// so no call is made to systemd.
+	m := &manager{}
+	err := m.jobError(context.Background(), OperationStart, "a.target", 7, "failed")
+	require.EqualError(t, err, `failed to start unit "a.target" with result "failed"`)
+	err = m.jobError(context.Background(), OperationStop, "a.service", 7, "canceled")
+	require.ErrorIs(t, err, ErrJobCanceled)
+}
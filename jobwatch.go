@@ -0,0 +1,162 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// JobEventType is the kind of a JobEvent.
+type JobEventType string
+
+const (
+	// JobEventNew is a job being enqueued.
+	JobEventNew JobEventType = "new"
+	// JobEventRemoved is a job being completed or cancelled.
+	JobEventRemoved JobEventType = "removed"
+)
+
+// JobEvent is a job enqueued or removed by systemd, by any client, as
+// observed by WatchJobs.
+type JobEvent struct {
+	Type JobEventType `json:"type" yaml:"type"`
+	Time time.Time    `json:"time" yaml:"time"`
+	ID   uint32       `json:"id" yaml:"id"`
+	Path string       `json:"path" yaml:"path"`
+	Unit string       `json:"unit" yaml:"unit"`
+	// JobType is e.g. "start", "stop", or "restart", for new jobs whose type
+	// could be retrieved before they completed.
+	JobType string `json:"job_type,omitempty" yaml:"job_type,omitempty"`
+	// Result is e.g. "done", "canceled", "failed", or "skipped", for
+	// removed jobs.
+	Result string `json:"result,omitempty" yaml:"result,omitempty"`
+}
+
+// WatchJobs sends every job enqueued and removed by systemd to jobsChan,
+// including those of other clients, such as administrators running
+// systemctl or timers elapsing, so controllers can avoid fighting them. This
+// is a blocking function. Watching survives losing the D-Bus connection,
+// although jobs may be missed while disconnected. It fails with
+// ErrDisconnected if reconnecting fails.
+func (m *manager) WatchJobs(parentCtx context.Context, jobsChan chan<- JobEvent) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "WatchJobs")
+	defer span.End()
+
+	// Ensure a non-nil channel is provided.
+	if jobsChan == nil {
+		err := fmt.Errorf("a chan is required for WatchJobs to write jobs to")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+
+	err := m.watchJobs(ctx, jobsChan)
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+
+	return err
+}
+
+// watchJobs follows jobs, reconnecting as needed, until ctx is done or
+// reconnecting fails.
+func (m *manager) watchJobs(ctx context.Context, jobsChan chan<- JobEvent) error {
+	for {
+		err := m.followJobs(ctx, jobsChan)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, ErrDisconnected) {
+			return err
+		}
+		if err := m.reconnect(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// followJobs sends the jobs signaled over the current connection to
+// jobsChan, until ctx is done or the connection is lost.
+func (m *manager) followJobs(ctx context.Context, jobsChan chan<- JobEvent) error {
+	c := m.dbusConn.Load()
+	// Ensure connection to D-Bus API.
+	if !c.Connected() {
+		return ErrDisconnected
+	}
+	signals, unsubscribe, err := c.subscribe(ctx, "JobNew", "JobRemoved")
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s, ok := <-signals:
+			if !ok {
+				return ErrDisconnected
+			}
+			event, ok := newJobEvent(s, time.Now())
+			if !ok || !m.allowed(event.Unit) {
+				continue
+			}
+			if event.Type == JobEventNew {
+				// Jobs may complete before their type is retrieved.
+				event.JobType, _ = c.jobType(ctx, godbus.ObjectPath(event.Path))
+			}
+
+			select {
+			case jobsChan <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// newJobEvent returns the job event of a JobNew or JobRemoved signal, and
+// whether s is one.
+func newJobEvent(s *godbus.Signal, now time.Time) (JobEvent, bool) {
+	var (
+		event JobEvent
+		path  godbus.ObjectPath
+		err   error
+	)
+	switch s.Name {
+	case managerInterface + ".JobNew":
+		event.Type = JobEventNew
+		err = godbus.Store(s.Body, &event.ID, &path, &event.Unit)
+	case managerInterface + ".JobRemoved":
+		event.Type = JobEventRemoved
+		err = godbus.Store(s.Body, &event.ID, &path, &event.Unit, &event.Result)
+	default:
+		return JobEvent{}, false
+	}
+	if err != nil {
+		return JobEvent{}, false
+	}
+	event.Time, event.Path = now, string(path)
+
+	return event, true
+}
+
+// jobType returns the type of the job at path.
+func (c *conn) jobType(ctx context.Context, path godbus.ObjectPath) (string, error) {
+	var variant godbus.Variant
+	err := c.busConn.Object(systemdDest, path).
+		CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, systemdDest+".Job", "JobType").
+		Store(&variant)
+	if err != nil {
+		return "", err
+	}
+	jobType, _ := variant.Value().(string)
+
+	return jobType, nil
+}
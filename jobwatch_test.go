@@ -0,0 +1,38 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_newJobEvent(t *testing.T) {
+	now := time.Now()
+
+	event, ok := newJobEvent(&godbus.Signal{
+		Name: "org.freedesktop.systemd1.Manager.JobNew",
+		Body: []any{uint32(42), godbus.ObjectPath("/org/freedesktop/systemd1/job/42"), "a.service"},
+	}, now)
+	require.True(t, ok)
+	require.Equal(t, JobEvent{Type: JobEventNew, Time: now, ID: 42, Path: "/org/freedesktop/systemd1/job/42", Unit: "a.service"}, event)
+
+	event, ok = newJobEvent(&godbus.Signal{
+		Name: "org.freedesktop.systemd1.Manager.JobRemoved",
+		Body: []any{uint32(42), godbus.ObjectPath("/org/freedesktop/systemd1/job/42"), "a.service", "done"},
+	}, now)
+	require.True(t, ok)
+	require.Equal(t, JobEvent{Type: JobEventRemoved, Time: now, ID: 42, Path: "/org/freedesktop/systemd1/job/42", Unit: "a.service", Result: "done"}, event)
+
+	// Other signals and malformed ones are ignored.
+	_, ok = newJobEvent(&godbus.Signal{Name: "org.freedesktop.systemd1.Manager.UnitNew"}, now)
+	require.False(t, ok)
+	_, ok = newJobEvent(&godbus.Signal{Name: "org.freedesktop.systemd1.Manager.JobNew", Body: []any{"42"}}, now)
+	require.False(t, ok)
+}
+
+func Test_Unit_WatchJobs_NilChan(t *testing.T) {
+	require.Error(t, (&manager{}).WatchJobs(context.Background(), nil))
+}
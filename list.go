@@ -0,0 +1,116 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitFilter selects the units listed by EachUnit. Filtering is done by
+// systemd, so units which don't match never reach the caller.
+type UnitFilter struct {
+	// Patterns are globs matched against unit names, e.g. "run-*.scope".
+	// Empty matches all names.
+	Patterns []string
+	// States are load, active, or sub states, e.g. "loaded" or "failed".
+	// Empty matches all states.
+	States []string
+}
+
+// EachUnit calls fn with the status of each loaded unit matching filter,
+// stopping at the first error, which is returned. The reply of systemd is
+// received and decoded whole, so filtering is what keeps it small on hosts
+// with tens of thousands of units; statuses are only converted as fn is
+// called.
+func (m *manager) EachUnit(parentCtx context.Context, filter UnitFilter, fn func(dbus.UnitStatus) error) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "EachUnit")
+	span.SetAttributes(
+		otelattr.StringSlice("patterns", filter.Patterns),
+		otelattr.StringSlice("states", filter.States),
+	)
+	defer span.End()
+
+	count, err := m.eachUnit(ctx, filter, fn)
+	span.SetAttributes(otelattr.Int("units", count))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "listed units")
+
+	return nil
+}
+
+// eachUnit implements EachUnit, returning how many units fn was called with.
+func (m *manager) eachUnit(ctx context.Context, filter UnitFilter, fn func(dbus.UnitStatus) error) (int, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return 0, ErrDisconnected
+	}
+
+	// Calling the method directly converts the reply into statuses as units
+	// are visited, rather than all at once, so stopping early skips the rest.
+	// The D-Bus library still decodes the whole reply beforehand.
+	states, patterns := filter.States, filter.Patterns
+	if states == nil {
+		states = []string{}
+	}
+	if patterns == nil {
+		patterns = []string{}
+	}
+	var body []any
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		call := m.dbusConn.Load().systemd().CallWithContext(ctx, managerInterface+".ListUnitsByPatterns", 0, states, patterns)
+		body = call.Body
+
+		return call.Err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list units: %w", err)
+	}
+
+	// Leave out units the manager isn't allowed to touch.
+	count := 0
+	_, err = eachUnitStatus(body, func(status dbus.UnitStatus) error {
+		if !m.allowed(status.Name) {
+			return nil
+		}
+		count++
+
+		return fn(status)
+	})
+
+	return count, err
+}
+
+// eachUnitStatus converts the decoded reply of ListUnitsByPatterns into
+// statuses one unit at a time, calling fn with each.
+func eachUnitStatus(body []any, fn func(dbus.UnitStatus) error) (int, error) {
+	if len(body) != 1 {
+		return 0, fmt.Errorf("unexpected reply to list units: %d values", len(body))
+	}
+	entries, ok := body[0].([][]any)
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply to list units: %T", body[0])
+	}
+
+	for i, entry := range entries {
+		var status dbus.UnitStatus
+		if err := godbus.Store([]any{entry}, &status); err != nil {
+			return i, fmt.Errorf("unexpected unit in reply to list units: %w", err)
+		}
+		if err := fn(status); err != nil {
+			return i + 1, err
+		}
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,52 @@
+package systemdmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_eachUnitStatus(t *testing.T) {
+	entry := func(name, active string) []any {
+		return []any{name, "", "loaded", active, "running", "", godbus.ObjectPath("/org/freedesktop/systemd1/unit/" + name), uint32(0), "", godbus.ObjectPath("/")}
+	}
+	body := func() []any {
+		return []any{[][]any{entry("a.service", "active"), entry("b.service", "failed")}}
+	}
+
+	t.Run("Visits all units", func(t *testing.T) {
+		var got []dbus.UnitStatus
+		n, err := eachUnitStatus(body(), func(s dbus.UnitStatus) error {
+			got = append(got, s)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, dbus.UnitStatus{
+			Name:        "a.service",
+			LoadState:   "loaded",
+			ActiveState: "active",
+			SubState:    "running",
+			Path:        "/org/freedesktop/systemd1/unit/a.service",
+			JobPath:     "/",
+		}, got[0])
+		require.Equal(t, "failed", got[1].ActiveState)
+	})
+
+	t.Run("Stops on error", func(t *testing.T) {
+		stop := errors.New("stop")
+		n, err := eachUnitStatus(body(), func(dbus.UnitStatus) error { return stop })
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 1, n)
+	})
+
+	t.Run("Unexpected reply", func(t *testing.T) {
+		_, err := eachUnitStatus([]any{"a.service"}, func(dbus.UnitStatus) error { return nil })
+		require.Error(t, err)
+		_, err = eachUnitStatus([]any{[][]any{{"a.service"}}}, func(dbus.UnitStatus) error { return nil })
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+package systemdmanager
+
+import (
+	"context"
+	"sync"
+)
+
+// unitLocks serializes operations per unit, while operations on different
+// units proceed in parallel.
+type unitLocks struct {
+	mutex sync.Mutex
+	locks map[string]*unitLock
+}
+
+// unitLock is a lock on a single unit. It is a channel so that waiting for it
+// can be cancelled.
+type unitLock struct {
+	ch chan struct{}
+	// refs counts holders and waiters, so the lock is dropped once unused.
+	refs int
+}
+
+// lock blocks until the named unit is locked or ctx is done. On success, the
+// returned function must be called to unlock the unit.
+func (l *unitLocks) lock(ctx context.Context, unit string) (func(), error) {
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*unitLock)
+	}
+	ul, ok := l.locks[unit]
+	if !ok {
+		ul = &unitLock{ch: make(chan struct{}, 1)}
+		l.locks[unit] = ul
+	}
+	ul.refs++
+	l.mutex.Unlock()
+
+	release := func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		ul.refs--
+		if ul.refs == 0 {
+			delete(l.locks, unit)
+		}
+	}
+
+	select {
+	case ul.ch <- struct{}{}:
+		return func() {
+			<-ul.ch
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package systemdmanager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_unitLocks(t *testing.T) {
+	var l unitLocks
+
+	t.Run("Serializes operations on the same unit", func(t *testing.T) {
+		var (
+			wg      sync.WaitGroup
+			mutex   sync.Mutex
+			running int
+		)
+		for range 10 {
+			wg.Go(func() {
+				unlock, err := l.lock(context.Background(), "a.service")
+				require.NoError(t, err)
+				defer unlock()
+
+				mutex.Lock()
+				running++
+				require.Equal(t, 1, running)
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+			})
+		}
+		wg.Wait()
+		require.Empty(t, l.locks)
+	})
+
+	t.Run("Doesn't block other units", func(t *testing.T) {
+		unlockA, err := l.lock(context.Background(), "a.service")
+		require.NoError(t, err)
+		defer unlockA()
+
+		unlockB, err := l.lock(context.Background(), "b.service")
+		require.NoError(t, err)
+		unlockB()
+	})
+
+	t.Run("Waiting is cancellable", func(t *testing.T) {
+		unlock, err := l.lock(context.Background(), "c.service")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = l.lock(ctx, "c.service")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		unlock()
+		require.NotContains(t, l.locks, "c.service")
+	})
+}
@@ -0,0 +1,6 @@
+// Package logind controls login sessions and users through the systemd login
+// manager, systemd-logind.
+package logind
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/logind"
@@ -0,0 +1,26 @@
+package logind
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Lock_Close(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	lock := newLock(w)
+	require.NoError(t, lock.Close())
+	// Closing again is a no-op.
+	require.NoError(t, lock.Close())
+	<-lock.done
+
+	// The reader sees the end of file once the lock is released, which is
+	// how logind notices.
+	n, err := r.Read(make([]byte, 1))
+	require.Zero(t, n)
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package logind
+
+import (
+	"context"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// logindDest is the D-Bus destination of the login manager.
+	logindDest = "org.freedesktop.login1"
+	// logindPath is the D-Bus object path of the login manager.
+	logindPath godbus.ObjectPath = "/org/freedesktop/login1"
+	// managerInterface is the D-Bus interface of the login manager.
+	managerInterface = "org.freedesktop.login1.Manager"
+	// sessionInterface is the D-Bus interface of sessions.
+	sessionInterface = "org.freedesktop.login1.Session"
+)
+
+// Manager controls login sessions, users, and power.
+type Manager interface {
+	CancelScheduledShutdown(ctx context.Context) (bool, error)
+	Inhibit(ctx context.Context, what InhibitWhat, who, why string, mode InhibitMode) (*Lock, error)
+	Kexec(ctx context.Context, opts PowerOptions) error
+	ListSessions(ctx context.Context) ([]Session, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	LockSessions(ctx context.Context) error
+	PowerOff(ctx context.Context, opts PowerOptions) error
+	Reboot(ctx context.Context, opts PowerOptions) error
+	ScheduleShutdown(ctx context.Context, kind ShutdownKind, at time.Time, wallMessage string) error
+	ScheduledShutdown(ctx context.Context) (*ScheduledShutdown, error)
+	SessionProperties(ctx context.Context, id string) (SessionProperties, error)
+	SoftReboot(ctx context.Context, opts PowerOptions) error
+	Suspend(ctx context.Context, opts PowerOptions) error
+	TerminateSession(ctx context.Context, id string) error
+}
+
+// manager manages sessions via a D-Bus connection to logind.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to logind failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to logind over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, logindDest, logindPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up login manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
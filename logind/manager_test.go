@@ -0,0 +1,89 @@
+//go:build linux
+
+package logind
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_Sessions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	sessions, err := mgr.ListSessions(ctx)
+	require.NoError(t, err)
+	for _, s := range sessions {
+		props, err := mgr.SessionProperties(ctx, s.ID)
+		require.NoError(t, err)
+		require.Equal(t, s.ID, props.ID)
+		require.Equal(t, s.UID, props.UID)
+		require.Equal(t, s.User, props.User)
+	}
+
+	users, err := mgr.ListUsers(ctx)
+	require.NoError(t, err)
+	for _, s := range sessions {
+		require.True(t, slices.ContainsFunc(users, func(u User) bool {
+			return u.UID == s.UID && u.Name == s.User
+		}), "user of session %q", s.ID)
+	}
+}
+
+func Test_E2E_Manager_Inhibit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	lock, err := mgr.Inhibit(ctx, InhibitSleep, "systemdmanager", "e2e test", InhibitModeDelay)
+	require.NoError(t, err)
+	require.NoError(t, lock.Close())
+
+	// Locks are released when their context is done.
+	lockCtx, lockCancel := context.WithCancel(ctx)
+	lock, err = mgr.Inhibit(lockCtx, InhibitSleep, "systemdmanager", "e2e test", InhibitModeDelay)
+	require.NoError(t, err)
+	lockCancel()
+	require.Eventually(t, func() bool {
+		select {
+		case <-lock.done:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_E2E_Manager_ScheduleShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// A dry run only notifies users, in case cancelling fails.
+	at := time.Now().Add(time.Hour).Truncate(time.Microsecond).UTC()
+	require.NoError(t, mgr.ScheduleShutdown(ctx, ShutdownDryReboot, at, "systemdmanager e2e test"))
+	scheduled, err := mgr.ScheduledShutdown(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &ScheduledShutdown{Kind: ShutdownDryReboot, At: at}, scheduled)
+
+	cancelled, err := mgr.CancelScheduledShutdown(ctx)
+	require.NoError(t, err)
+	require.True(t, cancelled)
+	scheduled, err = mgr.ScheduledShutdown(ctx)
+	require.NoError(t, err)
+	require.Nil(t, scheduled)
+}
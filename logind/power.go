@@ -0,0 +1,205 @@
+package logind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Flags of the *WithFlags power methods.
+const (
+	// flagCheckInhibitors makes root respect inhibitors, like other users.
+	flagCheckInhibitors uint64 = 1 << 0
+	// flagKexec reboots into the kernel loaded with kexec.
+	flagKexec uint64 = 1 << 1
+	// flagSoftReboot only restarts userspace.
+	flagSoftReboot uint64 = 1 << 2
+)
+
+// PowerOptions configures power operations.
+type PowerOptions struct {
+	// Interactive allows polkit to ask the user for authentication.
+	Interactive bool
+	// CheckInhibitors makes the operation fail when inhibited, even when
+	// running as root, which otherwise overrides inhibitors.
+	CheckInhibitors bool
+	// RebootParameter is passed to the reboot system call, e.g. to select
+	// a recovery mode on devices supporting it. Only used by Reboot.
+	RebootParameter string
+}
+
+// flags returns the flags of the *WithFlags power methods.
+func (o PowerOptions) flags() uint64 {
+	var flags uint64
+	if o.CheckInhibitors {
+		flags |= flagCheckInhibitors
+	}
+
+	return flags
+}
+
+// Reboot reboots the system.
+func (m *manager) Reboot(ctx context.Context, opts PowerOptions) error {
+	return m.power(ctx, "Reboot", "Reboot", opts, 0)
+}
+
+// SoftReboot restarts userspace only, keeping the kernel running, e.g. after
+// updating the root file system image.
+func (m *manager) SoftReboot(ctx context.Context, opts PowerOptions) error {
+	return m.power(ctx, "SoftReboot", "Reboot", opts, flagSoftReboot)
+}
+
+// Kexec reboots into the kernel loaded with kexec, skipping the firmware.
+// The system reboots normally if no kernel is loaded.
+func (m *manager) Kexec(ctx context.Context, opts PowerOptions) error {
+	return m.power(ctx, "Kexec", "Reboot", opts, flagKexec)
+}
+
+// PowerOff powers off the system.
+func (m *manager) PowerOff(ctx context.Context, opts PowerOptions) error {
+	return m.power(ctx, "PowerOff", "PowerOff", opts, 0)
+}
+
+// Suspend suspends the system.
+func (m *manager) Suspend(ctx context.Context, opts PowerOptions) error {
+	return m.power(ctx, "Suspend", "Suspend", opts, 0)
+}
+
+// power calls one of the power methods of logind, with extra flags. The
+// *WithFlags variant is only used when flags are needed, for compatibility
+// with older logind versions, and since it can't be interactive.
+func (m *manager) power(parentCtx context.Context, operation, method string, opts PowerOptions, flags uint64) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, operation)
+	defer span.End()
+
+	var err error
+	if operation == "Reboot" && opts.RebootParameter != "" {
+		err = m.conn.Call(ctx, "SetRebootParameter", []any{opts.RebootParameter})
+	}
+	if err == nil {
+		if flags |= opts.flags(); flags != 0 {
+			err = m.conn.Call(ctx, method+"WithFlags", []any{flags})
+		} else {
+			err = m.conn.Call(ctx, method, []any{opts.Interactive})
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to %s: %w", operation, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, operation+" requested")
+
+	return nil
+}
+
+// ShutdownKind is the kind of a scheduled shutdown.
+type ShutdownKind string
+
+// Kinds of scheduled shutdowns.
+const (
+	ShutdownPowerOff   ShutdownKind = "poweroff"
+	ShutdownReboot     ShutdownKind = "reboot"
+	ShutdownHalt       ShutdownKind = "halt"
+	ShutdownKexec      ShutdownKind = "kexec"
+	ShutdownSoftReboot ShutdownKind = "soft-reboot"
+	// The dry-run kinds only notify users, without shutting down.
+	ShutdownDryPowerOff ShutdownKind = "dry-poweroff"
+	ShutdownDryReboot   ShutdownKind = "dry-reboot"
+	ShutdownDryHalt     ShutdownKind = "dry-halt"
+)
+
+// ScheduledShutdown is a shutdown scheduled with ScheduleShutdown.
+type ScheduledShutdown struct {
+	Kind ShutdownKind `json:"kind" yaml:"kind"`
+	At   time.Time    `json:"at" yaml:"at"`
+}
+
+// ScheduleShutdown schedules a shutdown of the given kind at the given time,
+// replacing any previously scheduled. A non-empty wallMessage is broadcast
+// to logged in users ahead of the shutdown.
+func (m *manager) ScheduleShutdown(parentCtx context.Context, kind ShutdownKind, at time.Time, wallMessage string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ScheduleShutdown")
+	span.SetAttributes(otelattr.String("kind", string(kind)), otelattr.String("at", at.Format(time.RFC3339)))
+	defer span.End()
+
+	err := m.conn.Call(ctx, "SetWallMessage", []any{wallMessage, wallMessage != ""})
+	if err == nil {
+		err = m.conn.Call(ctx, "ScheduleShutdown", []any{string(kind), uint64(at.UnixMicro())})
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to schedule %s at %s: %w", kind, at.Format(time.RFC3339), err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("scheduled %s", kind))
+
+	return nil
+}
+
+// CancelScheduledShutdown cancels the scheduled shutdown, and reports whether
+// there was one.
+func (m *manager) CancelScheduledShutdown(parentCtx context.Context) (bool, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "CancelScheduledShutdown")
+	defer span.End()
+
+	var cancelled bool
+	if err := m.conn.Call(ctx, "CancelScheduledShutdown", nil, &cancelled); err != nil {
+		err = fmt.Errorf("failed to cancel scheduled shutdown: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return false, err
+	}
+	span.SetAttributes(otelattr.Bool("cancelled", cancelled))
+	span.SetStatus(otelcodes.Ok, "cancelled scheduled shutdown")
+
+	return cancelled, nil
+}
+
+// ScheduledShutdown returns the scheduled shutdown, or nil if there's none.
+func (m *manager) ScheduledShutdown(parentCtx context.Context) (*ScheduledShutdown, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ScheduledShutdown")
+	defer span.End()
+
+	variant, err := m.conn.Property(ctx, m.conn.Path(), "", "ScheduledShutdown")
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve scheduled shutdown: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved scheduled shutdown")
+
+	return decodeScheduledShutdown(variant), nil
+}
+
+// decodeScheduledShutdown converts the ScheduledShutdown property, a
+// (kind, usec) struct which is empty when no shutdown is scheduled.
+func decodeScheduledShutdown(variant godbus.Variant) *ScheduledShutdown {
+	v, ok := variant.Value().([]any)
+	if !ok || len(v) != 2 {
+		return nil
+	}
+	kind, _ := v[0].(string)
+	usec, _ := v[1].(uint64)
+	if kind == "" || usec == 0 {
+		return nil
+	}
+
+	return &ScheduledShutdown{Kind: ShutdownKind(kind), At: time.UnixMicro(int64(usec)).UTC()}
+}
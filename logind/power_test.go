@@ -0,0 +1,24 @@
+package logind
+
+import (
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_PowerOptions_flags(t *testing.T) {
+	require.Zero(t, PowerOptions{Interactive: true}.flags())
+	require.Equal(t, flagCheckInhibitors, PowerOptions{CheckInhibitors: true}.flags())
+}
+
+func Test_Unit_decodeScheduledShutdown(t *testing.T) {
+	at := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	require.Equal(t, &ScheduledShutdown{Kind: ShutdownReboot, At: at},
+		decodeScheduledShutdown(godbus.MakeVariant([]any{"reboot", uint64(at.UnixMicro())})))
+
+	// Nothing is scheduled.
+	require.Nil(t, decodeScheduledShutdown(godbus.MakeVariant([]any{"", uint64(0)})))
+	require.Nil(t, decodeScheduledShutdown(godbus.Variant{}))
+}
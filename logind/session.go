@@ -0,0 +1,196 @@
+package logind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Session is a login session, as listed by ListSessions.
+type Session struct {
+	ID   string            `json:"id" yaml:"id"`
+	UID  uint32            `json:"uid" yaml:"uid"`
+	User string            `json:"user" yaml:"user"`
+	Seat string            `json:"seat,omitempty" yaml:"seat,omitempty"`
+	Path godbus.ObjectPath `json:"path" yaml:"path"`
+}
+
+// User is a logged in user, as listed by ListUsers.
+type User struct {
+	UID  uint32            `json:"uid" yaml:"uid"`
+	Name string            `json:"name" yaml:"name"`
+	Path godbus.ObjectPath `json:"path" yaml:"path"`
+}
+
+// SessionProperties are the details of a session.
+type SessionProperties struct {
+	ID      string `json:"id" yaml:"id"`
+	UID     uint32 `json:"uid" yaml:"uid"`
+	User    string `json:"user" yaml:"user"`
+	Seat    string `json:"seat,omitempty" yaml:"seat,omitempty"`
+	TTY     string `json:"tty,omitempty" yaml:"tty,omitempty"`
+	Display string `json:"display,omitempty" yaml:"display,omitempty"`
+	Remote  bool   `json:"remote" yaml:"remote"`
+	// RemoteHost is the host the session comes from, for remote sessions.
+	RemoteHost string `json:"remote_host,omitempty" yaml:"remote_host,omitempty"`
+	// Service is the PAM service which registered the session, e.g. "sshd".
+	Service string `json:"service" yaml:"service"`
+	// Type is e.g. "tty", "x11", "wayland", or "unspecified".
+	Type string `json:"type" yaml:"type"`
+	// Class is e.g. "user", "greeter", or "background".
+	Class string `json:"class" yaml:"class"`
+	// State is "online", "active", or "closing".
+	State  string `json:"state" yaml:"state"`
+	Active bool   `json:"active" yaml:"active"`
+	Idle   bool   `json:"idle" yaml:"idle"`
+	// Leader is the PID of the process which registered the session.
+	Leader    uint32    `json:"leader" yaml:"leader"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// ListSessions returns the current login sessions.
+func (m *manager) ListSessions(parentCtx context.Context) ([]Session, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListSessions")
+	defer span.End()
+
+	var sessions []Session
+	if err := m.conn.Call(ctx, "ListSessions", nil, &sessions); err != nil {
+		err = fmt.Errorf("failed to list sessions: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "listed sessions")
+
+	return sessions, nil
+}
+
+// ListUsers returns the users currently logged in.
+func (m *manager) ListUsers(parentCtx context.Context) ([]User, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListUsers")
+	defer span.End()
+
+	var users []User
+	if err := m.conn.Call(ctx, "ListUsers", nil, &users); err != nil {
+		err = fmt.Errorf("failed to list users: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "listed users")
+
+	return users, nil
+}
+
+// SessionProperties returns the details of the session with the given ID.
+func (m *manager) SessionProperties(parentCtx context.Context, id string) (SessionProperties, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SessionProperties")
+	span.SetAttributes(otelattr.String("session", id))
+	defer span.End()
+
+	props, err := m.sessionProperties(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve properties of session %q: %w", id, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return SessionProperties{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved session properties")
+
+	return props, nil
+}
+
+// sessionProperties implements SessionProperties.
+func (m *manager) sessionProperties(ctx context.Context, id string) (SessionProperties, error) {
+	var path godbus.ObjectPath
+	if err := m.conn.Call(ctx, "GetSession", []any{id}, &path); err != nil {
+		return SessionProperties{}, err
+	}
+
+	props, err := m.conn.Properties(ctx, path, sessionInterface)
+	if err != nil {
+		return SessionProperties{}, err
+	}
+
+	return decodeSessionProperties(props), nil
+}
+
+// decodeSessionProperties converts the D-Bus properties of a session.
+// Missing or mistyped properties are left zero.
+func decodeSessionProperties(props map[string]godbus.Variant) SessionProperties {
+	var s SessionProperties
+	s.ID, _ = props["Id"].Value().(string)
+	s.User, _ = props["Name"].Value().(string)
+	// User and Seat are (uid, path) and (id, path) structs.
+	if v, ok := props["User"].Value().([]any); ok && len(v) > 0 {
+		s.UID, _ = v[0].(uint32)
+	}
+	if v, ok := props["Seat"].Value().([]any); ok && len(v) > 0 {
+		s.Seat, _ = v[0].(string)
+	}
+	s.TTY, _ = props["TTY"].Value().(string)
+	s.Display, _ = props["Display"].Value().(string)
+	s.Remote, _ = props["Remote"].Value().(bool)
+	s.RemoteHost, _ = props["RemoteHost"].Value().(string)
+	s.Service, _ = props["Service"].Value().(string)
+	s.Type, _ = props["Type"].Value().(string)
+	s.Class, _ = props["Class"].Value().(string)
+	s.State, _ = props["State"].Value().(string)
+	s.Active, _ = props["Active"].Value().(bool)
+	s.Idle, _ = props["IdleHint"].Value().(bool)
+	s.Leader, _ = props["Leader"].Value().(uint32)
+	if usec, _ := props["Timestamp"].Value().(uint64); usec != 0 {
+		s.CreatedAt = time.UnixMicro(int64(usec)).UTC()
+	}
+
+	return s
+}
+
+// TerminateSession ends the session with the given ID, killing its
+// processes.
+func (m *manager) TerminateSession(parentCtx context.Context, id string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "TerminateSession")
+	span.SetAttributes(otelattr.String("session", id))
+	defer span.End()
+
+	if err := m.conn.Call(ctx, "TerminateSession", []any{id}); err != nil {
+		err = fmt.Errorf("failed to terminate session %q: %w", id, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("terminated session %q", id))
+
+	return nil
+}
+
+// LockSessions asks all sessions to lock their screens.
+func (m *manager) LockSessions(parentCtx context.Context) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "LockSessions")
+	defer span.End()
+
+	if err := m.conn.Call(ctx, "LockSessions", nil); err != nil {
+		err = fmt.Errorf("failed to lock sessions: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "locked sessions")
+
+	return nil
+}
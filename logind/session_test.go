@@ -0,0 +1,47 @@
+package logind
+
+import (
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeSessionProperties(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	props := map[string]godbus.Variant{
+		"Id":         godbus.MakeVariant("3"),
+		"Name":       godbus.MakeVariant("alice"),
+		"User":       godbus.MakeVariant([]any{uint32(1000), godbus.ObjectPath("/org/freedesktop/login1/user/_1000")}),
+		"Seat":       godbus.MakeVariant([]any{"seat0", godbus.ObjectPath("/org/freedesktop/login1/seat/seat0")}),
+		"TTY":        godbus.MakeVariant("tty2"),
+		"Remote":     godbus.MakeVariant(false),
+		"Service":    godbus.MakeVariant("login"),
+		"Type":       godbus.MakeVariant("tty"),
+		"Class":      godbus.MakeVariant("user"),
+		"State":      godbus.MakeVariant("active"),
+		"Active":     godbus.MakeVariant(true),
+		"IdleHint":   godbus.MakeVariant(false),
+		"Leader":     godbus.MakeVariant(uint32(1234)),
+		"Timestamp":  godbus.MakeVariant(uint64(created.UnixMicro())),
+		"RemoteHost": godbus.MakeVariant(""),
+	}
+	require.Equal(t, SessionProperties{
+		ID:        "3",
+		UID:       1000,
+		User:      "alice",
+		Seat:      "seat0",
+		TTY:       "tty2",
+		Service:   "login",
+		Type:      "tty",
+		Class:     "user",
+		State:     "active",
+		Active:    true,
+		Leader:    1234,
+		CreatedAt: created,
+	}, decodeSessionProperties(props))
+
+	// Missing properties are left zero.
+	require.Equal(t, SessionProperties{}, decodeSessionProperties(nil))
+}
@@ -0,0 +1,6 @@
+// Package machined lists, inspects, and terminates the virtual machines and
+// containers registered with systemd-machined.
+package machined
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/machined"
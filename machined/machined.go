@@ -0,0 +1,238 @@
+package machined
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"syscall"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// machinedDest is the D-Bus destination of machined.
+	machinedDest = "org.freedesktop.machine1"
+	// machinedPath is the D-Bus object path of machined.
+	machinedPath godbus.ObjectPath = "/org/freedesktop/machine1"
+	// managerInterface is the D-Bus interface of machined.
+	managerInterface = "org.freedesktop.machine1.Manager"
+	// machineInterface is the D-Bus interface of machines.
+	machineInterface = "org.freedesktop.machine1.Machine"
+)
+
+// Machine is a registered machine, as listed by ListMachines.
+type Machine struct {
+	Name string `json:"name" yaml:"name"`
+	// Class is "container" or "vm".
+	Class string `json:"class" yaml:"class"`
+	// Service is the software which registered the machine, e.g.
+	// "systemd-nspawn".
+	Service string            `json:"service" yaml:"service"`
+	Path    godbus.ObjectPath `json:"path" yaml:"path"`
+}
+
+// MachineStatus are the details of a machine.
+type MachineStatus struct {
+	Name string `json:"name" yaml:"name"`
+	// ID is the machine ID, in hexadecimal, if known.
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Class   string `json:"class" yaml:"class"`
+	Service string `json:"service" yaml:"service"`
+	// Unit is the scope or service the machine runs in.
+	Unit string `json:"unit" yaml:"unit"`
+	// Leader is the PID of the machine's init process or hypervisor.
+	Leader        uint32 `json:"leader" yaml:"leader"`
+	RootDirectory string `json:"root_directory,omitempty" yaml:"root_directory,omitempty"`
+	// State is "opening", "running", or "closing".
+	State     string    `json:"state" yaml:"state"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	// Addresses are the IP addresses of the machine, only known for
+	// containers with their own network namespace.
+	Addresses []netip.Addr `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+}
+
+// Manager lists, inspects, and terminates machines.
+type Manager interface {
+	ListMachines(ctx context.Context) ([]Machine, error)
+	MachineStatus(ctx context.Context, name string) (MachineStatus, error)
+	TerminateMachine(ctx context.Context, name string) error
+}
+
+// manager manages machines via a D-Bus connection to machined.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to machined failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to machined over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, machinedDest, machinedPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up machine manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// ListMachines returns the registered machines.
+func (m *manager) ListMachines(parentCtx context.Context) ([]Machine, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListMachines")
+	defer span.End()
+
+	var machines []Machine
+	if err := m.conn.Call(ctx, "ListMachines", nil, &machines); err != nil {
+		err = fmt.Errorf("failed to list machines: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "listed machines")
+
+	return machines, nil
+}
+
+// MachineStatus returns the details of the named machine.
+func (m *manager) MachineStatus(parentCtx context.Context, machine string) (MachineStatus, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "MachineStatus")
+	span.SetAttributes(otelattr.String("machine", machine))
+	defer span.End()
+
+	status, err := m.machineStatus(ctx, machine)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve status of machine %q: %w", machine, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return MachineStatus{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved machine status")
+
+	return status, nil
+}
+
+// machineStatus implements MachineStatus.
+func (m *manager) machineStatus(ctx context.Context, machine string) (MachineStatus, error) {
+	var path godbus.ObjectPath
+	if err := m.conn.Call(ctx, "GetMachine", []any{machine}, &path); err != nil {
+		return MachineStatus{}, err
+	}
+	props, err := m.conn.Properties(ctx, path, machineInterface)
+	if err != nil {
+		return MachineStatus{}, err
+	}
+	status := decodeMachineStatus(props)
+
+	// Addresses are only available for containers.
+	if status.Class == "container" {
+		var addrs []machineAddress
+		if err := m.conn.Call(ctx, "GetMachineAddresses", []any{machine}, &addrs); err != nil {
+			return MachineStatus{}, err
+		}
+		status.Addresses = decodeAddresses(addrs)
+	}
+
+	return status, nil
+}
+
+// decodeMachineStatus converts the D-Bus properties of a machine. Missing or
+// mistyped properties are left zero.
+func decodeMachineStatus(props map[string]godbus.Variant) MachineStatus {
+	var s MachineStatus
+	s.Name, _ = props["Name"].Value().(string)
+	if id, _ := props["Id"].Value().([]byte); len(id) > 0 && !allZero(id) {
+		s.ID = hex.EncodeToString(id)
+	}
+	s.Class, _ = props["Class"].Value().(string)
+	s.Service, _ = props["Service"].Value().(string)
+	s.Unit, _ = props["Unit"].Value().(string)
+	s.Leader, _ = props["Leader"].Value().(uint32)
+	s.RootDirectory, _ = props["RootDirectory"].Value().(string)
+	s.State, _ = props["State"].Value().(string)
+	if usec, _ := props["Timestamp"].Value().(uint64); usec != 0 {
+		s.CreatedAt = time.UnixMicro(int64(usec)).UTC()
+	}
+
+	return s
+}
+
+// allZero reports whether b only holds zeros, which is how an unknown
+// machine ID is reported.
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// machineAddress is an address as returned by GetMachineAddresses.
+type machineAddress struct {
+	Family  int32
+	Address []byte
+}
+
+// decodeAddresses converts the addresses of a machine, skipping those which
+// are malformed.
+func decodeAddresses(addrs []machineAddress) []netip.Addr {
+	var ips []netip.Addr
+	for _, a := range addrs {
+		ip, ok := netip.AddrFromSlice(a.Address)
+		if !ok || (a.Family == syscall.AF_INET) != ip.Is4() {
+			continue
+		}
+		ips = append(ips, ip)
+	}
+
+	return ips
+}
+
+// TerminateMachine kills all processes of the named machine and unregisters
+// it.
+func (m *manager) TerminateMachine(parentCtx context.Context, machine string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "TerminateMachine")
+	span.SetAttributes(otelattr.String("machine", machine))
+	defer span.End()
+
+	if err := m.conn.Call(ctx, "TerminateMachine", []any{machine}); err != nil {
+		err = fmt.Errorf("failed to terminate machine %q: %w", machine, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("terminated machine %q", machine))
+
+	return nil
+}
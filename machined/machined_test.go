@@ -0,0 +1,55 @@
+package machined
+
+import (
+	"net/netip"
+	"syscall"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeMachineStatus(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	props := map[string]godbus.Variant{
+		"Name":          godbus.MakeVariant("web"),
+		"Id":            godbus.MakeVariant([]byte{0xde, 0xad, 0xbe, 0xef, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}),
+		"Class":         godbus.MakeVariant("container"),
+		"Service":       godbus.MakeVariant("systemd-nspawn"),
+		"Unit":          godbus.MakeVariant("systemd-nspawn@web.service"),
+		"Leader":        godbus.MakeVariant(uint32(4242)),
+		"RootDirectory": godbus.MakeVariant("/var/lib/machines/web"),
+		"State":         godbus.MakeVariant("running"),
+		"Timestamp":     godbus.MakeVariant(uint64(created.UnixMicro())),
+	}
+	require.Equal(t, MachineStatus{
+		Name:          "web",
+		ID:            "deadbeef000000000000000000000001",
+		Class:         "container",
+		Service:       "systemd-nspawn",
+		Unit:          "systemd-nspawn@web.service",
+		Leader:        4242,
+		RootDirectory: "/var/lib/machines/web",
+		State:         "running",
+		CreatedAt:     created,
+	}, decodeMachineStatus(props))
+
+	// An all-zero ID is unknown.
+	props = map[string]godbus.Variant{"Id": godbus.MakeVariant(make([]byte, 16))}
+	require.Empty(t, decodeMachineStatus(props).ID)
+}
+
+func Test_Unit_decodeAddresses(t *testing.T) {
+	addrs := []machineAddress{
+		{Family: syscall.AF_INET, Address: []byte{10, 0, 0, 2}},
+		{Family: syscall.AF_INET6, Address: netip.MustParseAddr("fe80::1").AsSlice()},
+		// Malformed addresses are skipped.
+		{Family: syscall.AF_INET, Address: []byte{10, 0}},
+		{Family: syscall.AF_INET6, Address: []byte{10, 0, 0, 3}},
+	}
+	require.Equal(t, []netip.Addr{
+		netip.MustParseAddr("10.0.0.2"),
+		netip.MustParseAddr("fe80::1"),
+	}, decodeAddresses(addrs))
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package machined
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_Machines(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	machines, err := mgr.ListMachines(ctx)
+	require.NoError(t, err)
+	for _, machine := range machines {
+		status, err := mgr.MachineStatus(ctx, machine.Name)
+		require.NoError(t, err)
+		require.Equal(t, machine.Name, status.Name)
+		require.Equal(t, machine.Class, status.Class)
+	}
+
+	_, err = mgr.MachineStatus(ctx, "systemdmanager-e2e-missing")
+	require.Error(t, err)
+}
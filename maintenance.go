@@ -0,0 +1,151 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrOutsideMaintenanceWindow means an operation was rejected for running
+// outside the configured maintenance windows.
+var ErrOutsideMaintenanceWindow = errors.New("outside maintenance window")
+
+// MaintenanceWindow is a recurring period of time during which mutating
+// operations are allowed.
+type MaintenanceWindow struct {
+	// Days are the days the window opens on. Empty means every day.
+	Days []time.Weekday
+	// Start and End are the times of day the window opens and closes at, as
+	// offsets from midnight, e.g. 2*time.Hour for 02:00. A window ending
+	// before it starts closes on the next day.
+	Start time.Duration
+	End   time.Duration
+	// Location is the time zone of the window. Defaults to UTC.
+	Location *time.Location
+}
+
+// opens returns when the window opens on the day of t.
+func (w MaintenanceWindow) opens(t time.Time) time.Time {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(w.Start)
+}
+
+// length returns how long the window is open.
+func (w MaintenanceWindow) length() time.Duration {
+	if w.End <= w.Start {
+		return w.End + 24*time.Hour - w.Start
+	}
+
+	return w.End - w.Start
+}
+
+// opensOn reports whether the window opens on the day of t.
+func (w MaintenanceWindow) opensOn(t time.Time) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, t.Weekday())
+}
+
+// Contains reports whether the window is open at t.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	// A window open at t opened on the same day, or the day before.
+	for _, days := range []int{0, -1} {
+		opens := w.opens(w.opens(t).AddDate(0, 0, days))
+		if w.opensOn(opens) && !t.Before(opens) && t.Before(opens.Add(w.length())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Next returns when the window next opens at or after t, or the zero time if
+// it never does.
+func (w MaintenanceWindow) Next(t time.Time) time.Time {
+	for days := range 8 {
+		opens := w.opens(w.opens(t).AddDate(0, 0, days))
+		if w.opensOn(opens) && !opens.Before(t) {
+			return opens
+		}
+	}
+
+	return time.Time{}
+}
+
+// MaintenancePolicy restricts mutating operations to maintenance windows.
+type MaintenancePolicy struct {
+	// Windows are the periods during which operations are allowed. Empty
+	// means operations are always allowed.
+	Windows []MaintenanceWindow
+	// Wait queues operations outside the windows until the next one opens,
+	// instead of failing them with ErrOutsideMaintenanceWindow.
+	Wait bool
+}
+
+// WithMaintenancePolicy restricts mutating operations to maintenance
+// windows, which prevents automation from restarting units during business
+// hours.
+func WithMaintenancePolicy(policy MaintenancePolicy) Option {
+	return func(o *options) {
+		o.maintenancePolicy = policy
+	}
+}
+
+// open reports whether any window is open at t, and otherwise when the
+// next one opens, which is the zero time if none does.
+func (p MaintenancePolicy) open(t time.Time) (bool, time.Time) {
+	if len(p.Windows) == 0 {
+		return true, time.Time{}
+	}
+	var next time.Time
+	for _, w := range p.Windows {
+		if w.Contains(t) {
+			return true, time.Time{}
+		}
+		if n := w.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+
+	return false, next
+}
+
+// admit allows an operation on the named unit to run now, if within a
+// maintenance window. Otherwise, it fails or, if the policy says so, waits
+// for the next window to open.
+func (p MaintenancePolicy) admit(ctx context.Context, unit string, now func() time.Time) error {
+	open, next := p.open(now())
+	if open {
+		return nil
+	}
+	if !p.Wait || next.IsZero() {
+		return fmt.Errorf("unit %q: %w", unit, ErrOutsideMaintenanceWindow)
+	}
+
+	timer := time.NewTimer(next.Sub(now()))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("unit %q: %w, waiting until %s: %w", unit, ErrOutsideMaintenanceWindow, next.Format(time.RFC3339), context.Cause(ctx))
+	case <-timer.C:
+		return nil
+	}
+}
+
+// admitChange admits a change to the named units made outside of jobs, such
+// as writing their unit files, within maintenance windows and if none is
+// claimed by another owner.
+func (m *manager) admitChange(ctx context.Context, units ...string) error {
+	for _, unit := range units {
+		if err := m.options.maintenancePolicy.admit(ctx, unit, time.Now); err != nil {
+			return err
+		}
+	}
+
+	return m.checkClaims(units...)
+}
@@ -0,0 +1,93 @@
+package systemdmanager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_MaintenanceWindow(t *testing.T) {
+	// Thursday, January 2nd 2025.
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Same day", func(t *testing.T) {
+		w := MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour}
+		require.False(t, w.Contains(day.Add(time.Hour)))
+		require.True(t, w.Contains(day.Add(2*time.Hour)))
+		require.True(t, w.Contains(day.Add(3*time.Hour)))
+		require.False(t, w.Contains(day.Add(4*time.Hour)))
+		require.Equal(t, day.Add(2*time.Hour), w.Next(day.Add(time.Hour)))
+		require.Equal(t, day.Add(26*time.Hour), w.Next(day.Add(3*time.Hour)))
+	})
+
+	t.Run("Across midnight", func(t *testing.T) {
+		w := MaintenanceWindow{Days: []time.Weekday{time.Thursday}, Start: 22 * time.Hour, End: 2 * time.Hour}
+		require.True(t, w.Contains(day.Add(23*time.Hour)))
+		require.True(t, w.Contains(day.Add(25*time.Hour)))
+		require.False(t, w.Contains(day.Add(27*time.Hour)))
+		// Opened on Wednesday, which isn't allowed.
+		require.False(t, w.Contains(day.Add(time.Hour)))
+		require.Equal(t, day.Add(22*time.Hour), w.Next(day.Add(time.Hour)))
+		require.Equal(t, day.AddDate(0, 0, 7).Add(22*time.Hour), w.Next(day.Add(23*time.Hour)))
+	})
+
+	t.Run("Time zone", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		w := MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour, Location: loc}
+		require.True(t, w.Contains(day.Add(time.Hour)))
+		require.False(t, w.Contains(day.Add(3*time.Hour)))
+	})
+}
+
+func Test_Unit_MaintenancePolicy_admit(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	policy := MaintenancePolicy{Windows: []MaintenanceWindow{
+		{Start: 2 * time.Hour, End: 4 * time.Hour},
+		{Start: 11 * time.Hour, End: 11*time.Hour + 30*time.Minute},
+	}}
+
+	require.NoError(t, MaintenancePolicy{}.admit(t.Context(), "a.service", clock))
+	require.ErrorIs(t, policy.admit(t.Context(), "a.service", clock), ErrOutsideMaintenanceWindow)
+
+	// Waiting is bound by ctx.
+	policy.Wait = true
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancel()
+	err := policy.admit(ctx, "a.service", clock)
+	require.ErrorIs(t, err, ErrOutsideMaintenanceWindow)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "2025-01-03T02:00:00Z")
+
+	// Operations proceed once the window opens.
+	now = time.Date(2025, 1, 3, 1, 59, 59, 999_000_000, time.UTC)
+	require.NoError(t, policy.admit(t.Context(), "a.service", clock))
+}
+
+func Test_Unit_MaintenancePolicy_UnitFiles(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	// A window open all day, the day after tomorrow.
+	closed := MaintenanceWindow{Days: []time.Weekday{(time.Now().UTC().Weekday() + 2) % 7}}
+	m := &manager{options: options{unitDirectory: dir, maintenancePolicy: MaintenancePolicy{Windows: []MaintenanceWindow{closed}}}}
+	original := []byte("[Service]\nExecStart=/bin/true\n")
+	require.NoError(t, os.WriteFile(m.unitPath("a.service"), original, 0o644))
+
+	// Files are left untouched outside maintenance windows.
+	fsys := fstest.MapFS{"a.service": {Data: []byte("[Service]\nExecStart=/bin/false\n")}}
+	require.ErrorIs(t, m.InstallFromFS(ctx, fsys), ErrOutsideMaintenanceWindow)
+	content, err := os.ReadFile(m.unitPath("a.service"))
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+
+	require.ErrorIs(t, m.SetUnitEnvironment(ctx, "a.service", map[string]string{"FOO": "bar"}, false), ErrOutsideMaintenanceWindow)
+	require.NoFileExists(t, m.dropInPath("a.service", environmentDropIn))
+	require.ErrorIs(t, m.SetFailureHandler(ctx, "a.service", "alert.service"), ErrOutsideMaintenanceWindow)
+	require.NoFileExists(t, m.dropInPath("a.service", failureHandlerDropIn))
+	_, err = m.restoreUnitFileState(ctx, "a.service", UnitFileDisabled)
+	require.ErrorIs(t, err, ErrOutsideMaintenanceWindow)
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"io/fs"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 	"go.opentelemetry.io/otel"
 	otelattr "go.opentelemetry.io/otel/attribute"
 	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -19,253 +21,435 @@ var (
 	ErrDisconnected = errors.New("systemd D-Bus API client is disconnected")
 
 	ErrFailedStart = errors.New("failed to start unit")
+
+	// ErrTimeout means a unit operation didn't complete in time.
+	ErrTimeout = errors.New("unit operation timed out")
+
+	// ErrUnitNotFound means a unit isn't loaded and has no unit file.
+	ErrUnitNotFound = errors.New("unit not found")
 )
 
 const done string = "done"
 
-// Manager controls the lifecycle of a single systemd unit.
+// cancelJobTimeout bounds cancelling the job of an operation that timed out.
+const cancelJobTimeout = 5 * time.Second
+
+// Manager controls the lifecycle of systemd units.
+//
+// A Manager is safe for concurrent use by multiple goroutines. Mutating
+// operations on the same unit, such as Start, Stop, and Restart, are
+// serialized, while operations on different units run in parallel. Watches
+// and reads run concurrently with all other operations.
 type Manager interface {
-	Restart(ctx context.Context, unit string) error
-	Start(ctx context.Context, unit string) error
-	Stop(ctx context.Context, unit string) error
+	ActivateSwap(ctx context.Context, what string, opts ...CallOption) error
+	AutomountStatus(ctx context.Context, where string) (UnitAutomount, error)
+	BootTimes(ctx context.Context) (BootTimes, error)
+	CanManage(ctx context.Context, unit string, action Action) (bool, error)
+	CanaryRestart(ctx context.Context, units []string, canaryCount int, observationWindow time.Duration, opts CanaryOptions) (*CanaryResult, error)
+	Claim(ctx context.Context, unit, owner string) error
+	ClaimedBy(ctx context.Context, unit string) (string, error)
+	Conditions(ctx context.Context, unit string) (UnitConditions, error)
+	ControlGroupPath(ctx context.Context, unit string) (string, error)
+	Coredumps(ctx context.Context, unit string, since time.Time) ([]CoredumpInfo, error)
+	DeactivateSwap(ctx context.Context, what string, opts ...CallOption) error
+	DeviceStatus(ctx context.Context, device string) (UnitDevice, error)
+	DiffUnit(ctx context.Context, unit string, desired []byte) (Diff, bool, error)
+	DumpFileDescriptorStore(ctx context.Context, unit string) ([]StoredFileDescriptor, error)
+	EachUnit(ctx context.Context, filter UnitFilter, fn func(dbus.UnitStatus) error) error
+	EnablementState(ctx context.Context, unit string) (UnitFileState, error)
+	Exists(ctx context.Context, unit string) (bool, error)
+	FileDescriptorStore(ctx context.Context, unit string) (UnitFileDescriptorStore, error)
+	Healthy() bool
+	History(unit string) []Transition
+	InstallFromFS(ctx context.Context, fsys fs.FS, names ...string) error
+	IsActive(ctx context.Context, unit string) (bool, error)
+	IsEnabled(ctx context.Context, unit string) (bool, error)
+	IsFailed(ctx context.Context, unit string) (bool, error)
+	LastOOM(ctx context.Context, unit string) (*OOMKill, error)
+	ListInstances(ctx context.Context, template string) ([]string, error)
+	ListSwaps(ctx context.Context) ([]UnitSwap, error)
+	MountStatus(ctx context.Context, where string) (UnitMount, error)
+	MountTransient(ctx context.Context, what, where, fstype, options string, opts ...CallOption) (string, error)
+	OnAfterStart(hook Hook)
+	OnAfterStop(hook Hook)
+	OnBeforeStart(hook Hook)
+	OnBeforeStop(hook Hook)
+	OnFailure(hook FailureHook)
+	PathTriggers(ctx context.Context, unit string) (UnitPathTriggers, error)
+	Ping(ctx context.Context) error
+	Raw() *dbus.Conn
+	Release(ctx context.Context, unit, owner string) error
+	Reload(ctx context.Context, unit string, opts ...CallOption) error
+	ReloadOnChange(ctx context.Context, unit string, paths []string, opts ReloadOnChangeOptions) error
+	ReloadOrRestart(ctx context.Context, unit string, opts ...CallOption) error
+	Restart(ctx context.Context, unit string, opts ...CallOption) error
+	RestartAt(ctx context.Context, unit string, t time.Time, notify func(delay time.Duration), opts ...CallOption) error
+	Restore(ctx context.Context, snap StateSnapshot) error
+	RestartGroup(ctx context.Context, units []string, opts GroupOptions) (*GroupResult, error)
+	RollingRestart(ctx context.Context, template string, opts RollingOptions) (*RollingResult, error)
+	RunTransient(ctx context.Context, spec TransientSpec, opts ...CallOption) (*TransientRun, error)
+	Scale(ctx context.Context, template string, n int, instanceNamer func(i int) string) (*ScaleResult, error)
+	SecurityProfile(ctx context.Context, unit string) (SecurityProfile, error)
+	SecurityScore(ctx context.Context, unit string) (Score, []Finding, error)
+	SetEnvironment(ctx context.Context, vars map[string]string) error
+	SetFailureHandler(ctx context.Context, unit, handlerUnit string) error
+	SetUnitEnvironment(ctx context.Context, unit string, vars map[string]string, restart bool) error
+	ShowEnvironment(ctx context.Context) (map[string]string, error)
+	Snapshot(ctx context.Context, units []string) (StateSnapshot, error)
+	SocketStats(ctx context.Context, unit string) (UnitSocketStats, error)
+	Start(ctx context.Context, unit string, opts ...CallOption) error
+	StartAndWaitReady(ctx context.Context, unit string, probe ReadinessProbe, opts ...CallOption) error
+	StartTransient(ctx context.Context, spec TransientSpec, opts ...CallOption) error
+	StartTransientPath(ctx context.Context, spec TransientPathSpec, opts ...CallOption) error
+	Status(ctx context.Context, unit string) (*dbus.UnitStatus, error)
+	StatusAll(ctx context.Context, units []string) (map[string]*dbus.UnitStatus, error)
+	Stop(ctx context.Context, unit string, opts ...CallOption) error
+	StopWithDrain(ctx context.Context, unit string, drain DrainFunc, opts ...CallOption) error
+	SwapStatus(ctx context.Context, what string) (UnitSwap, error)
+	Switchover(ctx context.Context, oldInstance, newInstance string, health func(ctx context.Context) error, opts SwitchoverOptions) error
+	Timestamps(ctx context.Context, unit string) (UnitTimestamps, error)
+	UnitStartupBlame(ctx context.Context) ([]UnitStartup, error)
+	Unmount(ctx context.Context, where string, opts ...CallOption) error
+	UnsetEnvironment(ctx context.Context, names []string) error
 	Uptime(ctx context.Context, unit string) (time.Duration, error)
-	Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error
+	WaitAllActive(ctx context.Context, units []string) error
+	WaitForDevice(ctx context.Context, device string) error
+	Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, opts ...WatchOption) error
+	WatchDrift(ctx context.Context, units []string, driftChan chan<- DriftEvent, opts ...WatchOption) error
+	WatchFailures(ctx context.Context, failuresChan chan<- FailureEvent) error
+	WatchJobs(ctx context.Context, jobsChan chan<- JobEvent) error
 }
 
 // manager manages units via a D-Bus connection to systemd.
 type manager struct {
-	dbusConn *dbus.Conn
-	mutex    sync.RWMutex
+	cache  propertyCache
+	claims claims
+	// dbusConn is replaced when reconnecting.
+	dbusConn atomic.Pointer[conn]
+	history  history
+	hooks    hooks
+	// lifetime is the context of New, which bounds connections.
+	lifetime context.Context
+	// mutex guards hooks, which may be registered while operations run.
+	mutex sync.RWMutex
+	// options are set once by New, and only read afterwards.
+	options     options
+	origins     jobOrigins
+	pingFailed  atomic.Bool
+	rateLimiter rateLimiter
+	// reconnectMutex serializes replacing and closing dbusConn.
+	reconnectMutex sync.Mutex
+	unitLocks      unitLocks
+	writes         fileWrites
 }
 
 // Assert manager fulfills the Manager interface.
 var _ Manager = (*manager)(nil)
 
-// New returns an initialized D-Bus unit manager.
-// TODO repair connection on failure.
-func New(ctx context.Context) (Manager, error) {
+// New returns an initialized D-Bus unit manager, connected to systemd until
+// ctx is done. Should the connection be lost, e.g. when dbus-daemon
+// restarts, watches reconnect as set by WithReconnectPolicy, replacing the
+// connection used by all operations, which fail with ErrDisconnected until
+// then.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(ctx, "New")
 	defer span.End()
 
-	// Connect to dbusConn D-Bus API.
-	dbusConn, err := dbus.NewWithContext(ctx)
-	if err != nil {
+	o := newOptions(opts)
+	if err := validateAllowedUnits(o.allowedUnits); err != nil {
 		span.RecordError(err)
 		span.SetStatus(otelcodes.Error, "failed setting up systemd manager")
 
 		return nil, err
 	}
 
-	// Ensure the systemd D-Bus API client disconnects when done.
-	go func(conn *dbus.Conn) {
-		<-ctx.Done()
-		conn.Close()
-	}(dbusConn)
+	// Connect to dbusConn D-Bus API.
+	dbusConn, err := connect(ctx, o.busAddress)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up systemd manager")
 
-	mgr := manager{
-		dbusConn: dbusConn,
-		mutex:    sync.RWMutex{},
+		return nil, err
 	}
 
-	return &mgr, nil
-}
-
-// serviceProperty returns the property of the named unit.
-func (m *manager) serviceProperty(ctx context.Context, unit string, property string) (string, error) {
-	// Ensure connection to D-Bus API.
-	if !m.dbusConn.Connected() {
-		return "", ErrDisconnected
+	mgr := &manager{
+		lifetime: ctx,
+		mutex:    sync.RWMutex{},
+		options:  o,
 	}
+	mgr.dbusConn.Store(dbusConn)
 
-	p, err := m.dbusConn.GetServicePropertyContext(ctx, unit, property)
-	if err != nil {
-		return "", err
-	}
-	if p == nil {
-		return "", nil
-	}
-	// these value string encode the type with @<Char><Space>, if so remove it before returning
-	vs := p.Value.String()
-	if vs[0] == '@' {
-		return vs[3:], nil
-	}
+	// Ensure the systemd D-Bus API client disconnects when done.
+	go func() {
+		<-ctx.Done()
+		mgr.reconnectMutex.Lock()
+		defer mgr.reconnectMutex.Unlock()
+		mgr.dbusConn.Load().Close()
+	}()
 
-	return vs, nil
+	return mgr, nil
 }
 
 // Restart synchronously reloads and restarts the named unit.
-func (m *manager) Restart(parentCtx context.Context, unit string) error {
+func (m *manager) Restart(parentCtx context.Context, unit string, opts ...CallOption) error {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(parentCtx, "Restart")
 	span.SetAttributes(otelattr.String("unit", unit))
 	defer span.End()
 
-	// Ensure connection to D-Bus API.
-	if !m.dbusConn.Connected() {
-		span.RecordError(ErrDisconnected)
-		span.SetStatus(otelcodes.Error, "failed to restart unit %q, can't reach systemd D-Bus API")
-
-		return ErrDisconnected
-	}
-
-	// An error is expected when reload a unit that is not started, so ignore
-	// any error.
-	_, _ = m.dbusConn.ReloadUnitContext(ctx, unit, "replace", nil)
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationRestart, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		// An error is expected when reload a unit that is not started, so
+		// ignore any error.
+		_, _ = m.dbusConn.Load().ReloadUnitContext(ctx, unit, string(o.jobMode), nil)
 
-	// Restart the unit.
-	resultChan := make(chan string, 1)
-	_, err := m.dbusConn.RestartUnitContext(ctx, unit, "replace", resultChan)
+		return m.dbusConn.Load().RestartUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
 	if err != nil {
-		err := fmt.Errorf("failed to restart unit %q: %w", unit, err)
 		span.RecordError(err)
 		span.SetStatus(otelcodes.Error, err.Error())
 
 		return err
 	}
-
-	select {
-	case <-ctx.Done():
-		span.RecordError(ctx.Err())
-		span.SetStatus(otelcodes.Error, err.Error())
-		return ctx.Err()
-	case result := <-resultChan:
-		if result != done {
-			err := fmt.Errorf("failed to restart unit %q with result %q", unit, result)
-			span.RecordError(err)
-			span.SetStatus(otelcodes.Error, err.Error())
-
-			return err
-		}
-	}
 	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully restarted unit %q", unit))
 
-	return err
+	return nil
 }
 
 // Start synchronously starts a named unit.
-func (m *manager) Start(parentCtx context.Context, unit string) error {
+func (m *manager) Start(parentCtx context.Context, unit string, opts ...CallOption) error {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(parentCtx, "Start")
 	span.SetAttributes(otelattr.String("unit", unit))
 	defer span.End()
 
-	// Ensure connection to D-Bus API.
-	if !m.dbusConn.Connected() {
-		span.RecordError(ErrDisconnected)
-		span.SetStatus(otelcodes.Error, "failed to start unit %q, can't reach systemd D-Bus API")
-
-		return ErrDisconnected
-	}
-
-	resultChan := make(chan string, 1)
-	_, err := m.dbusConn.StartUnitContext(ctx, unit, "replace", resultChan)
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationStart, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
 	if err != nil {
-		err = fmt.Errorf("failed to start unit %q: %w", unit, err)
 		span.RecordError(err)
 		span.SetStatus(otelcodes.Error, err.Error())
 
 		return err
 	}
-
-	select {
-	case <-ctx.Done():
-		span.RecordError(ctx.Err())
-		span.SetStatus(otelcodes.Error, ctx.Err().Error())
-
-		return ctx.Err()
-	case result := <-resultChan:
-		if result != done {
-			err := fmt.Errorf("failed to start unit %q with result %q", unit, result)
-			span.RecordError(err)
-			span.SetStatus(otelcodes.Error, err.Error())
-
-			return err
-		}
-	}
 	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully started unit %q", unit))
 
 	return nil
 }
 
 // Stop synchronously stops a named unit.
-func (m *manager) Stop(parentCtx context.Context, unit string) error {
+func (m *manager) Stop(parentCtx context.Context, unit string, opts ...CallOption) error {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(parentCtx, "Stop")
 	span.SetAttributes(otelattr.String("unit", unit))
 	defer span.End()
 
-	// Ensure connection to D-Bus API.
-	if !m.dbusConn.Connected() {
-		span.RecordError(ErrDisconnected)
-		span.SetStatus(otelcodes.Error, "failed to stop unit %q, can't reach systemd D-Bus API")
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationStop, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StopUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully stopped unit %q", unit))
+
+	return nil
+}
+
+// jobFunc enqueues a systemd job, which result is written to resultChan.
+type jobFunc func(ctx context.Context, resultChan chan<- string) (int, error)
+
+// runJob runs a mutating operation on the named unit, including its hooks,
+// and waits for the resulting job to complete.
+func (m *manager) runJob(ctx context.Context, op Operation, unit string, o callOptions, job jobFunc) (err error) {
+	o = withContextMetadata(ctx, o)
+
+	// Record the operation, whatever its outcome.
+	if m.options.auditSink != nil {
+		auditCtx, started := context.WithoutCancel(ctx), time.Now()
+		defer func() {
+			m.audit(auditCtx, op, unit, o, started, err)
+		}()
+	}
 
+	// Fail early rather than with a confusing D-Bus error.
+	if err := m.validateUnit(unit); err != nil {
+		return fmt.Errorf("failed to %s unit: %w", op, err)
+	}
+	if err := o.jobMode.validate(); err != nil {
+		return fmt.Errorf("failed to %s unit %q: %w", op, unit, err)
+	}
+
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
 		return ErrDisconnected
 	}
 
-	resultChan := make(chan string, 1)
-	_, err := m.dbusConn.StopUnitContext(ctx, unit, "replace", resultChan)
+	// Only run within maintenance windows, which waiting for isn't bound
+	// by the operation timeout.
+	if err := m.options.maintenancePolicy.admit(ctx, unit, time.Now); err != nil {
+		return fmt.Errorf("failed to %s unit: %w", op, err)
+	}
+
+	// Don't step on units claimed by other owners.
+	if err := m.checkClaim(op, unit); err != nil {
+		return fmt.Errorf("failed to %s unit: %w", op, err)
+	}
+
+	// Bound the operation, so a stuck job can't block forever.
+	timeout := m.options.defaultTimeout
+	if o.timeout > 0 {
+		timeout = o.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s: %w", ErrTimeout, timeout, context.DeadlineExceeded))
+		defer cancel()
+	}
+
+	// Protect systemd from runaway callers.
+	if err := m.rateLimiter.take(ctx, m.options.rateLimits, unit); err != nil {
+		return fmt.Errorf("failed to %s unit %q: %w", op, unit, err)
+	}
+
+	// Serialize operations on the same unit.
+	unlock, err := m.unitLocks.lock(ctx, unit)
 	if err != nil {
-		err = fmt.Errorf("failed to stop unit %q: %w", unit, err)
-		span.RecordError(err)
-		span.SetStatus(otelcodes.Error, err.Error())
+		return fmt.Errorf("failed to %s unit %q while waiting for other operations: %w", op, unit, context.Cause(ctx))
+	}
+	defer unlock()
+	// Whatever the outcome, the unit may have changed.
+	defer m.cache.invalidate(unit)
+
+	err = m.runHooksBefore(ctx, op, unit)
+	if err == nil {
+		err = runDrain(ctx, unit, o)
+	}
+	if err == nil {
+		err = m.waitJob(ctx, op, unit, job)
+	}
+	if err == nil {
+		err = m.runHooksAfter(ctx, op, unit)
+	}
+	if err != nil {
+		m.runHooksFailure(ctx, op, unit, err)
+	}
+
+	return err
+}
 
+// waitJob enqueues a job and waits for it to complete.
+func (m *manager) waitJob(ctx context.Context, op Operation, unit string, job jobFunc) error {
+	resultChan := make(chan string, 1)
+	var jobID int
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		jobID, err = job(ctx, resultChan)
 		return err
+	})
+	if IsAccessDenied(err) {
+		return fmt.Errorf("failed to %s unit %q: %w: %w", op, unit, ErrPermissionDenied, err)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to %s unit %q: %w", op, unit, err)
+	}
+	// Let watches link the state changes of the unit to this operation.
+	m.origins.record(unit, jobOrigin{op: op, jobID: jobID, spanContext: trace.SpanContextFromContext(ctx), enqueued: time.Now()})
 
 	select {
 	case <-ctx.Done():
-		span.RecordError(ctx.Err())
-		span.SetStatus(otelcodes.Error, ctx.Err().Error())
+		cause := context.Cause(ctx)
+		if !errors.Is(cause, ErrTimeout) {
+			return ctx.Err()
+		}
+		// Don't leave the job behind when the operation timed out.
+		cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelJobTimeout)
+		defer cancel()
+		if err := m.dbusConn.Load().cancelJob(cancelCtx, jobID); err != nil {
+			return fmt.Errorf("failed to %s unit %q: %w (failed to cancel job %d: %w)", op, unit, cause, jobID, err)
+		}
 
-		return ctx.Err()
+		return fmt.Errorf("failed to %s unit %q: %w", op, unit, cause)
 	case result := <-resultChan:
 		if result != done {
-			err := fmt.Errorf("failed to stop unit %q with result %q", unit, result)
-			span.RecordError(err)
-			span.SetStatus(otelcodes.Error, err.Error())
-
-			return err
+			return m.jobError(ctx, op, unit, jobID, result)
 		}
 	}
-	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully stopped unit %q", unit))
 
 	return nil
 }
 
-// Uptime returns the duration since a unit started.
+// Uptime returns the duration since a unit started: since the main process
+// started for services, and since becoming active for other units. It's zero
+// if the unit isn't running.
 func (m *manager) Uptime(parentCtx context.Context, unit string) (time.Duration, error) {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(parentCtx, "Uptime")
 	span.SetAttributes(otelattr.String("unit", unit))
 	defer span.End()
 
-	const attrStartTimestamp string = "ExecMainStartTimestamp"
-
 	// There's an implicit check for connectivity to D-Bus API, so there's
 	// no need to check here.
-	propStartTime, err := m.serviceProperty(ctx, unit, attrStartTimestamp)
+	startTime, err := m.startTime(ctx, unit)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(otelcodes.Error, fmt.Sprintf("failed to retrieve attribute %q for unit %q", attrStartTimestamp, unit))
+		span.SetStatus(otelcodes.Error, err.Error())
 
 		return -1, err
 	}
-	startTime, err := propertyTimestampToTime(propStartTime)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(otelcodes.Error, fmt.Sprintf("failed to convert %q to time.Duration for unit %q uptime", propStartTime, unit))
+	// The unit isn't running.
+	if startTime.IsZero() {
+		span.SetStatus(otelcodes.Ok, "unit isn't running")
 
-		return -1, err
+		return 0, nil
 	}
 	span.SetStatus(otelcodes.Ok, "retrieved unit uptime")
 
 	return time.Now().UTC().Sub(startTime), nil
 }
 
+// startTime returns when a unit started, or the zero time if it isn't
+// running. Services start with their main process, other units when they
+// become active.
+func (m *manager) startTime(ctx context.Context, unit string) (time.Time, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return time.Time{}, err
+	}
+	if unitType(unit) == "service" {
+		return getProperty[time.Time](ctx, m, unit, "Service", "ExecMainStartTimestamp")
+	}
+
+	activeEnter, err := getProperty[time.Time](ctx, m, unit, "Unit", "ActiveEnterTimestamp")
+	if err != nil {
+		return time.Time{}, err
+	}
+	activeExit, err := getProperty[time.Time](ctx, m, unit, "Unit", "ActiveExitTimestamp")
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The unit became inactive since.
+	if !activeExit.Before(activeEnter) {
+		return time.Time{}, nil
+	}
+
+	return activeEnter, nil
+}
+
 // Watch subscribes to a named unit status changes, which when found are sent
-// to updatesChan. This is a blocking function.
-func (m *manager) Watch(parentCtx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error {
+// to updatesChan. This is a blocking function. It fails with ErrUnitNotFound
+// if the unit doesn't exist, unless WithWaitForUnit is given. Watching
+// survives losing the D-Bus connection: once reconnected, the current status
+// is sent even if unchanged, since changes may have been missed. It fails
+// with ErrDisconnected if reconnecting fails. When ctx is done, it fails with
+// a LastStatusError holding the last status observed.
+func (m *manager) Watch(parentCtx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, opts ...WatchOption) error {
 	// Set-up tracing context.
 	ctx, span := otel.Tracer(name).Start(parentCtx, "Watch")
 	span.SetAttributes(otelattr.String("unit", unit))
@@ -280,40 +464,87 @@ func (m *manager) Watch(parentCtx context.Context, unit string, updatesChan chan
 		return err
 	}
 
-	// Subscribe to status changes for the desired unit alone.
-	subset := m.dbusConn.NewSubscriptionSet()
-	subset.Add(unit)
-	// TODO understand if such errors are critical and handle
-	// them if it turns out to be the case.
-	updateChan, _ := subset.Subscribe()
+	o := newWatchOptions(opts)
+	span.SetAttributes(otelattr.String("interval", o.interval.String()))
+	if err := m.checkWatchable(ctx, unit, o); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
 
-	for {
+		return err
+	}
+
+	// Poll the status of the desired unit alone, until done watching.
+	pollChan := make(chan unitPoll)
+	pollDone := make(chan error, 1)
+	go func() {
+		pollDone <- m.newUnitPoller(o.interval).run(ctx, unit, pollChan)
+	}()
+
+	// report sends status to updatesChan, if it passes the filters.
+	report := func(status *dbus.UnitStatus) error {
+		if !o.report(status) {
+			return nil
+		}
+		// Don't block forever on a receiver which is gone.
 		select {
+		case updatesChan <- status:
+			return nil
 		case <-ctx.Done():
+			return &LastStatusError{Unit: unit, Status: status, Err: ctx.Err()}
+		}
+	}
+
+	var (
+		watcher = unitWatcher{unit: unit, changed: o.changed}
+		// pending is the status to report once settled, when debouncing.
+		pending *dbus.UnitStatus
+		settled <-chan time.Time
+	)
+	for {
+		select {
+		case err := <-pollDone:
+			if ctx.Err() != nil {
+				err = &LastStatusError{Unit: unit, Status: watcher.last, Err: ctx.Err()}
+			}
 			// Set span status.
-			span.RecordError(ctx.Err())
-			span.SetStatus(otelcodes.Error, ctx.Err().Error())
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
 
-			return ctx.Err()
-		case changes := <-updateChan:
-			// Filter changes by the desired unit.
-			unitChanges, ok := changes[unit]
-			if ok {
-				updatesChan <- unitChanges
+			return err
+		case poll := <-pollChan:
+			unitChanges, ok := watcher.update(poll.statuses)
+			// Changes may have been missed while disconnected, so report
+			// the current status once reconnected.
+			if !ok && !poll.resync {
+				continue
 			}
-		}
-	}
-}
+			m.cache.invalidate(unit)
+			m.history.record(unit, unitChanges, m.options.historySize)
+			typ := NotificationStatus
+			if poll.resync {
+				typ = NotificationResync
+			}
+			eventCtx, eventSpan := m.startEventSpan(ctx, unit, unitChanges)
+			m.publishStatus(eventCtx, typ, unit, unitChanges)
+			eventSpan.End()
+			if o.debounce > 0 {
+				pending, settled = unitChanges, time.After(o.debounce)
+				continue
+			}
+			if err := report(unitChanges); err != nil {
+				span.RecordError(err)
+				span.SetStatus(otelcodes.Error, err.Error())
 
-func propertyTimestampToTime(s string) (time.Time, error) {
-	i, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		return time.Time{}, err
-	}
+				return err
+			}
+		case <-settled:
+			settled = nil
+			if err := report(pending); err != nil {
+				span.RecordError(err)
+				span.SetStatus(otelcodes.Error, err.Error())
 
-	// i is microseconds as per systemd D-Bus documentation:
-	// "Note that properties exposing time values are usually encoded in
-	// microseconds (usec) on the bus, even if their corresponding settings
-	// in the unit files are in seconds."
-	return time.Unix(i/1000000, i%1000000).UTC(), nil
+				return err
+			}
+		}
+	}
 }
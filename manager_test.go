@@ -4,16 +4,31 @@ package systemdmanager
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 	"github.com/pires/go-systemdmanager/fixtures"
+	"github.com/pires/go-systemdmanager/unitfile"
 	"github.com/stretchr/testify/require"
 )
 
 // Fixtures
-const unitDummy = "manager_dummy.service"
+const (
+	unitDummy     = "manager_dummy.service"
+	unitSlow      = "manager_slow.service"
+	unitCondition = "manager_condition.service"
+	unitFailing   = "manager_failing.service"
+	unitDelegate  = "manager_delegate.service"
+)
 
 // uninstallUnit is a wrapper for uninstalling units. It is required for
 // deferring unit removal while making the errcheck linter happy, since it
@@ -100,8 +115,11 @@ func Test_E2E_Manager_Watch(t *testing.T) {
 
 		// Watch for unit status changes.
 		updatesChan := make(chan *dbus.UnitStatus)
-		// Ensure Watch stops due to context being cancelled.
-		require.ErrorIs(t, mgr.Watch(ctx, "non-existing", updatesChan), context.DeadlineExceeded)
+		// Ensure Watch fails right away.
+		require.ErrorIs(t, mgr.Watch(ctx, "non-existing.service", updatesChan), ErrUnitNotFound)
+		// Unless waiting for the unit to appear, until the context is
+		// cancelled.
+		require.ErrorIs(t, mgr.Watch(ctx, "non-existing.service", updatesChan, WithWaitForUnit()), context.DeadlineExceeded)
 	})
 
 	t.Run("Watch unit that isn't started", func(t *testing.T) {
@@ -119,7 +137,1215 @@ func Test_E2E_Manager_Watch(t *testing.T) {
 
 		// Watch for unit status changes.
 		updatesChan := make(chan *dbus.UnitStatus)
-		// Ensure Watch stops due to context being cancelled.
-		require.ErrorIs(t, mgr.Watch(ctx, unitDummy, updatesChan), context.DeadlineExceeded)
+		// Ensure Watch stops due to context being cancelled, reporting the
+		// last status observed.
+		err = mgr.Watch(ctx, unitDummy, updatesChan)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		var lastStatusErr *LastStatusError
+		require.ErrorAs(t, err, &lastStatusErr)
+		require.NotNil(t, lastStatusErr.Status)
+		require.Equal(t, "inactive", lastStatusErr.Status.ActiveState)
+	})
+}
+
+func Test_E2E_Manager_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitSlow))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitSlow)
+
+	// Set-up manager.
+	mgr, err := New(ctx, WithDefaultTimeout(time.Second))
+	require.NoError(t, err)
+
+	// The start job never completes, so it must time out and be cancelled.
+	err = mgr.Start(ctx, unitSlow)
+	require.ErrorIs(t, err, ErrTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Per-operation timeouts take precedence.
+	start := time.Now()
+	require.ErrorIs(t, mgr.Start(ctx, unitSlow, WithTimeout(2*time.Second)), ErrTimeout)
+	require.GreaterOrEqual(t, time.Since(start), 2*time.Second)
+}
+
+func Test_E2E_Manager_Status(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	status, err := mgr.Status(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Equal(t, unitDummy, status.Name)
+	require.Equal(t, "active", status.ActiveState)
+
+	status, err = mgr.Status(ctx, "non-existing.service")
+	require.NoError(t, err)
+	require.Equal(t, "not-found", status.LoadState)
+}
+
+func Test_E2E_Manager_StatusAll(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	statuses, err := mgr.StatusAll(ctx, []string{unitDummy, "non-existing.service"})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, "active", statuses[unitDummy].ActiveState)
+	require.Equal(t, "not-found", statuses["non-existing.service"].LoadState)
+
+	_, err = mgr.StatusAll(ctx, []string{unitDummy, "invalid"})
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
+
+func Test_E2E_Manager_EachUnit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	var names []string
+	err = mgr.EachUnit(ctx, UnitFilter{Patterns: []string{"manager_*.service"}, States: []string{"active"}}, func(s dbus.UnitStatus) error {
+		names = append(names, s.Name)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Contains(t, names, unitDummy)
+}
+
+func Test_E2E_Manager_Ping(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.True(t, mgr.Healthy())
+	require.NoError(t, mgr.Ping(ctx))
+	require.True(t, mgr.Healthy())
+}
+
+func Test_E2E_Manager_Raw(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	version, err := mgr.Raw().GetManagerProperty("Version")
+	require.NoError(t, err)
+	require.NotEmpty(t, version)
+}
+
+func Test_E2E_Manager_Concurrent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Watch while others start and stop the unit, and read its status.
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	updatesChan := make(chan *dbus.UnitStatus)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- mgr.Watch(watchCtx, unitDummy, updatesChan, WithWatchInterval(10*time.Millisecond))
+	}()
+	go func() {
+		for range updatesChan {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for range 5 {
+				if i%2 == 0 {
+					require.NoError(t, mgr.Start(ctx, unitDummy))
+				} else {
+					require.NoError(t, mgr.Stop(ctx, unitDummy))
+				}
+				_, err := mgr.Status(ctx, unitDummy)
+				require.NoError(t, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	stopWatching()
+	require.ErrorIs(t, <-watchDone, context.Canceled)
+	close(updatesChan)
+}
+
+func Test_E2E_Manager_CanManage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// E2E tests run with permission to manage units.
+	ok, err := mgr.CanManage(ctx, unitDummy, ActionManageUnits)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func Test_E2E_Manager_EnablementState(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Fixtures are linked at runtime, not enabled.
+	state, err := mgr.EnablementState(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Equal(t, UnitFileLinkedRuntime, state)
+
+	enabled, err := mgr.IsEnabled(ctx, unitDummy)
+	require.NoError(t, err)
+	require.False(t, enabled)
+}
+
+func Test_E2E_Manager_DiffUnit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Same settings, different formatting.
+	same := []byte("# Reformatted\n[Unit]\nDescription = dummy unit for e2e tests\n[Service]\nExecStart=/bin/sleep 400\n")
+	diff, needed, err := mgr.DiffUnit(ctx, unitDummy, same)
+	require.NoError(t, err)
+	require.False(t, needed)
+	require.Empty(t, diff.Changes)
+	require.NotEmpty(t, diff.Path)
+
+	changed := []byte("[Unit]\nDescription=dummy unit for e2e tests\n[Service]\nExecStart=/bin/sleep 500\n")
+	diff, needed, err = mgr.DiffUnit(ctx, unitDummy, changed)
+	require.NoError(t, err)
+	require.True(t, needed)
+	require.Equal(t, []Change{{
+		Section: "Service",
+		Key:     "ExecStart",
+		Current: []string{"/bin/sleep 400"},
+		Desired: []string{"/bin/sleep 500"},
+	}}, diff.Changes)
+}
+
+func Test_E2E_Manager_InstallFromFS(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	const unit = "manager_embedded.service"
+	fsys := fstest.MapFS{
+		unit: &fstest.MapFile{Data: []byte("[Unit]\nDescription=embedded unit for e2e tests\n[Service]\nExecStart=/bin/sleep 400\n")},
+	}
+
+	// Install to the runtime directory, so nothing outlives a reboot.
+	mgr, err := New(ctx, WithUnitDirectory("/run/systemd/system"))
+	require.NoError(t, err)
+	defer func() {
+		ctx := t.Context()
+		_ = mgr.Stop(ctx, unit)
+		_ = os.Remove(filepath.Join("/run/systemd/system", unit))
+	}()
+
+	require.NoError(t, mgr.InstallFromFS(ctx, fsys))
+	status, err := mgr.Status(ctx, unit)
+	require.NoError(t, err)
+	require.Equal(t, "active", status.ActiveState)
+	started, err := mgr.Uptime(ctx, unit)
+	require.NoError(t, err)
+
+	// Installing the same unit again changes nothing.
+	require.NoError(t, mgr.InstallFromFS(ctx, fsys, unit))
+	uptime, err := mgr.Uptime(ctx, unit)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, uptime, started)
+}
+
+func Test_E2E_Manager_SnapshotRestore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	snap, err := mgr.Snapshot(ctx, []string{unitDummy})
+	require.NoError(t, err)
+	require.Len(t, snap.Units, 1)
+	require.Equal(t, "active", snap.Units[0].ActiveState)
+	require.Equal(t, UnitFileLinkedRuntime, snap.Units[0].UnitFileState)
+
+	// Maintenance.
+	require.NoError(t, mgr.Stop(ctx, unitDummy))
+
+	require.NoError(t, mgr.Restore(ctx, snap))
+	status, err := mgr.Status(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Equal(t, "active", status.ActiveState)
+}
+
+func Test_E2E_Manager_Timestamps(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	before := time.Now().Add(-time.Second)
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	ts, err := mgr.Timestamps(ctx, unitDummy)
+	require.NoError(t, err)
+	require.True(t, ts.ActiveEnter.After(before))
+	require.True(t, ts.ExecMainStart.After(before))
+	require.True(t, ts.ExecMainExit.IsZero())
+}
+
+func Test_E2E_Manager_Conditions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitCondition))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitCondition)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// The unit is skipped, not failed.
+	require.NoError(t, mgr.Start(ctx, unitCondition))
+	c, err := mgr.Conditions(ctx, unitCondition)
+	require.NoError(t, err)
+	require.False(t, c.ConditionResult)
+	require.Equal(t, []Condition{{Type: "ConditionPathExists", Parameter: "/non-existing", State: -1}}, c.Failed())
+}
+
+func Test_E2E_Manager_Environment(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	const variable = "SYSTEMDMANAGER_E2E"
+	require.NoError(t, mgr.SetEnvironment(ctx, map[string]string{variable: "value"}))
+	vars, err := mgr.ShowEnvironment(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "value", vars[variable])
+
+	require.NoError(t, mgr.UnsetEnvironment(ctx, []string{variable}))
+	vars, err = mgr.ShowEnvironment(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, vars, variable)
+}
+
+func Test_E2E_Manager_SetUnitEnvironment(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager, with drop-ins next to the runtime link of the fixture.
+	mgr, err := New(ctx, WithUnitDirectory("/run/systemd/system"))
+	require.NoError(t, err)
+	defer func() {
+		_ = mgr.SetUnitEnvironment(t.Context(), unitDummy, nil, false)
+	}()
+
+	require.NoError(t, mgr.SetUnitEnvironment(ctx, unitDummy, map[string]string{"FOO": "bar baz"}, true))
+	env, err := GetProperty[[]string](ctx, mgr, unitDummy, "Service", "Environment")
+	require.NoError(t, err)
+	require.Equal(t, []string{"FOO=bar baz"}, env)
+
+	require.NoError(t, mgr.SetUnitEnvironment(ctx, unitDummy, nil, false))
+	env, err = GetProperty[[]string](ctx, mgr, unitDummy, "Service", "Environment")
+	require.NoError(t, err)
+	require.Empty(t, env)
+}
+
+func Test_E2E_Manager_StartTransient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	const unit = "systemdmanager-e2e-transient.service"
+	spec := TransientSpec{
+		Name:        unit,
+		Description: "systemdmanager transient e2e test",
+		Command:     []string{"/bin/sleep", "60"},
+	}
+	spec.ApplyProfile(unitfile.ProfileStrict)
+	require.NoError(t, mgr.StartTransient(ctx, spec))
+	defer func() {
+		_ = mgr.Stop(t.Context(), unit)
+	}()
+
+	status, err := mgr.Status(ctx, unit)
+	require.NoError(t, err)
+	require.Equal(t, "active", status.ActiveState)
+	noNewPrivileges, err := GetProperty[bool](ctx, mgr, unit, "Service", "NoNewPrivileges")
+	require.NoError(t, err)
+	require.True(t, noNewPrivileges)
+}
+
+func Test_E2E_Manager_StartTransient_DynamicUser(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	const unit = "systemdmanager-e2e-dynamic.service"
+	require.NoError(t, mgr.StartTransient(ctx, TransientSpec{
+		Name:    unit,
+		Command: []string{"/bin/sh", "-c", "touch $STATE_DIRECTORY/ok && exec sleep 60"},
+		Settings: []unitfile.Entry{
+			{Key: "DynamicUser", Value: "yes"},
+			{Key: "StateDirectory", Value: "systemdmanager-e2e"},
+		},
+	}))
+	defer func() {
+		_ = mgr.Stop(t.Context(), unit)
+	}()
+
+	status, err := mgr.Status(ctx, unit)
+	require.NoError(t, err)
+	require.Equal(t, "active", status.ActiveState)
+	// The state directory is reached through a symlink to the private one,
+	// owned by the dynamic user.
+	require.Eventually(t, func() bool {
+		_, err := os.Stat("/var/lib/systemdmanager-e2e/ok")
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func Test_E2E_Manager_RunTransient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	run, err := mgr.RunTransient(ctx, TransientSpec{
+		Name:    "systemdmanager-e2e-run.service",
+		Command: []string{"/bin/sh", "-c", "echo out; echo err >&2; exit 3"},
 	})
+	require.NoError(t, err)
+	defer run.Output.Close()
+
+	output, err := io.ReadAll(run.Output)
+	require.NoError(t, err)
+	require.Equal(t, "out\nerr\n", string(output))
+	status, err := run.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ExitStatus{Code: "exited", Status: 3}, status)
+
+	// The unit is unloaded once waited for.
+	unitStatus, err := mgr.Status(ctx, run.Unit)
+	require.NoError(t, err)
+	require.Equal(t, "not-found", unitStatus.LoadState)
+}
+
+func Test_E2E_Manager_BootTimes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	b, err := mgr.BootTimes(ctx)
+	require.NoError(t, err)
+	require.True(t, b.Finished)
+	require.Positive(t, b.Userspace)
+
+	blame, err := mgr.UnitStartupBlame(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, blame)
+	for i := 1; i < len(blame); i++ {
+		require.GreaterOrEqual(t, blame[i-1].Duration, blame[i].Duration)
+	}
+}
+
+func Test_E2E_Manager_WatchFailures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	failuresChan := make(chan FailureEvent)
+	go func() {
+		_ = mgr.WatchFailures(ctx, failuresChan)
+	}()
+	// Give journalctl time to start following.
+	time.Sleep(time.Second)
+
+	// Output goes to the journal, unlike with RunTransient.
+	const unit = "systemdmanager-e2e-failure.service"
+	require.NoError(t, mgr.StartTransient(ctx, TransientSpec{
+		Name:    unit,
+		Command: []string{"/bin/sh", "-c", "echo failing; exit 1"},
+	}))
+	// Failed units stay loaded until reset.
+	defer func() {
+		_ = mgr.(*manager).unload(t.Context(), unit)
+	}()
+
+	for event := range failuresChan {
+		if event.Unit != unit {
+			continue
+		}
+		require.Equal(t, "exit-code", event.Result)
+		require.Contains(t, event.Lines, "failing")
+		break
+	}
+}
+
+func Test_E2E_Available(t *testing.T) {
+	ok, reason := Available(context.Background())
+	require.True(t, ok)
+	require.Equal(t, ReasonNone, reason)
+}
+
+func Test_E2E_Manager_Uptime_UnitTypes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Targets are up since becoming active.
+	uptime, err := mgr.Uptime(ctx, "multi-user.target")
+	require.NoError(t, err)
+	require.Positive(t, uptime)
+
+	_, err = GetProperty[time.Time](ctx, mgr, "multi-user.target", "Service", "ExecMainStartTimestamp")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
+
+func Test_E2E_Manager_Watch_Filters(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Only report the unit once it settled as active.
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	updatesChan := make(chan *dbus.UnitStatus, 1)
+	go func() {
+		_ = mgr.Watch(watchCtx, unitDummy, updatesChan, OnlyStates("active"), Debounce(500*time.Millisecond))
+	}()
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+
+	select {
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	case res := <-updatesChan:
+		require.NotNil(t, res)
+		require.Equal(t, "active", res.ActiveState)
+	}
+}
+
+func Test_E2E_Manager_JobError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitFailing))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitFailing)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	err = mgr.Start(ctx, unitFailing)
+	require.ErrorIs(t, err, ErrJobFailed)
+	var jobErr *JobError
+	require.ErrorAs(t, err, &jobErr)
+	require.Equal(t, "exit-code", jobErr.UnitResult)
+	require.True(t, jobErr.Path.IsValid())
+}
+
+func Test_E2E_Manager_LastOOM(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	kill, err := mgr.LastOOM(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Nil(t, kill)
+}
+
+func Test_E2E_Manager_ControlGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	path, err := mgr.ControlGroupPath(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Equal(t, "/system.slice/"+unitDummy, path)
+
+	cgroup, err := OpenControlGroup(ctx, mgr, unitDummy)
+	require.NoError(t, err)
+	defer cgroup.Close()
+	_, err = cgroup.ReadFile("cpu.stat")
+	require.NoError(t, err)
+}
+
+func Test_E2E_Manager_DelegatedControlGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixtures.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDelegate))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+	defer uninstallUnit(t, t.Context(), unitDelegate)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	_, err = OpenDelegatedControlGroup(ctx, mgr, unitDummy)
+	require.ErrorIs(t, err, ErrNotDelegated)
+
+	require.NoError(t, mgr.Start(ctx, unitDelegate))
+	g, err := OpenDelegatedControlGroup(ctx, mgr, unitDelegate)
+	require.NoError(t, err)
+	defer g.Close()
+	pids, err := g.Processes()
+	require.NoError(t, err)
+	require.Len(t, pids, 1)
+
+	// Move the unit's process to a child, so controllers can be enabled.
+	main, err := g.CreateChild("main")
+	require.NoError(t, err)
+	defer main.Close()
+	require.NoError(t, main.AddProcess(pids[0]))
+	require.NoError(t, g.EnableControllers("memory"))
+	workers, err := g.CreateChild("workers")
+	require.NoError(t, err)
+	require.NoError(t, workers.Set("memory.max", "104857600"))
+	require.NoError(t, workers.Close())
+	require.NoError(t, g.RemoveChild("workers"))
+}
+
+func Test_E2E_Manager_SecurityProfile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// The fixture isn't confined.
+	profile, err := mgr.SecurityProfile(ctx, unitDummy)
+	require.NoError(t, err)
+	require.False(t, profile.NoNewPrivileges)
+	require.Contains(t, profile.CapabilityBoundingSet, "CAP_SYS_ADMIN")
+	require.Empty(t, profile.User)
+}
+
+func Test_E2E_Manager_SecurityScore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// The fixture isn't sandboxed.
+	score, findings, err := mgr.SecurityScore(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Equal(t, "UNSAFE", score.Rating())
+	require.NotEmpty(t, findings)
+}
+
+func Test_E2E_Manager_Predicates(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	exists, err := mgr.Exists(ctx, unitDummy)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = mgr.Exists(ctx, "systemdmanager-e2e-missing.service")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	active, err := mgr.IsActive(ctx, unitDummy)
+	require.NoError(t, err)
+	require.True(t, active)
+	failed, err := mgr.IsFailed(ctx, unitDummy)
+	require.NoError(t, err)
+	require.False(t, failed)
+
+	require.NoError(t, mgr.Stop(ctx, unitDummy))
+	active, err = mgr.IsActive(ctx, unitDummy)
+	require.NoError(t, err)
+	require.False(t, active)
+}
+
+func Test_E2E_Manager_StartAndWaitReady(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	require.NoError(t, mgr.StartAndWaitReady(ctx, unitDummy, TCPProbe(l.Addr().String())))
+	require.NoError(t, mgr.Stop(ctx, unitDummy))
+
+	// Probes that never pass fail once ctx is done.
+	waitCtx, waitCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer waitCancel()
+	err = mgr.StartAndWaitReady(waitCtx, unitDummy, FileProbe(filepath.Join(t.TempDir(), "missing")))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func Test_E2E_Manager_SetFailureHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.SetFailureHandler(ctx, unitDummy, WebhookNotifierUnit))
+	onFailure, err := GetProperty[[]string](ctx, mgr, unitDummy, "Unit", "OnFailure")
+	require.NoError(t, err)
+	require.Equal(t, []string{"systemdmanager-notify-failure@" + unitDummy + ".service"}, onFailure)
+
+	require.NoError(t, mgr.SetFailureHandler(ctx, unitDummy, ""))
+	onFailure, err = GetProperty[[]string](ctx, mgr, unitDummy, "Unit", "OnFailure")
+	require.NoError(t, err)
+	require.Empty(t, onFailure)
+}
+
+func Test_E2E_Manager_WatchJobs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	jobsChan := make(chan JobEvent, 16)
+	go mgr.WatchJobs(watchCtx, jobsChan)
+	// Give the watch time to subscribe.
+	time.Sleep(time.Second)
+
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+	var seen []JobEventType
+	for len(seen) < 2 {
+		select {
+		case event := <-jobsChan:
+			if event.Unit != unitDummy {
+				continue
+			}
+			seen = append(seen, event.Type)
+			if event.Type == JobEventRemoved {
+				require.Equal(t, "done", event.Result)
+			}
+		case <-ctx.Done():
+			t.Fatal("jobs not observed")
+		}
+	}
+	require.Equal(t, []JobEventType{JobEventNew, JobEventRemoved}, seen)
+}
+
+func Test_E2E_Manager_WatchDrift(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	driftChan := make(chan DriftEvent, 16)
+	go mgr.WatchDrift(watchCtx, []string{unitDummy}, driftChan, WithWatchInterval(100*time.Millisecond))
+	// Give the watch time to fingerprint the unit.
+	time.Sleep(time.Second)
+
+	// Changes made by the manager aren't drift.
+	require.NoError(t, mgr.SetFailureHandler(ctx, unitDummy, "alert.service"))
+	defer mgr.SetFailureHandler(t.Context(), unitDummy, "")
+	time.Sleep(500 * time.Millisecond)
+	require.Empty(t, driftChan)
+
+	external := filepath.Join("/etc/systemd/system", unitDummy+".d", "99-external.conf")
+	require.NoError(t, os.WriteFile(external, []byte("[Unit]\nDescription=Edited\n"), 0o644))
+	defer os.Remove(external)
+	select {
+	case event := <-driftChan:
+		require.Equal(t, unitDummy, event.Unit)
+		require.Equal(t, external, event.Path)
+		require.Equal(t, DriftCreated, event.Change)
+	case <-ctx.Done():
+		t.Fatal("drift not observed")
+	}
+}
+
+func Test_E2E_Manager_Claim(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx, WithClaimPolicy(ClaimPolicy{Owner: "agent-a", Storage: ClaimStorageDropIn}))
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Claim(ctx, unitDummy, "agent-b"))
+	defer mgr.Release(t.Context(), unitDummy, "agent-b")
+	require.ErrorIs(t, mgr.Start(ctx, unitDummy), ErrUnitClaimed)
+
+	require.NoError(t, mgr.Release(ctx, unitDummy, "agent-b"))
+	require.NoError(t, mgr.Claim(ctx, unitDummy, "agent-a"))
+	defer mgr.Release(t.Context(), unitDummy, "agent-a")
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+}
+
+func Test_E2E_Manager_RestartAt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	var notified []time.Duration
+	at := time.Now().Add(2 * time.Second)
+	require.NoError(t, mgr.RestartAt(ctx, unitDummy, at, func(delay time.Duration) {
+		notified = append(notified, delay)
+	}))
+	require.False(t, time.Now().Before(at))
+	require.Len(t, notified, 1)
+
+	active, err := mgr.IsActive(ctx, unitDummy)
+	require.NoError(t, err)
+	require.True(t, active)
+
+	// Cancelling ctx cancels the restart.
+	cancelCtx, cancelRestart := context.WithCancel(ctx)
+	cancelRestart()
+	require.ErrorIs(t, mgr.RestartAt(cancelCtx, unitDummy, time.Now().Add(time.Hour), nil), context.Canceled)
+}
+
+func Test_E2E_Manager_CanaryRestart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	rolledBack := false
+	result, err := mgr.CanaryRestart(ctx, []string{unitDummy}, 1, time.Second, CanaryOptions{
+		Rollback: func(context.Context, []string) error {
+			rolledBack = true
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{unitDummy}, result.Canaries)
+	require.Equal(t, []string{unitDummy}, result.Restarted)
+	require.Empty(t, result.Failed)
+	require.False(t, rolledBack)
+}
+
+func Test_E2E_Manager_ReloadOnChange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+
+	// The fixture doesn't support reloading.
+	require.Error(t, mgr.Reload(ctx, unitDummy))
+
+	config := filepath.Join(t.TempDir(), "dummy.conf")
+	require.NoError(t, os.WriteFile(config, []byte("a"), 0o644))
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	reloads := make(chan error, 1)
+	go mgr.ReloadOnChange(watchCtx, unitDummy, []string{config}, ReloadOnChangeOptions{
+		Debounce: 100 * time.Millisecond,
+		Restart:  true,
+		OnReload: func(err error) { reloads <- err },
+	})
+	// Give the watch time to start.
+	time.Sleep(500 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(config, []byte("b"), 0o644))
+	select {
+	case err := <-reloads:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("unit not reloaded")
+	}
+}
+
+func Test_E2E_Manager_PathUnits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixtures.
+	require.NoError(t, fixtures.InstallUnits(ctx, []string{unitDummy, "dummy", "dummy.path"}))
+	// By the time of uninstall, ctx may be cancelled.
+	defer func() {
+		require.NoError(t, fixtures.UninstallUnits(t.Context(), []string{unitDummy, "dummy", "dummy.path"}))
+	}()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	triggers, err := mgr.PathTriggers(ctx, "dummy.path")
+	require.NoError(t, err)
+	require.Equal(t, "dummy.service", triggers.Unit)
+	require.Equal(t, []PathTrigger{{Type: PathExists, Path: "/run/systemdmanager-dummy"}}, triggers.Triggers)
+
+	// A transient path unit activates the service once the file appears.
+	dir := t.TempDir()
+	const unit = "systemdmanager-e2e-transient.path"
+	require.NoError(t, mgr.StartTransientPath(ctx, TransientPathSpec{
+		Name:     unit,
+		Unit:     unitDummy,
+		Triggers: []PathTrigger{{Type: PathExists, Path: filepath.Join(dir, "trigger")}},
+	}))
+	defer func() {
+		_ = mgr.Stop(t.Context(), unit)
+		_ = mgr.Stop(t.Context(), unitDummy)
+	}()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "trigger"), nil, 0o644))
+	require.Eventually(t, func() bool {
+		status, err := mgr.Status(ctx, unitDummy)
+		return err == nil && status.ActiveState == "active"
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func Test_E2E_Manager_MountTransient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	where := t.TempDir()
+	unit, err := mgr.MountTransient(ctx, "tmpfs", where, "tmpfs", "size=1m,mode=0755")
+	require.NoError(t, err)
+	require.Equal(t, MountUnitName(where), unit)
+	defer func() {
+		_ = mgr.Unmount(t.Context(), where)
+	}()
+
+	mount, err := mgr.MountStatus(ctx, where)
+	require.NoError(t, err)
+	require.Equal(t, "active", mount.ActiveState)
+	require.Equal(t, "tmpfs", mount.Type)
+	require.Equal(t, where, mount.Where)
+
+	// Unmounting stops the transient mount unit, which is then gone.
+	require.NoError(t, mgr.Unmount(ctx, where))
+	exists, err := mgr.Exists(ctx, unit)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func Test_E2E_Manager_ListSwaps(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Hosts may have no swap at all.
+	swaps, err := mgr.ListSwaps(ctx)
+	require.NoError(t, err)
+	for _, swap := range swaps {
+		status, err := mgr.SwapStatus(ctx, swap.What)
+		require.NoError(t, err)
+		require.Equal(t, swap.What, status.What)
+		if status.ActiveState == "active" {
+			require.NotZero(t, status.Size)
+		}
+	}
+}
+
+func Test_E2E_Manager_WaitForDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// The loopback interface is always plugged.
+	const device = "/sys/subsystem/net/devices/lo"
+	require.NoError(t, mgr.WaitForDevice(ctx, device))
+	dev, err := mgr.DeviceStatus(ctx, device)
+	require.NoError(t, err)
+	require.Equal(t, "active", dev.ActiveState)
+	require.Equal(t, "/sys/devices/virtual/net/lo", dev.SysFSPath)
+
+	// Waiting for a device which never shows up fails once ctx is done.
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Second)
+	defer waitCancel()
+	var lastStatus *LastStatusError
+	require.ErrorAs(t, mgr.WaitForDevice(waitCtx, "/dev/disk/by-label/systemdmanager-missing"), &lastStatus)
+}
+
+func Test_E2E_Manager_FileDescriptorStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+
+	// The fixture doesn't store file descriptors.
+	store, err := mgr.FileDescriptorStore(ctx, unitDummy)
+	require.NoError(t, err)
+	require.Zero(t, store.Max)
+	require.Zero(t, store.Count)
+
+	fds, err := mgr.DumpFileDescriptorStore(ctx, unitDummy)
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("systemd can't dump file descriptor stores")
+	}
+	require.NoError(t, err)
+	require.Empty(t, fds)
+}
+
+func Test_E2E_Manager_SocketStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixtures.
+	units := []string{"dummy", "dummy.socket"}
+	require.NoError(t, fixtures.InstallUnits(ctx, units))
+	// By the time of uninstall, ctx may be cancelled.
+	defer func() {
+		require.NoError(t, fixtures.UninstallUnits(t.Context(), units))
+	}()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start(ctx, "dummy.socket"))
+	defer func() {
+		_ = mgr.Stop(t.Context(), "dummy.socket")
+	}()
+
+	// The fixture doesn't accept connections itself, so nothing is counted.
+	stats, err := mgr.SocketStats(ctx, "dummy.socket")
+	require.NoError(t, err)
+	require.Equal(t, UnitSocketStats{}, stats)
+}
+
+func Test_E2E_Manager_Coredumps(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitDummy))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitDummy)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+	since := time.Now()
+	require.NoError(t, mgr.Start(ctx, unitDummy))
+
+	// The fixture doesn't crash.
+	dumps, err := mgr.Coredumps(ctx, unitDummy, since)
+	require.NoError(t, err)
+	require.Empty(t, dumps)
 }
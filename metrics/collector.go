@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// namespace prefixes the names of all metrics.
+const namespace = "systemd"
+
+// defaultTimeout bounds how long collecting metrics may take by default.
+const defaultTimeout = 10 * time.Second
+
+// activeStates are the active states reported by the unit_state metric.
+var activeStates = []string{"active", "activating", "deactivating", "inactive", "failed", "reloading"}
+
+// Options configures a Collector.
+type Options struct {
+	// Units are the names of the units to export metrics of.
+	Units []string
+	// Patterns are globs matched against the names of loaded units to
+	// export metrics of, e.g. "app-*.service".
+	Patterns []string
+	// Timeout bounds how long collecting metrics may take. Defaults to ten
+	// seconds.
+	Timeout time.Duration
+}
+
+// Collector is a prometheus.Collector exporting metrics of units:
+//
+//   - systemd_unit_state: whether a unit is in each active state.
+//   - systemd_unit_uptime_seconds: how long a unit has been running.
+//   - systemd_unit_restarts_total: how many times a service was
+//     automatically restarted.
+//   - systemd_unit_memory_bytes: the memory used by a service, if memory
+//     accounting is on.
+//   - systemd_socket_accepted_connections_total,
+//     systemd_socket_current_connections, and
+//     systemd_socket_refused_connections_total: the connections of a socket.
+//
+// Units which aren't loaded are skipped, as are metrics of a unit which
+// fail to be retrieved.
+type Collector struct {
+	mgr  systemdmanager.Manager
+	opts Options
+
+	state    *prometheus.Desc
+	uptime   *prometheus.Desc
+	restarts *prometheus.Desc
+	memory   *prometheus.Desc
+
+	socketAccepted    *prometheus.Desc
+	socketConnections *prometheus.Desc
+	socketRefused     *prometheus.Desc
+}
+
+// Assert Collector fulfills the prometheus.Collector interface.
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector of the metrics of units selected by
+// opts, retrieved through mgr.
+func NewCollector(mgr systemdmanager.Manager, opts Options) *Collector {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	return &Collector{
+		mgr:  mgr,
+		opts: opts,
+		state: prometheus.NewDesc(prometheus.BuildFQName(namespace, "unit", "state"),
+			"Whether the unit is in the active state.", []string{"unit", "state"}, nil),
+		uptime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "unit", "uptime_seconds"),
+			"Seconds since the unit started, zero if it isn't running.", []string{"unit"}, nil),
+		restarts: prometheus.NewDesc(prometheus.BuildFQName(namespace, "unit", "restarts_total"),
+			"Automatic restarts of the service.", []string{"unit"}, nil),
+		memory: prometheus.NewDesc(prometheus.BuildFQName(namespace, "unit", "memory_bytes"),
+			"Memory used by the service.", []string{"unit"}, nil),
+		socketAccepted: prometheus.NewDesc(prometheus.BuildFQName(namespace, "socket", "accepted_connections_total"),
+			"Connections accepted by the socket.", []string{"unit"}, nil),
+		socketConnections: prometheus.NewDesc(prometheus.BuildFQName(namespace, "socket", "current_connections"),
+			"Open connections accepted by the socket.", []string{"unit"}, nil),
+		socketRefused: prometheus.NewDesc(prometheus.BuildFQName(namespace, "socket", "refused_connections_total"),
+			"Connections refused by the socket.", []string{"unit"}, nil),
+	}
+}
+
+// Describe sends the descriptors of the metrics to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.state
+	ch <- c.uptime
+	ch <- c.restarts
+	ch <- c.memory
+	ch <- c.socketAccepted
+	ch <- c.socketConnections
+	ch <- c.socketRefused
+}
+
+// Collect sends the metrics of the units to ch. Failing to list the units
+// is reported as an invalid metric, failing the scrape.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+
+	statuses, err := c.statuses(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.state, err)
+		return
+	}
+	for _, status := range statuses {
+		c.collectUnit(ctx, ch, status)
+	}
+}
+
+// statuses returns the statuses of the loaded units, sorted by name.
+func (c *Collector) statuses(ctx context.Context) ([]dbus.UnitStatus, error) {
+	byName := make(map[string]dbus.UnitStatus)
+	if len(c.opts.Units) > 0 {
+		statuses, err := c.mgr.StatusAll(ctx, c.opts.Units)
+		if err != nil {
+			return nil, err
+		}
+		for name, status := range statuses {
+			byName[name] = *status
+		}
+	}
+	if len(c.opts.Patterns) > 0 {
+		err := c.mgr.EachUnit(ctx, systemdmanager.UnitFilter{Patterns: c.opts.Patterns}, func(status dbus.UnitStatus) error {
+			byName[status.Name] = status
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]dbus.UnitStatus, 0, len(byName))
+	for _, status := range byName {
+		if status.LoadState != "not-found" {
+			statuses = append(statuses, status)
+		}
+	}
+	slices.SortFunc(statuses, func(a, b dbus.UnitStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses, nil
+}
+
+// collectUnit sends the metrics of a unit to ch.
+func (c *Collector) collectUnit(ctx context.Context, ch chan<- prometheus.Metric, status dbus.UnitStatus) {
+	for _, state := range activeStates {
+		var v float64
+		if status.ActiveState == state {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, v, status.Name, state)
+	}
+
+	if uptime, err := c.mgr.Uptime(ctx, status.Name); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.uptime, prometheus.GaugeValue, uptime.Seconds(), status.Name)
+	}
+
+	if path.Ext(status.Name) == ".socket" {
+		if stats, err := c.mgr.SocketStats(ctx, status.Name); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.socketAccepted, prometheus.CounterValue, float64(stats.Accepted), status.Name)
+			ch <- prometheus.MustNewConstMetric(c.socketConnections, prometheus.GaugeValue, float64(stats.Connections), status.Name)
+			ch <- prometheus.MustNewConstMetric(c.socketRefused, prometheus.CounterValue, float64(stats.Refused), status.Name)
+		}
+	}
+
+	// Restarts and memory are only tracked for services.
+	if path.Ext(status.Name) != ".service" {
+		return
+	}
+	if restarts, err := systemdmanager.GetProperty[uint32](ctx, c.mgr, status.Name, "Service", "NRestarts"); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.restarts, prometheus.CounterValue, float64(restarts), status.Name)
+	}
+	// Memory is unset unless accounted for.
+	if memory, err := systemdmanager.GetProperty[uint64](ctx, c.mgr, status.Name, "Service", "MemoryCurrent"); err == nil && memory != math.MaxUint64 {
+		ch <- prometheus.MustNewConstMetric(c.memory, prometheus.GaugeValue, float64(memory), status.Name)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pires/go-systemdmanager"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Collector(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	mgr, err := systemdmanager.New(ctx)
+	require.NoError(t, err)
+
+	// A state per active state, and the uptime.
+	c := NewCollector(mgr, Options{Units: []string{"multi-user.target"}})
+	require.Equal(t, len(activeStates)+1, testutil.CollectAndCount(c))
+}
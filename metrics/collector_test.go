@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeManager reports fixed unit statuses, uptimes, and socket stats.
+type fakeManager struct {
+	systemdmanager.Manager
+
+	statuses    []dbus.UnitStatus
+	uptime      time.Duration
+	socketStats systemdmanager.UnitSocketStats
+	err         error
+}
+
+func (f *fakeManager) StatusAll(_ context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	statuses := make(map[string]*dbus.UnitStatus)
+	for _, unit := range units {
+		statuses[unit] = &dbus.UnitStatus{Name: unit, LoadState: "not-found", ActiveState: "inactive"}
+		for i := range f.statuses {
+			if f.statuses[i].Name == unit {
+				statuses[unit] = &f.statuses[i]
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+func (f *fakeManager) EachUnit(_ context.Context, _ systemdmanager.UnitFilter, fn func(dbus.UnitStatus) error) error {
+	for _, status := range f.statuses {
+		if err := fn(status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeManager) SocketStats(_ context.Context, _ string) (systemdmanager.UnitSocketStats, error) {
+	return f.socketStats, nil
+}
+
+func (f *fakeManager) Uptime(_ context.Context, _ string) (time.Duration, error) {
+	return f.uptime, nil
+}
+
+func Test_Unit_Collector(t *testing.T) {
+	mgr := &fakeManager{
+		statuses: []dbus.UnitStatus{
+			{Name: "b.service", LoadState: "loaded", ActiveState: "failed"},
+			{Name: "a.timer", LoadState: "loaded", ActiveState: "active"},
+		},
+		uptime: 90 * time.Second,
+	}
+	c := NewCollector(mgr, Options{Units: []string{"a.timer", "missing.service"}, Patterns: []string{"*.service"}})
+
+	// Units which aren't loaded are skipped, and properties the manager
+	// doesn't support aren't exported.
+	expected := `
+# HELP systemd_unit_state Whether the unit is in the active state.
+# TYPE systemd_unit_state gauge
+systemd_unit_state{state="activating",unit="a.timer"} 0
+systemd_unit_state{state="active",unit="a.timer"} 1
+systemd_unit_state{state="deactivating",unit="a.timer"} 0
+systemd_unit_state{state="failed",unit="a.timer"} 0
+systemd_unit_state{state="inactive",unit="a.timer"} 0
+systemd_unit_state{state="reloading",unit="a.timer"} 0
+systemd_unit_state{state="activating",unit="b.service"} 0
+systemd_unit_state{state="active",unit="b.service"} 0
+systemd_unit_state{state="deactivating",unit="b.service"} 0
+systemd_unit_state{state="failed",unit="b.service"} 1
+systemd_unit_state{state="inactive",unit="b.service"} 0
+systemd_unit_state{state="reloading",unit="b.service"} 0
+# HELP systemd_unit_uptime_seconds Seconds since the unit started, zero if it isn't running.
+# TYPE systemd_unit_uptime_seconds gauge
+systemd_unit_uptime_seconds{unit="a.timer"} 90
+systemd_unit_uptime_seconds{unit="b.service"} 90
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
+
+func Test_Unit_Collector_Error(t *testing.T) {
+	c := NewCollector(&fakeManager{err: errors.New("boom")}, Options{Units: []string{"a.service"}})
+	require.ErrorContains(t, testutil.CollectAndCompare(c, strings.NewReader("")), "boom")
+}
+
+func Test_Unit_Collector_Socket(t *testing.T) {
+	mgr := &fakeManager{
+		statuses:    []dbus.UnitStatus{{Name: "a.socket", LoadState: "loaded", ActiveState: "active"}},
+		socketStats: systemdmanager.UnitSocketStats{Accepted: 42, Connections: 3, Refused: 1},
+	}
+	c := NewCollector(mgr, Options{Patterns: []string{"*.socket"}})
+
+	expected := `
+# HELP systemd_socket_accepted_connections_total Connections accepted by the socket.
+# TYPE systemd_socket_accepted_connections_total counter
+systemd_socket_accepted_connections_total{unit="a.socket"} 42
+# HELP systemd_socket_current_connections Open connections accepted by the socket.
+# TYPE systemd_socket_current_connections gauge
+systemd_socket_current_connections{unit="a.socket"} 3
+# HELP systemd_socket_refused_connections_total Connections refused by the socket.
+# TYPE systemd_socket_refused_connections_total counter
+systemd_socket_refused_connections_total{unit="a.socket"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"systemd_socket_accepted_connections_total", "systemd_socket_current_connections", "systemd_socket_refused_connections_total"))
+}
@@ -0,0 +1,3 @@
+// Package metrics exports the state and resource usage of systemd units as
+// Prometheus metrics, like a node exporter for services.
+package metrics
@@ -0,0 +1,263 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitMount is the state of a mount unit.
+type UnitMount struct {
+	// Unit is the name of the mount unit, e.g. "var-lib-data.mount".
+	Unit string `json:"unit" yaml:"unit"`
+	// What is the device, or remote file system, mounted.
+	What    string `json:"what" yaml:"what"`
+	Where   string `json:"where" yaml:"where"`
+	Type    string `json:"type" yaml:"type"`
+	Options string `json:"options" yaml:"options"`
+	// ActiveState is "active" while mounted.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	// Result is "success", or why the mount failed, e.g. "exit-code".
+	Result string `json:"result" yaml:"result"`
+}
+
+// UnitAutomount is the state of an automount unit.
+type UnitAutomount struct {
+	// Unit is the name of the automount unit, e.g. "var-lib-data.automount".
+	Unit  string `json:"unit" yaml:"unit"`
+	Where string `json:"where" yaml:"where"`
+	// TimeoutIdle is how long the file system may stay unused before it's
+	// unmounted. Zero means never.
+	TimeoutIdle time.Duration `json:"timeout_idle" yaml:"timeout_idle"`
+	// ActiveState is "active" while the mount point is watched.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	Result      string `json:"result" yaml:"result"`
+}
+
+// MountUnitName returns the name of the mount unit of the mount point where,
+// e.g. "var-lib-data.mount" for "/var/lib/data".
+func MountUnitName(where string) string {
+	return EscapePath(where) + ".mount"
+}
+
+// AutomountUnitName returns the name of the automount unit of the mount
+// point where, e.g. "var-lib-data.automount" for "/var/lib/data".
+func AutomountUnitName(where string) string {
+	return EscapePath(where) + ".automount"
+}
+
+// MountTransient mounts what on where through a transient mount unit, so
+// that systemd supervises the mount, orders it against other units, and
+// unmounts it on shutdown. fstype and options are as for mount(8), and may
+// be empty. It returns the name of the mount unit, which only exists until
+// unmounted, e.g. with Unmount.
+func (m *manager) MountTransient(parentCtx context.Context, what, where, fstype, options string, opts ...CallOption) (string, error) {
+	unit := MountUnitName(where)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "MountTransient")
+	span.SetAttributes(
+		otelattr.String("unit", unit),
+		otelattr.String("what", what),
+		otelattr.String("where", where),
+		otelattr.String("fstype", fstype),
+	)
+	defer span.End()
+
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	props, err := mountProperties(what, where, fstype, options)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	err = m.runJob(ctx, OperationStart, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartTransientUnitContext(ctx, unit, string(o.jobMode), props, resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully mounted %q on %q", what, where))
+
+	return unit, nil
+}
+
+// mountProperties returns the D-Bus properties of a transient mount unit.
+func mountProperties(what, where, fstype, options string) ([]dbus.Property, error) {
+	if what == "" {
+		return nil, fmt.Errorf("failed to mount on %q: nothing to mount", where)
+	}
+	if !path.IsAbs(where) {
+		return nil, fmt.Errorf("failed to mount %q on %q: mount point must be absolute", what, where)
+	}
+
+	props := []dbus.Property{
+		dbus.PropDescription("Mount of " + path.Clean(where)),
+		{Name: "What", Value: godbus.MakeVariant(what)},
+		{Name: "Where", Value: godbus.MakeVariant(path.Clean(where))},
+	}
+	if fstype != "" {
+		props = append(props, dbus.Property{Name: "Type", Value: godbus.MakeVariant(fstype)})
+	}
+	if options != "" {
+		props = append(props, dbus.Property{Name: "Options", Value: godbus.MakeVariant(options)})
+	}
+
+	return props, nil
+}
+
+// Unmount synchronously unmounts the file system mounted on where, by
+// stopping its mount unit. Transient mount units are then gone.
+func (m *manager) Unmount(parentCtx context.Context, where string, opts ...CallOption) error {
+	unit := MountUnitName(where)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Unmount")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("where", where))
+	defer span.End()
+
+	if err := m.Stop(ctx, unit, opts...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully unmounted %q", where))
+
+	return nil
+}
+
+// MountStatus returns the state of the mount unit of the mount point where,
+// whether it was created with MountTransient, from /etc/fstab, or by a unit
+// file.
+func (m *manager) MountStatus(parentCtx context.Context, where string) (UnitMount, error) {
+	unit := MountUnitName(where)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "MountStatus")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("where", where))
+	defer span.End()
+
+	mount, err := m.mountStatus(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitMount{}, err
+	}
+	span.SetAttributes(otelattr.String("active_state", mount.ActiveState))
+	span.SetStatus(otelcodes.Ok, "retrieved mount status")
+
+	return mount, nil
+}
+
+// mountStatus implements MountStatus.
+func (m *manager) mountStatus(ctx context.Context, unit string) (UnitMount, error) {
+	props, err := m.unitProperties(ctx, unit, "Mount")
+	if err != nil {
+		return UnitMount{}, err
+	}
+	mount, err := decodeMount(props)
+	if err != nil {
+		return UnitMount{}, fmt.Errorf("failed to decode mount properties of unit %q: %w", unit, err)
+	}
+	mount.Unit = unit
+	mount.ActiveState, err = m.activeState(ctx, unit)
+	if err != nil {
+		return UnitMount{}, fmt.Errorf("failed to retrieve state of unit %q: %w", unit, err)
+	}
+
+	return mount, nil
+}
+
+// decodeMount decodes the properties of a mount unit.
+func decodeMount(props map[string]godbus.Variant) (UnitMount, error) {
+	var (
+		mount UnitMount
+		errs  = make([]error, 5)
+	)
+	mount.What, errs[0] = decodeProperty[string](props["What"])
+	mount.Where, errs[1] = decodeProperty[string](props["Where"])
+	mount.Type, errs[2] = decodeProperty[string](props["Type"])
+	mount.Options, errs[3] = decodeProperty[string](props["Options"])
+	mount.Result, errs[4] = decodeProperty[string](props["Result"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitMount{}, err
+		}
+	}
+
+	return mount, nil
+}
+
+// AutomountStatus returns the state of the automount unit of the mount
+// point where, which mounts the file system on first access.
+func (m *manager) AutomountStatus(parentCtx context.Context, where string) (UnitAutomount, error) {
+	unit := AutomountUnitName(where)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "AutomountStatus")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("where", where))
+	defer span.End()
+
+	automount, err := m.automountStatus(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitAutomount{}, err
+	}
+	span.SetAttributes(otelattr.String("active_state", automount.ActiveState))
+	span.SetStatus(otelcodes.Ok, "retrieved automount status")
+
+	return automount, nil
+}
+
+// automountStatus implements AutomountStatus.
+func (m *manager) automountStatus(ctx context.Context, unit string) (UnitAutomount, error) {
+	props, err := m.unitProperties(ctx, unit, "Automount")
+	if err != nil {
+		return UnitAutomount{}, err
+	}
+	automount, err := decodeAutomount(props)
+	if err != nil {
+		return UnitAutomount{}, fmt.Errorf("failed to decode automount properties of unit %q: %w", unit, err)
+	}
+	automount.Unit = unit
+	automount.ActiveState, err = m.activeState(ctx, unit)
+	if err != nil {
+		return UnitAutomount{}, fmt.Errorf("failed to retrieve state of unit %q: %w", unit, err)
+	}
+
+	return automount, nil
+}
+
+// decodeAutomount decodes the properties of an automount unit.
+func decodeAutomount(props map[string]godbus.Variant) (UnitAutomount, error) {
+	var (
+		automount UnitAutomount
+		errs      = make([]error, 3)
+	)
+	automount.Where, errs[0] = decodeProperty[string](props["Where"])
+	automount.TimeoutIdle, errs[1] = decodeProperty[time.Duration](props["TimeoutIdleUSec"])
+	automount.Result, errs[2] = decodeProperty[string](props["Result"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitAutomount{}, err
+		}
+	}
+
+	return automount, nil
+}
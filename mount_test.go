@@ -0,0 +1,79 @@
+package systemdmanager
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_MountUnitName(t *testing.T) {
+	require.Equal(t, "var-lib-data.mount", MountUnitName("/var/lib/data"))
+	require.Equal(t, "var-lib-data.mount", MountUnitName("/var/lib/data/"))
+	require.Equal(t, "-.mount", MountUnitName("/"))
+	require.Equal(t, `mnt-my\x2ddisk.automount`, AutomountUnitName("/mnt/my-disk"))
+}
+
+func Test_Unit_mountProperties(t *testing.T) {
+	props, err := mountProperties("/dev/sdb1", "/mnt/data/", "ext4", "noatime,ro")
+	require.NoError(t, err)
+	require.Equal(t, []dbus.Property{
+		dbus.PropDescription("Mount of /mnt/data"),
+		{Name: "What", Value: godbus.MakeVariant("/dev/sdb1")},
+		{Name: "Where", Value: godbus.MakeVariant("/mnt/data")},
+		{Name: "Type", Value: godbus.MakeVariant("ext4")},
+		{Name: "Options", Value: godbus.MakeVariant("noatime,ro")},
+	}, props)
+
+	// The type and options may be left to mount(8).
+	props, err = mountProperties("/dev/sdb1", "/mnt/data", "", "")
+	require.NoError(t, err)
+	require.Len(t, props, 3)
+
+	_, err = mountProperties("", "/mnt/data", "ext4", "")
+	require.Error(t, err)
+	_, err = mountProperties("/dev/sdb1", "mnt/data", "ext4", "")
+	require.Error(t, err)
+}
+
+func Test_Unit_decodeMount(t *testing.T) {
+	mount, err := decodeMount(map[string]godbus.Variant{
+		"What":    godbus.MakeVariant("tmpfs"),
+		"Where":   godbus.MakeVariant("/mnt/scratch"),
+		"Type":    godbus.MakeVariant("tmpfs"),
+		"Options": godbus.MakeVariant("rw,size=1m"),
+		"Result":  godbus.MakeVariant("success"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitMount{What: "tmpfs", Where: "/mnt/scratch", Type: "tmpfs", Options: "rw,size=1m", Result: "success"}, mount)
+
+	_, err = decodeMount(map[string]godbus.Variant{"What": godbus.MakeVariant("tmpfs")})
+	require.Error(t, err)
+}
+
+func Test_Unit_decodeAutomount(t *testing.T) {
+	automount, err := decodeAutomount(map[string]godbus.Variant{
+		"Where":           godbus.MakeVariant("/mnt/nfs"),
+		"TimeoutIdleUSec": godbus.MakeVariant(uint64(5 * time.Minute.Microseconds())),
+		"Result":          godbus.MakeVariant("success"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitAutomount{Where: "/mnt/nfs", TimeoutIdle: 5 * time.Minute, Result: "success"}, automount)
+
+	automount, err = decodeAutomount(map[string]godbus.Variant{
+		"Where":           godbus.MakeVariant("/mnt/nfs"),
+		"TimeoutIdleUSec": godbus.MakeVariant(uint64(math.MaxUint64)),
+		"Result":          godbus.MakeVariant("success"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(math.MaxInt64), automount.TimeoutIdle)
+}
+
+func Test_Unit_MountTransient_Invalid(t *testing.T) {
+	_, err := (&manager{}).MountTransient(context.Background(), "tmpfs", "relative", "tmpfs", "")
+	require.Error(t, err)
+}
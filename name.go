@@ -0,0 +1,197 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidUnitName means a unit name doesn't follow systemd naming rules.
+var ErrInvalidUnitName = errors.New("invalid unit name")
+
+// ErrWrongUnitType means an operation or property doesn't apply to the type
+// of a unit, such as reading service properties of a timer.
+var ErrWrongUnitType = errors.New("wrong unit type")
+
+// unitNameMax is the maximum length of a unit name.
+const unitNameMax = 255
+
+// unitTypes are the valid unit name suffixes.
+var unitTypes = []string{
+	"service", "socket", "target", "device", "mount", "automount",
+	"swap", "timer", "path", "slice", "scope",
+}
+
+// isUnitNameChar returns whether c may appear unescaped in a unit name.
+func isUnitNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == ':' || c == '-' || c == '_' || c == '.' || c == '\\'
+}
+
+// ValidateUnitName ensures name is a valid unit, template, or instance name,
+// such as "foo.service", "foo@.service", or "foo@bar.service".
+func ValidateUnitName(name string) error {
+	if name == "" || len(name) > unitNameMax {
+		return fmt.Errorf("%w %q: length must be between 1 and %d", ErrInvalidUnitName, name, unitNameMax)
+	}
+
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return fmt.Errorf("%w %q: missing unit type suffix", ErrInvalidUnitName, name)
+	}
+	unitType := name[dot+1:]
+	valid := false
+	for _, t := range unitTypes {
+		valid = valid || t == unitType
+	}
+	if !valid {
+		return fmt.Errorf("%w %q: unknown unit type %q", ErrInvalidUnitName, name, unitType)
+	}
+
+	prefix, instance, templated := strings.Cut(name[:dot], "@")
+	if prefix == "" {
+		return fmt.Errorf("%w %q: empty prefix", ErrInvalidUnitName, name)
+	}
+	for i := range len(prefix) {
+		if !isUnitNameChar(prefix[i]) {
+			return fmt.Errorf("%w %q: invalid character %q", ErrInvalidUnitName, name, prefix[i])
+		}
+	}
+	if templated {
+		for i := range len(instance) {
+			if !isUnitNameChar(instance[i]) && instance[i] != '@' {
+				return fmt.Errorf("%w %q: invalid character %q in instance", ErrInvalidUnitName, name, instance[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// EscapeUnitName escapes s for use in a unit name, the same way as
+// systemd-escape does.
+func EscapeUnitName(s string) string {
+	var b strings.Builder
+	for i := range len(s) {
+		c := s[i]
+		switch {
+		case c == '/':
+			b.WriteByte('-')
+		case c == '.' && i == 0, c == '-', c == '\\', !isUnitNameChar(c):
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// EscapePath escapes a file system path for use in a unit name, the same way
+// as systemd-escape --path does. For example "/var/lib/foo" becomes
+// "var-lib-foo".
+func EscapePath(path string) string {
+	// Simplify the path the way systemd does.
+	var parts []string
+	for _, p := range strings.Split(path, "/") {
+		if p != "" && p != "." {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+
+	return EscapeUnitName(strings.Join(parts, "/"))
+}
+
+// UnescapeUnitName reverses EscapeUnitName.
+func UnescapeUnitName(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '-':
+			b.WriteByte('/')
+		case c == '\\':
+			if i+4 > len(s) || s[i+1] != 'x' {
+				return "", fmt.Errorf("invalid escape sequence at offset %d in %q", i, s)
+			}
+			v, err := strconv.ParseUint(s[i+2:i+4], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence at offset %d in %q: %w", i, s, err)
+			}
+			b.WriteByte(byte(v))
+			i += 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// UnescapePath reverses EscapePath, returning an absolute path.
+func UnescapePath(s string) (string, error) {
+	if s == "-" {
+		return "/", nil
+	}
+	p, err := UnescapeUnitName(s)
+	if err != nil {
+		return "", err
+	}
+
+	return "/" + p, nil
+}
+
+// InstanceName returns the name of the instance of a template unit, such as
+// "foo@bar.service" for template "foo@.service" and instance "bar". The
+// instance is escaped with EscapeUnitName.
+func InstanceName(template, instance string) (string, error) {
+	if !IsTemplate(template) {
+		return "", fmt.Errorf("%w %q: not a template", ErrInvalidUnitName, template)
+	}
+	prefix, suffix, _ := strings.Cut(template, "@")
+	name := prefix + "@" + EscapeUnitName(instance) + suffix
+	if err := ValidateUnitName(name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// unitType returns the type of the named unit, such as "service" for
+// "foo.service".
+func unitType(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// unitTypeInterface returns the D-Bus interface, relative to systemd's, of
+// the properties specific to the type of the named unit, such as "Service"
+// for "foo.service".
+func unitTypeInterface(name string) string {
+	t := unitType(name)
+
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// checkUnitType ensures the properties on a D-Bus interface, given in full,
+// apply to the named unit. Interfaces specific to a unit type, such as
+// "org.freedesktop.systemd1.Service", only apply to units of that type.
+func checkUnitType(unit, iface string) error {
+	ifaceType := strings.ToLower(iface[strings.LastIndexByte(iface, '.')+1:])
+	if !slices.Contains(unitTypes, ifaceType) || ifaceType == unitType(unit) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: unit %q is a %s, not a %s", ErrWrongUnitType, unit, unitType(unit), ifaceType)
+}
+
+// IsTemplate returns whether name is a template unit, such as
+// "foo@.service", rather than an instance of one, such as "foo@bar.service".
+func IsTemplate(name string) bool {
+	_, suffix, ok := strings.Cut(name, "@")
+
+	return ok && strings.HasPrefix(suffix, ".")
+}
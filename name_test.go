@@ -0,0 +1,115 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_ValidateUnitName(t *testing.T) {
+	valid := []string{
+		"foo.service",
+		"foo@.service",
+		"foo@bar.service",
+		"foo@bar@baz.service",
+		"dev-sda1.device",
+		`var-lib-foo\x2dbar.mount`,
+		"a:b_c.timer",
+	}
+	for _, name := range valid {
+		require.NoError(t, ValidateUnitName(name), name)
+	}
+
+	invalid := []string{
+		"",
+		"foo",
+		"foo.bar",
+		".service",
+		"@foo.service",
+		"foo bar.service",
+		"foo/bar.service",
+		"foo@b r.service",
+		string(make([]byte, 256)) + ".service",
+	}
+	for _, name := range invalid {
+		require.ErrorIs(t, ValidateUnitName(name), ErrInvalidUnitName, name)
+	}
+}
+
+func Test_Unit_EscapeUnitName(t *testing.T) {
+	tests := []struct {
+		s       string
+		escaped string
+	}{
+		{s: "foo", escaped: "foo"},
+		{s: "foo/bar", escaped: "foo-bar"},
+		{s: "foo-bar", escaped: `foo\x2dbar`},
+		{s: "foo bar", escaped: `foo\x20bar`},
+		{s: ".hidden", escaped: `\x2ehidden`},
+		{s: "a.b", escaped: "a.b"},
+		{s: `back\slash`, escaped: `back\x5cslash`},
+		{s: "héllo", escaped: `h\xc3\xa9llo`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			require.Equal(t, tt.escaped, EscapeUnitName(tt.s))
+			s, err := UnescapeUnitName(tt.escaped)
+			require.NoError(t, err)
+			require.Equal(t, tt.s, s)
+		})
+	}
+
+	_, err := UnescapeUnitName(`foo\x2`)
+	require.Error(t, err)
+	_, err = UnescapeUnitName(`foo\y20`)
+	require.Error(t, err)
+}
+
+func Test_Unit_EscapePath(t *testing.T) {
+	require.Equal(t, "-", EscapePath("/"))
+	require.Equal(t, "var-lib-foo", EscapePath("/var/lib/foo"))
+	require.Equal(t, "var-lib-foo", EscapePath("//var/./lib/foo/"))
+	require.Equal(t, `mnt-my\x2ddisk`, EscapePath("/mnt/my-disk"))
+
+	p, err := UnescapePath("var-lib-foo")
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/foo", p)
+	p, err = UnescapePath("-")
+	require.NoError(t, err)
+	require.Equal(t, "/", p)
+}
+
+func Test_Unit_InstanceName(t *testing.T) {
+	name, err := InstanceName("foo@.service", "bar")
+	require.NoError(t, err)
+	require.Equal(t, "foo@bar.service", name)
+
+	name, err = InstanceName("getty@.service", "tty/1")
+	require.NoError(t, err)
+	require.Equal(t, "getty@tty-1.service", name)
+
+	_, err = InstanceName("foo.service", "bar")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
+
+func Test_Unit_IsTemplate(t *testing.T) {
+	require.True(t, IsTemplate("foo@.service"))
+	require.False(t, IsTemplate("foo@bar.service"))
+	require.False(t, IsTemplate("foo.service"))
+	// As with systemd, the instance starts at the first "@".
+	require.False(t, IsTemplate("foo@bar@.service"))
+}
+
+func Test_Unit_checkUnitType(t *testing.T) {
+	require.NoError(t, checkUnitType("a.service", "org.freedesktop.systemd1.Service"))
+	require.NoError(t, checkUnitType("a.timer", "org.freedesktop.systemd1.Timer"))
+	// Unit properties apply to all units.
+	require.NoError(t, checkUnitType("a.timer", "org.freedesktop.systemd1.Unit"))
+	require.ErrorIs(t, checkUnitType("a.timer", "org.freedesktop.systemd1.Service"), ErrWrongUnitType)
+	require.ErrorIs(t, checkUnitType("a.target", "org.freedesktop.systemd1.Socket"), ErrWrongUnitType)
+}
+
+func Test_Unit_unitTypeInterface(t *testing.T) {
+	require.Equal(t, "Service", unitTypeInterface("a.service"))
+	require.Equal(t, "Automount", unitTypeInterface("a-b.automount"))
+}
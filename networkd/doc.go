@@ -0,0 +1,7 @@
+// Package networkd reports the state of network links managed by
+// systemd-networkd, as shown by networkctl, and waits for the network to be
+// online.
+package networkd
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/networkd"
@@ -0,0 +1,31 @@
+//go:build linux
+
+package networkd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_Links(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	_, err = mgr.Status(ctx)
+	require.NoError(t, err)
+	links, err := mgr.ListLinks(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, links)
+
+	// Missing links are never online.
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Second)
+	defer waitCancel()
+	require.ErrorIs(t, mgr.WaitOnline(waitCtx, "systemdmanager-e2e-missing"), context.DeadlineExceeded)
+}
@@ -0,0 +1,328 @@
+package networkd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// networkdDest is the D-Bus destination of networkd.
+	networkdDest = "org.freedesktop.network1"
+	// networkdPath is the D-Bus object path of networkd.
+	networkdPath godbus.ObjectPath = "/org/freedesktop/network1"
+	// managerInterface is the D-Bus interface of networkd.
+	managerInterface = "org.freedesktop.network1.Manager"
+	// linkInterface is the D-Bus interface of links.
+	linkInterface = "org.freedesktop.network1.Link"
+)
+
+// onlinePollInterval is how often the online state is checked while waiting
+// for the network.
+const onlinePollInterval = 250 * time.Millisecond
+
+// Online states.
+const (
+	OnlineStateOnline  = "online"
+	OnlineStatePartial = "partial"
+	OnlineStateOffline = "offline"
+)
+
+// State is the state of the network, or of a link. See networkctl(1) for
+// the possible values.
+type State struct {
+	// OperationalState is e.g. "off", "no-carrier", "carrier", "degraded",
+	// or "routable".
+	OperationalState string `json:"operational_state" yaml:"operational_state"`
+	CarrierState     string `json:"carrier_state" yaml:"carrier_state"`
+	// AddressState is "off", "degraded", or "routable".
+	AddressState     string `json:"address_state" yaml:"address_state"`
+	IPv4AddressState string `json:"ipv4_address_state" yaml:"ipv4_address_state"`
+	IPv6AddressState string `json:"ipv6_address_state" yaml:"ipv6_address_state"`
+	// OnlineState is one of the OnlineState constants, or empty when links
+	// aren't required to be online.
+	OnlineState string `json:"online_state" yaml:"online_state"`
+}
+
+// Link is a network link.
+type Link struct {
+	Index int32  `json:"index" yaml:"index"`
+	Name  string `json:"name" yaml:"name"`
+	State `yaml:",inline"`
+	// AdministrativeState is e.g. "configured", "configuring", or
+	// "unmanaged".
+	AdministrativeState string         `json:"administrative_state" yaml:"administrative_state"`
+	Addresses           []netip.Prefix `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+}
+
+// Manager reports the state of the network.
+type Manager interface {
+	ListLinks(ctx context.Context) ([]Link, error)
+	Status(ctx context.Context) (State, error)
+	WaitOnline(ctx context.Context, links ...string) error
+}
+
+// manager reports the network state via a D-Bus connection to networkd.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to networkd failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to networkd over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, networkdDest, networkdPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up network manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// Status returns the overall state of the network.
+func (m *manager) Status(parentCtx context.Context) (State, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Status")
+	defer span.End()
+
+	props, err := m.conn.Properties(ctx, networkdPath, "")
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve network state: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return State{}, err
+	}
+	state := decodeState(props)
+	span.SetAttributes(otelattr.String("online_state", state.OnlineState))
+	span.SetStatus(otelcodes.Ok, "retrieved network state")
+
+	return state, nil
+}
+
+// ListLinks returns the network links, with their states and addresses.
+func (m *manager) ListLinks(parentCtx context.Context) ([]Link, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListLinks")
+	defer span.End()
+
+	links, err := m.listLinks(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to list links: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "listed links")
+
+	return links, nil
+}
+
+// listLinks implements ListLinks.
+func (m *manager) listLinks(ctx context.Context) ([]Link, error) {
+	var entries []linkEntry
+	if err := m.conn.Call(ctx, "ListLinks", nil, &entries); err != nil {
+		return nil, err
+	}
+
+	links := make([]Link, 0, len(entries))
+	for _, e := range entries {
+		props, err := m.conn.Properties(ctx, e.Path, linkInterface)
+		if err != nil {
+			return nil, fmt.Errorf("link %q: %w", e.Name, err)
+		}
+		var description string
+		if err := m.conn.CallObject(ctx, e.Path, linkInterface+".Describe", nil, &description); err != nil {
+			return nil, fmt.Errorf("link %q: %w", e.Name, err)
+		}
+		addrs, err := decodeAddresses(description)
+		if err != nil {
+			return nil, fmt.Errorf("link %q: %w", e.Name, err)
+		}
+
+		link := Link{
+			Index:     e.Index,
+			Name:      e.Name,
+			State:     decodeState(props),
+			Addresses: addrs,
+		}
+		link.AdministrativeState, _ = props["AdministrativeState"].Value().(string)
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// linkEntry is a link as listed by ListLinks.
+type linkEntry struct {
+	Index int32
+	Name  string
+	Path  godbus.ObjectPath
+}
+
+// decodeState converts the D-Bus state properties of networkd or a link.
+// Missing or mistyped properties are left empty.
+func decodeState(props map[string]godbus.Variant) State {
+	str := func(key string) string {
+		s, _ := props[key].Value().(string)
+		return s
+	}
+
+	return State{
+		OperationalState: str("OperationalState"),
+		CarrierState:     str("CarrierState"),
+		AddressState:     str("AddressState"),
+		IPv4AddressState: str("IPv4AddressState"),
+		IPv6AddressState: str("IPv6AddressState"),
+		OnlineState:      str("OnlineState"),
+	}
+}
+
+// linkDescription is the part of the JSON description of a link holding its
+// addresses.
+type linkDescription struct {
+	Addresses []struct {
+		// Address is an array of bytes, which encoding/json would only
+		// decode into a []byte from base64.
+		Address      []int
+		PrefixLength int
+	}
+}
+
+// decodeAddresses returns the addresses in the JSON description of a link.
+func decodeAddresses(description string) ([]netip.Prefix, error) {
+	var d linkDescription
+	if err := json.Unmarshal([]byte(description), &d); err != nil {
+		return nil, fmt.Errorf("failed to decode link description: %w", err)
+	}
+
+	var prefixes []netip.Prefix
+	for _, a := range d.Addresses {
+		b := make([]byte, 0, len(a.Address))
+		for _, n := range a.Address {
+			b = append(b, byte(n))
+		}
+		ip, ok := netip.AddrFromSlice(b)
+		if !ok {
+			return nil, fmt.Errorf("invalid address %v", a.Address)
+		}
+		p, err := ip.Prefix(a.PrefixLength)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the host address rather than the network one.
+		prefixes = append(prefixes, netip.PrefixFrom(ip, p.Bits()))
+	}
+
+	return prefixes, nil
+}
+
+// WaitOnline blocks until the named links are online or, without links,
+// until the network is online as a whole.
+func (m *manager) WaitOnline(parentCtx context.Context, links ...string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "WaitOnline")
+	span.SetAttributes(otelattr.StringSlice("links", links))
+	defer span.End()
+
+	if err := m.waitOnline(ctx, links); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, "network online")
+
+	return nil
+}
+
+// waitOnline implements WaitOnline.
+func (m *manager) waitOnline(ctx context.Context, links []string) error {
+	ticker := time.NewTicker(onlinePollInterval)
+	defer ticker.Stop()
+
+	for {
+		offline, err := m.offline(ctx, links)
+		if err != nil {
+			return err
+		}
+		if len(offline) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("network isn't online, offline %q: %w", offline, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// offline returns which of the named links aren't online, or "network" if
+// no links are named and the network isn't online.
+func (m *manager) offline(ctx context.Context, links []string) ([]string, error) {
+	if len(links) == 0 {
+		variant, err := m.conn.Property(ctx, networkdPath, "", "OnlineState")
+		if err != nil {
+			return nil, err
+		}
+		if state, _ := variant.Value().(string); state != OnlineStateOnline {
+			return []string{"network"}, nil
+		}
+
+		return nil, nil
+	}
+
+	var entries []linkEntry
+	if err := m.conn.Call(ctx, "ListLinks", nil, &entries); err != nil {
+		return nil, err
+	}
+	var offline []string
+	for _, link := range links {
+		i := slices.IndexFunc(entries, func(e linkEntry) bool {
+			return e.Name == link
+		})
+		if i < 0 {
+			offline = append(offline, link)
+			continue
+		}
+		variant, err := m.conn.Property(ctx, entries[i].Path, linkInterface, "OnlineState")
+		if err != nil {
+			return nil, err
+		}
+		if state, _ := variant.Value().(string); state != OnlineStateOnline {
+			offline = append(offline, link)
+		}
+	}
+
+	return offline, nil
+}
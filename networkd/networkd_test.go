@@ -0,0 +1,55 @@
+package networkd
+
+import (
+	"net/netip"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeState(t *testing.T) {
+	props := map[string]godbus.Variant{
+		"OperationalState": godbus.MakeVariant("routable"),
+		"CarrierState":     godbus.MakeVariant("carrier"),
+		"AddressState":     godbus.MakeVariant("routable"),
+		"IPv4AddressState": godbus.MakeVariant("routable"),
+		"IPv6AddressState": godbus.MakeVariant("degraded"),
+		"OnlineState":      godbus.MakeVariant(OnlineStateOnline),
+	}
+	require.Equal(t, State{
+		OperationalState: "routable",
+		CarrierState:     "carrier",
+		AddressState:     "routable",
+		IPv4AddressState: "routable",
+		IPv6AddressState: "degraded",
+		OnlineState:      OnlineStateOnline,
+	}, decodeState(props))
+}
+
+func Test_Unit_decodeAddresses(t *testing.T) {
+	description := `{
+		"Index": 2,
+		"Name": "eth0",
+		"Addresses": [
+			{"Family": 2, "Address": [192, 168, 1, 10], "PrefixLength": 24, "ConfigSource": "DHCPv4"},
+			{"Family": 10, "Address": [254, 128, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1], "PrefixLength": 64}
+		]
+	}`
+	addrs, err := decodeAddresses(description)
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.168.1.10/24"),
+		netip.MustParsePrefix("fe80::1/64"),
+	}, addrs)
+
+	// Links without addresses.
+	addrs, err = decodeAddresses(`{"Index": 1, "Name": "lo"}`)
+	require.NoError(t, err)
+	require.Empty(t, addrs)
+
+	_, err = decodeAddresses(`{"Addresses": [{"Address": [1, 2], "PrefixLength": 8}]}`)
+	require.Error(t, err)
+	_, err = decodeAddresses(`{"Addresses": [{"Address": [10, 0, 0, 1], "PrefixLength": 33}]}`)
+	require.Error(t, err)
+}
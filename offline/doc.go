@@ -0,0 +1,8 @@
+// Package offline enables, disables, and masks units, and manages their
+// drop-ins, by manipulating unit files and symlinks under an arbitrary root
+// directory, such as a mounted image, without a running systemd, as
+// systemctl --root does.
+package offline
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/offline"
@@ -0,0 +1,110 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// dropInPath returns where the named drop-in of a unit is installed,
+// relative to the root.
+func dropInPath(unit, dropIn string) (string, error) {
+	if dropIn == "" || dropIn == "." || dropIn == ".." || strings.ContainsRune(dropIn, '/') {
+		return "", fmt.Errorf("invalid drop-in name %q", dropIn)
+	}
+
+	return path.Join(configDirectory, unit+".d", dropIn+".conf"), nil
+}
+
+// WriteDropIn installs a drop-in named dropIn extending unit in
+// /etc/systemd/system/<unit>.d, replacing any drop-in of the same name.
+func (m *manager) WriteDropIn(parentCtx context.Context, unit, dropIn string, f *unitfile.File) error {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "WriteDropIn")
+	span.SetAttributes(
+		otelattr.String("unit", unit),
+		otelattr.String("dropIn", dropIn),
+		otelattr.String("root", m.root),
+	)
+	defer span.End()
+
+	_, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		return nil, writeDropIn(root, unit, dropIn, []byte(f.String()))
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to install drop-in %q of unit %q: %w", dropIn, unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("installed drop-in %q of unit %q", dropIn, unit))
+
+	return nil
+}
+
+// RemoveDropIn removes the drop-in named dropIn of unit, if installed.
+func (m *manager) RemoveDropIn(parentCtx context.Context, unit, dropIn string) error {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "RemoveDropIn")
+	span.SetAttributes(
+		otelattr.String("unit", unit),
+		otelattr.String("dropIn", dropIn),
+		otelattr.String("root", m.root),
+	)
+	defer span.End()
+
+	_, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		p, err := dropInPath(unit, dropIn)
+		if err != nil {
+			return nil, err
+		}
+		if err := root.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to remove drop-in %q of unit %q: %w", dropIn, unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("removed drop-in %q of unit %q", dropIn, unit))
+
+	return nil
+}
+
+// writeDropIn writes content to the drop-in atomically, so systemd never
+// reads a partial one.
+func writeDropIn(root *os.Root, unit, dropIn string, content []byte) error {
+	p, err := dropInPath(unit, dropIn)
+	if err != nil {
+		return err
+	}
+	if err := root.MkdirAll(path.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path.Join(path.Dir(p), "."+path.Base(p)+".tmp")
+	if err := root.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	if err := root.Rename(tmp, p); err != nil {
+		root.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package offline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Manager_WriteDropIn(t *testing.T) {
+	ctx := context.Background()
+	m, dir := newImage(t, nil)
+
+	f := &unitfile.File{}
+	f.Set("Service", "Environment", "FOO=bar")
+	require.NoError(t, m.WriteDropIn(ctx, "foo.service", "10-env", f))
+
+	content, err := os.ReadFile(filepath.Join(dir, "etc/systemd/system/foo.service.d/10-env.conf"))
+	require.NoError(t, err)
+	require.Equal(t, f.String(), string(content))
+	entries, err := os.ReadDir(filepath.Join(dir, "etc/systemd/system/foo.service.d"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.Error(t, m.WriteDropIn(ctx, "foo.service", "../../escape", f))
+	require.Error(t, m.WriteDropIn(ctx, "foo", "10-env", f))
+
+	require.NoError(t, m.RemoveDropIn(ctx, "foo.service", "10-env"))
+	require.NoFileExists(t, filepath.Join(dir, "etc/systemd/system/foo.service.d/10-env.conf"))
+	// Removing a missing drop-in succeeds.
+	require.NoError(t, m.RemoveDropIn(ctx, "foo.service", "10-env"))
+}
@@ -0,0 +1,339 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pires/go-systemdmanager"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Enable links a unit into the units named by the WantedBy=, RequiredBy=,
+// and UpheldBy= settings of its [Install] section, creates its Alias=
+// links, and enables the units listed by Also=. Templates are enabled with
+// their DefaultInstance=, if any. Enabling a unit without [Install] settings
+// changes nothing. It fails with ErrMasked if the unit is masked.
+func (m *manager) Enable(parentCtx context.Context, unit string) ([]Change, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Enable")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("root", m.root))
+	defer span.End()
+
+	changes, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		return enable(root, unit, map[string]bool{})
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return changes, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("made %d changes enabling unit %q", len(changes), unit))
+
+	return changes, nil
+}
+
+// Disable removes the links to a unit made by enabling it, including those
+// of its aliases and, for templates, of all its instances, and disables the
+// units listed by Also=. Masked units stay masked.
+func (m *manager) Disable(parentCtx context.Context, unit string) ([]Change, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Disable")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("root", m.root))
+	defer span.End()
+
+	changes, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		return disable(root, unit, map[string]bool{})
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return changes, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("made %d changes disabling unit %q", len(changes), unit))
+
+	return changes, nil
+}
+
+// Mask links a unit to /dev/null in /etc/systemd/system, so it can't be
+// started, even as a dependency. It fails with fs.ErrExist if a unit file
+// is installed there.
+func (m *manager) Mask(parentCtx context.Context, unit string) ([]Change, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Mask")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("root", m.root))
+	defer span.End()
+
+	changes, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		changes, err := link(root, path.Join(configDirectory, unit), devNull)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mask unit %q: %w", unit, err)
+		}
+
+		return changes, nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return changes, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("masked unit %q", unit))
+
+	return changes, nil
+}
+
+// Unmask removes the link of a unit to /dev/null in /etc/systemd/system, if
+// any.
+func (m *manager) Unmask(parentCtx context.Context, unit string) ([]Change, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "Unmask")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("root", m.root))
+	defer span.End()
+
+	changes, err := m.withRoot(unit, func(root *os.Root) ([]Change, error) {
+		p := path.Join(configDirectory, unit)
+		linked, target, err := isLink(root, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmask unit %q: %w", unit, err)
+		}
+		if !linked || target != devNull {
+			return nil, nil
+		}
+		if err := root.Remove(p); err != nil {
+			return nil, fmt.Errorf("failed to unmask unit %q: %w", unit, err)
+		}
+
+		return []Change{{Type: "unlink", Path: "/" + p}}, nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return changes, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("unmasked unit %q", unit))
+
+	return changes, nil
+}
+
+// EnablementState returns the enablement state of a unit file, which is one
+// of enabled, enabled-runtime, masked, masked-runtime, static, indirect, or
+// disabled.
+func (m *manager) EnablementState(parentCtx context.Context, unit string) (systemdmanager.UnitFileState, error) {
+	// Set-up tracing context.
+	_, span := otel.Tracer(name).Start(parentCtx, "EnablementState")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("root", m.root))
+	defer span.End()
+
+	var state systemdmanager.UnitFileState
+	_, err := m.withRoot(unit, func(root *os.Root) (_ []Change, err error) {
+		state, err = enablementState(root, unit)
+		return nil, err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return "", err
+	}
+	span.SetAttributes(otelattr.String("state", string(state)))
+	span.SetStatus(otelcodes.Ok, "retrieved unit file state")
+
+	return state, nil
+}
+
+// withRoot validates the name of unit, and calls fn with the root opened.
+func (m *manager) withRoot(unit string, fn func(root *os.Root) ([]Change, error)) ([]Change, error) {
+	if err := systemdmanager.ValidateUnitName(unit); err != nil {
+		return nil, err
+	}
+	root, err := m.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	return fn(root)
+}
+
+// defaultInstance returns the name links to a unit are made with: the unit
+// itself, the default instance of a template, or nothing for templates
+// without one.
+func defaultInstance(unit string, u *unitFile) string {
+	if !systemdmanager.IsTemplate(unit) {
+		return unit
+	}
+	instance, ok := u.file.Get("Install", "DefaultInstance")
+	if !ok || instance == "" {
+		return ""
+	}
+
+	return strings.Replace(unit, "@.", "@"+instance+".", 1)
+}
+
+// enable enables unit and the units it lists in Also=, skipping those
+// already seen.
+func enable(root *os.Root, unit string, seen map[string]bool) ([]Change, error) {
+	if seen[unit] {
+		return nil, nil
+	}
+	seen[unit] = true
+
+	u, err := findUnit(root, unit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable unit %q: %w", unit, err)
+	}
+	target := "/" + u.path
+
+	// Collect and validate the links to make before making any, as names
+	// are joined into paths.
+	var names []string
+	if instance := defaultInstance(unit, u); instance != "" {
+		for _, t := range installTargets {
+			for _, dependent := range u.install(t.key) {
+				if err := systemdmanager.ValidateUnitName(dependent); err != nil {
+					return nil, fmt.Errorf("failed to enable unit %q: %w", unit, err)
+				}
+				names = append(names, path.Join(configDirectory, dependent+t.suffix, instance))
+			}
+		}
+	}
+	for _, alias := range u.install("Alias") {
+		if err := systemdmanager.ValidateUnitName(alias); err != nil {
+			return nil, fmt.Errorf("failed to enable unit %q: %w", unit, err)
+		}
+		names = append(names, path.Join(configDirectory, alias))
+	}
+
+	var changes []Change
+	for _, p := range names {
+		c, err := link(root, p, target)
+		changes = append(changes, c...)
+		if err != nil {
+			return changes, fmt.Errorf("failed to enable unit %q: %w", unit, err)
+		}
+	}
+	for _, also := range u.install("Also") {
+		c, err := enable(root, also, seen)
+		changes = append(changes, c...)
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+// disable disables unit and the units it lists in Also=, skipping those
+// already seen. Units without a unit file are disabled too, removing
+// dangling links.
+func disable(root *os.Root, unit string, seen map[string]bool) ([]Change, error) {
+	if seen[unit] {
+		return nil, nil
+	}
+	seen[unit] = true
+
+	names := []string{unit}
+	var also []string
+	u, err := findUnit(root, unit)
+	switch {
+	case err == nil:
+		names = append(names, u.install("Alias")...)
+		also = u.install("Also")
+	case errors.Is(err, ErrUnitFileNotFound), errors.Is(err, ErrMasked):
+	default:
+		return nil, fmt.Errorf("failed to disable unit %q: %w", unit, err)
+	}
+
+	paths, err := links(root, configDirectory, func(name string) bool {
+		t, instance := template(name)
+		return slices.Contains(names, name) || systemdmanager.IsTemplate(unit) && instance && t == unit
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable unit %q: %w", unit, err)
+	}
+
+	var changes []Change
+	for _, p := range paths {
+		if err := root.Remove(p); err != nil {
+			return changes, fmt.Errorf("failed to disable unit %q: %w", unit, err)
+		}
+		changes = append(changes, Change{Type: "unlink", Path: "/" + p})
+	}
+	for _, a := range also {
+		c, err := disable(root, a, seen)
+		changes = append(changes, c...)
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+// enablementState returns the enablement state of unit.
+func enablementState(root *os.Root, unit string) (systemdmanager.UnitFileState, error) {
+	masks := []struct {
+		dir   string
+		state systemdmanager.UnitFileState
+	}{
+		{configDirectory, systemdmanager.UnitFileMasked},
+		{runtimeDirectory, systemdmanager.UnitFileMaskedRuntime},
+	}
+	for _, mask := range masks {
+		linked, target, err := isLink(root, path.Join(mask.dir, unit))
+		if err != nil {
+			return "", fmt.Errorf("failed to read state of unit %q: %w", unit, err)
+		}
+		if linked && target == devNull {
+			return mask.state, nil
+		}
+	}
+
+	u, err := findUnit(root, unit)
+	if err != nil {
+		return "", err
+	}
+
+	names := u.install("Alias")
+	if instance := defaultInstance(unit, u); instance != "" {
+		names = append(names, instance)
+	}
+	for _, dir := range []string{configDirectory, runtimeDirectory} {
+		paths, err := links(root, dir, func(name string) bool {
+			return slices.Contains(names, name)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to read state of unit %q: %w", unit, err)
+		}
+		// The unit file itself may be linked into the directory.
+		paths = slices.DeleteFunc(paths, func(p string) bool {
+			return p == path.Join(dir, unit)
+		})
+		if len(paths) > 0 {
+			if dir == runtimeDirectory {
+				return systemdmanager.UnitFileEnabledRuntime, nil
+			}
+
+			return systemdmanager.UnitFileEnabled, nil
+		}
+	}
+
+	switch {
+	case !u.installable() && len(u.install("Also")) == 0:
+		return systemdmanager.UnitFileStatic, nil
+	case !u.installable(), defaultInstance(unit, u) == "":
+		return systemdmanager.UnitFileIndirect, nil
+	default:
+		return systemdmanager.UnitFileDisabled, nil
+	}
+}
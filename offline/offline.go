@@ -0,0 +1,318 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/unitfile"
+)
+
+var (
+	// ErrUnitFileNotFound means no unit file of a unit exists under the root.
+	ErrUnitFileNotFound = errors.New("unit file not found")
+	// ErrMasked means a unit file is masked, so it can't be enabled.
+	ErrMasked = errors.New("unit file is masked")
+)
+
+const (
+	// configDirectory is where local administrators' units and links go,
+	// relative to the root.
+	configDirectory = "etc/systemd/system"
+	// runtimeDirectory is where units and links lasting until reboot go,
+	// relative to the root.
+	runtimeDirectory = "run/systemd/system"
+	// devNull is the target of links masking units.
+	devNull = "/dev/null"
+	// maxSymlinks bounds how many symlinks are followed to read a unit file.
+	maxSymlinks = 32
+)
+
+// searchPath lists where unit files are looked up, relative to the root, in
+// order of precedence.
+var searchPath = []string{
+	configDirectory,
+	runtimeDirectory,
+	"usr/local/lib/systemd/system",
+	"usr/lib/systemd/system",
+	"lib/systemd/system",
+}
+
+// Change is a file created or removed under the root.
+type Change struct {
+	// Type is "symlink", "write", or "unlink".
+	Type string `json:"type" yaml:"type"`
+	// Path is where the change was made, relative to the root but starting
+	// with a slash, as seen from within the image.
+	Path string `json:"path" yaml:"path"`
+	// Source is the target of a symlink.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// Manager manipulates unit files under a root directory.
+type Manager interface {
+	Disable(ctx context.Context, unit string) ([]Change, error)
+	Enable(ctx context.Context, unit string) ([]Change, error)
+	EnablementState(ctx context.Context, unit string) (systemdmanager.UnitFileState, error)
+	Mask(ctx context.Context, unit string) ([]Change, error)
+	RemoveDropIn(ctx context.Context, unit, dropIn string) error
+	Unmask(ctx context.Context, unit string) ([]Change, error)
+	WriteDropIn(ctx context.Context, unit, dropIn string, f *unitfile.File) error
+}
+
+// manager manages unit files under a root directory.
+type manager struct {
+	root string
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option func(*options)
+
+// options holds the settings of a Manager.
+type options struct {
+	root string
+}
+
+// WithRoot sets the directory unit files are manipulated under, such as
+// "/mnt/image". Defaults to "/".
+func WithRoot(root string) Option {
+	return func(o *options) {
+		o.root = root
+	}
+}
+
+// New returns a Manager manipulating unit files under the root, which must be
+// an existing directory. Changes aren't seen by a running systemd until it's
+// reloaded.
+func New(opts ...Option) (Manager, error) {
+	o := options{root: "/"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	info, err := os.Stat(o.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root %q: %w", o.root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open root %q: not a directory", o.root)
+	}
+
+	return &manager{root: o.root}, nil
+}
+
+// open opens the root. Paths are resolved within it, as if it were "/".
+func (m *manager) open() (*os.Root, error) {
+	root, err := os.OpenRoot(m.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root %q: %w", m.root, err)
+	}
+
+	return root, nil
+}
+
+// unitFile is a unit file found under the root.
+type unitFile struct {
+	// path is where the unit file was found, relative to the root.
+	path string
+	file *unitfile.File
+}
+
+// installTargets maps the [Install] settings of a unit file linking it into
+// other units to the suffix of the directories the links are made in.
+var installTargets = []struct {
+	key    string
+	suffix string
+}{
+	{"WantedBy", ".wants"},
+	{"RequiredBy", ".requires"},
+	{"UpheldBy", ".upholds"},
+}
+
+// install returns the space separated words of the [Install] setting key,
+// across all its assignments.
+func (u *unitFile) install(key string) []string {
+	var words []string
+	for _, v := range u.file.Values("Install", key) {
+		words = append(words, strings.Fields(v)...)
+	}
+
+	return words
+}
+
+// installable returns whether the unit file has [Install] settings making
+// enabling it do something.
+func (u *unitFile) installable() bool {
+	for _, target := range installTargets {
+		if len(u.install(target.key)) > 0 {
+			return true
+		}
+	}
+
+	return len(u.install("Alias")) > 0
+}
+
+// template returns the template of an instance name, such as "foo@.service"
+// for "foo@bar.service", and whether unit is an instance.
+func template(unit string) (string, bool) {
+	prefix, rest, templated := strings.Cut(unit, "@")
+	if !templated {
+		return "", false
+	}
+	dot := strings.LastIndexByte(rest, '.')
+	if dot <= 0 {
+		return "", false
+	}
+
+	return prefix + "@" + rest[dot:], true
+}
+
+// findUnit returns the unit file of unit found first in the search path,
+// falling back to the template of instances. It fails with ErrMasked if the
+// unit is masked, and with ErrUnitFileNotFound if it doesn't exist.
+func findUnit(root *os.Root, unit string) (*unitFile, error) {
+	names := []string{unit}
+	if t, ok := template(unit); ok {
+		names = append(names, t)
+	}
+
+	for _, name := range names {
+		for _, dir := range searchPath {
+			p := path.Join(dir, name)
+			content, err := readFollow(root, p)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read unit file of %q: %w", unit, err)
+			}
+
+			f, err := unitfile.Parse(strings.NewReader(string(content)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse unit file of %q: %w", unit, err)
+			}
+
+			return &unitFile{path: p, file: f}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnitFileNotFound, unit)
+}
+
+// readFollow reads the file at p, following symlinks as if the root were
+// "/", since links in images are usually absolute. It fails with ErrMasked if
+// a link points to /dev/null.
+func readFollow(root *os.Root, p string) ([]byte, error) {
+	for range maxSymlinks {
+		info, err := root.Lstat(p)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return root.ReadFile(p)
+		}
+
+		target, err := root.Readlink(p)
+		if err != nil {
+			return nil, err
+		}
+		if target == devNull {
+			return nil, ErrMasked
+		}
+		if path.IsAbs(target) {
+			p = path.Clean(strings.TrimPrefix(target, "/"))
+		} else {
+			p = path.Join(path.Dir(p), target)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to read %q: too many levels of symbolic links", "/"+p)
+}
+
+// isLink returns whether p is a symlink, and its target.
+func isLink(root *os.Root, p string) (bool, string, error) {
+	info, err := root.Lstat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		return false, "", nil
+	}
+	target, err := root.Readlink(p)
+	if err != nil {
+		return false, "", err
+	}
+
+	return true, target, nil
+}
+
+// link creates a symlink at p to target, making its parent directory if
+// needed. It does nothing if the link already exists, and fails with
+// fs.ErrExist if something else exists at p.
+func link(root *os.Root, p, target string) ([]Change, error) {
+	linked, current, err := isLink(root, p)
+	if err != nil {
+		return nil, err
+	}
+	if linked && current == target {
+		return nil, nil
+	}
+	if _, err := root.Lstat(p); err == nil {
+		return nil, fmt.Errorf("failed to link %q to %q: %w", "/"+p, target, fs.ErrExist)
+	}
+
+	if err := root.MkdirAll(path.Dir(p), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to link %q to %q: %w", "/"+p, target, err)
+	}
+	if err := root.Symlink(target, p); err != nil {
+		return nil, fmt.Errorf("failed to link %q to %q: %w", "/"+p, target, err)
+	}
+
+	return []Change{{Type: "symlink", Path: "/" + p, Source: target}}, nil
+}
+
+// links returns the symlinks in dir, and in the dependency directories under
+// it, whose name is accepted by match, leaving out those masking units.
+func links(root *os.Root, dir string, match func(name string) bool) ([]string, error) {
+	var found []string
+	err := fs.WalkDir(root.FS(), dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == dir || slices.ContainsFunc(installTargets, func(t struct{ key, suffix string }) bool {
+				return strings.HasSuffix(p, t.suffix) && path.Dir(p) == dir
+			}) {
+				return nil
+			}
+
+			return fs.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink == 0 || !match(d.Name()) {
+			return nil
+		}
+		if _, target, err := isLink(root, p); err != nil || target == devNull {
+			return err
+		}
+		found = append(found, p)
+
+		return nil
+	})
+
+	return found, err
+}
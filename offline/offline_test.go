@@ -0,0 +1,204 @@
+package offline
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pires/go-systemdmanager"
+	"github.com/stretchr/testify/require"
+)
+
+// newImage returns a Manager over a temporary root holding units, keyed by
+// their path relative to the root.
+func newImage(t *testing.T, units map[string]string) (Manager, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for p, content := range units {
+		p = filepath.Join(dir, p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	m, err := New(WithRoot(dir))
+	require.NoError(t, err)
+
+	return m, dir
+}
+
+func Test_Unit_New(t *testing.T) {
+	_, err := New(WithRoot(filepath.Join(t.TempDir(), "missing")))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	_, err = New(WithRoot(file))
+	require.Error(t, err)
+
+	m, err := New()
+	require.NoError(t, err)
+	require.Equal(t, "/", m.(*manager).root)
+}
+
+func Test_Unit_Manager_Enable(t *testing.T) {
+	ctx := context.Background()
+	m, dir := newImage(t, map[string]string{
+		"usr/lib/systemd/system/foo.service": "[Service]\nExecStart=/bin/true\n[Install]\nWantedBy=multi-user.target\nAlias=bar.service\nAlso=baz.service\n",
+		"usr/lib/systemd/system/baz.service": "[Install]\nRequiredBy=foo.service\nAlso=foo.service\n",
+	})
+
+	changes, err := m.Enable(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Type: "symlink", Path: "/etc/systemd/system/multi-user.target.wants/foo.service", Source: "/usr/lib/systemd/system/foo.service"},
+		{Type: "symlink", Path: "/etc/systemd/system/bar.service", Source: "/usr/lib/systemd/system/foo.service"},
+		{Type: "symlink", Path: "/etc/systemd/system/foo.service.requires/baz.service", Source: "/usr/lib/systemd/system/baz.service"},
+	}, changes)
+	target, err := os.Readlink(filepath.Join(dir, "etc/systemd/system/multi-user.target.wants/foo.service"))
+	require.NoError(t, err)
+	require.Equal(t, "/usr/lib/systemd/system/foo.service", target)
+
+	// Enabling again changes nothing.
+	changes, err = m.Enable(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	state, err := m.EnablementState(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileEnabled, state)
+
+	changes, err = m.Disable(ctx, "foo.service")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Change{
+		{Type: "unlink", Path: "/etc/systemd/system/multi-user.target.wants/foo.service"},
+		{Type: "unlink", Path: "/etc/systemd/system/bar.service"},
+		{Type: "unlink", Path: "/etc/systemd/system/foo.service.requires/baz.service"},
+	}, changes)
+
+	state, err = m.EnablementState(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileDisabled, state)
+
+	_, err = m.Enable(ctx, "missing.service")
+	require.ErrorIs(t, err, ErrUnitFileNotFound)
+	_, err = m.Enable(ctx, "../foo.service")
+	require.ErrorIs(t, err, systemdmanager.ErrInvalidUnitName)
+}
+
+func Test_Unit_Manager_Enable_conflict(t *testing.T) {
+	m, _ := newImage(t, map[string]string{
+		"usr/lib/systemd/system/foo.service":    "[Install]\nAlias=bar.service\n",
+		"etc/systemd/system/bar.service":        "[Service]\nExecStart=/bin/true\n",
+		"usr/lib/systemd/system/evil.service":   "[Install]\nWantedBy=../../../evil\n",
+		"usr/lib/systemd/system/static.service": "[Service]\nExecStart=/bin/true\n",
+	})
+
+	_, err := m.Enable(context.Background(), "foo.service")
+	require.ErrorIs(t, err, fs.ErrExist)
+	_, err = m.Enable(context.Background(), "evil.service")
+	require.ErrorIs(t, err, systemdmanager.ErrInvalidUnitName)
+
+	changes, err := m.Enable(context.Background(), "static.service")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	state, err := m.EnablementState(context.Background(), "static.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileStatic, state)
+}
+
+func Test_Unit_Manager_Enable_template(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newImage(t, map[string]string{
+		"lib/systemd/system/getty@.service":  "[Install]\nWantedBy=getty.target\nDefaultInstance=tty1\n",
+		"lib/systemd/system/worker@.service": "[Install]\nWantedBy=multi-user.target\n",
+	})
+
+	changes, err := m.Enable(ctx, "getty@.service")
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Type: "symlink", Path: "/etc/systemd/system/getty.target.wants/getty@tty1.service", Source: "/lib/systemd/system/getty@.service"},
+	}, changes)
+
+	// Templates without a default instance are enabled per instance.
+	state, err := m.EnablementState(ctx, "worker@.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileIndirect, state)
+	changes, err = m.Enable(ctx, "worker@.service")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	for _, instance := range []string{"worker@a.service", "worker@b.service"} {
+		changes, err = m.Enable(ctx, instance)
+		require.NoError(t, err)
+		require.Equal(t, []Change{
+			{Type: "symlink", Path: "/etc/systemd/system/multi-user.target.wants/" + instance, Source: "/lib/systemd/system/worker@.service"},
+		}, changes)
+	}
+	state, err = m.EnablementState(ctx, "worker@a.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileEnabled, state)
+
+	// Disabling a template disables all its instances.
+	changes, err = m.Disable(ctx, "worker@.service")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+}
+
+func Test_Unit_Manager_Mask(t *testing.T) {
+	ctx := context.Background()
+	m, dir := newImage(t, map[string]string{
+		"usr/lib/systemd/system/foo.service": "[Install]\nWantedBy=multi-user.target\n",
+		"etc/systemd/system/local.service":   "[Service]\nExecStart=/bin/true\n",
+	})
+
+	changes, err := m.Mask(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, []Change{{Type: "symlink", Path: "/etc/systemd/system/foo.service", Source: "/dev/null"}}, changes)
+	target, err := os.Readlink(filepath.Join(dir, "etc/systemd/system/foo.service"))
+	require.NoError(t, err)
+	require.Equal(t, "/dev/null", target)
+
+	state, err := m.EnablementState(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, systemdmanager.UnitFileMasked, state)
+	_, err = m.Enable(ctx, "foo.service")
+	require.ErrorIs(t, err, ErrMasked)
+
+	// Disabling leaves units masked.
+	changes, err = m.Disable(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = m.Unmask(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Equal(t, []Change{{Type: "unlink", Path: "/etc/systemd/system/foo.service"}}, changes)
+	changes, err = m.Unmask(ctx, "foo.service")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = m.Mask(ctx, "local.service")
+	require.ErrorIs(t, err, fs.ErrExist)
+}
+
+func Test_Unit_readFollow(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "etc/systemd/system"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "opt/app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "opt/app/app.service"), []byte("[Unit]\n"), 0o644))
+	// Absolute links resolve within the root, not the host.
+	require.NoError(t, os.Symlink("/opt/app/app.service", filepath.Join(dir, "etc/systemd/system/app.service")))
+	require.NoError(t, os.Symlink("loop.service", filepath.Join(dir, "etc/systemd/system/loop.service")))
+
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	defer root.Close()
+
+	content, err := readFollow(root, "etc/systemd/system/app.service")
+	require.NoError(t, err)
+	require.Equal(t, "[Unit]\n", string(content))
+
+	_, err = readFollow(root, "etc/systemd/system/loop.service")
+	require.Error(t, err)
+}
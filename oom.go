@@ -0,0 +1,89 @@
+package systemdmanager
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// oomKillResult is the Result of units whose processes were killed by the
+// OOM killer.
+const oomKillResult = "oom-kill"
+
+// OOMKill is a process of a unit killed by the kernel OOM killer.
+type OOMKill struct {
+	Time time.Time `json:"time" yaml:"time"`
+	Unit string    `json:"unit" yaml:"unit"`
+	// Message is the message systemd logged, empty when the kill was told
+	// by the unit's result rather than the journal.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// LastOOM returns the last OOM kill of a process of the named unit, or nil
+// if there was none. It looks for the message systemd logs on OOM kills in
+// the journal and, when the journal can't be read, falls back to whether
+// the unit's last run ended with result "oom-kill".
+func (m *manager) LastOOM(parentCtx context.Context, unit string) (*OOMKill, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "LastOOM")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	kill, err := m.lastOOM(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved last OOM kill")
+
+	return kill, nil
+}
+
+// lastOOM implements LastOOM.
+func (m *manager) lastOOM(ctx context.Context, unit string) (*OOMKill, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return nil, err
+	}
+
+	e, found, err := lastJournalEntry(ctx, "MESSAGE_ID="+unitOOMMessageID, "UNIT="+unit)
+	if err == nil {
+		if !found {
+			return nil, nil
+		}
+
+		return &OOMKill{Time: e.Time, Unit: unit, Message: e.Message}, nil
+	}
+
+	// Only some unit types have a result.
+	if !slices.Contains(unitResultTypes, unitType(unit)) {
+		return nil, nil
+	}
+	iface := unitTypeInterface(unit)
+	result, err := getProperty[string](ctx, m, unit, iface, "Result")
+	if err != nil || result != oomKillResult {
+		return nil, err
+	}
+	exited, err := getProperty[time.Time](ctx, m, unit, "Unit", "ActiveExitTimestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &OOMKill{Time: exited, Unit: unit}, nil
+}
+
+// lastJournalEntry returns the last journal entry matching matches, and
+// whether there is one.
+func lastJournalEntry(ctx context.Context, matches ...string) (journalEntry, bool, error) {
+	entries, err := readJournal(ctx, append([]string{"--lines=1"}, matches...)...)
+	if err != nil || len(entries) == 0 {
+		return journalEntry{}, false, err
+	}
+
+	return entries[len(entries)-1], true, nil
+}
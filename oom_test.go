@@ -0,0 +1,13 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_LastOOM_InvalidUnitName(t *testing.T) {
+	_, err := (&manager{}).LastOOM(context.Background(), "a")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
@@ -0,0 +1,242 @@
+package oomd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy, which oomd requires, is
+// mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// Reason is why oomd would kill a candidate.
+type Reason string
+
+const (
+	// ReasonSwap candidates are killed, most swap used first, when the
+	// system uses more swap than SwapUsedLimit.
+	ReasonSwap Reason = "swap"
+	// ReasonMemoryPressure candidates are killed, most reclaim activity
+	// first, when the memory pressure of the cgroup monitoring them exceeds
+	// its limit for long enough.
+	ReasonMemoryPressure Reason = "memory-pressure"
+)
+
+// Candidate is a cgroup oomd would consider killing, read from the cgroup
+// hierarchy.
+type Candidate struct {
+	// Path is relative to the cgroup root, e.g.
+	// "/user.slice/user-1000.slice/user@1000.service/app.slice/foo.service".
+	Path string `json:"path" yaml:"path"`
+	// Unit is the unit of the cgroup, e.g. "foo.service", if any.
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Monitored is the path of the monitored cgroup the candidate is in.
+	Monitored string `json:"monitored" yaml:"monitored"`
+	Reason    Reason `json:"reason" yaml:"reason"`
+	// MemoryUsage and SwapUsage are in bytes.
+	MemoryUsage uint64 `json:"memory_usage" yaml:"memory_usage"`
+	SwapUsage   uint64 `json:"swap_usage" yaml:"swap_usage"`
+	// Pgscan is how many pages were scanned for reclaim since the cgroup
+	// was created.
+	Pgscan   uint64   `json:"pgscan" yaml:"pgscan"`
+	Pressure Pressure `json:"pressure" yaml:"pressure"`
+}
+
+// KillCandidates returns the cgroups oomd would consider killing, should
+// the cgroups monitoring them exceed their limits, in the order oomd would
+// kill them: swap candidates by swap used, then memory pressure candidates
+// by pages scanned for reclaim, then by memory used. oomd ranks the latter
+// by pages scanned since its previous check instead, which only it knows,
+// and skips cgroups with ManagedOOMPreference=omit, which isn't accounted
+// for.
+func (m *manager) KillCandidates(parentCtx context.Context) ([]Candidate, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "KillCandidates")
+	defer span.End()
+
+	status, err := m.status(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	candidates, err := killCandidates(os.DirFS(m.cgroupRoot), status)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetAttributes(otelattr.Int("candidates", len(candidates)))
+	span.SetStatus(otelcodes.Ok, "listed kill candidates")
+
+	return candidates, nil
+}
+
+// killCandidates returns the candidates of the cgroups monitored per status,
+// read from the cgroup hierarchy fsys, in kill order.
+func killCandidates(fsys fs.FS, status Status) ([]Candidate, error) {
+	var swap, pressure []Candidate
+	for _, monitored := range status.SwapMonitored {
+		candidates, err := cgroupCandidates(fsys, monitored.Path, ReasonSwap)
+		if err != nil {
+			return nil, err
+		}
+		swap = append(swap, candidates...)
+	}
+	for _, monitored := range status.MemoryPressureMonitored {
+		candidates, err := cgroupCandidates(fsys, monitored.Path, ReasonMemoryPressure)
+		if err != nil {
+			return nil, err
+		}
+		pressure = append(pressure, candidates...)
+	}
+
+	slices.SortStableFunc(swap, func(a, b Candidate) int {
+		return cmp.Compare(b.SwapUsage, a.SwapUsage)
+	})
+	slices.SortStableFunc(pressure, func(a, b Candidate) int {
+		return cmp.Or(cmp.Compare(b.Pgscan, a.Pgscan), cmp.Compare(b.MemoryUsage, a.MemoryUsage))
+	})
+
+	return append(swap, pressure...), nil
+}
+
+// cgroupCandidates returns the candidates in the monitored cgroup, which
+// are the cgroups in it, itself included, without children, or killed as a
+// whole as set by memory.oom.group. Cgroups disappearing meanwhile are
+// skipped.
+func cgroupCandidates(fsys fs.FS, monitored string, reason Reason) ([]Candidate, error) {
+	var candidates []Candidate
+	root := strings.TrimPrefix(path.Clean(monitored), "/")
+	if root == "" {
+		root = "."
+	}
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipDir
+		}
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		entries, err := fs.ReadDir(fsys, p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		leaf := !slices.ContainsFunc(entries, fs.DirEntry.IsDir)
+		group, _ := fs.ReadFile(fsys, path.Join(p, "memory.oom.group"))
+		if !leaf && strings.TrimSpace(string(group)) != "1" {
+			return nil
+		}
+
+		c, err := readCandidate(fsys, p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		c.Monitored, c.Reason = monitored, reason
+		candidates = append(candidates, c)
+
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kill candidates of cgroup %q: %w", monitored, err)
+	}
+
+	return candidates, nil
+}
+
+// readCandidate reads the memory usage of the cgroup at p in fsys.
+func readCandidate(fsys fs.FS, p string) (Candidate, error) {
+	c := Candidate{Path: "/" + p, Unit: cgroupUnit("/" + p)}
+
+	var err error
+	if c.MemoryUsage, err = readCounter(fsys, path.Join(p, "memory.current")); err != nil {
+		return Candidate{}, err
+	}
+	// Swap isn't accounted for without swap.
+	if c.SwapUsage, err = readCounter(fsys, path.Join(p, "memory.swap.current")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Candidate{}, err
+	}
+	stat, err := fs.ReadFile(fsys, path.Join(p, "memory.stat"))
+	if err != nil {
+		return Candidate{}, err
+	}
+	if c.Pgscan, err = parseMemoryStat(string(stat), "pgscan"); err != nil {
+		return Candidate{}, fmt.Errorf("invalid memory.stat of cgroup %q: %w", c.Path, err)
+	}
+	pressure, err := fs.ReadFile(fsys, path.Join(p, "memory.pressure"))
+	if err != nil {
+		return Candidate{}, err
+	}
+	if c.Pressure, err = parsePressure(string(pressure)); err != nil {
+		return Candidate{}, fmt.Errorf("invalid memory.pressure of cgroup %q: %w", c.Path, err)
+	}
+
+	return c, nil
+}
+
+// readCounter reads a cgroup file holding a single number.
+func readCounter(fsys fs.FS, name string) (uint64, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// parseMemoryStat returns the value of key in the contents of memory.stat,
+// which has a "key value" line per statistic. Missing keys are zero.
+func parseMemoryStat(stat, key string) (uint64, error) {
+	for line := range strings.Lines(stat) {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && k == key {
+			return strconv.ParseUint(v, 10, 64)
+		}
+	}
+
+	return 0, nil
+}
+
+// parsePressure parses the "full" line of memory.pressure, e.g.
+// "full avg10=0.00 avg60=0.00 avg300=0.00 total=0", which is what oomd
+// monitors. total is in microseconds.
+func parsePressure(s string) (Pressure, error) {
+	for line := range strings.Lines(s) {
+		if !strings.HasPrefix(line, "full ") {
+			continue
+		}
+		var (
+			p     Pressure
+			total uint64
+		)
+		if _, err := fmt.Sscanf(line, "full avg10=%g avg60=%g avg300=%g total=%d", &p.Avg10, &p.Avg60, &p.Avg300, &total); err != nil {
+			return Pressure{}, err
+		}
+		p.Total = time.Duration(total) * time.Microsecond
+
+		return p, nil
+	}
+
+	return Pressure{}, fmt.Errorf("missing full pressure")
+}
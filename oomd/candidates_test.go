@@ -0,0 +1,84 @@
+package oomd
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cgroup adds the files of a cgroup using memory bytes of memory and swap
+// bytes of swap, having scanned pgscan pages.
+func cgroup(fsys fstest.MapFS, p string, memory, swap, pgscan string) {
+	fsys[p+"/memory.current"] = &fstest.MapFile{Data: []byte(memory + "\n")}
+	fsys[p+"/memory.swap.current"] = &fstest.MapFile{Data: []byte(swap + "\n")}
+	fsys[p+"/memory.stat"] = &fstest.MapFile{Data: []byte("anon 1024\npgscan " + pgscan + "\npgsteal 0\n")}
+	fsys[p+"/memory.pressure"] = &fstest.MapFile{Data: []byte("some avg10=1.00 avg60=0.50 avg300=0.10 total=3000000\nfull avg10=0.50 avg60=0.25 avg300=0.05 total=2000000\n")}
+}
+
+func Test_Unit_killCandidates(t *testing.T) {
+	fsys := fstest.MapFS{}
+	cgroup(fsys, "system.slice", "300", "0", "0")
+	cgroup(fsys, "system.slice/a.service", "100", "10", "5")
+	cgroup(fsys, "system.slice/b.service", "200", "20", "5")
+	// Cgroups killed as a whole aren't descended into.
+	cgroup(fsys, "system.slice/c.service", "50", "30", "1")
+	fsys["system.slice/c.service/memory.oom.group"] = &fstest.MapFile{Data: []byte("1\n")}
+	cgroup(fsys, "system.slice/c.service/payload", "50", "30", "1")
+	// Inner cgroups aren't candidates.
+	cgroup(fsys, "system.slice/d.slice", "0", "0", "0")
+	cgroup(fsys, "system.slice/d.slice/e.service", "10", "0", "9")
+
+	candidates, err := killCandidates(fsys, Status{
+		SwapMonitored:           []CGroup{{Path: "/system.slice"}},
+		MemoryPressureMonitored: []CGroup{{Path: "/system.slice/d.slice"}, {Path: "/system.slice/gone.slice"}},
+	})
+	require.NoError(t, err)
+
+	pressure := Pressure{Avg10: 0.5, Avg60: 0.25, Avg300: 0.05, Total: 2 * time.Second}
+	require.Equal(t, []Candidate{
+		{Path: "/system.slice/c.service", Unit: "c.service", Monitored: "/system.slice", Reason: ReasonSwap, MemoryUsage: 50, SwapUsage: 30, Pgscan: 1, Pressure: pressure},
+		{Path: "/system.slice/b.service", Unit: "b.service", Monitored: "/system.slice", Reason: ReasonSwap, MemoryUsage: 200, SwapUsage: 20, Pgscan: 5, Pressure: pressure},
+		{Path: "/system.slice/a.service", Unit: "a.service", Monitored: "/system.slice", Reason: ReasonSwap, MemoryUsage: 100, SwapUsage: 10, Pgscan: 5, Pressure: pressure},
+		{Path: "/system.slice/d.slice/e.service", Unit: "e.service", Monitored: "/system.slice", Reason: ReasonSwap, MemoryUsage: 10, Pgscan: 9, Pressure: pressure},
+		{Path: "/system.slice/d.slice/e.service", Unit: "e.service", Monitored: "/system.slice/d.slice", Reason: ReasonMemoryPressure, MemoryUsage: 10, Pgscan: 9, Pressure: pressure},
+	}, candidates)
+
+	// Memory pressure candidates are ranked by pages scanned, then memory.
+	candidates, err = killCandidates(fsys, Status{MemoryPressureMonitored: []CGroup{{Path: "/system.slice"}}})
+	require.NoError(t, err)
+	var units []string
+	for _, c := range candidates {
+		units = append(units, c.Unit)
+	}
+	require.Equal(t, []string{"e.service", "b.service", "a.service", "c.service"}, units)
+
+	// A monitored cgroup without children is its own candidate.
+	candidates, err = killCandidates(fsys, Status{SwapMonitored: []CGroup{{Path: "/system.slice/a.service"}}})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.Equal(t, "/system.slice/a.service", candidates[0].Path)
+}
+
+func Test_Unit_parseMemoryStat(t *testing.T) {
+	v, err := parseMemoryStat("anon 1024\npgscan 42\n", "pgscan")
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), v)
+
+	v, err = parseMemoryStat("anon 1024\n", "pgscan")
+	require.NoError(t, err)
+	require.Zero(t, v)
+
+	_, err = parseMemoryStat("pgscan lots\n", "pgscan")
+	require.Error(t, err)
+}
+
+func Test_Unit_parsePressure(t *testing.T) {
+	p, err := parsePressure("some avg10=1.00 avg60=0.50 avg300=0.10 total=3000000\nfull avg10=0.50 avg60=0.25 avg300=0.05 total=2000000\n")
+	require.NoError(t, err)
+	require.Equal(t, Pressure{Avg10: 0.5, Avg60: 0.25, Avg300: 0.05, Total: 2 * time.Second}, p)
+
+	_, err = parsePressure("some avg10=1.00 avg60=0.50 avg300=0.10 total=3000000\n")
+	require.Error(t, err)
+}
@@ -0,0 +1,7 @@
+// Package oomd inspects systemd-oomd, the userspace out-of-memory killer:
+// its limits, the cgroups it monitors for swap usage and memory pressure,
+// and the cgroups it would kill first.
+package oomd
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/oomd"
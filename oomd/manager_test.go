@@ -0,0 +1,42 @@
+//go:build linux
+
+package oomd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_Status(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	status, err := mgr.Status(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, status.System.MemoryTotal)
+	require.NotZero(t, status.SwapUsedLimit)
+}
+
+func Test_E2E_Manager_KillCandidates(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// Hosts may monitor no cgroups at all.
+	candidates, err := mgr.KillCandidates(ctx)
+	require.NoError(t, err)
+	for _, c := range candidates {
+		require.NotEmpty(t, c.Path)
+		require.Contains(t, []Reason{ReasonSwap, ReasonMemoryPressure}, c.Reason)
+	}
+}
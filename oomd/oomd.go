@@ -0,0 +1,340 @@
+package oomd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// oomdDest is the D-Bus destination of oomd.
+	oomdDest = "org.freedesktop.oom1"
+	// oomdPath is the D-Bus object path of oomd.
+	oomdPath godbus.ObjectPath = "/org/freedesktop/oom1"
+	// managerInterface is the D-Bus interface of oomd.
+	managerInterface = "org.freedesktop.oom1.Manager"
+)
+
+// Status is the state of oomd, as dumped by oomctl.
+type Status struct {
+	// DryRun means oomd only logs the cgroups it would kill.
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+	// SwapUsedLimit is the percentage of swap used above which oomd kills
+	// the cgroups using the most swap.
+	SwapUsedLimit float64 `json:"swap_used_limit" yaml:"swap_used_limit"`
+	// DefaultMemoryPressureLimit and DefaultMemoryPressureDuration are the
+	// memory pressure, as a percentage, sustained for the duration, above
+	// which oomd kills cgroups, unless overridden by
+	// ManagedOOMMemoryPressureLimit=.
+	DefaultMemoryPressureLimit    float64       `json:"default_memory_pressure_limit" yaml:"default_memory_pressure_limit"`
+	DefaultMemoryPressureDuration time.Duration `json:"default_memory_pressure_duration" yaml:"default_memory_pressure_duration"`
+	System                        SystemContext `json:"system" yaml:"system"`
+	// SwapMonitored are the cgroups with ManagedOOMSwap=kill.
+	SwapMonitored []CGroup `json:"swap_monitored" yaml:"swap_monitored"`
+	// MemoryPressureMonitored are the cgroups with
+	// ManagedOOMMemoryPressure=kill.
+	MemoryPressureMonitored []CGroup `json:"memory_pressure_monitored" yaml:"memory_pressure_monitored"`
+}
+
+// SystemContext is the memory and swap usage of the system, in bytes.
+type SystemContext struct {
+	MemoryUsed  uint64 `json:"memory_used" yaml:"memory_used"`
+	MemoryTotal uint64 `json:"memory_total" yaml:"memory_total"`
+	SwapUsed    uint64 `json:"swap_used" yaml:"swap_used"`
+	SwapTotal   uint64 `json:"swap_total" yaml:"swap_total"`
+}
+
+// Pressure is the memory pressure of a cgroup, as the percentage of time all
+// its tasks were stalled on memory over 10, 60, and 300 seconds, and the
+// total time stalled.
+type Pressure struct {
+	Avg10  float64       `json:"avg10" yaml:"avg10"`
+	Avg60  float64       `json:"avg60" yaml:"avg60"`
+	Avg300 float64       `json:"avg300" yaml:"avg300"`
+	Total  time.Duration `json:"total" yaml:"total"`
+}
+
+// CGroup is a cgroup monitored by oomd. Sizes are in bytes, and only as
+// precise as oomd formats them, e.g. "1.5G".
+type CGroup struct {
+	// Path is relative to the cgroup root, e.g. "/user.slice".
+	Path string `json:"path" yaml:"path"`
+	// Unit is the unit of the cgroup, e.g. "user.slice", if any.
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// SwapUsage is only reported for swap monitored cgroups.
+	SwapUsage uint64 `json:"swap_usage,omitempty" yaml:"swap_usage,omitempty"`
+	// The other fields are only reported for memory pressure monitored
+	// cgroups.
+	MemoryPressureLimit    float64       `json:"memory_pressure_limit,omitempty" yaml:"memory_pressure_limit,omitempty"`
+	MemoryPressureDuration time.Duration `json:"memory_pressure_duration,omitempty" yaml:"memory_pressure_duration,omitempty"`
+	Pressure               Pressure      `json:"pressure" yaml:"pressure"`
+	MemoryUsage            uint64        `json:"memory_usage,omitempty" yaml:"memory_usage,omitempty"`
+	MemoryMin              uint64        `json:"memory_min,omitempty" yaml:"memory_min,omitempty"`
+	MemoryLow              uint64        `json:"memory_low,omitempty" yaml:"memory_low,omitempty"`
+	// Pgscan is how many pages were scanned for reclaim, and LastPgscan how
+	// many were as of the previous check of oomd. Their difference ranks
+	// cgroups to kill under memory pressure.
+	Pgscan     uint64 `json:"pgscan,omitempty" yaml:"pgscan,omitempty"`
+	LastPgscan uint64 `json:"last_pgscan,omitempty" yaml:"last_pgscan,omitempty"`
+}
+
+// Manager inspects oomd.
+type Manager interface {
+	KillCandidates(ctx context.Context) ([]Candidate, error)
+	Status(ctx context.Context) (Status, error)
+}
+
+// manager inspects oomd via a D-Bus connection.
+type manager struct {
+	conn *bus.Conn
+	// cgroupRoot is where the cgroup hierarchy is mounted.
+	cgroupRoot string
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to oomd failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to oomd over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, oomdDest, oomdPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up oomd manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn, cgroupRoot: cgroupRoot}, nil
+}
+
+// Status returns the limits of oomd, the memory and swap usage of the
+// system, and the cgroups oomd monitors.
+func (m *manager) Status(parentCtx context.Context) (Status, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Status")
+	defer span.End()
+
+	status, err := m.status(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return Status{}, err
+	}
+	span.SetAttributes(
+		otelattr.Int("swap_monitored", len(status.SwapMonitored)),
+		otelattr.Int("memory_pressure_monitored", len(status.MemoryPressureMonitored)),
+	)
+	span.SetStatus(otelcodes.Ok, "retrieved oomd status")
+
+	return status, nil
+}
+
+// status implements Status, parsing the dump oomd writes to a file
+// descriptor.
+func (m *manager) status(ctx context.Context) (Status, error) {
+	var fd godbus.UnixFD
+	if err := m.conn.Call(ctx, "DumpByFileDescriptor", nil, &fd); err != nil {
+		return Status{}, fmt.Errorf("failed to dump oomd status: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "oomd-dump")
+	defer f.Close()
+
+	status, err := parseDump(f)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to parse oomd status: %w", err)
+	}
+
+	return status, nil
+}
+
+// parseDump parses the dump of oomd, which is made of "Key: value" lines,
+// with cgroups listed under section headers, each starting with its path.
+func parseDump(r io.Reader) (Status, error) {
+	var (
+		status  Status
+		section *[]CGroup
+		cgroup  *CGroup
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "":
+			continue
+		case "Dry Run":
+			status.DryRun = value == "yes"
+		case "Swap Used Limit":
+			status.SwapUsedLimit, err = parsePercent(value)
+		case "Default Memory Pressure Limit":
+			status.DefaultMemoryPressureLimit, err = parsePercent(value)
+		case "Default Memory Pressure Duration":
+			status.DefaultMemoryPressureDuration, err = unitfile.ParseTimespan(value)
+		case "System Context":
+			section, cgroup = nil, nil
+		case "Memory":
+			status.System.MemoryUsed, status.System.MemoryTotal, err = parseUsedTotal(value)
+		case "Swap":
+			status.System.SwapUsed, status.System.SwapTotal, err = parseUsedTotal(value)
+		case "Swap Monitored CGroups":
+			section, cgroup = &status.SwapMonitored, nil
+		case "Memory Pressure Monitored CGroups":
+			section, cgroup = &status.MemoryPressureMonitored, nil
+		case "Path":
+			if section == nil {
+				return Status{}, fmt.Errorf("unexpected cgroup %q outside of monitored cgroups", value)
+			}
+			*section = append(*section, CGroup{Path: value, Unit: cgroupUnit(value)})
+			cgroup = &(*section)[len(*section)-1]
+		default:
+			if cgroup == nil {
+				// Skip settings added by newer oomd versions.
+				continue
+			}
+			err = parseCGroupField(cgroup, key, value)
+		}
+		if err != nil {
+			return Status{}, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+	}
+
+	return status, scanner.Err()
+}
+
+// parseCGroupField sets the field of cgroup named key. Unknown fields are
+// skipped.
+func parseCGroupField(cgroup *CGroup, key, value string) (err error) {
+	switch key {
+	case "Swap Usage":
+		cgroup.SwapUsage, err = parseBytes(value)
+	case "Memory Pressure Limit":
+		cgroup.MemoryPressureLimit, err = parsePercent(value)
+	case "Memory Pressure Duration":
+		cgroup.MemoryPressureDuration, err = unitfile.ParseTimespan(value)
+	case "Pressure":
+		cgroup.Pressure, err = parseDumpPressure(value)
+	case "Current Memory Usage":
+		cgroup.MemoryUsage, err = parseBytes(value)
+	case "Memory Min":
+		cgroup.MemoryMin, err = parseBytes(value)
+	case "Memory Low":
+		cgroup.MemoryLow, err = parseBytes(value)
+	case "Pgscan":
+		cgroup.Pgscan, err = strconv.ParseUint(value, 10, 64)
+	case "Last Pgscan":
+		cgroup.LastPgscan, err = strconv.ParseUint(value, 10, 64)
+	}
+
+	return err
+}
+
+// parsePercent parses a percentage, e.g. "60.00%".
+func parsePercent(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+}
+
+// parseUsedTotal parses the usage of memory or swap, e.g.
+// "Used: 1.5G Total: 15.3G".
+func parseUsedTotal(s string) (used, total uint64, err error) {
+	var usedStr, totalStr string
+	if _, err := fmt.Sscanf(s, "Used: %s Total: %s", &usedStr, &totalStr); err != nil {
+		return 0, 0, err
+	}
+	if used, err = parseBytes(usedStr); err != nil {
+		return 0, 0, err
+	}
+	if total, err = parseBytes(totalStr); err != nil {
+		return 0, 0, err
+	}
+
+	return used, total, nil
+}
+
+// parseDumpPressure parses memory pressure as dumped by oomd, e.g.
+// "Avg10: 0.00 Avg60: 0.00 Avg300: 0.00 Total: 1min 2s".
+func parseDumpPressure(s string) (Pressure, error) {
+	var p Pressure
+	n, err := fmt.Sscanf(s, "Avg10: %g Avg60: %g Avg300: %g Total:", &p.Avg10, &p.Avg60, &p.Avg300)
+	if err != nil || n != 3 {
+		return Pressure{}, fmt.Errorf("unexpected pressure format: %w", err)
+	}
+	_, total, _ := strings.Cut(s, "Total:")
+	if p.Total, err = unitfile.ParseTimespan(total); err != nil {
+		return Pressure{}, err
+	}
+
+	return p, nil
+}
+
+// byteSuffixes are the suffixes of sizes formatted by systemd, which are
+// powers of 1024.
+var byteSuffixes = map[byte]uint64{
+	'B': 1,
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+	'T': 1 << 40,
+	'P': 1 << 50,
+	'E': 1 << 60,
+}
+
+// parseBytes parses a size formatted by systemd, e.g. "0B" or "1.5G". A
+// size without suffix is in bytes.
+func parseBytes(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	multiplier := uint64(1)
+	if m, ok := byteSuffixes[s[len(s)-1]]; ok {
+		multiplier, s = m, s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return uint64(v * float64(multiplier)), nil
+}
+
+// cgroupUnit returns the unit of the cgroup at path, if its last component
+// is a unit name, e.g. "foo.service" for "/system.slice/foo.service".
+func cgroupUnit(cgroupPath string) string {
+	unit := path.Base(cgroupPath)
+	if systemdmanager.ValidateUnitName(unit) != nil {
+		return ""
+	}
+
+	return unit
+}
@@ -0,0 +1,112 @@
+package oomd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dump is the status of oomd, as dumped by it.
+const dump = `Dry Run: no
+Swap Used Limit: 90.00%
+Default Memory Pressure Limit: 60.00%
+Default Memory Pressure Duration: 20s
+System Context:
+	Memory: Used: 4.5G Total: 15.5G
+	Swap: Used: 0B Total: 8.0G
+Swap Monitored CGroups:
+	Path: /
+		Swap Usage: 0B
+Memory Pressure Monitored CGroups:
+	Path: /user.slice/user-1000.slice/user@1000.service
+		Memory Pressure Limit: 50.00%
+		Memory Pressure Duration: 30s
+		Pressure: Avg10: 0.12 Avg60: 0.05 Avg300: 0.01 Total: 1min 2s
+		Current Memory Usage: 1.5G
+		Memory Min: 0B
+		Memory Low: 512.0M
+		Pgscan: 1234
+		Last Pgscan: 1200
+		Unknown Setting: 42
+`
+
+func Test_Unit_parseDump(t *testing.T) {
+	status, err := parseDump(strings.NewReader(dump))
+	require.NoError(t, err)
+	require.Equal(t, Status{
+		SwapUsedLimit:                 90,
+		DefaultMemoryPressureLimit:    60,
+		DefaultMemoryPressureDuration: 20 * time.Second,
+		System: SystemContext{
+			MemoryUsed:  4.5 * (1 << 30),
+			MemoryTotal: 15.5 * (1 << 30),
+			SwapTotal:   8 << 30,
+		},
+		SwapMonitored: []CGroup{{Path: "/"}},
+		MemoryPressureMonitored: []CGroup{{
+			Path:                   "/user.slice/user-1000.slice/user@1000.service",
+			Unit:                   "user@1000.service",
+			MemoryPressureLimit:    50,
+			MemoryPressureDuration: 30 * time.Second,
+			Pressure:               Pressure{Avg10: 0.12, Avg60: 0.05, Avg300: 0.01, Total: 62 * time.Second},
+			MemoryUsage:            1.5 * (1 << 30),
+			MemoryLow:              512 << 20,
+			Pgscan:                 1234,
+			LastPgscan:             1200,
+		}},
+	}, status)
+
+	// Nothing monitored, in dry run mode.
+	status, err = parseDump(strings.NewReader("Dry Run: yes\nSwap Monitored CGroups:\nMemory Pressure Monitored CGroups:\n"))
+	require.NoError(t, err)
+	require.Equal(t, Status{DryRun: true}, status)
+
+	_, err = parseDump(strings.NewReader("Path: /\n"))
+	require.Error(t, err)
+	_, err = parseDump(strings.NewReader("Swap Used Limit: lots\n"))
+	require.Error(t, err)
+	_, err = parseDump(strings.NewReader("Swap Monitored CGroups:\n\tPath: /\n\t\tSwap Usage: 1X\n"))
+	require.Error(t, err)
+}
+
+func Test_Unit_parseBytes(t *testing.T) {
+	tests := map[string]uint64{
+		"0B":     0,
+		"1023B":  1023,
+		"1.0K":   1024,
+		"512.0M": 512 << 20,
+		"1.5G":   3 << 29,
+		"2T":     2 << 40,
+		"42":     42,
+	}
+	for s, want := range tests {
+		got, err := parseBytes(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, got, s)
+	}
+
+	for _, s := range []string{"", "G", "-1B", "1.5X"} {
+		_, err := parseBytes(s)
+		require.Error(t, err, s)
+	}
+}
+
+func Test_Unit_parseDumpPressure(t *testing.T) {
+	p, err := parseDumpPressure("Avg10: 1.50 Avg60: 0.75 Avg300: 0.25 Total: 0")
+	require.NoError(t, err)
+	require.Equal(t, Pressure{Avg10: 1.5, Avg60: 0.75, Avg300: 0.25}, p)
+
+	_, err = parseDumpPressure("Avg10: 1.50")
+	require.Error(t, err)
+	_, err = parseDumpPressure("Avg10: 1.50 Avg60: 0.75 Avg300: 0.25 Total: forever")
+	require.Error(t, err)
+}
+
+func Test_Unit_cgroupUnit(t *testing.T) {
+	require.Equal(t, "foo.service", cgroupUnit("/system.slice/foo.service"))
+	require.Equal(t, "system.slice", cgroupUnit("/system.slice"))
+	require.Empty(t, cgroupUnit("/"))
+	require.Empty(t, cgroupUnit("/system.slice/foo.service/payload"))
+}
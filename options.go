@@ -0,0 +1,259 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// Option configures a Manager.
+type Option func(*options)
+
+// options holds the settings of a Manager.
+type options struct {
+	defaultTimeout    time.Duration
+	retryPolicy       RetryPolicy
+	auditSink         AuditSink
+	rateLimits        RateLimits
+	unitDirectory     string
+	eventSinks        []EventSink
+	historySize       int
+	cacheTTL          time.Duration
+	maintenancePolicy MaintenancePolicy
+	claimPolicy       ClaimPolicy
+	allowedUnits      []string
+	reconnectPolicy   RetryPolicy
+	busAddress        string
+}
+
+// newOptions returns the settings resulting from applying opts over the
+// defaults.
+func newOptions(opts []Option) options {
+	o := options{
+		unitDirectory:   defaultUnitDirectory,
+		reconnectPolicy: defaultReconnectPolicy,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithDefaultTimeout bounds how long unit operations may take, unless
+// overridden per operation with WithTimeout. When exceeded, the pending job
+// is cancelled. Zero, the default, means operations are only bound by their
+// context.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = d
+	}
+}
+
+// defaultUnitDirectory is where unit files are installed by default, which
+// is where local administrators' units go.
+const defaultUnitDirectory = "/etc/systemd/system"
+
+// WithUnitDirectory sets the directory unit files are installed to. Defaults
+// to /etc/systemd/system.
+func WithUnitDirectory(dir string) Option {
+	return func(o *options) {
+		o.unitDirectory = dir
+	}
+}
+
+// JobMode controls how a new job interacts with jobs already queued by
+// systemd. See the documentation of systemctl --job-mode for details.
+type JobMode string
+
+const (
+	// JobModeReplace replaces conflicting queued jobs. This is the default.
+	JobModeReplace JobMode = "replace"
+	// JobModeFail fails the operation if it conflicts with a queued job.
+	JobModeFail JobMode = "fail"
+	// JobModeIsolate stops all other units when starting a unit.
+	JobModeIsolate JobMode = "isolate"
+	// JobModeIgnoreDependencies ignores all unit dependencies.
+	JobModeIgnoreDependencies JobMode = "ignore-dependencies"
+	// JobModeIgnoreRequirements ignores requirement dependencies but keeps
+	// ordering dependencies.
+	JobModeIgnoreRequirements JobMode = "ignore-requirements"
+)
+
+// ErrInvalidJobMode means an operation was given a job mode other than the
+// JobMode constants.
+var ErrInvalidJobMode = errors.New("invalid job mode")
+
+// validate returns ErrInvalidJobMode unless mode is one of the JobMode
+// constants.
+func (mode JobMode) validate() error {
+	switch mode {
+	case JobModeReplace, JobModeFail, JobModeIsolate, JobModeIgnoreDependencies, JobModeIgnoreRequirements:
+		return nil
+	}
+
+	return fmt.Errorf("%w %q", ErrInvalidJobMode, mode)
+}
+
+// CallOption configures a single unit operation.
+type CallOption func(*callOptions)
+
+// callOptions holds the settings of a single unit operation.
+type callOptions struct {
+	jobMode      JobMode
+	timeout      time.Duration
+	metadata     map[string]string
+	drain        DrainFunc
+	drainTimeout time.Duration
+}
+
+// newCallOptions returns the settings resulting from applying opts over the
+// defaults.
+func newCallOptions(opts []CallOption) callOptions {
+	o := callOptions{
+		jobMode: JobModeReplace,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithJobMode sets the mode of the job enqueued by an operation. Operations
+// given a mode other than the JobMode constants fail with ErrInvalidJobMode,
+// before enqueuing anything.
+func WithJobMode(mode JobMode) CallOption {
+	return func(o *callOptions) {
+		o.jobMode = mode
+	}
+}
+
+// WithTimeout bounds how long an operation may take, overriding the
+// Manager's default timeout. When exceeded, the pending job is cancelled.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// defaultWatchInterval is how often watches poll unit statuses by default,
+// as go-systemd subscriptions do.
+const defaultWatchInterval = time.Second
+
+// WatchOption configures a single watch.
+type WatchOption func(*watchOptions)
+
+// watchOptions holds the settings of a single watch.
+type watchOptions struct {
+	interval    time.Duration
+	changed     func(previous, current *dbus.UnitStatus) bool
+	waitForUnit bool
+	filters     []func(status *dbus.UnitStatus) bool
+	debounce    time.Duration
+}
+
+// newWatchOptions returns the settings resulting from applying opts over the
+// defaults.
+func newWatchOptions(opts []WatchOption) watchOptions {
+	o := watchOptions{
+		interval: defaultWatchInterval,
+		changed:  StateChanged,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithWatchInterval sets how often a watch polls unit statuses. Longer
+// intervals lower D-Bus and CPU load on busy hosts, at the expense of
+// reporting changes later, and missing those reverted in between. Defaults
+// to one second.
+func WithWatchInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// report returns whether status passes the filters of the watch. The status
+// is nil when the unit was unloaded.
+func (o watchOptions) report(status *dbus.UnitStatus) bool {
+	for _, filter := range o.filters {
+		if !filter(status) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithWaitForUnit watches a unit even if it doesn't exist yet, e.g. because
+// it is about to be installed, instead of failing with ErrUnitNotFound.
+func WithWaitForUnit() WatchOption {
+	return func(o *watchOptions) {
+		o.waitForUnit = true
+	}
+}
+
+// WithChangeFilter sets which status changes a watch reports: changed is
+// called with the previously reported and the current status of the unit,
+// and returns whether to report the current one. Units being loaded and
+// unloaded are always reported. Defaults to StateChanged.
+func WithChangeFilter(changed func(previous, current *dbus.UnitStatus) bool) WatchOption {
+	return func(o *watchOptions) {
+		o.changed = changed
+	}
+}
+
+// StateChanged reports whether the description, load, active, or sub state
+// of a unit changed.
+func StateChanged(previous, current *dbus.UnitStatus) bool {
+	return previous.Description != current.Description ||
+		previous.LoadState != current.LoadState ||
+		ActiveStateChanged(previous, current)
+}
+
+// ActiveStateChanged reports whether the active or sub state of a unit
+// changed.
+func ActiveStateChanged(previous, current *dbus.UnitStatus) bool {
+	return previous.ActiveState != current.ActiveState ||
+		previous.SubState != current.SubState
+}
+
+// OnlyStates reports only the statuses of a watch whose active state is one
+// of states, such as "failed". Unloaded units aren't reported. Like the other
+// filters, it may be combined with any watch option, and only affects what
+// is sent to the updates channel.
+func OnlyStates(states ...string) WatchOption {
+	return func(o *watchOptions) {
+		o.filters = append(o.filters, func(status *dbus.UnitStatus) bool {
+			return status != nil && slices.Contains(states, status.ActiveState)
+		})
+	}
+}
+
+// IgnoreSubState doesn't report the statuses of a watch whose sub state is
+// one of subStates, such as "auto-restart".
+func IgnoreSubState(subStates ...string) WatchOption {
+	return func(o *watchOptions) {
+		o.filters = append(o.filters, func(status *dbus.UnitStatus) bool {
+			return status == nil || !slices.Contains(subStates, status.SubState)
+		})
+	}
+}
+
+// Debounce reports the status of a watch only once it settled, without
+// changing for d, so units flapping between states aren't reported on every
+// change. Filters apply to the settled status.
+func Debounce(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = d
+	}
+}
@@ -0,0 +1,91 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_newCallOptions(t *testing.T) {
+	require.Equal(t, JobModeReplace, newCallOptions(nil).jobMode)
+	require.Equal(t, JobModeFail, newCallOptions([]CallOption{WithJobMode(JobModeFail)}).jobMode)
+	// Last option wins.
+	o := newCallOptions([]CallOption{WithJobMode(JobModeFail), WithJobMode(JobModeIgnoreDependencies)})
+	require.Equal(t, JobModeIgnoreDependencies, o.jobMode)
+}
+
+func Test_Unit_JobMode_validate(t *testing.T) {
+	for _, mode := range []JobMode{JobModeReplace, JobModeFail, JobModeIsolate, JobModeIgnoreDependencies, JobModeIgnoreRequirements} {
+		require.NoError(t, mode.validate())
+	}
+	require.ErrorIs(t, JobMode("").validate(), ErrInvalidJobMode)
+	require.ErrorIs(t, JobMode("flush").validate(), ErrInvalidJobMode)
+
+	// Operations fail before enqueuing anything.
+	require.ErrorIs(t, (&manager{}).Start(t.Context(), "a.service", WithJobMode("flush")), ErrInvalidJobMode)
+}
+
+func Test_Unit_newOptions(t *testing.T) {
+	require.Zero(t, newOptions(nil).defaultTimeout)
+	require.Equal(t, time.Second, newOptions([]Option{WithDefaultTimeout(time.Second)}).defaultTimeout)
+	require.Equal(t, time.Second, newCallOptions([]CallOption{WithTimeout(time.Second)}).timeout)
+}
+
+func Test_Unit_WithUnitDirectory(t *testing.T) {
+	require.Equal(t, "/etc/systemd/system", newOptions(nil).unitDirectory)
+	require.Equal(t, "/run/systemd/system", newOptions([]Option{WithUnitDirectory("/run/systemd/system")}).unitDirectory)
+}
+
+func Test_Unit_newWatchOptions(t *testing.T) {
+	require.Equal(t, time.Second, newWatchOptions(nil).interval)
+	require.Equal(t, time.Minute, newWatchOptions([]WatchOption{WithWatchInterval(time.Minute)}).interval)
+	// Non-positive intervals keep the default.
+	require.Equal(t, time.Second, newWatchOptions([]WatchOption{WithWatchInterval(0)}).interval)
+
+	running := &dbus.UnitStatus{Description: "A", LoadState: "loaded", ActiveState: "active", SubState: "running"}
+	renamed := &dbus.UnitStatus{Description: "B", LoadState: "loaded", ActiveState: "active", SubState: "running"}
+	exited := &dbus.UnitStatus{Description: "A", LoadState: "loaded", ActiveState: "active", SubState: "exited"}
+	require.True(t, newWatchOptions(nil).changed(running, renamed))
+	require.False(t, newWatchOptions(nil).changed(running, running))
+
+	o := newWatchOptions([]WatchOption{WithChangeFilter(ActiveStateChanged)})
+	require.False(t, o.changed(running, renamed))
+	require.True(t, o.changed(running, exited))
+}
+
+func Test_Unit_WithBusAddress(t *testing.T) {
+	require.Empty(t, newOptions(nil).busAddress)
+	require.Equal(t, "unix:path=/tmp/bus", newOptions([]Option{WithBusAddress("unix:path=/tmp/bus")}).busAddress)
+}
+
+func Test_Unit_watchOptions_report(t *testing.T) {
+	active := &dbus.UnitStatus{ActiveState: "active", SubState: "running"}
+	restarting := &dbus.UnitStatus{ActiveState: "activating", SubState: "auto-restart"}
+	failed := &dbus.UnitStatus{ActiveState: "failed", SubState: "failed"}
+
+	// Everything is reported by default, including unloaded units.
+	o := newWatchOptions(nil)
+	require.True(t, o.report(active))
+	require.True(t, o.report(nil))
+
+	o = newWatchOptions([]WatchOption{OnlyStates("failed", "active")})
+	require.True(t, o.report(active))
+	require.True(t, o.report(failed))
+	require.False(t, o.report(restarting))
+	require.False(t, o.report(nil))
+
+	o = newWatchOptions([]WatchOption{IgnoreSubState("auto-restart")})
+	require.True(t, o.report(active))
+	require.False(t, o.report(restarting))
+	require.True(t, o.report(nil))
+
+	// Filters compose.
+	o = newWatchOptions([]WatchOption{OnlyStates("activating", "failed"), IgnoreSubState("auto-restart")})
+	require.False(t, o.report(restarting))
+	require.True(t, o.report(failed))
+
+	require.Zero(t, newWatchOptions(nil).debounce)
+	require.Equal(t, 2*time.Second, newWatchOptions([]WatchOption{Debounce(2 * time.Second)}).debounce)
+}
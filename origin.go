@@ -0,0 +1,94 @@
+package systemdmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// originTTL is how long after enqueuing a job the state changes of its unit
+// are attributed to the operation which enqueued it.
+const originTTL = time.Minute
+
+// maxOrigins bounds how many units origins are tracked for, past which
+// expired origins are dropped.
+const maxOrigins = 1024
+
+// jobOrigin is the operation which enqueued the last job of a unit.
+type jobOrigin struct {
+	op          Operation
+	jobID       int
+	spanContext trace.SpanContext
+	enqueued    time.Time
+}
+
+// jobOrigins tracks the operations which enqueued jobs, so state changes
+// observed by watches can be linked to the operations which caused them.
+type jobOrigins struct {
+	mutex sync.Mutex
+	units map[string]jobOrigin
+}
+
+// record sets the origin of the last job of the named unit, if the operation
+// is traced.
+func (o *jobOrigins) record(unit string, origin jobOrigin) {
+	if !origin.spanContext.IsValid() {
+		return
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.units == nil {
+		o.units = make(map[string]jobOrigin)
+	}
+	if len(o.units) >= maxOrigins {
+		for u, previous := range o.units {
+			if origin.enqueued.Sub(previous.enqueued) > originTTL {
+				delete(o.units, u)
+			}
+		}
+	}
+	o.units[unit] = origin
+}
+
+// get returns the origin of the last job of the named unit, unless it was
+// enqueued too long before now to be the cause of state changes.
+func (o *jobOrigins) get(unit string, now time.Time) (jobOrigin, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	origin, ok := o.units[unit]
+	if !ok || now.Sub(origin.enqueued) > originTTL {
+		return jobOrigin{}, false
+	}
+
+	return origin, true
+}
+
+// startEventSpan starts the span of a state change observed by a watch,
+// linked to the span of the operation which caused it, if any was issued
+// through this manager.
+func (m *manager) startEventSpan(ctx context.Context, unit string, status *dbus.UnitStatus) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithAttributes(otelattr.String("unit", unit))}
+	if status != nil {
+		opts = append(opts, trace.WithAttributes(
+			otelattr.String("active_state", status.ActiveState),
+			otelattr.String("sub_state", status.SubState),
+		))
+	}
+	if origin, ok := m.origins.get(unit, time.Now()); ok {
+		opts = append(opts, trace.WithLinks(trace.Link{
+			SpanContext: origin.spanContext,
+			Attributes: []otelattr.KeyValue{
+				otelattr.String("operation", string(origin.op)),
+				otelattr.Int("job.id", origin.jobID),
+			},
+		}))
+	}
+
+	return otel.Tracer(name).Start(ctx, "Watch.Event", opts...)
+}
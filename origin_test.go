@@ -0,0 +1,45 @@
+package systemdmanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_Unit_jobOrigins(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+	})
+	now := time.Now()
+
+	var origins jobOrigins
+	_, ok := origins.get("a.service", now)
+	require.False(t, ok)
+
+	// Untraced operations aren't recorded.
+	origins.record("a.service", jobOrigin{op: OperationStart, jobID: 1, enqueued: now})
+	_, ok = origins.get("a.service", now)
+	require.False(t, ok)
+
+	origins.record("a.service", jobOrigin{op: OperationStart, jobID: 2, spanContext: spanContext, enqueued: now})
+	origin, ok := origins.get("a.service", now.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, OperationStart, origin.op)
+	require.Equal(t, 2, origin.jobID)
+	require.Equal(t, spanContext, origin.spanContext)
+
+	// State changes long after the job aren't its doing.
+	_, ok = origins.get("a.service", now.Add(originTTL+time.Second))
+	require.False(t, ok)
+
+	// Expired origins are dropped once too many units are tracked.
+	for i := range maxOrigins {
+		origins.record(fmt.Sprintf("%d.service", i), jobOrigin{spanContext: spanContext, enqueued: now})
+	}
+	origins.record("b.service", jobOrigin{spanContext: spanContext, enqueued: now.Add(2 * originTTL)})
+	require.Len(t, origins.units, 1)
+}
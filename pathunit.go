@@ -0,0 +1,198 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// PathTriggerType is the setting of a path unit trigger.
+type PathTriggerType string
+
+const (
+	// PathExists activates the unit while the path exists.
+	PathExists PathTriggerType = "PathExists"
+	// PathExistsGlob activates the unit while a path matching the glob
+	// exists.
+	PathExistsGlob PathTriggerType = "PathExistsGlob"
+	// PathChanged activates the unit when the file is closed after being
+	// written, or is created, removed, or moved.
+	PathChanged PathTriggerType = "PathChanged"
+	// PathModified activates the unit like PathChanged, and on every write.
+	PathModified PathTriggerType = "PathModified"
+	// DirectoryNotEmpty activates the unit while the directory holds any
+	// file.
+	DirectoryNotEmpty PathTriggerType = "DirectoryNotEmpty"
+)
+
+// PathTrigger is a path watched by a path unit, such as PathExists=.
+type PathTrigger struct {
+	Type PathTriggerType `json:"type" yaml:"type"`
+	Path string          `json:"path" yaml:"path"`
+}
+
+// UnitPathTriggers are the paths watched by a path unit, and the unit it
+// activates.
+type UnitPathTriggers struct {
+	// Unit is the unit activated, e.g. "foo.service" for "foo.path".
+	Unit     string        `json:"unit" yaml:"unit"`
+	Triggers []PathTrigger `json:"triggers" yaml:"triggers"`
+	// Result is "success", or why the path unit failed, e.g.
+	// "trigger-limit-hit".
+	Result string `json:"result" yaml:"result"`
+}
+
+// PathTriggers returns the paths watched by the named path unit, and the
+// unit it activates when they're triggered. It fails with ErrWrongUnitType
+// for other units.
+func (m *manager) PathTriggers(parentCtx context.Context, unit string) (UnitPathTriggers, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "PathTriggers")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.unitProperties(ctx, unit, "Path")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitPathTriggers{}, err
+	}
+	p, err := decodePathTriggers(props)
+	if err != nil {
+		err = fmt.Errorf("failed to decode path triggers of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitPathTriggers{}, err
+	}
+	span.SetAttributes(otelattr.String("activates", p.Unit), otelattr.Int("triggers", len(p.Triggers)))
+	span.SetStatus(otelcodes.Ok, "retrieved path triggers")
+
+	return p, nil
+}
+
+// decodePathTriggers decodes the properties of a path unit.
+func decodePathTriggers(props map[string]godbus.Variant) (UnitPathTriggers, error) {
+	var (
+		p    UnitPathTriggers
+		errs = make([]error, 3)
+	)
+	p.Unit, errs[0] = decodeProperty[string](props["Unit"])
+	p.Triggers, errs[1] = decodeProperty[[]PathTrigger](props["Paths"])
+	p.Result, errs[2] = decodeProperty[string](props["Result"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitPathTriggers{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// TransientPathSpec describes a transient path unit, which only exists
+// until it stops.
+type TransientPathSpec struct {
+	// Name is the unit name, e.g. "upload.path".
+	Name        string
+	Description string
+	// Unit is the unit to activate, which must be loadable, e.g. installed.
+	// Defaults to the service of the same name as the path unit.
+	Unit     string
+	Triggers []PathTrigger
+	// MakeDirectory creates the watched directories if missing.
+	MakeDirectory bool
+}
+
+// StartTransientPath creates and starts a transient path unit, which
+// activates a unit when a file appears or changes, e.g. to process uploads
+// dropped in a directory.
+func (m *manager) StartTransientPath(parentCtx context.Context, spec TransientPathSpec, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "StartTransientPath")
+	span.SetAttributes(otelattr.String("unit", spec.Name), otelattr.String("activates", spec.Unit))
+	defer span.End()
+
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	props, err := m.transientPathProperties(spec)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	err = m.runJob(ctx, OperationStart, spec.Name, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartTransientUnitContext(ctx, spec.Name, string(o.jobMode), props, resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully started transient path unit %q", spec.Name))
+
+	return nil
+}
+
+// transientPathProperties converts a spec into the D-Bus properties of a
+// transient path unit.
+func (m *manager) transientPathProperties(spec TransientPathSpec) ([]dbus.Property, error) {
+	if !strings.HasSuffix(spec.Name, ".path") {
+		return nil, fmt.Errorf("%w %q: transient path units must be paths", ErrInvalidUnitName, spec.Name)
+	}
+	if len(spec.Triggers) == 0 {
+		return nil, fmt.Errorf("transient path unit %q needs at least one trigger", spec.Name)
+	}
+	for _, t := range spec.Triggers {
+		switch t.Type {
+		case PathExists, PathExistsGlob, PathChanged, PathModified, DirectoryNotEmpty:
+		default:
+			return nil, fmt.Errorf("invalid trigger %s=%s of transient path unit %q: unknown type", t.Type, t.Path, spec.Name)
+		}
+		if !path.IsAbs(t.Path) {
+			return nil, fmt.Errorf("invalid trigger %s=%s of transient path unit %q: path must be absolute", t.Type, t.Path, spec.Name)
+		}
+	}
+	activated := spec.Unit
+	if activated == "" {
+		activated = pathActivatedUnit(spec.Name, nil)
+	}
+	if err := m.validateUnit(activated); err != nil {
+		return nil, err
+	}
+
+	props := []dbus.Property{
+		{Name: "Paths", Value: godbus.MakeVariant(spec.Triggers)},
+		{Name: "Unit", Value: godbus.MakeVariant(activated)},
+	}
+	if spec.Description != "" {
+		props = append(props, dbus.PropDescription(spec.Description))
+	}
+	if spec.MakeDirectory {
+		props = append(props, dbus.Property{Name: "MakeDirectory", Value: godbus.MakeVariant(true)})
+	}
+
+	return props, nil
+}
+
+// pathActivatedUnit returns the unit activated by the named path unit, as
+// set by Unit= in its unit file f, if any, or the service of the same name.
+func pathActivatedUnit(unit string, f *unitfile.File) string {
+	if f != nil {
+		if activated, ok := f.Get("Path", "Unit"); ok && activated != "" {
+			return activated
+		}
+	}
+
+	return strings.TrimSuffix(unit, ".path") + ".service"
+}
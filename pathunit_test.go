@@ -0,0 +1,91 @@
+package systemdmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodePathTriggers(t *testing.T) {
+	p, err := decodePathTriggers(map[string]godbus.Variant{
+		"Unit": godbus.MakeVariant("upload.service"),
+		"Paths": godbus.MakeVariant([]PathTrigger{
+			{Type: PathExists, Path: "/run/upload"},
+			{Type: PathChanged, Path: "/var/spool/upload"},
+		}),
+		"Result": godbus.MakeVariant("success"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitPathTriggers{
+		Unit: "upload.service",
+		Triggers: []PathTrigger{
+			{Type: PathExists, Path: "/run/upload"},
+			{Type: PathChanged, Path: "/var/spool/upload"},
+		},
+		Result: "success",
+	}, p)
+
+	_, err = decodePathTriggers(map[string]godbus.Variant{"Unit": godbus.MakeVariant(uint32(1))})
+	require.Error(t, err)
+}
+
+func Test_Unit_transientPathProperties(t *testing.T) {
+	m := &manager{}
+	props, err := m.transientPathProperties(TransientPathSpec{
+		Name:          "upload.path",
+		Description:   "Uploads",
+		Triggers:      []PathTrigger{{Type: DirectoryNotEmpty, Path: "/var/spool/upload"}},
+		MakeDirectory: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []dbus.Property{
+		{Name: "Paths", Value: godbus.MakeVariant([]PathTrigger{{Type: DirectoryNotEmpty, Path: "/var/spool/upload"}})},
+		{Name: "Unit", Value: godbus.MakeVariant("upload.service")},
+		dbus.PropDescription("Uploads"),
+		{Name: "MakeDirectory", Value: godbus.MakeVariant(true)},
+	}, props)
+	require.Equal(t, "a(ss)", props[0].Value.Signature().String())
+
+	invalid := []TransientPathSpec{
+		{Name: "upload.service", Triggers: []PathTrigger{{Type: PathExists, Path: "/run/upload"}}},
+		{Name: "upload.path"},
+		{Name: "upload.path", Triggers: []PathTrigger{{Type: "PathGone", Path: "/run/upload"}}},
+		{Name: "upload.path", Triggers: []PathTrigger{{Type: PathExists, Path: "run/upload"}}},
+		{Name: "upload.path", Unit: "bad unit", Triggers: []PathTrigger{{Type: PathExists, Path: "/run/upload"}}},
+	}
+	for _, spec := range invalid {
+		_, err := m.transientPathProperties(spec)
+		require.Error(t, err, spec)
+	}
+
+	// Activated units must be allowed too.
+	m = &manager{options: newOptions([]Option{WithAllowedUnits("upload.*")})}
+	_, err = m.transientPathProperties(TransientPathSpec{
+		Name:     "upload.path",
+		Unit:     "other.service",
+		Triggers: []PathTrigger{{Type: PathExists, Path: "/run/upload"}},
+	})
+	require.ErrorIs(t, err, ErrUnitNotAllowed)
+}
+
+func Test_Unit_pathActivatedUnit(t *testing.T) {
+	require.Equal(t, "upload.service", pathActivatedUnit("upload.path", nil))
+
+	f, err := unitfile.Parse(strings.NewReader("[Path]\nPathExists=/run/upload\nUnit=process.service\n"))
+	require.NoError(t, err)
+	require.Equal(t, "process.service", pathActivatedUnit("upload.path", f))
+
+	f, err = unitfile.Parse(strings.NewReader("[Path]\nPathExists=/run/upload\n"))
+	require.NoError(t, err)
+	require.Equal(t, "upload.service", pathActivatedUnit("upload.path", f))
+}
+
+func Test_Unit_PathTriggers_WrongUnitType(t *testing.T) {
+	_, err := (&manager{}).PathTriggers(context.Background(), "upload.service")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
@@ -0,0 +1,112 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrPermissionDenied means the process isn't allowed to perform an action.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Action is a polkit action guarding systemd operations.
+type Action string
+
+const (
+	// ActionManageUnits guards starting, stopping, and restarting units.
+	ActionManageUnits Action = "org.freedesktop.systemd1.manage-units"
+	// ActionManageUnitFiles guards enabling, disabling, and linking unit
+	// files.
+	ActionManageUnitFiles Action = "org.freedesktop.systemd1.manage-unit-files"
+	// ActionReloadDaemon guards reloading systemd configuration.
+	ActionReloadDaemon Action = "org.freedesktop.systemd1.reload-daemon"
+)
+
+const (
+	polkitDest      = "org.freedesktop.PolicyKit1"
+	polkitPath      = godbus.ObjectPath("/org/freedesktop/PolicyKit1/Authority")
+	polkitInterface = "org.freedesktop.PolicyKit1.Authority"
+)
+
+// accessDeniedErrorNames are D-Bus errors returned when polkit denies a call.
+var accessDeniedErrorNames = []string{
+	"org.freedesktop.DBus.Error.AccessDenied",
+	"org.freedesktop.DBus.Error.InteractiveAuthorizationRequired",
+}
+
+// IsAccessDenied returns whether err is a D-Bus error denying access to a
+// call, e.g. by polkit.
+func IsAccessDenied(err error) bool {
+	var dbusErr godbus.Error
+
+	return errors.As(err, &dbusErr) && slices.Contains(accessDeniedErrorNames, dbusErr.Name)
+}
+
+// CanManage returns whether the current process is authorized to perform
+// action on the named unit, without prompting for authentication.
+func (m *manager) CanManage(parentCtx context.Context, unit string, action Action) (bool, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "CanManage")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("action", string(action)))
+	defer span.End()
+
+	// root is always authorized.
+	if os.Geteuid() == 0 {
+		span.SetStatus(otelcodes.Ok, "authorized as root")
+		return true, nil
+	}
+
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		span.RecordError(ErrDisconnected)
+		span.SetStatus(otelcodes.Error, ErrDisconnected.Error())
+
+		return false, ErrDisconnected
+	}
+
+	// Ask polkit about this very connection, as systemd does.
+	names := m.dbusConn.Load().busConn.Names()
+	if len(names) == 0 {
+		err := errors.New("failed to check authorization: connection has no bus name")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return false, err
+	}
+	subject := struct {
+		Kind    string
+		Details map[string]godbus.Variant
+	}{
+		Kind:    "system-bus-name",
+		Details: map[string]godbus.Variant{"name": godbus.MakeVariant(names[0])},
+	}
+	details := map[string]string{"unit": unit}
+	var result struct {
+		IsAuthorized bool
+		IsChallenge  bool
+		Details      map[string]string
+	}
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().busConn.Object(polkitDest, polkitPath).
+			CallWithContext(ctx, polkitInterface+".CheckAuthorization", 0, subject, string(action), details, uint32(0), "").
+			Store(&result)
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to check authorization for %q on unit %q: %w", action, unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return false, err
+	}
+	span.SetAttributes(otelattr.Bool("authorized", result.IsAuthorized))
+	span.SetStatus(otelcodes.Ok, "checked authorization")
+
+	return result.IsAuthorized, nil
+}
@@ -0,0 +1,18 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_IsAccessDenied(t *testing.T) {
+	require.True(t, IsAccessDenied(godbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied"}))
+	require.True(t, IsAccessDenied(fmt.Errorf("wrapped: %w", godbus.Error{Name: "org.freedesktop.DBus.Error.InteractiveAuthorizationRequired"})))
+	require.False(t, IsAccessDenied(godbus.Error{Name: "org.freedesktop.DBus.Error.NoReply"}))
+	require.False(t, IsAccessDenied(errors.New("access denied")))
+	require.False(t, IsAccessDenied(nil))
+}
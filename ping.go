@@ -0,0 +1,61 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Ping checks that systemd answers over D-Bus, by reading its version. It
+// isn't retried, so failures surface as soon as connectivity breaks.
+func (m *manager) Ping(parentCtx context.Context) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Ping")
+	defer span.End()
+
+	version, err := m.ping(ctx)
+	m.pingFailed.Store(err != nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetAttributes(otelattr.String("version", version))
+	span.SetStatus(otelcodes.Ok, "systemd is reachable")
+
+	return nil
+}
+
+// ping implements Ping, returning the version of systemd.
+func (m *manager) ping(ctx context.Context) (string, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return "", ErrDisconnected
+	}
+
+	return m.dbusConn.Load().version(ctx)
+}
+
+// version returns the version of systemd.
+func (c *conn) version(ctx context.Context) (string, error) {
+	var version string
+	err := c.systemd().
+		CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, managerInterface, "Version").
+		Store(&version)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach systemd: %w", err)
+	}
+
+	return version, nil
+}
+
+// Healthy reports whether the D-Bus connection is up and the last Ping, if
+// any, succeeded. It doesn't call systemd, so it is cheap enough for
+// readiness probes, which should Ping periodically to keep it current.
+func (m *manager) Healthy() bool {
+	return m.dbusConn.Load().Connected() && !m.pingFailed.Load()
+}
@@ -0,0 +1,6 @@
+// Package portable attaches and detaches portable service images through
+// systemd-portabled, as portablectl does.
+package portable
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/portable"
@@ -0,0 +1,30 @@
+//go:build linux
+
+package portable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_ListImages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	images, err := mgr.ListImages(ctx)
+	require.NoError(t, err)
+	for _, image := range images {
+		require.NotEmpty(t, image.Name)
+		require.NotEmpty(t, image.State)
+	}
+
+	_, err = mgr.Attach(ctx, "systemdmanager-e2e-missing", AttachOptions{Runtime: true})
+	require.Error(t, err)
+}
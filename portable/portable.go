@@ -0,0 +1,269 @@
+package portable
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// portableDest is the D-Bus destination of portabled.
+	portableDest = "org.freedesktop.portable1"
+	// portablePath is the D-Bus object path of portabled.
+	portablePath godbus.ObjectPath = "/org/freedesktop/portable1"
+	// managerInterface is the D-Bus interface of portabled.
+	managerInterface = "org.freedesktop.portable1.Manager"
+)
+
+// flagRuntime attaches to, or detaches from, /run rather than /etc.
+const flagRuntime uint64 = 1 << 0
+
+// Profiles shipped with systemd, which sandbox the services of an image.
+const (
+	ProfileDefault   = "default"
+	ProfileNoNetwork = "nonetwork"
+	ProfileStrict    = "strict"
+	ProfileTrusted   = "trusted"
+)
+
+// Image is a portable service image.
+type Image struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is "directory", "subvolume", "raw", or "block".
+	Type     string `json:"type" yaml:"type"`
+	ReadOnly bool   `json:"read_only" yaml:"read_only"`
+	// State is "detached", "attached", "attached-runtime", "enabled",
+	// "running", etc.
+	State      string            `json:"state" yaml:"state"`
+	CreatedAt  time.Time         `json:"created_at" yaml:"created_at"`
+	ModifiedAt time.Time         `json:"modified_at" yaml:"modified_at"`
+	Usage      uint64            `json:"usage" yaml:"usage"`
+	Path       godbus.ObjectPath `json:"path" yaml:"path"`
+}
+
+// Change is a file created or removed by attaching or detaching an image.
+type Change struct {
+	// Type is e.g. "symlink", "copy", "write", "mkdir", or "unlink".
+	Type string `json:"type" yaml:"type"`
+	Path string `json:"path" yaml:"path"`
+	// Source is the file a copy or symlink is made from.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// AttachOptions configures attaching an image.
+type AttachOptions struct {
+	// Extensions are extension images layered on top of the image.
+	Extensions []string
+	// Matches select the units to attach by prefix. Defaults to those
+	// named after the image.
+	Matches []string
+	// Profile is the sandboxing profile, one of the Profile constants.
+	// Defaults to ProfileDefault.
+	Profile string
+	// Runtime attaches to /run, which doesn't survive reboots.
+	Runtime bool
+	// CopyMode is "copy" or "symlink". Defaults to symlinking when
+	// possible.
+	CopyMode string
+}
+
+// DetachOptions configures detaching an image.
+type DetachOptions struct {
+	// Extensions are the extension images the image was attached with.
+	Extensions []string
+	// Runtime detaches from /run, for images attached with Runtime.
+	Runtime bool
+}
+
+// Manager attaches and detaches portable service images.
+type Manager interface {
+	Attach(ctx context.Context, image string, opts AttachOptions) ([]Change, error)
+	Detach(ctx context.Context, image string, opts DetachOptions) ([]Change, error)
+	ListImages(ctx context.Context) ([]Image, error)
+}
+
+// manager manages images via a D-Bus connection to portabled.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to portabled failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to portabled over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, portableDest, portablePath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up portable service manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// listedImage is an image as returned by ListImages.
+type listedImage struct {
+	Name       string
+	Type       string
+	ReadOnly   bool
+	CreatedAt  uint64
+	ModifiedAt uint64
+	Usage      uint64
+	State      string
+	Path       godbus.ObjectPath
+}
+
+// ListImages returns the portable service images found by portabled.
+func (m *manager) ListImages(parentCtx context.Context) ([]Image, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListImages")
+	defer span.End()
+
+	var listed []listedImage
+	if err := m.conn.Call(ctx, "ListImages", nil, &listed); err != nil {
+		err = fmt.Errorf("failed to list images: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "listed images")
+
+	images := make([]Image, 0, len(listed))
+	for _, l := range listed {
+		images = append(images, Image{
+			Name:       l.Name,
+			Type:       l.Type,
+			ReadOnly:   l.ReadOnly,
+			State:      l.State,
+			CreatedAt:  usecToTime(l.CreatedAt),
+			ModifiedAt: usecToTime(l.ModifiedAt),
+			Usage:      l.Usage,
+			Path:       l.Path,
+		})
+	}
+
+	return images, nil
+}
+
+// usecToTime converts a timestamp in microseconds since the epoch, where
+// zero means unknown.
+func usecToTime(usec uint64) time.Time {
+	if usec == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(int64(usec)).UTC()
+}
+
+// Attach attaches the units of an image, which is a name known to
+// portabled or a path, making them available to systemd. The units still
+// need to be enabled or started.
+func (m *manager) Attach(parentCtx context.Context, image string, opts AttachOptions) ([]Change, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Attach")
+	span.SetAttributes(
+		otelattr.String("image", image),
+		otelattr.StringSlice("extensions", opts.Extensions),
+		otelattr.String("profile", opts.Profile),
+	)
+	defer span.End()
+
+	var changes []Change
+	method, args := attachCall(image, opts)
+	if err := m.conn.Call(ctx, method, args, &changes); err != nil {
+		err = fmt.Errorf("failed to attach image %q: %w", image, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("attached image %q", image))
+
+	return changes, nil
+}
+
+// Detach detaches the units of an image, which must be stopped.
+func (m *manager) Detach(parentCtx context.Context, image string, opts DetachOptions) ([]Change, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Detach")
+	span.SetAttributes(otelattr.String("image", image), otelattr.StringSlice("extensions", opts.Extensions))
+	defer span.End()
+
+	// Detaching only reports a type and path.
+	var removed []struct{ Type, Path string }
+	method, args := detachCall(image, opts)
+	if err := m.conn.Call(ctx, method, args, &removed); err != nil {
+		err = fmt.Errorf("failed to detach image %q: %w", image, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("detached image %q", image))
+
+	changes := make([]Change, 0, len(removed))
+	for _, r := range removed {
+		changes = append(changes, Change{Type: r.Type, Path: r.Path})
+	}
+
+	return changes, nil
+}
+
+// attachCall returns the method and arguments attaching an image. The
+// variant with extensions is only used when needed, for compatibility with
+// older portabled versions.
+func attachCall(image string, opts AttachOptions) (string, []any) {
+	profile := opts.Profile
+	if profile == "" {
+		profile = ProfileDefault
+	}
+	if len(opts.Extensions) == 0 {
+		return "AttachImage", []any{image, opts.Matches, profile, opts.Runtime, opts.CopyMode}
+	}
+
+	var flags uint64
+	if opts.Runtime {
+		flags |= flagRuntime
+	}
+
+	return "AttachImageWithExtensions", []any{image, opts.Extensions, opts.Matches, profile, opts.CopyMode, flags}
+}
+
+// detachCall returns the method and arguments detaching an image, like
+// attachCall.
+func detachCall(image string, opts DetachOptions) (string, []any) {
+	if len(opts.Extensions) == 0 {
+		return "DetachImage", []any{image, opts.Runtime}
+	}
+
+	var flags uint64
+	if opts.Runtime {
+		flags |= flagRuntime
+	}
+
+	return "DetachImageWithExtensions", []any{image, opts.Extensions, flags}
+}
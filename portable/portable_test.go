@@ -0,0 +1,33 @@
+package portable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_attachCall(t *testing.T) {
+	method, args := attachCall("/var/lib/portables/app.raw", AttachOptions{Runtime: true})
+	require.Equal(t, "AttachImage", method)
+	require.Equal(t, []any{"/var/lib/portables/app.raw", []string(nil), ProfileDefault, true, ""}, args)
+
+	method, args = attachCall("app", AttachOptions{
+		Extensions: []string{"app-debug.raw"},
+		Matches:    []string{"app-web"},
+		Profile:    ProfileStrict,
+		Runtime:    true,
+		CopyMode:   "copy",
+	})
+	require.Equal(t, "AttachImageWithExtensions", method)
+	require.Equal(t, []any{"app", []string{"app-debug.raw"}, []string{"app-web"}, ProfileStrict, "copy", flagRuntime}, args)
+}
+
+func Test_Unit_detachCall(t *testing.T) {
+	method, args := detachCall("app", DetachOptions{})
+	require.Equal(t, "DetachImage", method)
+	require.Equal(t, []any{"app", false}, args)
+
+	method, args = detachCall("app", DetachOptions{Extensions: []string{"app-debug.raw"}, Runtime: true})
+	require.Equal(t, "DetachImageWithExtensions", method)
+	require.Equal(t, []any{"app", []string{"app-debug.raw"}, flagRuntime}, args)
+}
@@ -0,0 +1,64 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// IsActive returns whether the named unit is active, including while it's
+// reloading, as systemctl is-active does.
+func (m *manager) IsActive(parentCtx context.Context, unit string) (bool, error) {
+	return m.unitPredicate(parentCtx, "IsActive", unit, "ActiveState", func(state string) bool {
+		return state == "active" || state == "reloading" || state == "refreshing"
+	})
+}
+
+// IsFailed returns whether the named unit is in the failed state, as
+// systemctl is-failed does.
+func (m *manager) IsFailed(parentCtx context.Context, unit string) (bool, error) {
+	return m.unitPredicate(parentCtx, "IsFailed", unit, "ActiveState", func(state string) bool {
+		return state == "failed"
+	})
+}
+
+// Exists returns whether the named unit is loaded or has a unit file,
+// including masked units.
+func (m *manager) Exists(parentCtx context.Context, unit string) (bool, error) {
+	return m.unitPredicate(parentCtx, "Exists", unit, "LoadState", func(state string) bool {
+		return state != "not-found"
+	})
+}
+
+// unitPredicate retrieves a single string property of the named unit, and
+// returns whether it satisfies fn.
+func (m *manager) unitPredicate(parentCtx context.Context, op, unit, prop string, fn func(string) bool) (bool, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, op)
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	variant, err := m.unitProperty(ctx, unit, "Unit", prop)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return false, err
+	}
+	state, err := decodeProperty[string](variant)
+	if err != nil {
+		err = fmt.Errorf("failed to decode property %q of unit %q: %w", prop, unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return false, err
+	}
+	ok := fn(state)
+	span.SetAttributes(otelattr.String(prop, state), otelattr.Bool("result", ok))
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("retrieved %s of unit %q", prop, unit))
+
+	return ok, nil
+}
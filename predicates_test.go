@@ -0,0 +1,18 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Predicates_InvalidUnitName(t *testing.T) {
+	m := &manager{}
+	_, err := m.IsActive(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+	_, err = m.IsFailed(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+	_, err = m.Exists(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrInvalidUnitName)
+}
@@ -0,0 +1,190 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrUnsupported means a Manager implementation, or the version of systemd
+// it manages, doesn't support an operation.
+var ErrUnsupported = errors.New("operation not supported by manager")
+
+// usecInfinity is how systemd encodes an infinite time span or timestamp.
+const usecInfinity = math.MaxUint64
+
+// propertyGetter is implemented by managers able to read raw unit
+// properties.
+type propertyGetter interface {
+	unitProperty(ctx context.Context, unit, iface, prop string) (godbus.Variant, error)
+}
+
+// GetProperty returns a property of the named unit, decoded into T. iface is
+// the D-Bus interface holding the property, either in full, e.g.
+// "org.freedesktop.systemd1.Service", or relative to systemd's, e.g.
+// "Service" or "Unit".
+//
+// Timestamps and time spans, which systemd encodes in microseconds, may be
+// decoded into time.Time and time.Duration. Unset timestamps decode into the
+// zero time, and infinite time spans into the maximum duration.
+func GetProperty[T any](parentCtx context.Context, m Manager, unit, iface, prop string) (T, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "GetProperty")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("property", prop))
+	defer span.End()
+
+	var v T
+	getter, ok := m.(propertyGetter)
+	if !ok {
+		err := fmt.Errorf("failed to retrieve property %q of unit %q: %w", prop, unit, ErrUnsupported)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return v, err
+	}
+	v, err := getProperty[T](ctx, getter, unit, iface, prop)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return v, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved unit property")
+
+	return v, nil
+}
+
+// getProperty implements GetProperty.
+func getProperty[T any](ctx context.Context, getter propertyGetter, unit, iface, prop string) (T, error) {
+	var v T
+	variant, err := getter.unitProperty(ctx, unit, iface, prop)
+	if err != nil {
+		return v, err
+	}
+	if v, err = decodeProperty[T](variant); err != nil {
+		return v, fmt.Errorf("failed to decode property %q of unit %q: %w", prop, unit, err)
+	}
+
+	return v, nil
+}
+
+// decodeProperty decodes a property value into T.
+func decodeProperty[T any](variant godbus.Variant) (T, error) {
+	var v T
+	// Store panics on missing values.
+	if variant.Value() == nil {
+		return v, errors.New("missing value")
+	}
+	switch p := any(&v).(type) {
+	case *time.Time:
+		var usec uint64
+		if err := godbus.Store([]any{variant.Value()}, &usec); err != nil {
+			return v, err
+		}
+		*p = usecToTime(usec)
+	case *time.Duration:
+		var usec uint64
+		if err := godbus.Store([]any{variant.Value()}, &usec); err != nil {
+			return v, err
+		}
+		*p = usecToDuration(usec)
+	default:
+		if err := godbus.Store([]any{variant.Value()}, &v); err != nil {
+			return v, err
+		}
+	}
+
+	return v, nil
+}
+
+// usecToTime converts a systemd timestamp in microseconds since the epoch.
+// Zero and infinity, meaning unset, convert to the zero time.
+func usecToTime(usec uint64) time.Time {
+	if usec == 0 || usec == usecInfinity {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(int64(usec)).UTC()
+}
+
+// usecToDuration converts a systemd time span in microseconds. Infinity
+// converts to the maximum duration.
+func usecToDuration(usec uint64) time.Duration {
+	if usec >= math.MaxInt64/uint64(time.Microsecond) {
+		return math.MaxInt64
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
+
+// unitProperty returns a raw property of the named unit.
+func (m *manager) unitProperty(ctx context.Context, unit, iface, prop string) (godbus.Variant, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return godbus.Variant{}, err
+	}
+	if !strings.Contains(iface, ".") {
+		iface = systemdDest + "." + iface
+	}
+	if err := checkUnitType(unit, iface); err != nil {
+		return godbus.Variant{}, err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return godbus.Variant{}, ErrDisconnected
+	}
+	key := cacheKey{unit: unit, iface: iface, prop: prop}
+	if cached, ok := m.cache.get(key); ok {
+		return cached.(godbus.Variant), nil
+	}
+
+	var variant godbus.Variant
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().unit(unit).
+			CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, iface, prop).
+			Store(&variant)
+	})
+	if err != nil {
+		return godbus.Variant{}, fmt.Errorf("failed to retrieve property %q of unit %q: %w", prop, unit, err)
+	}
+	m.cache.put(key, variant, m.options.cacheTTL)
+
+	return variant, nil
+}
+
+// unitProperties returns all raw properties of the named unit on an
+// interface, which is given as for GetProperty.
+func (m *manager) unitProperties(ctx context.Context, unit, iface string) (map[string]godbus.Variant, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(iface, ".") {
+		iface = systemdDest + "." + iface
+	}
+	if err := checkUnitType(unit, iface); err != nil {
+		return nil, err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var props map[string]godbus.Variant
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return m.dbusConn.Load().unit(unit).
+			CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, iface).
+			Store(&props)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve properties of unit %q: %w", unit, err)
+	}
+
+	return props, nil
+}
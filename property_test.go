@@ -0,0 +1,83 @@
+package systemdmanager
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePropertyGetter serves properties from a map.
+type fakePropertyGetter map[string]any
+
+func (f fakePropertyGetter) unitProperty(_ context.Context, _, _, prop string) (godbus.Variant, error) {
+	return godbus.MakeVariant(f[prop]), nil
+}
+
+func Test_Unit_decodeProperty(t *testing.T) {
+	s, err := decodeProperty[string](godbus.MakeVariant("active"))
+	require.NoError(t, err)
+	require.Equal(t, "active", s)
+
+	u, err := decodeProperty[uint64](godbus.MakeVariant(uint64(42)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), u)
+
+	ss, err := decodeProperty[[]string](godbus.MakeVariant([]string{"a.target", "b.target"}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.target", "b.target"}, ss)
+
+	ts, err := decodeProperty[time.Time](godbus.MakeVariant(uint64(1700000000123456)))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 123456000, time.UTC), ts)
+
+	ts, err = decodeProperty[time.Time](godbus.MakeVariant(uint64(0)))
+	require.NoError(t, err)
+	require.True(t, ts.IsZero())
+
+	d, err := decodeProperty[time.Duration](godbus.MakeVariant(uint64(1500000)))
+	require.NoError(t, err)
+	require.Equal(t, 1500*time.Millisecond, d)
+
+	d, err = decodeProperty[time.Duration](godbus.MakeVariant(uint64(math.MaxUint64)))
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(math.MaxInt64), d)
+
+	_, err = decodeProperty[uint64](godbus.MakeVariant("not a number"))
+	require.Error(t, err)
+}
+
+func Test_Unit_getProperty(t *testing.T) {
+	getter := fakePropertyGetter{"NRestarts": uint32(3)}
+
+	n, err := getProperty[uint32](t.Context(), getter, "foo.service", "Service", "NRestarts")
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), n)
+
+	_, err = getProperty[[]string](t.Context(), getter, "foo.service", "Service", "NRestarts")
+	require.ErrorContains(t, err, `failed to decode property "NRestarts" of unit "foo.service"`)
+}
+
+func Test_Unit_GetProperty_Unsupported(t *testing.T) {
+	_, err := GetProperty[string](t.Context(), &fakeManager{}, "foo.service", "Unit", "ActiveState")
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func Test_Unit_timestampProperty(t *testing.T) {
+	props := map[string]any{"ActiveEnterTimestamp": uint64(1700000000000000)}
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), timestampProperty(props, "ActiveEnterTimestamp"))
+	require.True(t, timestampProperty(props, "ActiveExitTimestamp").IsZero())
+	require.True(t, timestampProperty(nil, "ActiveEnterTimestamp").IsZero())
+}
+
+func Test_Unit_unitProperty_WrongUnitType(t *testing.T) {
+	// The unit type is checked before calling systemd.
+	m := &manager{}
+	_, err := m.unitProperty(context.Background(), "a.timer", "Service", "ExecMainStartTimestamp")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+	_, err = m.unitProperties(context.Background(), "a.target", "Service")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
@@ -0,0 +1,148 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited means an operation was rejected for exceeding the
+// configured rate limits.
+var ErrRateLimited = errors.New("unit operation rate limited")
+
+// maxIdleUnitBuckets is how many per-unit buckets are kept before idle ones
+// are dropped.
+const maxIdleUnitBuckets = 1024
+
+// Limit is a token bucket limit. The zero value means no limit.
+type Limit struct {
+	// Every is the interval at which a new operation is allowed.
+	Every time.Duration
+	// Burst is how many operations are allowed at once. Defaults to 1.
+	Burst int
+}
+
+// RateLimits configures rate limiting of mutating unit operations.
+type RateLimits struct {
+	// Global limits operations across all units.
+	Global Limit
+	// PerUnit limits operations on each unit.
+	PerUnit Limit
+	// Wait queues operations exceeding the limits until they are allowed,
+	// instead of failing them with ErrRateLimited.
+	Wait bool
+}
+
+// WithRateLimits limits how often mutating unit operations may run, which
+// protects systemd from runaway callers.
+func WithRateLimits(limits RateLimits) Option {
+	return func(o *options) {
+		o.rateLimits = limits
+	}
+}
+
+// tokenBucket implements a Limit.
+type tokenBucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket.
+func newTokenBucket(limit Limit, now time.Time) *tokenBucket {
+	limit.Burst = max(limit.Burst, 1)
+
+	return &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+}
+
+// refill adds the tokens accumulated since the bucket was last used.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = min(b.tokens+float64(now.Sub(b.last))/float64(b.limit.Every), float64(b.limit.Burst))
+	b.last = now
+}
+
+// delay returns how long until a token is available.
+func (b *tokenBucket) delay() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) * float64(b.limit.Every))
+}
+
+// rateLimiter enforces RateLimits.
+type rateLimiter struct {
+	mutex   sync.Mutex
+	global  *tokenBucket
+	perUnit map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// take reserves an operation on the named unit, waiting for it to be allowed
+// if limits.Wait is set.
+func (l *rateLimiter) take(ctx context.Context, limits RateLimits, unit string) error {
+	if limits.Global.Every <= 0 && limits.PerUnit.Every <= 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+	if l.now == nil {
+		l.now = time.Now
+	}
+	now := l.now()
+
+	var buckets []*tokenBucket
+	if limits.Global.Every > 0 {
+		if l.global == nil {
+			l.global = newTokenBucket(limits.Global, now)
+		}
+		buckets = append(buckets, l.global)
+	}
+	if limits.PerUnit.Every > 0 {
+		if l.perUnit == nil {
+			l.perUnit = make(map[string]*tokenBucket)
+		}
+		if len(l.perUnit) > maxIdleUnitBuckets {
+			for u, b := range l.perUnit {
+				if b.refill(now); b.tokens >= float64(b.limit.Burst) {
+					delete(l.perUnit, u)
+				}
+			}
+		}
+		b, ok := l.perUnit[unit]
+		if !ok {
+			b = newTokenBucket(limits.PerUnit, now)
+			l.perUnit[unit] = b
+		}
+		buckets = append(buckets, b)
+	}
+
+	var delay time.Duration
+	for _, b := range buckets {
+		b.refill(now)
+		delay = max(delay, b.delay())
+	}
+	if delay > 0 && !limits.Wait {
+		l.mutex.Unlock()
+		return fmt.Errorf("%w, retry in %s", ErrRateLimited, delay)
+	}
+	// Reserve the tokens now, so operations are queued in order.
+	for _, b := range buckets {
+		b.tokens--
+	}
+	l.mutex.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
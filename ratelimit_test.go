@@ -0,0 +1,66 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_rateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No limits", func(t *testing.T) {
+		var l rateLimiter
+		for range 100 {
+			require.NoError(t, l.take(ctx, RateLimits{}, "a.service"))
+		}
+	})
+
+	t.Run("Per unit limit", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		l := rateLimiter{now: func() time.Time { return now }}
+		limits := RateLimits{PerUnit: Limit{Every: time.Second, Burst: 2}}
+
+		require.NoError(t, l.take(ctx, limits, "a.service"))
+		require.NoError(t, l.take(ctx, limits, "a.service"))
+		require.ErrorIs(t, l.take(ctx, limits, "a.service"), ErrRateLimited)
+		// Other units have their own budget.
+		require.NoError(t, l.take(ctx, limits, "b.service"))
+
+		now = now.Add(time.Second)
+		require.NoError(t, l.take(ctx, limits, "a.service"))
+		require.ErrorIs(t, l.take(ctx, limits, "a.service"), ErrRateLimited)
+	})
+
+	t.Run("Global limit", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		l := rateLimiter{now: func() time.Time { return now }}
+		limits := RateLimits{Global: Limit{Every: time.Second}}
+
+		require.NoError(t, l.take(ctx, limits, "a.service"))
+		require.ErrorIs(t, l.take(ctx, limits, "b.service"), ErrRateLimited)
+	})
+
+	t.Run("Wait queues operations", func(t *testing.T) {
+		var l rateLimiter
+		limits := RateLimits{Global: Limit{Every: 20 * time.Millisecond}, Wait: true}
+
+		start := time.Now()
+		for range 3 {
+			require.NoError(t, l.take(ctx, limits, "a.service"))
+		}
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("Waiting is cancellable", func(t *testing.T) {
+		var l rateLimiter
+		limits := RateLimits{Global: Limit{Every: time.Hour}, Wait: true}
+		require.NoError(t, l.take(ctx, limits, "a.service"))
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, l.take(ctx, limits, "a.service"), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,186 @@
+package systemdmanager
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrNotReady means a readiness probe didn't pass yet.
+var ErrNotReady = errors.New("unit not ready")
+
+// readyPollInterval is how often readiness probes are run while waiting for
+// a unit to be ready.
+const readyPollInterval = 500 * time.Millisecond
+
+// ReadinessProbe checks whether a started unit is ready to serve, failing if
+// it isn't yet. Probes are run repeatedly, by StartAndWaitReady until they
+// pass and by a HealthChecker periodically, so they should be cheap and
+// bound by ctx.
+type ReadinessProbe func(ctx context.Context, m Manager, unit string) error
+
+// StartAndWaitReady starts a unit, and waits for it to be active and for
+// probe to pass. For Type=notify and Type=dbus services, a started unit is
+// already ready, but for Type=simple ones it only means the process was
+// forked. A nil probe only waits for the unit to be active. It fails if the
+// unit fails, and with the last probe error when ctx is done.
+func (m *manager) StartAndWaitReady(parentCtx context.Context, unit string, probe ReadinessProbe, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "StartAndWaitReady")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	if err := m.Start(ctx, unit, opts...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if err := m.waitReady(ctx, unit, probe); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("unit %q is ready", unit))
+
+	return nil
+}
+
+// waitReady blocks until the named unit is active and probe passes.
+func (m *manager) waitReady(ctx context.Context, unit string, probe ReadinessProbe) error {
+	if err := m.waitActive(ctx, unit); err != nil {
+		return err
+	}
+	if probe == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		probeErr := probe(ctx, m, unit)
+		if probeErr == nil {
+			return nil
+		}
+
+		// Don't wait for units that won't become ready.
+		state, err := m.activeState(ctx, unit)
+		if err == nil && state == "failed" {
+			return fmt.Errorf("unit %q: %w", unit, ErrUnitFailed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("unit %q failed readiness probe: %w", unit, errors.Join(ctx.Err(), probeErr))
+		case <-ticker.C:
+		}
+	}
+}
+
+// TCPProbe passes once a TCP connection to address, such as
+// "127.0.0.1:8080", is accepted.
+func TCPProbe(address string) ReadinessProbe {
+	return func(ctx context.Context, _ Manager, _ string) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// HTTPProbe passes once a GET request to url is answered with 200 OK.
+func HTTPProbe(url string) ReadinessProbe {
+	return func(ctx context.Context, _ Manager, _ string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%w: %s responded with status %d", ErrNotReady, url, resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// FileProbe passes once path exists, such as a socket or PID file created
+// by the unit.
+func FileProbe(path string) ReadinessProbe {
+	return func(context.Context, Manager, string) error {
+		_, err := os.Stat(path)
+		return err
+	}
+}
+
+// ExecProbe passes once the command name, run with args, exits with status
+// zero.
+func ExecProbe(name string, args ...string) ReadinessProbe {
+	return func(ctx context.Context, _ Manager, _ string) error {
+		out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w: %s", ErrNotReady, name, err, strings.TrimSpace(string(out)))
+		}
+
+		return nil
+	}
+}
+
+// PropertyProbe passes once a property of the unit, decoded into T as with
+// GetProperty, satisfies ok, such as "NRestarts" staying low.
+func PropertyProbe[T any](iface, prop string, ok func(T) bool) ReadinessProbe {
+	return func(ctx context.Context, m Manager, unit string) error {
+		v, err := GetProperty[T](ctx, m, unit, iface, prop)
+		if err != nil {
+			return err
+		}
+		if !ok(v) {
+			return fmt.Errorf("%w: property %q of unit %q is %v", ErrNotReady, prop, unit, v)
+		}
+
+		return nil
+	}
+}
+
+// JournalProbe passes once the unit logs a message matching pattern since it
+// was last started. Messages of previous runs are ignored.
+func JournalProbe(pattern *regexp.Regexp) ReadinessProbe {
+	return func(ctx context.Context, m Manager, unit string) error {
+		id, err := GetProperty[[]byte](ctx, m, unit, "Unit", "InvocationID")
+		if err != nil {
+			return err
+		}
+		entries, err := readJournal(ctx, "_SYSTEMD_INVOCATION_ID="+hex.EncodeToString(id))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if pattern.MatchString(e.Message) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: no message matching %q logged", ErrNotReady, pattern)
+	}
+}
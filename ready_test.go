@@ -0,0 +1,50 @@
+package systemdmanager
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_TCPProbe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := l.Addr().String()
+	require.NoError(t, TCPProbe(address)(context.Background(), nil, "a.service"))
+
+	require.NoError(t, l.Close())
+	require.Error(t, TCPProbe(address)(context.Background(), nil, "a.service"))
+}
+
+func Test_Unit_HTTPProbe(t *testing.T) {
+	ready := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	require.ErrorIs(t, HTTPProbe(srv.URL)(context.Background(), nil, "a.service"), ErrNotReady)
+	ready = true
+	require.NoError(t, HTTPProbe(srv.URL)(context.Background(), nil, "a.service"))
+}
+
+func Test_Unit_FileProbe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready")
+	require.ErrorIs(t, FileProbe(path)(context.Background(), nil, "a.service"), fs.ErrNotExist)
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	require.NoError(t, FileProbe(path)(context.Background(), nil, "a.service"))
+}
+
+func Test_Unit_JournalProbe_Unsupported(t *testing.T) {
+	err := JournalProbe(nil)(context.Background(), nil, "a.service")
+	require.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,180 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// defaultReloadDebounce is how long ReloadOnChange waits for changes to
+// settle by default.
+const defaultReloadDebounce = time.Second
+
+// Reload synchronously asks the named unit to reload its configuration,
+// which fails if the unit doesn't support reloading.
+func (m *manager) Reload(parentCtx context.Context, unit string, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Reload")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationReload, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().ReloadUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully reloaded unit %q", unit))
+
+	return nil
+}
+
+// ReloadOrRestart synchronously reloads the named unit if it supports
+// reloading, and restarts it otherwise. Units which aren't running are
+// started.
+func (m *manager) ReloadOrRestart(parentCtx context.Context, unit string, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ReloadOrRestart")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	err := m.runJob(ctx, OperationReloadOrRestart, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().ReloadOrRestartUnitContext(ctx, unit, string(o.jobMode), resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully reloaded or restarted unit %q", unit))
+
+	return nil
+}
+
+// ReloadOnChangeOptions configures ReloadOnChange.
+type ReloadOnChangeOptions struct {
+	// Debounce is how long changes must settle before reloading, so that a
+	// burst of writes, e.g. by configuration management, reloads once.
+	// Defaults to one second.
+	Debounce time.Duration
+	// Restart uses ReloadOrRestart instead of Reload, for units which don't
+	// support reloading.
+	Restart bool
+	// OnReload, if not nil, is called with the outcome of every reload.
+	// Failed reloads don't stop watching.
+	OnReload func(err error)
+}
+
+// ReloadOnChange reloads the named unit whenever any of paths changes,
+// including being created, replaced, or removed. Paths may be files, or
+// directories, any file of which changing counts. This is a blocking
+// function, which fails when the paths can't be watched any longer, or when
+// ctx is done.
+func (m *manager) ReloadOnChange(parentCtx context.Context, unit string, paths []string, opts ReloadOnChangeOptions) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ReloadOnChange")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.StringSlice("paths", paths))
+	defer span.End()
+
+	if err := m.validateUnit(unit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if len(paths) == 0 {
+		err := fmt.Errorf("at least one path is required for ReloadOnChange to watch")
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = defaultReloadDebounce
+	}
+	reload := m.Reload
+	if opts.Restart {
+		reload = m.ReloadOrRestart
+	}
+	err := watchChanges(ctx, paths, debounce, func() {
+		err := reload(ctx, unit)
+		if opts.OnReload != nil && ctx.Err() == nil {
+			opts.OnReload(err)
+		}
+	})
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+
+	return err
+}
+
+// watchChanges calls changed once any of paths changes and changes settle
+// for debounce, until ctx is done. Files are watched through their parent
+// directory, so that they're still watched after being replaced.
+func watchChanges(ctx context.Context, paths []string, debounce time.Duration, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch files: %w", err)
+	}
+	defer watcher.Close()
+
+	files, dirs := make(map[string]bool), make(map[string]bool)
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dir = path
+			dirs[path] = true
+		} else {
+			files[path] = true
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", path, err)
+		}
+	}
+
+	// settled fires once changes settle. It's nil while nothing changed.
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("stopped watching files")
+			}
+			return fmt.Errorf("failed to watch files: %w", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("stopped watching files")
+			}
+			if event.Op == fsnotify.Chmod || !files[event.Name] && !dirs[filepath.Dir(event.Name)] {
+				continue
+			}
+			settled = time.After(debounce)
+		case <-settled:
+			settled = nil
+			changed()
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package systemdmanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_watchChanges(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.conf")
+	require.NoError(t, os.WriteFile(file, []byte("a"), 0o644))
+	confDir := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(confDir, 0o755))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchChanges(ctx, []string{file, confDir}, 50*time.Millisecond, func() {
+			changes <- struct{}{}
+		})
+	}()
+	// Give the watch time to start.
+	time.Sleep(100 * time.Millisecond)
+
+	expectChange := func() {
+		t.Helper()
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("change not observed")
+		}
+	}
+
+	// A burst of writes is a single change.
+	for _, content := range []string{"b", "c", "d"} {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+	}
+	expectChange()
+	require.Empty(t, changes)
+
+	// Replacing the file is a change, and it's still watched afterwards.
+	tmp := filepath.Join(dir, ".app.conf.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("e"), 0o644))
+	require.NoError(t, os.Rename(tmp, file))
+	expectChange()
+	require.NoError(t, os.WriteFile(file, []byte("f"), 0o644))
+	expectChange()
+
+	// Files in watched directories count, but not other files.
+	require.NoError(t, os.WriteFile(filepath.Join(confDir, "extra.conf"), []byte("g"), 0o644))
+	expectChange()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.conf"), []byte("h"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+	require.Empty(t, changes)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func Test_Unit_watchChanges_MissingDirectory(t *testing.T) {
+	var called atomic.Bool
+	err := watchChanges(context.Background(), []string{filepath.Join(t.TempDir(), "missing", "app.conf")}, time.Millisecond, func() {
+		called.Store(true)
+	})
+	require.Error(t, err)
+	require.False(t, called.Load())
+}
+
+func Test_Unit_ReloadOnChange_InvalidArguments(t *testing.T) {
+	m := &manager{}
+	require.ErrorIs(t, m.ReloadOnChange(context.Background(), "foo", []string{"/etc/foo.conf"}, ReloadOnChangeOptions{}), ErrInvalidUnitName)
+	require.Error(t, m.ReloadOnChange(context.Background(), "foo.service", nil, ReloadOnChangeOptions{}))
+}
@@ -0,0 +1,6 @@
+// Package resolved resolves host names and inspects the DNS cache of
+// systemd-resolved.
+package resolved
+
+// name is the Tracer name used to identify this instrumentation library.
+const name = "systemd/resolved"
@@ -0,0 +1,41 @@
+//go:build linux
+
+package resolved
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_E2E_Manager_ResolveHostname(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	// localhost is synthesized by resolved, without network access.
+	r, err := mgr.ResolveHostname(ctx, "localhost")
+	require.NoError(t, err)
+	require.Contains(t, r.Addresses, netip.MustParseAddr("127.0.0.1"))
+	require.True(t, r.Authenticated)
+}
+
+func Test_E2E_Manager_Statistics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.FlushCaches(ctx))
+	stats, err := mgr.Statistics(ctx)
+	require.NoError(t, err)
+	require.Zero(t, stats.CacheSize)
+}
@@ -0,0 +1,220 @@
+package resolved
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"syscall"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/internal/bus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// resolvedDest is the D-Bus destination of resolved.
+	resolvedDest = "org.freedesktop.resolve1"
+	// resolvedPath is the D-Bus object path of resolved.
+	resolvedPath godbus.ObjectPath = "/org/freedesktop/resolve1"
+	// managerInterface is the D-Bus interface of resolved.
+	managerInterface = "org.freedesktop.resolve1.Manager"
+)
+
+// Resolution is the result of resolving a host name.
+type Resolution struct {
+	// CanonicalName is the name the addresses belong to, after following
+	// CNAMEs.
+	CanonicalName string       `json:"canonical_name" yaml:"canonical_name"`
+	Addresses     []netip.Addr `json:"addresses" yaml:"addresses"`
+	// Authenticated reports whether the result was validated with DNSSEC,
+	// or comes from a trusted source such as /etc/hosts.
+	Authenticated bool `json:"authenticated" yaml:"authenticated"`
+}
+
+// Statistics are counters of resolved, since it started or statistics were
+// last reset.
+type Statistics struct {
+	// CacheSize is the number of cached entries.
+	CacheSize   uint64 `json:"cache_size" yaml:"cache_size"`
+	CacheHits   uint64 `json:"cache_hits" yaml:"cache_hits"`
+	CacheMisses uint64 `json:"cache_misses" yaml:"cache_misses"`
+	// CurrentTransactions is the number of ongoing DNS transactions.
+	CurrentTransactions uint64 `json:"current_transactions" yaml:"current_transactions"`
+	TotalTransactions   uint64 `json:"total_transactions" yaml:"total_transactions"`
+	DNSSECSecure        uint64 `json:"dnssec_secure" yaml:"dnssec_secure"`
+	DNSSECInsecure      uint64 `json:"dnssec_insecure" yaml:"dnssec_insecure"`
+	DNSSECBogus         uint64 `json:"dnssec_bogus" yaml:"dnssec_bogus"`
+	DNSSECIndeterminate uint64 `json:"dnssec_indeterminate" yaml:"dnssec_indeterminate"`
+}
+
+// flagAuthenticated is set in the flags of a resolution which is
+// authenticated.
+const flagAuthenticated = 1 << 9
+
+// Manager resolves host names and inspects resolved.
+type Manager interface {
+	FlushCaches(ctx context.Context) error
+	ResetStatistics(ctx context.Context) error
+	ResolveHostname(ctx context.Context, hostname string) (Resolution, error)
+	Statistics(ctx context.Context) (Statistics, error)
+}
+
+// manager resolves names via a D-Bus connection to resolved.
+type manager struct {
+	conn *bus.Conn
+}
+
+// Assert manager fulfills the Manager interface.
+var _ Manager = (*manager)(nil)
+
+// Option configures a Manager.
+type Option = bus.Option
+
+// WithRetryPolicy retries calls to resolved failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p systemdmanager.RetryPolicy) Option {
+	return bus.WithRetryPolicy(p)
+}
+
+// New returns a Manager connected to resolved over the system bus. The
+// connection is closed when ctx is done.
+func New(ctx context.Context, opts ...Option) (Manager, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(ctx, "New")
+	defer span.End()
+
+	conn, err := bus.Connect(ctx, resolvedDest, resolvedPath, managerInterface, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "failed setting up resolver manager")
+
+		return nil, err
+	}
+
+	return &manager{conn: conn}, nil
+}
+
+// resolvedAddress is an address as returned by ResolveHostname.
+type resolvedAddress struct {
+	IfIndex int32
+	Family  int32
+	Address []byte
+}
+
+// ResolveHostname resolves a host name into IPv4 and IPv6 addresses, on all
+// interfaces.
+func (m *manager) ResolveHostname(parentCtx context.Context, hostname string) (Resolution, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ResolveHostname")
+	span.SetAttributes(otelattr.String("hostname", hostname))
+	defer span.End()
+
+	var (
+		addrs     []resolvedAddress
+		canonical string
+		flags     uint64
+	)
+	err := m.conn.Call(ctx, "ResolveHostname", []any{int32(0), hostname, int32(syscall.AF_UNSPEC), uint64(0)}, &addrs, &canonical, &flags)
+	if err != nil {
+		err = fmt.Errorf("failed to resolve %q: %w", hostname, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return Resolution{}, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("resolved %q", hostname))
+
+	return decodeResolution(addrs, canonical, flags), nil
+}
+
+// decodeResolution converts the results of ResolveHostname, skipping
+// malformed addresses.
+func decodeResolution(addrs []resolvedAddress, canonical string, flags uint64) Resolution {
+	r := Resolution{
+		CanonicalName: canonical,
+		Authenticated: flags&flagAuthenticated != 0,
+	}
+	for _, a := range addrs {
+		ip, ok := netip.AddrFromSlice(a.Address)
+		if !ok || (a.Family == syscall.AF_INET) != ip.Is4() {
+			continue
+		}
+		if a.IfIndex != 0 && ip.Is6() && ip.IsLinkLocalUnicast() {
+			ip = ip.WithZone(fmt.Sprint(a.IfIndex))
+		}
+		r.Addresses = append(r.Addresses, ip)
+	}
+
+	return r
+}
+
+// Statistics returns the cache, transaction, and DNSSEC counters.
+func (m *manager) Statistics(parentCtx context.Context) (Statistics, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Statistics")
+	defer span.End()
+
+	props, err := m.conn.Properties(ctx, resolvedPath, "")
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve statistics: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return Statistics{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved statistics")
+
+	return decodeStatistics(props), nil
+}
+
+// decodeStatistics converts the statistics properties of resolved, which
+// are structs of counters. Missing or mistyped counters are left zero.
+func decodeStatistics(props map[string]godbus.Variant) Statistics {
+	counters := func(key string, dst ...*uint64) {
+		v, _ := props[key].Value().([]any)
+		for i, c := range v {
+			if i < len(dst) {
+				*dst[i], _ = c.(uint64)
+			}
+		}
+	}
+
+	var s Statistics
+	counters("CacheStatistics", &s.CacheSize, &s.CacheHits, &s.CacheMisses)
+	counters("TransactionStatistics", &s.CurrentTransactions, &s.TotalTransactions)
+	counters("DNSSECStatistics", &s.DNSSECSecure, &s.DNSSECInsecure, &s.DNSSECBogus, &s.DNSSECIndeterminate)
+
+	return s
+}
+
+// FlushCaches empties the DNS caches.
+func (m *manager) FlushCaches(ctx context.Context) error {
+	return m.callTraced(ctx, "FlushCaches", "flushed caches")
+}
+
+// ResetStatistics resets the counters returned by Statistics.
+func (m *manager) ResetStatistics(ctx context.Context) error {
+	return m.callTraced(ctx, "ResetStatistics", "reset statistics")
+}
+
+// callTraced calls a method of resolved without arguments nor results, in
+// a span named after it.
+func (m *manager) callTraced(parentCtx context.Context, method, done string) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, method)
+	defer span.End()
+
+	if err := m.conn.Call(ctx, method, nil); err != nil {
+		err = fmt.Errorf("failed to call %s: %w", method, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, done)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package resolved
+
+import (
+	"net/netip"
+	"syscall"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeResolution(t *testing.T) {
+	addrs := []resolvedAddress{
+		{IfIndex: 2, Family: syscall.AF_INET, Address: []byte{93, 184, 216, 34}},
+		{IfIndex: 2, Family: syscall.AF_INET6, Address: netip.MustParseAddr("2606:2800:220:1::1").AsSlice()},
+		{IfIndex: 3, Family: syscall.AF_INET6, Address: netip.MustParseAddr("fe80::1").AsSlice()},
+		// Malformed addresses are skipped.
+		{Family: syscall.AF_INET, Address: []byte{1}},
+	}
+	require.Equal(t, Resolution{
+		CanonicalName: "example.com",
+		Addresses: []netip.Addr{
+			netip.MustParseAddr("93.184.216.34"),
+			netip.MustParseAddr("2606:2800:220:1::1"),
+			netip.MustParseAddr("fe80::1%3"),
+		},
+		Authenticated: true,
+	}, decodeResolution(addrs, "example.com", flagAuthenticated))
+}
+
+func Test_Unit_decodeStatistics(t *testing.T) {
+	props := map[string]godbus.Variant{
+		"CacheStatistics":       godbus.MakeVariant([]any{uint64(10), uint64(20), uint64(5)}),
+		"TransactionStatistics": godbus.MakeVariant([]any{uint64(1), uint64(25)}),
+		"DNSSECStatistics":      godbus.MakeVariant([]any{uint64(1), uint64(2), uint64(3), uint64(4)}),
+	}
+	require.Equal(t, Statistics{
+		CacheSize:           10,
+		CacheHits:           20,
+		CacheMisses:         5,
+		CurrentTransactions: 1,
+		TotalTransactions:   25,
+		DNSSECSecure:        1,
+		DNSSECInsecure:      2,
+		DNSSECBogus:         3,
+		DNSSECIndeterminate: 4,
+	}, decodeStatistics(props))
+
+	require.Equal(t, Statistics{}, decodeStatistics(nil))
+}
@@ -0,0 +1,81 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+)
+
+// transientErrorNames are D-Bus errors caused by brief unavailability of
+// dbus-daemon or systemd, such as while systemd re-executes itself.
+var transientErrorNames = []string{
+	"org.freedesktop.DBus.Error.NoReply",
+	"org.freedesktop.DBus.Error.Timeout",
+	"org.freedesktop.DBus.Error.TimedOut",
+	"org.freedesktop.DBus.Error.Disconnected",
+	"org.freedesktop.DBus.Error.ServiceUnknown",
+	"org.freedesktop.DBus.Error.NameHasNoOwner",
+}
+
+// IsTransient returns whether err is a D-Bus failure worth retrying, such as
+// a missing reply or a connection reset.
+func IsTransient(err error) bool {
+	var dbusErr godbus.Error
+	if errors.As(err, &dbusErr) {
+		for _, name := range transientErrorNames {
+			if dbusErr.Name == name {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// RetryPolicy configures how failed D-Bus calls are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	// Zero or one means no retries.
+	Attempts int
+	// Backoff is the delay between attempts.
+	Backoff Backoff
+	// Retryable classifies errors worth retrying. Defaults to IsTransient.
+	Retryable func(error) bool
+}
+
+// Do calls fn until it succeeds, fails with an error that isn't retryable,
+// runs out of attempts, or ctx is done. It returns the last error of fn.
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= p.Attempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.Backoff.Delay(attempt)):
+		}
+	}
+}
+
+// WithRetryPolicy retries D-Bus calls failing with transient errors. By
+// default, calls aren't retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = p
+	}
+}
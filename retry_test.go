@@ -0,0 +1,70 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_IsTransient(t *testing.T) {
+	require.True(t, IsTransient(godbus.Error{Name: "org.freedesktop.DBus.Error.NoReply"}))
+	require.True(t, IsTransient(fmt.Errorf("wrapped: %w", syscall.ECONNRESET)))
+	require.False(t, IsTransient(godbus.Error{Name: "org.freedesktop.systemd1.NoSuchUnit"}))
+	require.False(t, IsTransient(errors.New("some error")))
+}
+
+func Test_Unit_RetryPolicy_Do(t *testing.T) {
+	ctx := context.Background()
+	transientErr := godbus.Error{Name: "org.freedesktop.DBus.Error.NoReply"}
+	p := RetryPolicy{
+		Attempts: 3,
+		Backoff:  Backoff{Initial: time.Millisecond},
+	}
+
+	t.Run("Succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		require.NoError(t, p.Do(ctx, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return transientErr
+			}
+			return nil
+		}))
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives up after all attempts", func(t *testing.T) {
+		calls := 0
+		err := p.Do(ctx, func(context.Context) error {
+			calls++
+			return transientErr
+		})
+		require.Equal(t, transientErr, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Doesn't retry permanent failures", func(t *testing.T) {
+		calls := 0
+		permanentErr := errors.New("permanent")
+		require.ErrorIs(t, p.Do(ctx, func(context.Context) error {
+			calls++
+			return permanentErr
+		}), permanentErr)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Zero value doesn't retry", func(t *testing.T) {
+		calls := 0
+		require.Error(t, RetryPolicy{}.Do(ctx, func(context.Context) error {
+			calls++
+			return transientErr
+		}))
+		require.Equal(t, 1, calls)
+	})
+}
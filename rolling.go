@@ -0,0 +1,175 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrUnitFailed means a unit entered the failed state.
+var ErrUnitFailed = errors.New("unit failed")
+
+// activePollInterval is how often a unit state is checked while waiting for
+// it to become active.
+const activePollInterval = 250 * time.Millisecond
+
+// RollingOptions configures RollingRestart.
+type RollingOptions struct {
+	// BatchSize is how many instances are restarted at once. Defaults to 1.
+	BatchSize int
+	// HealthTimeout bounds how long each instance may take to become
+	// healthy after being restarted. Zero means no bound other than ctx.
+	HealthTimeout time.Duration
+	// Probe is an optional health check run once an instance is active.
+	Probe func(ctx context.Context, unit string) error
+}
+
+// RollingResult describes how far a rolling restart went, which is what is
+// needed to roll back an aborted one.
+type RollingResult struct {
+	// Restarted lists the instances that were restarted and are healthy.
+	Restarted []string
+	// Failed lists the instances of the aborted batch that failed to
+	// restart or to become healthy.
+	Failed []string
+	// Skipped lists the instances that weren't restarted because the
+	// rolling restart was aborted.
+	Skipped []string
+}
+
+// RollingRestart restarts the instances of a templated unit, e.g.
+// "foo@.service", in batches, waiting for every instance of a batch to be
+// healthy before moving on to the next. It aborts on the first batch with an
+// unhealthy instance.
+func (m *manager) RollingRestart(parentCtx context.Context, template string, opts RollingOptions) (*RollingResult, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "RollingRestart")
+	span.SetAttributes(otelattr.String("template", template))
+	defer span.End()
+
+	instances, err := m.listInstances(ctx, template)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+
+	batchSize := max(opts.BatchSize, 1)
+	result := &RollingResult{}
+	for i := 0; i < len(instances); i += batchSize {
+		batch := instances[i:min(i+batchSize, len(instances))]
+
+		var (
+			wg   sync.WaitGroup
+			errs = make([]error, len(batch))
+		)
+		for j, unit := range batch {
+			wg.Go(func() {
+				errs[j] = m.restartAndWaitHealthy(ctx, unit, opts)
+			})
+		}
+		wg.Wait()
+
+		for j, unit := range batch {
+			if errs[j] != nil {
+				result.Failed = append(result.Failed, unit)
+			} else {
+				result.Restarted = append(result.Restarted, unit)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			result.Skipped = append(result.Skipped, instances[i+len(batch):]...)
+			err = fmt.Errorf("aborted rolling restart of %q: %w", template, err)
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+
+			return result, err
+		}
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully restarted %d instances of %q", len(result.Restarted), template))
+
+	return result, nil
+}
+
+// restartAndWaitHealthy restarts a unit and waits for it to be active and to
+// pass the optional probe.
+func (m *manager) restartAndWaitHealthy(ctx context.Context, unit string, opts RollingOptions) error {
+	if err := m.Restart(ctx, unit); err != nil {
+		return err
+	}
+
+	if opts.HealthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.HealthTimeout)
+		defer cancel()
+	}
+	if err := m.waitActive(ctx, unit); err != nil {
+		return err
+	}
+	if opts.Probe != nil {
+		if err := opts.Probe(ctx, unit); err != nil {
+			return fmt.Errorf("unit %q failed health probe: %w", unit, err)
+		}
+	}
+
+	return nil
+}
+
+// activeState returns the ActiveState property of the named unit.
+func (m *manager) activeState(ctx context.Context, unit string) (string, error) {
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return "", ErrDisconnected
+	}
+
+	var p *dbus.Property
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		p, err = m.dbusConn.Load().GetUnitPropertyContext(ctx, unit, "ActiveState")
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	state, _ := p.Value.Value().(string)
+
+	return state, nil
+}
+
+// waitActive blocks until the named unit is active. It fails if the unit
+// enters the failed state, and with a LastStatusError when ctx is done.
+func (m *manager) waitActive(ctx context.Context, unit string) error {
+	ticker := time.NewTicker(activePollInterval)
+	defer ticker.Stop()
+
+	for {
+		statuses, err := m.statusAll(ctx, []string{unit})
+		if err != nil {
+			if ctx.Err() != nil {
+				return &LastStatusError{Unit: unit, Err: ctx.Err()}
+			}
+
+			return err
+		}
+		status := statuses[unit]
+		switch status.ActiveState {
+		case "active":
+			return nil
+		case "failed":
+			return fmt.Errorf("unit %q: %w", unit, ErrUnitFailed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return &LastStatusError{Unit: unit, Status: status, Err: ctx.Err()}
+		case <-ticker.C:
+		}
+	}
+}
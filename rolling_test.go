@@ -0,0 +1,91 @@
+//go:build linux
+
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pires/go-systemdmanager/fixtures"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixtures
+const unitRollingTemplate = "manager_rolling@.service"
+
+func Test_E2E_Manager_RollingRestart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitRollingTemplate))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitRollingTemplate)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+
+	instances := []string{"manager_rolling@a.service", "manager_rolling@b.service", "manager_rolling@c.service"}
+	for _, unit := range instances {
+		require.NoError(t, mgr.Start(ctx, unit))
+		defer func() { _ = mgr.Stop(t.Context(), unit) }()
+	}
+
+	t.Run("List instances", func(t *testing.T) {
+		res, err := mgr.ListInstances(ctx, unitRollingTemplate)
+		require.NoError(t, err)
+		require.Equal(t, instances, res)
+	})
+
+	t.Run("Restart all instances", func(t *testing.T) {
+		res, err := mgr.RollingRestart(ctx, unitRollingTemplate, RollingOptions{BatchSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, instances, res.Restarted)
+		require.Empty(t, res.Failed)
+		require.Empty(t, res.Skipped)
+	})
+
+	t.Run("Abort on failed probe", func(t *testing.T) {
+		probeErr := errors.New("unhealthy")
+		res, err := mgr.RollingRestart(ctx, unitRollingTemplate, RollingOptions{
+			Probe: func(_ context.Context, unit string) error {
+				if unit == instances[1] {
+					return probeErr
+				}
+				return nil
+			},
+		})
+		require.ErrorIs(t, err, probeErr)
+		require.Equal(t, instances[:1], res.Restarted)
+		require.Equal(t, instances[1:2], res.Failed)
+		require.Equal(t, instances[2:], res.Skipped)
+	})
+}
+
+func Test_E2E_Manager_Scale(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	// Install fixture.
+	require.NoError(t, fixtures.InstallUnit(ctx, unitRollingTemplate))
+	// By the time of uninstall, ctx may be cancelled.
+	defer uninstallUnit(t, t.Context(), unitRollingTemplate)
+
+	// Set-up manager.
+	mgr, err := New(ctx)
+	require.NoError(t, err)
+	defer func() { _, _ = mgr.Scale(t.Context(), unitRollingTemplate, 0, nil) }()
+
+	res, err := mgr.Scale(ctx, unitRollingTemplate, 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"manager_rolling@0.service", "manager_rolling@1.service", "manager_rolling@2.service"}, res.Started)
+	require.Empty(t, res.Stopped)
+
+	res, err = mgr.Scale(ctx, unitRollingTemplate, 1, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Started)
+	require.Equal(t, []string{"manager_rolling@1.service", "manager_rolling@2.service"}, res.Stopped)
+}
@@ -0,0 +1,217 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Exit codes of the main process of a service, as in ExecMainCode.
+const (
+	cldExited = 1
+	cldKilled = 2
+	cldDumped = 3
+)
+
+// ExitStatus is how the main process of a service ended.
+type ExitStatus struct {
+	// Code is "exited", "killed", or "dumped".
+	Code string `json:"code" yaml:"code"`
+	// Status is the exit status if the process exited, or the signal number
+	// if it was killed.
+	Status int `json:"status" yaml:"status"`
+}
+
+// Success reports whether the process exited with status 0.
+func (s ExitStatus) Success() bool {
+	return s.Code == "exited" && s.Status == 0
+}
+
+// String returns the status as shown by systemctl, e.g. "exited, status=1".
+func (s ExitStatus) String() string {
+	return fmt.Sprintf("%s, status=%d", s.Code, s.Status)
+}
+
+// TransientRun is a transient service started by RunTransient.
+type TransientRun struct {
+	// Unit is the name of the service.
+	Unit string
+	// Output is the standard output and error of the service. It must be
+	// read concurrently with Wait, or the service blocks once the pipe is
+	// full, and closed afterwards.
+	Output io.ReadCloser
+
+	m *manager
+}
+
+// RunTransient starts a transient service with its standard output and
+// error captured, which are read from the returned run. The service stays
+// loaded after its main process exits, until Wait collects its exit status,
+// so specs setting RemainAfterExit to false fail with ErrUnsupportedSetting.
+func (m *manager) RunTransient(parentCtx context.Context, spec TransientSpec, opts ...CallOption) (*TransientRun, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "RunTransient")
+	span.SetAttributes(otelattr.String("unit", spec.Name))
+	defer span.End()
+
+	run, err := m.runTransient(ctx, spec, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully started transient unit %q", spec.Name))
+
+	return run, nil
+}
+
+// runTransient implements RunTransient.
+func (m *manager) runTransient(ctx context.Context, spec TransientSpec, opts []CallOption) (*TransientRun, error) {
+	o := newCallOptions(opts)
+	props, err := transientProperties(spec)
+	if err != nil {
+		return nil, err
+	}
+	if props, err = withRemainAfterExit(spec.Name, props); err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output pipe of transient unit %q: %w", spec.Name, err)
+	}
+	// systemd receives a copy of the write end, which is closed here for
+	// readers to see the end of output once the service exits.
+	defer w.Close()
+	fd := godbus.MakeVariant(godbus.UnixFD(w.Fd()))
+	props = append(props,
+		dbus.Property{Name: "StandardOutputFileDescriptor", Value: fd},
+		dbus.Property{Name: "StandardErrorFileDescriptor", Value: fd},
+	)
+
+	err = m.runJob(ctx, OperationStart, spec.Name, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartTransientUnitContext(ctx, spec.Name, string(o.jobMode), props, resultChan)
+	})
+	if err != nil {
+		r.Close()
+
+		return nil, err
+	}
+
+	return &TransientRun{Unit: spec.Name, Output: r, m: m}, nil
+}
+
+// Wait blocks until the main process of the service exits, returns its exit
+// status, and unloads the service.
+func (r *TransientRun) Wait(parentCtx context.Context) (ExitStatus, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "TransientRun.Wait")
+	span.SetAttributes(otelattr.String("unit", r.Unit))
+	defer span.End()
+
+	status, err := r.m.waitExit(ctx, r.Unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return ExitStatus{}, err
+	}
+	span.SetAttributes(otelattr.String("exit_status", status.String()))
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("transient unit %q %s", r.Unit, status))
+
+	return status, nil
+}
+
+// withRemainAfterExit sets RemainAfterExit in props of the named unit, for
+// the exit status to be collected, unless already set. It fails with
+// ErrUnsupportedSetting if props turn it off, as the service would be
+// unloaded, exit status and all, as soon as it exits.
+func withRemainAfterExit(unit string, props []dbus.Property) ([]dbus.Property, error) {
+	i := slices.IndexFunc(props, func(p dbus.Property) bool { return p.Name == "RemainAfterExit" })
+	if i < 0 {
+		return append(props, dbus.Property{Name: "RemainAfterExit", Value: godbus.MakeVariant(true)}), nil
+	}
+	if remain, _ := props[i].Value.Value().(bool); !remain {
+		return nil, fmt.Errorf("invalid setting RemainAfterExit=no of transient unit %q, which exit status is collected: %w", unit, ErrUnsupportedSetting)
+	}
+
+	return props, nil
+}
+
+// waitExit blocks until the main process of the named service exits, then
+// unloads the service, which must have RemainAfterExit set.
+func (m *manager) waitExit(ctx context.Context, unit string) (ExitStatus, error) {
+	ticker := time.NewTicker(activePollInterval)
+	defer ticker.Stop()
+
+	for {
+		props, err := m.unitProperties(ctx, unit, "Service")
+		if err != nil {
+			return ExitStatus{}, err
+		}
+		if status, ok := exitStatus(props); ok {
+			return status, m.unload(ctx, unit)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ExitStatus{}, fmt.Errorf("unit %q didn't exit: %w", unit, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// exitStatus returns the exit status of a service from its properties, and
+// whether its main process exited.
+func exitStatus(props map[string]godbus.Variant) (ExitStatus, bool) {
+	exited, _ := props["ExecMainExitTimestamp"].Value().(uint64)
+	if exited == 0 {
+		return ExitStatus{}, false
+	}
+	code, _ := props["ExecMainCode"].Value().(int32)
+	status, _ := props["ExecMainStatus"].Value().(int32)
+	s := ExitStatus{Status: int(status)}
+	switch code {
+	case cldExited:
+		s.Code = "exited"
+	case cldKilled:
+		s.Code = "killed"
+	case cldDumped:
+		s.Code = "dumped"
+	default:
+		s.Code = fmt.Sprintf("code %d", code)
+	}
+
+	return s, true
+}
+
+// unload stops a transient unit which remained after exit, or resets it if
+// it failed, for systemd to unload it.
+func (m *manager) unload(ctx context.Context, unit string) error {
+	state, err := m.activeState(ctx, unit)
+	if err != nil {
+		return err
+	}
+	if state == "failed" {
+		err = m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+			return m.dbusConn.Load().ResetFailedUnitContext(ctx, unit)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reset unit %q: %w", unit, err)
+		}
+
+		return nil
+	}
+
+	return m.Stop(ctx, unit)
+}
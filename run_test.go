@@ -0,0 +1,53 @@
+package systemdmanager
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_exitStatus(t *testing.T) {
+	_, ok := exitStatus(map[string]godbus.Variant{})
+	require.False(t, ok)
+	_, ok = exitStatus(map[string]godbus.Variant{"ExecMainExitTimestamp": godbus.MakeVariant(uint64(0))})
+	require.False(t, ok)
+
+	status, ok := exitStatus(map[string]godbus.Variant{
+		"ExecMainExitTimestamp": godbus.MakeVariant(uint64(1)),
+		"ExecMainCode":          godbus.MakeVariant(int32(cldExited)),
+		"ExecMainStatus":        godbus.MakeVariant(int32(0)),
+	})
+	require.True(t, ok)
+	require.True(t, status.Success())
+	require.Equal(t, "exited, status=0", status.String())
+
+	status, ok = exitStatus(map[string]godbus.Variant{
+		"ExecMainExitTimestamp": godbus.MakeVariant(uint64(1)),
+		"ExecMainCode":          godbus.MakeVariant(int32(cldKilled)),
+		"ExecMainStatus":        godbus.MakeVariant(int32(9)),
+	})
+	require.True(t, ok)
+	require.False(t, status.Success())
+	require.Equal(t, ExitStatus{Code: "killed", Status: 9}, status)
+}
+
+func Test_Unit_withRemainAfterExit(t *testing.T) {
+	props, err := withRemainAfterExit("run.service", []dbus.Property{dbus.PropDescription("run")})
+	require.NoError(t, err)
+	require.Equal(t, []dbus.Property{
+		dbus.PropDescription("run"),
+		{Name: "RemainAfterExit", Value: godbus.MakeVariant(true)},
+	}, props)
+
+	// Services setting it themselves keep their own.
+	props = []dbus.Property{{Name: "RemainAfterExit", Value: godbus.MakeVariant(true)}}
+	got, err := withRemainAfterExit("run.service", props)
+	require.NoError(t, err)
+	require.Equal(t, props, got)
+
+	// Services unloaded once they exit have no exit status to collect.
+	_, err = withRemainAfterExit("run.service", []dbus.Property{{Name: "RemainAfterExit", Value: godbus.MakeVariant(false)}})
+	require.ErrorIs(t, err, ErrUnsupportedSetting)
+}
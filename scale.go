@@ -0,0 +1,99 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ScaleResult lists the instances changed by Scale.
+type ScaleResult struct {
+	Started []string
+	Stopped []string
+}
+
+// Scale starts or stops instances of a templated unit, such as
+// "app@.service", so that exactly n instances run. Instance i, starting at 0,
+// is named by instanceNamer, or by its index when instanceNamer is nil.
+// Missing instances are started before extra instances are stopped.
+func (m *manager) Scale(parentCtx context.Context, template string, n int, instanceNamer func(i int) string) (*ScaleResult, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Scale")
+	span.SetAttributes(otelattr.String("template", template), otelattr.Int("replicas", n))
+	defer span.End()
+
+	result, err := m.scale(ctx, template, n, instanceNamer)
+	if err != nil {
+		err = fmt.Errorf("failed to scale %q to %d instances: %w", template, n, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return result, err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("scaled %q to %d instances", template, n))
+
+	return result, nil
+}
+
+// scale implements Scale.
+func (m *manager) scale(ctx context.Context, template string, n int, instanceNamer func(i int) string) (*ScaleResult, error) {
+	if instanceNamer == nil {
+		instanceNamer = strconv.Itoa
+	}
+
+	desired := make([]string, 0, max(n, 0))
+	for i := range max(n, 0) {
+		unit, err := InstanceName(template, instanceNamer(i))
+		if err != nil {
+			return nil, err
+		}
+		desired = append(desired, unit)
+	}
+
+	statuses, err := m.instanceStatuses(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	running := map[string]bool{}
+	for _, status := range statuses {
+		running[status.Name] = isRunning(status.ActiveState)
+	}
+
+	result := &ScaleResult{}
+	for _, unit := range desired {
+		if running[unit] {
+			continue
+		}
+		if err := m.Start(ctx, unit); err != nil {
+			return result, err
+		}
+		result.Started = append(result.Started, unit)
+	}
+	for _, status := range statuses {
+		if !running[status.Name] || slices.Contains(desired, status.Name) {
+			continue
+		}
+		if err := m.Stop(ctx, status.Name); err != nil {
+			return result, err
+		}
+		result.Stopped = append(result.Stopped, status.Name)
+	}
+
+	return result, nil
+}
+
+// isRunning returns whether a unit in the given active state is running or
+// about to be.
+func isRunning(activeState string) bool {
+	switch activeState {
+	case "active", "activating", "reloading", "refreshing":
+		return true
+	default:
+		return false
+	}
+}
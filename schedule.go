@@ -0,0 +1,102 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// restartCountdown are the delays left before a scheduled restart at which
+// RestartAt notifies, as shutdown does before powering off.
+var restartCountdown = []time.Duration{
+	time.Hour, 30 * time.Minute, 15 * time.Minute, 10 * time.Minute,
+	5 * time.Minute, time.Minute, 30 * time.Second, 10 * time.Second,
+}
+
+// wallClockCheckInterval bounds how long waiting for a wall-clock time
+// sleeps at once, so that changes to the system clock are followed.
+const wallClockCheckInterval = time.Minute
+
+// RestartAt restarts the named unit at t, which is a wall-clock time, such
+// that changes to the system clock move the restart. Until then, notify, if
+// not nil, is called with the delay left when the restart is scheduled, and
+// when an hour, 30, 15, 10, 5, and 1 minute, and 30 and 10 seconds are
+// left, so users can be warned, e.g. of a VPN concentrator or game server
+// going away. A time in the past restarts the unit immediately. This is a
+// blocking function. Cancelling ctx cancels the restart.
+func (m *manager) RestartAt(parentCtx context.Context, unit string, t time.Time, notify func(delay time.Duration), opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "RestartAt")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("at", t.Format(time.RFC3339)))
+	defer span.End()
+
+	if err := m.validateUnit(unit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if err := countdown(ctx, t, restartCountdown, notify, time.Now); err != nil {
+		err = fmt.Errorf("scheduled restart of unit %q cancelled: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	if err := m.Restart(ctx, unit, opts...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("restarted unit %q as scheduled", unit))
+
+	return nil
+}
+
+// countdown blocks until the wall-clock time t, as read with now. It calls
+// notify, if not nil, with the delay left when called, and whenever the
+// delay left reaches one of marks, which are sorted in decreasing order.
+func countdown(ctx context.Context, t time.Time, marks []time.Duration, notify func(time.Duration), now func() time.Time) error {
+	// Compare wall-clock times, not monotonic ones.
+	t = t.Round(0)
+	delay := max(t.Sub(now().Round(0)), 0)
+	if notify != nil {
+		notify(delay)
+	}
+	for _, mark := range marks {
+		if mark >= delay {
+			continue
+		}
+		if err := sleepUntil(ctx, t.Add(-mark), now); err != nil {
+			return err
+		}
+		if notify != nil {
+			notify(mark)
+		}
+	}
+
+	return sleepUntil(ctx, t, now)
+}
+
+// sleepUntil blocks until the wall-clock time t, as read with now, or until
+// ctx is done.
+func sleepUntil(ctx context.Context, t time.Time, now func() time.Time) error {
+	for {
+		d := t.Sub(now().Round(0))
+		if d <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(min(d, wallClockCheckInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_countdown(t *testing.T) {
+	var notified []time.Duration
+	notify := func(delay time.Duration) {
+		notified = append(notified, delay)
+	}
+	marks := []time.Duration{time.Hour, 20 * time.Millisecond, 10 * time.Millisecond}
+
+	start := time.Now()
+	at := start.Add(50 * time.Millisecond)
+	require.NoError(t, countdown(context.Background(), at, marks, notify, time.Now))
+	require.False(t, time.Now().Before(at))
+	require.Len(t, notified, 3)
+	require.LessOrEqual(t, notified[0], 50*time.Millisecond)
+	require.Equal(t, []time.Duration{20 * time.Millisecond, 10 * time.Millisecond}, notified[1:])
+
+	// Times in the past don't wait.
+	notified = nil
+	require.NoError(t, countdown(context.Background(), start, marks, notify, time.Now))
+	require.Equal(t, []time.Duration{0}, notified)
+}
+
+func Test_Unit_countdown_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := countdown(ctx, time.Now().Add(time.Hour), restartCountdown, nil, time.Now)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_Unit_sleepUntil_WallClock(t *testing.T) {
+	// A clock set forward past t ends the wait.
+	now := func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+	require.NoError(t, sleepUntil(context.Background(), time.Now().Add(time.Minute), now))
+}
+
+func Test_Unit_RestartAt_InvalidUnitName(t *testing.T) {
+	require.ErrorIs(t, (&manager{}).RestartAt(context.Background(), "foo", time.Now(), nil), ErrInvalidUnitName)
+}
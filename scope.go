@@ -0,0 +1,73 @@
+package systemdmanager
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// ErrUnitNotAllowed means a unit is outside those the manager may touch.
+var ErrUnitNotAllowed = errors.New("unit not allowed")
+
+// WithAllowedUnits restricts the units the manager may touch to those
+// matching any of patterns, which are globs as understood by path.Match,
+// such as "myapp-*.service". Operations on other units fail with
+// ErrUnitNotAllowed, and they're left out of listings and watches of all
+// units. This guarantees plugins embedding a manager only manage their own
+// units. Operations not on units, such as SetEnvironment, and the
+// connection returned by Raw, aren't restricted. By default, all units are
+// allowed.
+func WithAllowedUnits(patterns ...string) Option {
+	return func(o *options) {
+		o.allowedUnits = append(o.allowedUnits, patterns...)
+	}
+}
+
+// validateAllowedUnits fails if any of patterns is malformed.
+func validateAllowedUnits(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowed units pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// allowed returns whether the manager may touch the named unit.
+func (m *manager) allowed(unit string) bool {
+	if m.options.allowedUnits == nil {
+		return true
+	}
+	for _, pattern := range m.options.allowedUnits {
+		if ok, _ := path.Match(pattern, unit); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedStatuses leaves out of statuses the units the manager isn't
+// allowed to touch.
+func (m *manager) allowedStatuses(statuses []dbus.UnitStatus) []dbus.UnitStatus {
+	return slices.DeleteFunc(statuses, func(status dbus.UnitStatus) bool {
+		return !m.allowed(status.Name)
+	})
+}
+
+// validateUnit fails if name isn't a valid unit name, or if the manager
+// isn't allowed to touch the unit.
+func (m *manager) validateUnit(name string) error {
+	if err := ValidateUnitName(name); err != nil {
+		return err
+	}
+	if !m.allowed(name) {
+		return fmt.Errorf("%w: %q", ErrUnitNotAllowed, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package systemdmanager
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_validateUnit(t *testing.T) {
+	m := &manager{options: newOptions([]Option{WithAllowedUnits("myapp-*.service", "myapp@*.service")})}
+
+	require.NoError(t, m.validateUnit("myapp-web.service"))
+	require.NoError(t, m.validateUnit("myapp@.service"))
+	require.NoError(t, m.validateUnit("myapp@1.service"))
+	require.ErrorIs(t, m.validateUnit("sshd.service"), ErrUnitNotAllowed)
+	require.ErrorIs(t, m.validateUnit("myapp-web.socket"), ErrUnitNotAllowed)
+	require.ErrorIs(t, m.validateUnit("myapp"), ErrInvalidUnitName)
+
+	// All units are allowed by default.
+	require.NoError(t, (&manager{}).validateUnit("sshd.service"))
+}
+
+func Test_Unit_WithAllowedUnits_Operations(t *testing.T) {
+	ctx := context.Background()
+	m := &manager{options: newOptions([]Option{WithAllowedUnits("myapp-*.service")})}
+
+	require.ErrorIs(t, m.Start(ctx, "sshd.service"), ErrUnitNotAllowed)
+	require.ErrorIs(t, m.Restart(ctx, "sshd.service"), ErrUnitNotAllowed)
+	_, err := m.Status(ctx, "sshd.service")
+	require.ErrorIs(t, err, ErrUnitNotAllowed)
+	_, err = GetProperty[string](ctx, m, "sshd.service", "Unit", "ActiveState")
+	require.ErrorIs(t, err, ErrUnitNotAllowed)
+	require.ErrorIs(t, m.SetFailureHandler(ctx, "myapp-web.service", "sshd.service"), ErrUnitNotAllowed)
+}
+
+func Test_Unit_validateAllowedUnits(t *testing.T) {
+	require.NoError(t, validateAllowedUnits([]string{"myapp-*.service", "foo@?.service"}))
+	require.ErrorIs(t, validateAllowedUnits([]string{"myapp-[.service"}), path.ErrBadPattern)
+}
+
+func Test_Unit_allowedStatuses(t *testing.T) {
+	statuses := []dbus.UnitStatus{{Name: "myapp-web.service"}, {Name: "sshd.service"}, {Name: "myapp-db.service"}}
+
+	m := &manager{options: newOptions([]Option{WithAllowedUnits("myapp-*.service")})}
+	require.Equal(t, []dbus.UnitStatus{{Name: "myapp-web.service"}, {Name: "myapp-db.service"}}, m.allowedStatuses(slices.Clone(statuses)))
+
+	// All units are allowed by default.
+	require.Equal(t, statuses, (&manager{}).allowedStatuses(slices.Clone(statuses)))
+}
@@ -0,0 +1,186 @@
+package systemdmanager
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Score is the exposure of a unit, from 0.0, fully sandboxed, to 10.0, not
+// sandboxed at all, as scored by systemd-analyze security.
+type Score float64
+
+// scoreRatings rate scores, from the worst, as systemd-analyze security does.
+var scoreRatings = []struct {
+	min    Score
+	rating string
+}{
+	{10, "DANGEROUS"},
+	{9, "UNSAFE"},
+	{7.5, "EXPOSED"},
+	{5, "MEDIUM"},
+	{1, "OK"},
+	{0.1, "SAFE"},
+	{0, "PERFECT"},
+}
+
+// Rating returns the rating of the score, from "PERFECT" to "DANGEROUS".
+func (s Score) Rating() string {
+	for _, r := range scoreRatings {
+		if s >= r.min {
+			return r.rating
+		}
+	}
+
+	return "PERFECT"
+}
+
+func (s Score) String() string {
+	return fmt.Sprintf("%.1f %s", float64(s), s.Rating())
+}
+
+// Finding is a sandboxing setting of a unit which leaves it exposed.
+type Finding struct {
+	// Setting is the unit setting at fault, e.g. "ProtectSystem".
+	Setting string `json:"setting" yaml:"setting"`
+	// Description tells what the unit is exposed to.
+	Description string `json:"description" yaml:"description"`
+	// Recommendation is the setting which would remedy it.
+	Recommendation string `json:"recommendation" yaml:"recommendation"`
+	// Weight is how much the finding adds to the score, relative to other
+	// findings.
+	Weight int `json:"weight" yaml:"weight"`
+}
+
+// securityRule is a sandboxing check of the built-in rule set.
+type securityRule struct {
+	Finding
+	// exposed returns whether the unit's properties fail the check.
+	exposed func(props map[string]godbus.Variant) bool
+}
+
+// securityRules are the built-in checks, weighted like those of
+// systemd-analyze security.
+var securityRules = []securityRule{
+	{Finding{"User", "Service runs as root", "User= or DynamicUser=yes", 2000}, func(props map[string]godbus.Variant) bool {
+		user, _ := props["User"].Value().(string)
+		dynamic, _ := props["DynamicUser"].Value().(bool)
+		return !dynamic && (user == "" || user == "root" || user == "0")
+	}},
+	{Finding{"NoNewPrivileges", "Processes may acquire new privileges", "NoNewPrivileges=yes", 1000}, propertyUnset("NoNewPrivileges")},
+	{Finding{"CapabilityBoundingSet", "Processes may acquire CAP_SYS_ADMIN", "CapabilityBoundingSet=~CAP_SYS_ADMIN", 1500}, func(props map[string]godbus.Variant) bool {
+		set, _ := props["CapabilityBoundingSet"].Value().(uint64)
+		return set&(1<<slices.Index(capabilities, "CAP_SYS_ADMIN")) != 0
+	}},
+	{Finding{"AmbientCapabilities", "Processes are granted capabilities", "AmbientCapabilities=", 500}, func(props map[string]godbus.Variant) bool {
+		set, _ := props["AmbientCapabilities"].Value().(uint64)
+		return set != 0
+	}},
+	{Finding{"PrivateTmp", "Service shares /tmp with other processes", "PrivateTmp=yes", 1000}, propertyUnset("PrivateTmp")},
+	{Finding{"PrivateDevices", "Service has access to hardware devices", "PrivateDevices=yes", 1000}, propertyUnset("PrivateDevices")},
+	{Finding{"PrivateNetwork", "Service has access to the host's network", "PrivateNetwork=yes", 500}, propertyUnset("PrivateNetwork")},
+	{Finding{"ProtectSystem", "Service may modify the OS file hierarchy", "ProtectSystem=strict", 1000}, func(props map[string]godbus.Variant) bool {
+		protect, _ := props["ProtectSystem"].Value().(string)
+		return protect != "strict"
+	}},
+	{Finding{"ProtectHome", "Service has access to home directories", "ProtectHome=yes", 1000}, func(props map[string]godbus.Variant) bool {
+		protect, _ := props["ProtectHome"].Value().(string)
+		return protect == "" || protect == "no"
+	}},
+	{Finding{"ProtectKernelTunables", "Service may alter kernel tunables", "ProtectKernelTunables=yes", 1000}, propertyUnset("ProtectKernelTunables")},
+	{Finding{"ProtectKernelModules", "Service may load kernel modules", "ProtectKernelModules=yes", 1000}, propertyUnset("ProtectKernelModules")},
+	{Finding{"ProtectKernelLogs", "Service may read the kernel log ring buffer", "ProtectKernelLogs=yes", 1000}, propertyUnset("ProtectKernelLogs")},
+	{Finding{"ProtectControlGroups", "Service may modify the control group hierarchy", "ProtectControlGroups=yes", 1000}, propertyUnset("ProtectControlGroups")},
+	{Finding{"ProtectClock", "Service may change the system clock", "ProtectClock=yes", 1000}, propertyUnset("ProtectClock")},
+	{Finding{"ProtectHostname", "Service may change the system host name", "ProtectHostname=yes", 500}, propertyUnset("ProtectHostname")},
+	{Finding{"RestrictSUIDSGID", "Service may create SUID/SGID files", "RestrictSUIDSGID=yes", 1000}, propertyUnset("RestrictSUIDSGID")},
+	{Finding{"RestrictRealtime", "Service may acquire realtime scheduling", "RestrictRealtime=yes", 500}, propertyUnset("RestrictRealtime")},
+	{Finding{"RestrictNamespaces", "Service may create any kind of namespace", "RestrictNamespaces=yes", 500}, func(props map[string]godbus.Variant) bool {
+		allowed, ok := props["RestrictNamespaces"].Value().(uint64)
+		return !ok || allowed&allNamespaces == allNamespaces
+	}},
+	{Finding{"RestrictAddressFamilies", "Service may allocate any socket address family", "RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6", 500}, propertyListUnset("RestrictAddressFamilies")},
+	{Finding{"SystemCallFilter", "Service may call any system call", "SystemCallFilter=@system-service", 1000}, propertyListUnset("SystemCallFilter")},
+	{Finding{"SystemCallArchitectures", "Service may call system calls of any architecture", "SystemCallArchitectures=native", 200}, func(props map[string]godbus.Variant) bool {
+		archs, _ := props["SystemCallArchitectures"].Value().([]string)
+		return len(archs) == 0
+	}},
+	{Finding{"LockPersonality", "Service may change its execution domain", "LockPersonality=yes", 100}, propertyUnset("LockPersonality")},
+	{Finding{"MemoryDenyWriteExecute", "Service may create writable executable memory", "MemoryDenyWriteExecute=yes", 100}, propertyUnset("MemoryDenyWriteExecute")},
+}
+
+// propertyUnset returns a check failing when a boolean property isn't set.
+func propertyUnset(key string) func(props map[string]godbus.Variant) bool {
+	return func(props map[string]godbus.Variant) bool {
+		set, _ := props[key].Value().(bool)
+		return !set
+	}
+}
+
+// propertyListUnset returns a check failing when an allow or deny list
+// property, encoded as whether it's an allow list and its items, is empty.
+func propertyListUnset(key string) func(props map[string]godbus.Variant) bool {
+	return func(props map[string]godbus.Variant) bool {
+		var list struct {
+			Allow bool
+			Items []string
+		}
+		if v := props[key].Value(); v == nil || godbus.Store([]any{v}, &list) != nil {
+			return true
+		}
+		return len(list.Items) == 0
+	}
+}
+
+// SecurityScore evaluates the sandboxing settings of the named unit against
+// a built-in rule set, similar to systemd-analyze security, and returns its
+// exposure score with the findings to remedy, heaviest first. Only
+// services, sockets, mounts, and swaps run processes; it fails with
+// ErrWrongUnitType for other units.
+func (m *manager) SecurityScore(parentCtx context.Context, unit string) (Score, []Finding, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SecurityScore")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.execProperties(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return 0, nil, err
+	}
+	score, findings := scoreSecurity(props)
+	span.SetAttributes(otelattr.Float64("score", float64(score)), otelattr.Int("findings", len(findings)))
+	span.SetStatus(otelcodes.Ok, "scored unit security")
+
+	return score, findings, nil
+}
+
+// scoreSecurity evaluates the built-in rules against the properties of a
+// unit.
+func scoreSecurity(props map[string]godbus.Variant) (Score, []Finding) {
+	var (
+		total, exposed int
+		findings       []Finding
+	)
+	for _, rule := range securityRules {
+		total += rule.Weight
+		if rule.exposed(props) {
+			exposed += rule.Weight
+			findings = append(findings, rule.Finding)
+		}
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Compare(b.Weight, a.Weight)
+	})
+
+	return Score(math.Round(100*float64(exposed)/float64(total)) / 10), findings
+}
@@ -0,0 +1,71 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Score_Rating(t *testing.T) {
+	require.Equal(t, "DANGEROUS", Score(10).Rating())
+	require.Equal(t, "UNSAFE", Score(9.6).Rating())
+	require.Equal(t, "EXPOSED", Score(7.5).Rating())
+	require.Equal(t, "MEDIUM", Score(5.2).Rating())
+	require.Equal(t, "OK", Score(1).Rating())
+	require.Equal(t, "SAFE", Score(0.4).Rating())
+	require.Equal(t, "PERFECT", Score(0).Rating())
+	require.Equal(t, "4.2 OK", Score(4.2).String())
+}
+
+func Test_Unit_scoreSecurity(t *testing.T) {
+	// Nothing is sandboxed.
+	score, findings := scoreSecurity(map[string]godbus.Variant{
+		"CapabilityBoundingSet": godbus.MakeVariant(uint64(1<<41 - 1)),
+		"AmbientCapabilities":   godbus.MakeVariant(uint64(1 << 10)),
+		"ProtectSystem":         godbus.MakeVariant("no"),
+		"ProtectHome":           godbus.MakeVariant("no"),
+		"SystemCallFilter":      godbus.MakeVariant([]any{false, []string{}}),
+	})
+	require.Equal(t, Score(10), score)
+	require.Len(t, findings, len(securityRules))
+	require.Equal(t, "User", findings[0].Setting)
+
+	// Everything is sandboxed.
+	sandboxed := map[string]godbus.Variant{
+		"DynamicUser":             godbus.MakeVariant(true),
+		"CapabilityBoundingSet":   godbus.MakeVariant(uint64(0)),
+		"AmbientCapabilities":     godbus.MakeVariant(uint64(0)),
+		"ProtectSystem":           godbus.MakeVariant("strict"),
+		"ProtectHome":             godbus.MakeVariant("yes"),
+		"RestrictNamespaces":      godbus.MakeVariant(uint64(0)),
+		"RestrictAddressFamilies": godbus.MakeVariant([]any{true, []string{"AF_UNIX"}}),
+		"SystemCallFilter":        godbus.MakeVariant([]any{true, []string{"read", "write"}}),
+		"SystemCallArchitectures": godbus.MakeVariant([]string{"native"}),
+	}
+	for _, key := range []string{
+		"NoNewPrivileges", "PrivateTmp", "PrivateDevices", "PrivateNetwork",
+		"ProtectKernelTunables", "ProtectKernelModules", "ProtectKernelLogs",
+		"ProtectControlGroups", "ProtectClock", "ProtectHostname",
+		"RestrictSUIDSGID", "RestrictRealtime", "LockPersonality",
+		"MemoryDenyWriteExecute",
+	} {
+		sandboxed[key] = godbus.MakeVariant(true)
+	}
+	score, findings = scoreSecurity(sandboxed)
+	require.Equal(t, Score(0), score)
+	require.Empty(t, findings)
+
+	// Findings are weighted.
+	sandboxed["PrivateNetwork"] = godbus.MakeVariant(false)
+	sandboxed["LockPersonality"] = godbus.MakeVariant(false)
+	score, findings = scoreSecurity(sandboxed)
+	require.Equal(t, "SAFE", score.Rating())
+	require.Equal(t, []string{"PrivateNetwork", "LockPersonality"}, []string{findings[0].Setting, findings[1].Setting})
+}
+
+func Test_Unit_SecurityScore_WrongUnitType(t *testing.T) {
+	_, _, err := (&manager{}).SecurityScore(context.Background(), "a.target")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
@@ -0,0 +1,107 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// execTypes are the unit types running processes, whose execution
+// environment is configurable.
+var execTypes = []string{"service", "socket", "mount", "swap"}
+
+// SecurityProfile is how the processes of a unit are confined, for auditing
+// services.
+type SecurityProfile struct {
+	// SELinuxContext is the SELinux context processes run in, empty for the
+	// default.
+	SELinuxContext string `json:"selinux_context" yaml:"selinux_context"`
+	// AppArmorProfile is the AppArmor profile processes run in, empty for
+	// the default.
+	AppArmorProfile string `json:"apparmor_profile" yaml:"apparmor_profile"`
+	// NoNewPrivileges is set when processes can't gain privileges, e.g.
+	// through setuid binaries.
+	NoNewPrivileges bool `json:"no_new_privileges" yaml:"no_new_privileges"`
+	// CapabilityBoundingSet lists the capabilities processes may ever have.
+	CapabilityBoundingSet []string `json:"capability_bounding_set" yaml:"capability_bounding_set"`
+	// AmbientCapabilities lists the capabilities processes are granted,
+	// even when not running as root.
+	AmbientCapabilities []string `json:"ambient_capabilities" yaml:"ambient_capabilities"`
+	// User is the user processes run as, empty for root.
+	User string `json:"user" yaml:"user"`
+	// DynamicUser is set when processes run as a transient user.
+	DynamicUser bool `json:"dynamic_user" yaml:"dynamic_user"`
+}
+
+// SecurityProfile returns how the processes of the named unit are confined.
+// Only services, sockets, mounts, and swaps run processes; it fails with
+// ErrWrongUnitType for other units.
+func (m *manager) SecurityProfile(parentCtx context.Context, unit string) (SecurityProfile, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SecurityProfile")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.execProperties(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return SecurityProfile{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved unit security profile")
+
+	return newSecurityProfile(props), nil
+}
+
+// execProperties returns the properties of the named unit's type, which
+// include those of the execution environment of its processes.
+func (m *manager) execProperties(ctx context.Context, unit string) (map[string]godbus.Variant, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(execTypes, unitType(unit)) {
+		return nil, fmt.Errorf("%w: unit %q is a %s, which runs no processes", ErrWrongUnitType, unit, unitType(unit))
+	}
+
+	return m.unitProperties(ctx, unit, unitTypeInterface(unit))
+}
+
+// newSecurityProfile returns the security profile of a unit from the
+// properties of its type. Missing properties, e.g. on older systemd
+// versions, are left unset.
+func newSecurityProfile(props map[string]godbus.Variant) SecurityProfile {
+	p := SecurityProfile{
+		SELinuxContext:  labelProperty(props, "SELinuxContext"),
+		AppArmorProfile: labelProperty(props, "AppArmorProfile"),
+	}
+	boundingSet, _ := props["CapabilityBoundingSet"].Value().(uint64)
+	p.CapabilityBoundingSet = formatCapabilities(boundingSet)
+	ambient, _ := props["AmbientCapabilities"].Value().(uint64)
+	p.AmbientCapabilities = formatCapabilities(ambient)
+	p.NoNewPrivileges, _ = props["NoNewPrivileges"].Value().(bool)
+	p.User, _ = props["User"].Value().(string)
+	p.DynamicUser, _ = props["DynamicUser"].Value().(bool)
+
+	return p
+}
+
+// labelProperty returns the label of a security module property, such as
+// SELinuxContext, which systemd encodes along with whether failing to apply
+// it is ignored.
+func labelProperty(props map[string]godbus.Variant, key string) string {
+	var label struct {
+		IgnoreFailure bool
+		Label         string
+	}
+	if v := props[key].Value(); v == nil || godbus.Store([]any{v}, &label) != nil {
+		return ""
+	}
+
+	return label.Label
+}
@@ -0,0 +1,40 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_newSecurityProfile(t *testing.T) {
+	props := map[string]godbus.Variant{
+		"SELinuxContext":        godbus.MakeVariant([]any{true, "system_u:system_r:httpd_t:s0"}),
+		"AppArmorProfile":       godbus.MakeVariant([]any{false, "httpd"}),
+		"NoNewPrivileges":       godbus.MakeVariant(true),
+		"CapabilityBoundingSet": godbus.MakeVariant(uint64(1 << 10)),
+		"AmbientCapabilities":   godbus.MakeVariant(uint64(0)),
+		"User":                  godbus.MakeVariant("www-data"),
+		"DynamicUser":           godbus.MakeVariant(false),
+	}
+	require.Equal(t, SecurityProfile{
+		SELinuxContext:        "system_u:system_r:httpd_t:s0",
+		AppArmorProfile:       "httpd",
+		NoNewPrivileges:       true,
+		CapabilityBoundingSet: []string{"CAP_NET_BIND_SERVICE"},
+		AmbientCapabilities:   []string{},
+		User:                  "www-data",
+	}, newSecurityProfile(props))
+
+	// Missing properties are left unset.
+	require.Equal(t, SecurityProfile{
+		CapabilityBoundingSet: []string{},
+		AmbientCapabilities:   []string{},
+	}, newSecurityProfile(nil))
+}
+
+func Test_Unit_SecurityProfile_WrongUnitType(t *testing.T) {
+	_, err := (&manager{}).SecurityProfile(context.Background(), "a.timer")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
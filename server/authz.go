@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ErrUnauthenticated means a request carries no token, or an unknown one.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrForbidden means the token of a request isn't allowed to run the
+// operation on the unit.
+var ErrForbidden = errors.New("forbidden")
+
+// Operation is what a request does to a unit, as checked by an Authorizer.
+type Operation string
+
+const (
+	// OperationStatus is retrieving the status of a unit.
+	OperationStatus Operation = "status"
+	// OperationWatch is streaming the status changes of a unit.
+	OperationWatch Operation = "watch"
+	// OperationStart is starting a unit.
+	OperationStart Operation = "start"
+	// OperationStop is stopping a unit.
+	OperationStop Operation = "stop"
+	// OperationRestart is restarting a unit.
+	OperationRestart Operation = "restart"
+)
+
+// Authorizer decides whether requests may run operations on units, which
+// keeps exposing units over the network from exposing all of systemd.
+type Authorizer interface {
+	// Authorize fails with ErrUnauthenticated if token is unknown, and with
+	// ErrForbidden if it isn't allowed to run op on the named unit. Token is
+	// the bearer token of the request, which is empty if it has none.
+	Authorize(ctx context.Context, token string, op Operation, unit string) error
+}
+
+// AuthorizerFunc adapts a function to an Authorizer.
+type AuthorizerFunc func(ctx context.Context, token string, op Operation, unit string) error
+
+// Authorize calls f.
+func (f AuthorizerFunc) Authorize(ctx context.Context, token string, op Operation, unit string) error {
+	return f(ctx, token, op, unit)
+}
+
+// Grant allows operations on units.
+type Grant struct {
+	// Units are globs, as understood by path.Match, of the units granted,
+	// such as "myapp-*.service".
+	Units []string
+	// Operations are the operations granted. Empty means all of them.
+	Operations []Operation
+}
+
+// allows returns whether the grant allows op on the named unit.
+func (g Grant) allows(op Operation, unit string) bool {
+	if len(g.Operations) > 0 && !slices.Contains(g.Operations, op) {
+		return false
+	}
+	for _, pattern := range g.Units {
+		if ok, _ := path.Match(pattern, unit); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StaticPolicy is an Authorizer with fixed grants per token. A request is
+// allowed if any grant of its token allows it:
+//
+//	policy := server.StaticPolicy{
+//		"deployer-token": {{Units: []string{"myapp-*.service"}}},
+//		"monitor-token": {{
+//			Units:      []string{"*"},
+//			Operations: []server.Operation{server.OperationStatus, server.OperationWatch},
+//		}},
+//	}
+type StaticPolicy map[string][]Grant
+
+// Assert StaticPolicy fulfills the Authorizer interface.
+var _ Authorizer = StaticPolicy(nil)
+
+// Authorize implements Authorizer.
+func (p StaticPolicy) Authorize(_ context.Context, token string, op Operation, unit string) error {
+	if token == "" {
+		return ErrUnauthenticated
+	}
+	// Compare the digests of all tokens, which have the same length, in
+	// constant time, so how long it takes depends on how many tokens there
+	// are, but not on the token given, nor on which are valid.
+	var grants []Grant
+	found := false
+	digest := sha256.Sum256([]byte(token))
+	for t, g := range p {
+		d := sha256.Sum256([]byte(t))
+		if subtle.ConstantTimeCompare(digest[:], d[:]) == 1 {
+			grants, found = g, true
+		}
+	}
+	if !found {
+		return ErrUnauthenticated
+	}
+	for _, g := range grants {
+		if g.allows(op, unit) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s unit %q", ErrForbidden, op, unit)
+}
+
+// bearerToken returns the token of an Authorization header value, which is
+// empty if it isn't a bearer token.
+func bearerToken(authorization string) string {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	return token
+}
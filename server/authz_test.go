@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/server/unitpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testPolicy lets the deployer manage myapp units, and the monitor read all
+// units.
+var testPolicy = StaticPolicy{
+	"deployer": {{Units: []string{"myapp-*.service"}}},
+	"monitor":  {{Units: []string{"*"}, Operations: []Operation{OperationStatus, OperationWatch}}},
+}
+
+func Test_Unit_StaticPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	require.NoError(t, testPolicy.Authorize(ctx, "deployer", OperationRestart, "myapp-web.service"))
+	require.ErrorIs(t, testPolicy.Authorize(ctx, "deployer", OperationRestart, "sshd.service"), ErrForbidden)
+	require.NoError(t, testPolicy.Authorize(ctx, "monitor", OperationStatus, "sshd.service"))
+	require.ErrorIs(t, testPolicy.Authorize(ctx, "monitor", OperationStop, "myapp-web.service"), ErrForbidden)
+	require.ErrorIs(t, testPolicy.Authorize(ctx, "unknown", OperationStatus, "sshd.service"), ErrUnauthenticated)
+	require.ErrorIs(t, testPolicy.Authorize(ctx, "", OperationStatus, "sshd.service"), ErrUnauthenticated)
+}
+
+func Test_Unit_bearerToken(t *testing.T) {
+	require.Equal(t, "secret", bearerToken("Bearer secret"))
+	require.Empty(t, bearerToken("Basic c2VjcmV0"))
+	require.Empty(t, bearerToken(""))
+}
+
+func Test_Unit_Authorizer(t *testing.T) {
+	mgr := &fakeManager{
+		start: func(context.Context, string, ...systemdmanager.CallOption) error {
+			return nil
+		},
+		status: func(_ context.Context, unit string) (*dbus.UnitStatus, error) {
+			return &dbus.UnitStatus{Name: unit, ActiveState: "active"}, nil
+		},
+	}
+
+	t.Run("gRPC", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		client := newTestClient(t, mgr, WithAuthorizer(testPolicy))
+		withToken := func(token string) context.Context {
+			return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+
+		_, err := client.Start(withToken("deployer"), &unitpb.UnitRequest{Unit: "myapp-web.service"})
+		require.NoError(t, err)
+		_, err = client.Start(withToken("deployer"), &unitpb.UnitRequest{Unit: "sshd.service"})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = client.Start(withToken("monitor"), &unitpb.UnitRequest{Unit: "myapp-web.service"})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = client.Status(withToken("monitor"), &unitpb.StatusRequest{Unit: "sshd.service"})
+		require.NoError(t, err)
+		_, err = client.Status(ctx, &unitpb.StatusRequest{Unit: "sshd.service"})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		srv := httptest.NewServer(NewHTTPHandler(mgr, WithHTTPAuthorizer(testPolicy)))
+		defer srv.Close()
+		do := func(method, path, token string) int {
+			req, err := http.NewRequest(method, srv.URL+path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			return res.StatusCode
+		}
+
+		require.Equal(t, http.StatusNoContent, do(http.MethodPost, "/units/myapp-web.service/start", "deployer"))
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/units/sshd.service/start", "deployer"))
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "/units/sshd.service", "monitor"))
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/units/sshd.service/start", "monitor"))
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/units/sshd.service", "unknown"))
+	})
+}
@@ -0,0 +1,5 @@
+// Package server exposes a systemdmanager.Manager remotely over gRPC and
+// HTTP.
+package server
+
+//go:generate buf generate
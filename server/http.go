@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+)
+
+// Middleware wraps an http.Handler, e.g. to authenticate requests.
+type Middleware func(http.Handler) http.Handler
+
+// HTTPOption configures the HTTP handler.
+type HTTPOption func(*httpHandler)
+
+// WithMiddleware wraps all endpoints with mw. Middlewares run in the order
+// they are provided.
+func WithMiddleware(mw Middleware) HTTPOption {
+	return func(h *httpHandler) {
+		h.middlewares = append(h.middlewares, mw)
+	}
+}
+
+// WithHTTPAuthorizer checks every request with a, using the bearer token of
+// its Authorization header. Unauthenticated requests are answered with 401
+// Unauthorized, and forbidden ones with 403 Forbidden.
+func WithHTTPAuthorizer(a Authorizer) HTTPOption {
+	return func(h *httpHandler) {
+		h.authorizer = a
+	}
+}
+
+// BearerTokenAuth rejects requests that don't carry one of tokens in their
+// Authorization header.
+func BearerTokenAuth(tokens ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			for _, t := range tokens {
+				if ok && subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		})
+	}
+}
+
+// httpHandler serves unit operations over HTTP.
+type httpHandler struct {
+	mgr         systemdmanager.Manager
+	authorizer  Authorizer
+	middlewares []Middleware
+}
+
+// NewHTTPHandler returns an http.Handler exposing unit operations backed by
+// mgr:
+//
+//	GET  /units/{unit}          returns the unit status
+//	GET  /units/{unit}/watch    streams status changes as Server-Sent Events
+//	POST /units/{unit}/start    starts the unit
+//	POST /units/{unit}/stop     stops the unit
+//	POST /units/{unit}/restart  restarts the unit
+//
+// Mutating endpoints accept the job_mode and timeout query parameters. The
+// job_mode may only be replace, the default, or fail.
+func NewHTTPHandler(mgr systemdmanager.Manager, opts ...HTTPOption) http.Handler {
+	h := &httpHandler{mgr: mgr}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /units/{unit}", h.authorize(OperationStatus, h.status))
+	mux.HandleFunc("GET /units/{unit}/watch", h.authorize(OperationWatch, h.watch))
+	mux.HandleFunc("POST /units/{unit}/start", h.authorize(OperationStart, h.operation(mgr.Start)))
+	mux.HandleFunc("POST /units/{unit}/stop", h.authorize(OperationStop, h.operation(mgr.Stop)))
+	mux.HandleFunc("POST /units/{unit}/restart", h.authorize(OperationRestart, h.operation(mgr.Restart)))
+
+	var handler http.Handler = mux
+	for _, mw := range h.middlewares {
+		handler = mw(handler)
+	}
+
+	return handler
+}
+
+// authorize returns a handler calling next if the authorizer, if any,
+// allows the request to run op on its unit.
+func (h *httpHandler) authorize(op Operation, next http.HandlerFunc) http.HandlerFunc {
+	if h.authorizer == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r.Header.Get("Authorization"))
+		if err := h.authorizer.Authorize(r.Context(), token, op, r.PathValue("unit")); err != nil {
+			writeError(w, httpStatus(err), err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// operation returns a handler running a mutating unit operation.
+func (h *httpHandler) operation(op func(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opts []systemdmanager.CallOption
+		if mode := r.URL.Query().Get("job_mode"); mode != "" {
+			if err := checkJobMode(mode); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			opts = append(opts, systemdmanager.WithJobMode(systemdmanager.JobMode(mode)))
+		}
+		if timeout := r.URL.Query().Get("timeout"); timeout != "" {
+			d, err := time.ParseDuration(timeout)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid timeout: %w", err))
+				return
+			}
+			opts = append(opts, systemdmanager.WithTimeout(d))
+		}
+
+		if err := op(r.Context(), r.PathValue("unit"), opts...); err != nil {
+			writeError(w, httpStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// status returns the status of a unit.
+func (h *httpHandler) status(w http.ResponseWriter, r *http.Request) {
+	s, err := h.mgr.Status(r.Context(), r.PathValue("unit"))
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, systemdmanager.NewUnitStatus(s))
+}
+
+// watch streams the status changes of a unit as Server-Sent Events until the
+// client goes away. Each event data is a JSON unit status, or null when the
+// unit is no longer loaded.
+func (h *httpHandler) watch(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	unit := r.PathValue("unit")
+	updatesChan := make(chan *dbus.UnitStatus)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- h.mgr.Watch(ctx, unit, updatesChan)
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-watchDone:
+			return
+		case s := <-updatesChan:
+			data, _ := json.Marshal(systemdmanager.NewUnitStatus(s))
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				// Stop watching, draining updates until Watch returns.
+				cancel()
+				for {
+					select {
+					case <-updatesChan:
+					case <-watchDone:
+						return
+					}
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// httpStatus maps Manager errors to HTTP status codes.
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, systemdmanager.ErrTimeout), errors.Is(err, systemdmanager.ErrJobTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, systemdmanager.ErrDisconnected):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, systemdmanager.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden), errors.Is(err, systemdmanager.ErrPermissionDenied), errors.Is(err, systemdmanager.ErrUnitNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, systemdmanager.ErrInvalidUnitName), errors.Is(err, systemdmanager.ErrWrongUnitType), errors.Is(err, systemdmanager.ErrInvalidJobMode):
+		return http.StatusBadRequest
+	case errors.Is(err, systemdmanager.ErrUnitFailed), errors.Is(err, systemdmanager.ErrJobFailed), errors.Is(err, systemdmanager.ErrJobDependency), errors.Is(err, systemdmanager.ErrUnitClaimed):
+		return http.StatusConflict
+	case errors.Is(err, systemdmanager.ErrUnitNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
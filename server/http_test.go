@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_HTTPHandler(t *testing.T) {
+	mgr := &fakeManager{
+		start: func(_ context.Context, unit string, opts ...systemdmanager.CallOption) error {
+			if unit == "limited.service" {
+				return systemdmanager.ErrRateLimited
+			}
+			return nil
+		},
+		status: func(_ context.Context, unit string) (*dbus.UnitStatus, error) {
+			return &dbus.UnitStatus{Name: unit, ActiveState: "active"}, nil
+		},
+		watch: func(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error {
+			updatesChan <- &dbus.UnitStatus{Name: unit, ActiveState: "active"}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	srv := httptest.NewServer(NewHTTPHandler(mgr, WithMiddleware(BearerTokenAuth("secret"))))
+	defer srv.Close()
+
+	do := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("Rejects unauthenticated requests", func(t *testing.T) {
+		res := do(http.MethodGet, "/units/a.service", "")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		res = do(http.MethodGet, "/units/a.service", "wrong")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Start", func(t *testing.T) {
+		res := do(http.MethodPost, "/units/a.service/start?job_mode=fail&timeout=1s", "secret")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res = do(http.MethodPost, "/units/a.service/start?timeout=soon", "secret")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res = do(http.MethodPost, "/units/a.service/start?job_mode=isolate", "secret")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res = do(http.MethodPost, "/units/limited.service/start", "secret")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		res := do(http.MethodGet, "/units/a.service", "secret")
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body strings.Builder
+		_, err := bufio.NewReader(res.Body).WriteTo(&body)
+		require.NoError(t, err)
+		require.Contains(t, body.String(), `"active_state":"active"`)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/units/a.service/watch", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(res.Body)
+		require.True(t, scanner.Scan())
+		require.Equal(t, "event: status", scanner.Text())
+		require.True(t, scanner.Scan())
+		require.Contains(t, scanner.Text(), `"name":"a.service"`)
+	})
+}
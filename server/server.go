@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/server/unitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the UnitService gRPC service, backed by a Manager.
+type Server struct {
+	unitpb.UnimplementedUnitServiceServer
+
+	mgr        systemdmanager.Manager
+	authorizer Authorizer
+}
+
+// Assert Server fulfills the UnitServiceServer interface.
+var _ unitpb.UnitServiceServer = (*Server)(nil)
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAuthorizer checks every request with a, using the bearer token of its
+// authorization metadata. Unauthenticated requests fail with the
+// Unauthenticated code, and forbidden ones with PermissionDenied.
+func WithAuthorizer(a Authorizer) Option {
+	return func(srv *Server) {
+		srv.authorizer = a
+	}
+}
+
+// New returns a Server managing units through mgr.
+func New(mgr systemdmanager.Manager, opts ...Option) *Server {
+	srv := &Server{mgr: mgr}
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	return srv
+}
+
+// Register registers the UnitService on s.
+func (srv *Server) Register(s grpc.ServiceRegistrar) {
+	unitpb.RegisterUnitServiceServer(s, srv)
+}
+
+// Start synchronously starts a unit.
+func (srv *Server) Start(ctx context.Context, req *unitpb.UnitRequest) (*unitpb.UnitResponse, error) {
+	if err := validateUnit(req.GetUnit()); err != nil {
+		return nil, err
+	}
+	opts, err := callOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.authorize(ctx, OperationStart, req.GetUnit()); err != nil {
+		return nil, err
+	}
+	if err := srv.mgr.Start(ctx, req.GetUnit(), opts...); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &unitpb.UnitResponse{}, nil
+}
+
+// Stop synchronously stops a unit.
+func (srv *Server) Stop(ctx context.Context, req *unitpb.UnitRequest) (*unitpb.UnitResponse, error) {
+	if err := validateUnit(req.GetUnit()); err != nil {
+		return nil, err
+	}
+	opts, err := callOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.authorize(ctx, OperationStop, req.GetUnit()); err != nil {
+		return nil, err
+	}
+	if err := srv.mgr.Stop(ctx, req.GetUnit(), opts...); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &unitpb.UnitResponse{}, nil
+}
+
+// Restart synchronously restarts a unit.
+func (srv *Server) Restart(ctx context.Context, req *unitpb.UnitRequest) (*unitpb.UnitResponse, error) {
+	if err := validateUnit(req.GetUnit()); err != nil {
+		return nil, err
+	}
+	opts, err := callOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.authorize(ctx, OperationRestart, req.GetUnit()); err != nil {
+		return nil, err
+	}
+	if err := srv.mgr.Restart(ctx, req.GetUnit(), opts...); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &unitpb.UnitResponse{}, nil
+}
+
+// Status returns the current status of a unit.
+func (srv *Server) Status(ctx context.Context, req *unitpb.StatusRequest) (*unitpb.StatusResponse, error) {
+	if err := validateUnit(req.GetUnit()); err != nil {
+		return nil, err
+	}
+	if err := srv.authorize(ctx, OperationStatus, req.GetUnit()); err != nil {
+		return nil, err
+	}
+	s, err := srv.mgr.Status(ctx, req.GetUnit())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &unitpb.StatusResponse{Status: toUnitStatus(s)}, nil
+}
+
+// Watch streams the status changes of a unit until the client goes away.
+func (srv *Server) Watch(req *unitpb.WatchRequest, stream grpc.ServerStreamingServer[unitpb.WatchResponse]) error {
+	if err := validateUnit(req.GetUnit()); err != nil {
+		return err
+	}
+	if err := srv.authorize(stream.Context(), OperationWatch, req.GetUnit()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	updatesChan := make(chan *dbus.UnitStatus)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- srv.mgr.Watch(ctx, req.GetUnit(), updatesChan)
+	}()
+
+	for {
+		select {
+		case err := <-watchDone:
+			return toStatusError(err)
+		case s := <-updatesChan:
+			if err := stream.Send(&unitpb.WatchResponse{Unit: req.GetUnit(), Status: toUnitStatus(s)}); err != nil {
+				// Stop watching, draining updates until Watch returns.
+				cancel()
+				for {
+					select {
+					case <-updatesChan:
+					case <-watchDone:
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// validateUnit ensures a unit name is provided.
+func validateUnit(unit string) error {
+	if unit == "" {
+		return status.Error(codes.InvalidArgument, "unit is required")
+	}
+
+	return nil
+}
+
+// authorize checks with the authorizer, if any, that the request may run op
+// on the named unit.
+func (srv *Server) authorize(ctx context.Context, op Operation, unit string) error {
+	if srv.authorizer == nil {
+		return nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = bearerToken(values[0])
+		}
+	}
+
+	return toStatusError(srv.authorizer.Authorize(ctx, token, op, unit))
+}
+
+// callOptions translates the settings of a request into call options. It
+// fails with the InvalidArgument code if the request asks for a job mode
+// clients may not use.
+func callOptions(req *unitpb.UnitRequest) ([]systemdmanager.CallOption, error) {
+	var opts []systemdmanager.CallOption
+	if mode := req.GetJobMode(); mode != "" {
+		if err := checkJobMode(mode); err != nil {
+			return nil, toStatusError(err)
+		}
+		opts = append(opts, systemdmanager.WithJobMode(systemdmanager.JobMode(mode)))
+	}
+	if req.GetTimeout() != nil {
+		opts = append(opts, systemdmanager.WithTimeout(req.GetTimeout().AsDuration()))
+	}
+
+	return opts, nil
+}
+
+// jobModes are the job modes clients may use. Others, such as isolate or
+// ignore-dependencies, affect units other than the one authorized.
+var jobModes = []systemdmanager.JobMode{systemdmanager.JobModeReplace, systemdmanager.JobModeFail}
+
+// checkJobMode returns an error wrapping systemdmanager.ErrInvalidJobMode
+// unless clients may use mode.
+func checkJobMode(mode string) error {
+	if !slices.Contains(jobModes, systemdmanager.JobMode(mode)) {
+		return fmt.Errorf("%w %q: clients may only use %q", systemdmanager.ErrInvalidJobMode, mode, jobModes)
+	}
+
+	return nil
+}
+
+// toUnitStatus converts a unit status to its wire representation.
+func toUnitStatus(s *dbus.UnitStatus) *unitpb.UnitStatus {
+	if s == nil {
+		return nil
+	}
+
+	return &unitpb.UnitStatus{
+		Name:        s.Name,
+		Description: s.Description,
+		LoadState:   s.LoadState,
+		ActiveState: s.ActiveState,
+		SubState:    s.SubState,
+		Followed:    s.Followed,
+		Path:        string(s.Path),
+		JobId:       s.JobId,
+		JobType:     s.JobType,
+		JobPath:     string(s.JobPath),
+	}
+}
+
+// toStatusError maps Manager errors to gRPC status errors.
+func toStatusError(err error) error {
+	var c codes.Code
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		c = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, systemdmanager.ErrTimeout), errors.Is(err, systemdmanager.ErrJobTimeout):
+		c = codes.DeadlineExceeded
+	case errors.Is(err, systemdmanager.ErrDisconnected):
+		c = codes.Unavailable
+	case errors.Is(err, systemdmanager.ErrRateLimited):
+		c = codes.ResourceExhausted
+	case errors.Is(err, ErrUnauthenticated):
+		c = codes.Unauthenticated
+	case errors.Is(err, ErrForbidden), errors.Is(err, systemdmanager.ErrPermissionDenied), errors.Is(err, systemdmanager.ErrUnitNotAllowed):
+		c = codes.PermissionDenied
+	case errors.Is(err, systemdmanager.ErrInvalidUnitName), errors.Is(err, systemdmanager.ErrWrongUnitType), errors.Is(err, systemdmanager.ErrInvalidJobMode):
+		c = codes.InvalidArgument
+	case errors.Is(err, systemdmanager.ErrUnitFailed), errors.Is(err, systemdmanager.ErrJobFailed), errors.Is(err, systemdmanager.ErrJobDependency), errors.Is(err, systemdmanager.ErrUnitClaimed):
+		c = codes.FailedPrecondition
+	case errors.Is(err, systemdmanager.ErrUnitNotFound):
+		c = codes.NotFound
+	default:
+		c = codes.Internal
+	}
+
+	return status.Error(c, err.Error())
+}
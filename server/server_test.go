@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/pires/go-systemdmanager/server/unitpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakeManager is a Manager whose methods are provided by the test. Calling a
+// method that isn't provided panics.
+type fakeManager struct {
+	systemdmanager.Manager
+
+	start  func(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error
+	status func(ctx context.Context, unit string) (*dbus.UnitStatus, error)
+	watch  func(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error
+}
+
+func (f *fakeManager) Start(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	return f.start(ctx, unit, opts...)
+}
+
+func (f *fakeManager) Status(ctx context.Context, unit string) (*dbus.UnitStatus, error) {
+	return f.status(ctx, unit)
+}
+
+func (f *fakeManager) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, _ ...systemdmanager.WatchOption) error {
+	return f.watch(ctx, unit, updatesChan)
+}
+
+// newTestClient serves mgr over an in-memory connection and returns a client
+// connected to it.
+func newTestClient(t *testing.T, mgr systemdmanager.Manager, opts ...Option) unitpb.UnitServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	New(mgr, opts...).Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return unitpb.NewUnitServiceClient(conn)
+}
+
+func Test_Unit_Server(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	mgr := &fakeManager{
+		start: func(_ context.Context, unit string, opts ...systemdmanager.CallOption) error {
+			require.Len(t, opts, 2)
+			if unit == "limited.service" {
+				return systemdmanager.ErrRateLimited
+			}
+			return nil
+		},
+		status: func(_ context.Context, unit string) (*dbus.UnitStatus, error) {
+			return &dbus.UnitStatus{Name: unit, ActiveState: "active"}, nil
+		},
+		watch: func(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error {
+			updatesChan <- &dbus.UnitStatus{Name: unit, ActiveState: "active"}
+			updatesChan <- nil
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	client := newTestClient(t, mgr)
+
+	t.Run("Start", func(t *testing.T) {
+		req := &unitpb.UnitRequest{Unit: "a.service", JobMode: "fail", Timeout: durationpb.New(time.Second)}
+		_, err := client.Start(ctx, req)
+		require.NoError(t, err)
+
+		req.Unit = "limited.service"
+		_, err = client.Start(ctx, req)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		_, err = client.Start(ctx, &unitpb.UnitRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		// Job modes affecting other units are rejected.
+		_, err = client.Start(ctx, &unitpb.UnitRequest{Unit: "a.service", JobMode: "isolate"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		res, err := client.Status(ctx, &unitpb.StatusRequest{Unit: "a.service"})
+		require.NoError(t, err)
+		require.Equal(t, "a.service", res.GetStatus().GetName())
+		require.Equal(t, "active", res.GetStatus().GetActiveState())
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &unitpb.WatchRequest{Unit: "a.service"})
+		require.NoError(t, err)
+
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "active", res.GetStatus().GetActiveState())
+
+		res, err = stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "a.service", res.GetUnit())
+		require.Nil(t, res.GetStatus())
+	})
+}
+
+func Test_Unit_toStatusError(t *testing.T) {
+	require.NoError(t, toStatusError(nil))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(toStatusError(systemdmanager.ErrTimeout)))
+	require.Equal(t, codes.Unavailable, status.Code(toStatusError(systemdmanager.ErrDisconnected)))
+	require.Equal(t, codes.Canceled, status.Code(toStatusError(context.Canceled)))
+	require.Equal(t, codes.NotFound, status.Code(toStatusError(fmt.Errorf("%w: %q", systemdmanager.ErrUnitNotFound, "a.service"))))
+	require.Equal(t, codes.InvalidArgument, status.Code(toStatusError(fmt.Errorf("%w: %q", systemdmanager.ErrWrongUnitType, "a.timer"))))
+	require.Equal(t, codes.FailedPrecondition, status.Code(toStatusError(&systemdmanager.JobError{Op: systemdmanager.OperationStart, Unit: "a.service", Result: "failed"})))
+	require.Equal(t, codes.PermissionDenied, status.Code(toStatusError(fmt.Errorf("%w: %q", systemdmanager.ErrUnitNotAllowed, "a.service"))))
+	require.Equal(t, codes.FailedPrecondition, status.Code(toStatusError(fmt.Errorf("unit %q is claimed by %q: %w", "a.service", "b", systemdmanager.ErrUnitClaimed))))
+	require.Equal(t, codes.Internal, status.Code(toStatusError(errors.New("boom"))))
+}
@@ -0,0 +1,532 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: unitpb/unit.proto
+
+package unitpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UnitRequest identifies the unit a mutating operation applies to.
+type UnitRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Unit  string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	// job_mode defaults to "replace". Only "replace" and "fail" are allowed.
+	JobMode string `protobuf:"bytes,2,opt,name=job_mode,json=jobMode,proto3" json:"job_mode,omitempty"`
+	// timeout bounds the operation, overriding the server default.
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitRequest) Reset() {
+	*x = UnitRequest{}
+	mi := &file_unitpb_unit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitRequest) ProtoMessage() {}
+
+func (x *UnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitRequest.ProtoReflect.Descriptor instead.
+func (*UnitRequest) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UnitRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *UnitRequest) GetJobMode() string {
+	if x != nil {
+		return x.JobMode
+	}
+	return ""
+}
+
+func (x *UnitRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type UnitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitResponse) Reset() {
+	*x = UnitResponse{}
+	mi := &file_unitpb_unit_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitResponse) ProtoMessage() {}
+
+func (x *UnitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitResponse.ProtoReflect.Descriptor instead.
+func (*UnitResponse) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{1}
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_unitpb_unit_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StatusRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *UnitStatus            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_unitpb_unit_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatusResponse) GetStatus() *UnitStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_unitpb_unit_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+type WatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Unit  string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	// status is unset when the unit is no longer loaded.
+	Status        *UnitStatus `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_unitpb_unit_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchResponse) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *WatchResponse) GetStatus() *UnitStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// UnitStatus mirrors the systemd ListUnits entry of a unit.
+type UnitStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	LoadState     string                 `protobuf:"bytes,3,opt,name=load_state,json=loadState,proto3" json:"load_state,omitempty"`
+	ActiveState   string                 `protobuf:"bytes,4,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`
+	SubState      string                 `protobuf:"bytes,5,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
+	Followed      string                 `protobuf:"bytes,6,opt,name=followed,proto3" json:"followed,omitempty"`
+	Path          string                 `protobuf:"bytes,7,opt,name=path,proto3" json:"path,omitempty"`
+	JobId         uint32                 `protobuf:"varint,8,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	JobType       string                 `protobuf:"bytes,9,opt,name=job_type,json=jobType,proto3" json:"job_type,omitempty"`
+	JobPath       string                 `protobuf:"bytes,10,opt,name=job_path,json=jobPath,proto3" json:"job_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitStatus) Reset() {
+	*x = UnitStatus{}
+	mi := &file_unitpb_unit_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitStatus) ProtoMessage() {}
+
+func (x *UnitStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_unitpb_unit_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitStatus.ProtoReflect.Descriptor instead.
+func (*UnitStatus) Descriptor() ([]byte, []int) {
+	return file_unitpb_unit_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UnitStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetLoadState() string {
+	if x != nil {
+		return x.LoadState
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetActiveState() string {
+	if x != nil {
+		return x.ActiveState
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetSubState() string {
+	if x != nil {
+		return x.SubState
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetFollowed() string {
+	if x != nil {
+		return x.Followed
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetJobId() uint32 {
+	if x != nil {
+		return x.JobId
+	}
+	return 0
+}
+
+func (x *UnitStatus) GetJobType() string {
+	if x != nil {
+		return x.JobType
+	}
+	return ""
+}
+
+func (x *UnitStatus) GetJobPath() string {
+	if x != nil {
+		return x.JobPath
+	}
+	return ""
+}
+
+var File_unitpb_unit_proto protoreflect.FileDescriptor
+
+const file_unitpb_unit_proto_rawDesc = "" +
+	"\n" +
+	"\x11unitpb/unit.proto\x12\x11systemdmanager.v1\x1a\x1egoogle/protobuf/duration.proto\"q\n" +
+	"\vUnitRequest\x12\x12\n" +
+	"\x04unit\x18\x01 \x01(\tR\x04unit\x12\x19\n" +
+	"\bjob_mode\x18\x02 \x01(\tR\ajobMode\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\x0e\n" +
+	"\fUnitResponse\"#\n" +
+	"\rStatusRequest\x12\x12\n" +
+	"\x04unit\x18\x01 \x01(\tR\x04unit\"G\n" +
+	"\x0eStatusResponse\x125\n" +
+	"\x06status\x18\x01 \x01(\v2\x1d.systemdmanager.v1.UnitStatusR\x06status\"\"\n" +
+	"\fWatchRequest\x12\x12\n" +
+	"\x04unit\x18\x01 \x01(\tR\x04unit\"Z\n" +
+	"\rWatchResponse\x12\x12\n" +
+	"\x04unit\x18\x01 \x01(\tR\x04unit\x125\n" +
+	"\x06status\x18\x02 \x01(\v2\x1d.systemdmanager.v1.UnitStatusR\x06status\"\x9e\x02\n" +
+	"\n" +
+	"UnitStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"load_state\x18\x03 \x01(\tR\tloadState\x12!\n" +
+	"\factive_state\x18\x04 \x01(\tR\vactiveState\x12\x1b\n" +
+	"\tsub_state\x18\x05 \x01(\tR\bsubState\x12\x1a\n" +
+	"\bfollowed\x18\x06 \x01(\tR\bfollowed\x12\x12\n" +
+	"\x04path\x18\a \x01(\tR\x04path\x12\x15\n" +
+	"\x06job_id\x18\b \x01(\rR\x05jobId\x12\x19\n" +
+	"\bjob_type\x18\t \x01(\tR\ajobType\x12\x19\n" +
+	"\bjob_path\x18\n" +
+	" \x01(\tR\ajobPath2\x89\x03\n" +
+	"\vUnitService\x12H\n" +
+	"\x05Start\x12\x1e.systemdmanager.v1.UnitRequest\x1a\x1f.systemdmanager.v1.UnitResponse\x12G\n" +
+	"\x04Stop\x12\x1e.systemdmanager.v1.UnitRequest\x1a\x1f.systemdmanager.v1.UnitResponse\x12J\n" +
+	"\aRestart\x12\x1e.systemdmanager.v1.UnitRequest\x1a\x1f.systemdmanager.v1.UnitResponse\x12M\n" +
+	"\x06Status\x12 .systemdmanager.v1.StatusRequest\x1a!.systemdmanager.v1.StatusResponse\x12L\n" +
+	"\x05Watch\x12\x1f.systemdmanager.v1.WatchRequest\x1a .systemdmanager.v1.WatchResponse0\x01B2Z0github.com/pires/go-systemdmanager/server/unitpbb\x06proto3"
+
+var (
+	file_unitpb_unit_proto_rawDescOnce sync.Once
+	file_unitpb_unit_proto_rawDescData []byte
+)
+
+func file_unitpb_unit_proto_rawDescGZIP() []byte {
+	file_unitpb_unit_proto_rawDescOnce.Do(func() {
+		file_unitpb_unit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_unitpb_unit_proto_rawDesc), len(file_unitpb_unit_proto_rawDesc)))
+	})
+	return file_unitpb_unit_proto_rawDescData
+}
+
+var file_unitpb_unit_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_unitpb_unit_proto_goTypes = []any{
+	(*UnitRequest)(nil),         // 0: systemdmanager.v1.UnitRequest
+	(*UnitResponse)(nil),        // 1: systemdmanager.v1.UnitResponse
+	(*StatusRequest)(nil),       // 2: systemdmanager.v1.StatusRequest
+	(*StatusResponse)(nil),      // 3: systemdmanager.v1.StatusResponse
+	(*WatchRequest)(nil),        // 4: systemdmanager.v1.WatchRequest
+	(*WatchResponse)(nil),       // 5: systemdmanager.v1.WatchResponse
+	(*UnitStatus)(nil),          // 6: systemdmanager.v1.UnitStatus
+	(*durationpb.Duration)(nil), // 7: google.protobuf.Duration
+}
+var file_unitpb_unit_proto_depIdxs = []int32{
+	7, // 0: systemdmanager.v1.UnitRequest.timeout:type_name -> google.protobuf.Duration
+	6, // 1: systemdmanager.v1.StatusResponse.status:type_name -> systemdmanager.v1.UnitStatus
+	6, // 2: systemdmanager.v1.WatchResponse.status:type_name -> systemdmanager.v1.UnitStatus
+	0, // 3: systemdmanager.v1.UnitService.Start:input_type -> systemdmanager.v1.UnitRequest
+	0, // 4: systemdmanager.v1.UnitService.Stop:input_type -> systemdmanager.v1.UnitRequest
+	0, // 5: systemdmanager.v1.UnitService.Restart:input_type -> systemdmanager.v1.UnitRequest
+	2, // 6: systemdmanager.v1.UnitService.Status:input_type -> systemdmanager.v1.StatusRequest
+	4, // 7: systemdmanager.v1.UnitService.Watch:input_type -> systemdmanager.v1.WatchRequest
+	1, // 8: systemdmanager.v1.UnitService.Start:output_type -> systemdmanager.v1.UnitResponse
+	1, // 9: systemdmanager.v1.UnitService.Stop:output_type -> systemdmanager.v1.UnitResponse
+	1, // 10: systemdmanager.v1.UnitService.Restart:output_type -> systemdmanager.v1.UnitResponse
+	3, // 11: systemdmanager.v1.UnitService.Status:output_type -> systemdmanager.v1.StatusResponse
+	5, // 12: systemdmanager.v1.UnitService.Watch:output_type -> systemdmanager.v1.WatchResponse
+	8, // [8:13] is the sub-list for method output_type
+	3, // [3:8] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_unitpb_unit_proto_init() }
+func file_unitpb_unit_proto_init() {
+	if File_unitpb_unit_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_unitpb_unit_proto_rawDesc), len(file_unitpb_unit_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_unitpb_unit_proto_goTypes,
+		DependencyIndexes: file_unitpb_unit_proto_depIdxs,
+		MessageInfos:      file_unitpb_unit_proto_msgTypes,
+	}.Build()
+	File_unitpb_unit_proto = out.File
+	file_unitpb_unit_proto_goTypes = nil
+	file_unitpb_unit_proto_depIdxs = nil
+}
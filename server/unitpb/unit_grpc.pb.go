@@ -0,0 +1,291 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: unitpb/unit.proto
+
+package unitpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UnitService_Start_FullMethodName   = "/systemdmanager.v1.UnitService/Start"
+	UnitService_Stop_FullMethodName    = "/systemdmanager.v1.UnitService/Stop"
+	UnitService_Restart_FullMethodName = "/systemdmanager.v1.UnitService/Restart"
+	UnitService_Status_FullMethodName  = "/systemdmanager.v1.UnitService/Status"
+	UnitService_Watch_FullMethodName   = "/systemdmanager.v1.UnitService/Watch"
+)
+
+// UnitServiceClient is the client API for UnitService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UnitService manages systemd units remotely.
+type UnitServiceClient interface {
+	// Start synchronously starts a unit.
+	Start(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error)
+	// Stop synchronously stops a unit.
+	Stop(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error)
+	// Restart synchronously restarts a unit.
+	Restart(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error)
+	// Status returns the current status of a unit.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Watch streams the status changes of a unit.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error)
+}
+
+type unitServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUnitServiceClient(cc grpc.ClientConnInterface) UnitServiceClient {
+	return &unitServiceClient{cc}
+}
+
+func (c *unitServiceClient) Start(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnitResponse)
+	err := c.cc.Invoke(ctx, UnitService_Start_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unitServiceClient) Stop(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnitResponse)
+	err := c.cc.Invoke(ctx, UnitService_Stop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unitServiceClient) Restart(ctx context.Context, in *UnitRequest, opts ...grpc.CallOption) (*UnitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnitResponse)
+	err := c.cc.Invoke(ctx, UnitService_Restart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unitServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, UnitService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unitServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UnitService_ServiceDesc.Streams[0], UnitService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UnitService_WatchClient = grpc.ServerStreamingClient[WatchResponse]
+
+// UnitServiceServer is the server API for UnitService service.
+// All implementations must embed UnimplementedUnitServiceServer
+// for forward compatibility.
+//
+// UnitService manages systemd units remotely.
+type UnitServiceServer interface {
+	// Start synchronously starts a unit.
+	Start(context.Context, *UnitRequest) (*UnitResponse, error)
+	// Stop synchronously stops a unit.
+	Stop(context.Context, *UnitRequest) (*UnitResponse, error)
+	// Restart synchronously restarts a unit.
+	Restart(context.Context, *UnitRequest) (*UnitResponse, error)
+	// Status returns the current status of a unit.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Watch streams the status changes of a unit.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error
+	mustEmbedUnimplementedUnitServiceServer()
+}
+
+// UnimplementedUnitServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUnitServiceServer struct{}
+
+func (UnimplementedUnitServiceServer) Start(context.Context, *UnitRequest) (*UnitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedUnitServiceServer) Stop(context.Context, *UnitRequest) (*UnitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedUnitServiceServer) Restart(context.Context, *UnitRequest) (*UnitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Restart not implemented")
+}
+func (UnimplementedUnitServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedUnitServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error {
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedUnitServiceServer) mustEmbedUnimplementedUnitServiceServer() {}
+func (UnimplementedUnitServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeUnitServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UnitServiceServer will
+// result in compilation errors.
+type UnsafeUnitServiceServer interface {
+	mustEmbedUnimplementedUnitServiceServer()
+}
+
+func RegisterUnitServiceServer(s grpc.ServiceRegistrar, srv UnitServiceServer) {
+	// If the following call panics, it indicates UnimplementedUnitServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UnitService_ServiceDesc, srv)
+}
+
+func _UnitService_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnitServiceServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnitService_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnitServiceServer).Start(ctx, req.(*UnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UnitService_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnitServiceServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnitService_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnitServiceServer).Stop(ctx, req.(*UnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UnitService_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnitServiceServer).Restart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnitService_Restart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnitServiceServer).Restart(ctx, req.(*UnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UnitService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnitServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnitService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnitServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UnitService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UnitServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UnitService_WatchServer = grpc.ServerStreamingServer[WatchResponse]
+
+// UnitService_ServiceDesc is the grpc.ServiceDesc for UnitService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UnitService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "systemdmanager.v1.UnitService",
+	HandlerType: (*UnitServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _UnitService_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _UnitService_Stop_Handler,
+		},
+		{
+			MethodName: "Restart",
+			Handler:    _UnitService_Restart_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _UnitService_Status_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _UnitService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "unitpb/unit.proto",
+}
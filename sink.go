@@ -0,0 +1,234 @@
+package systemdmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NotificationType is the kind of a Notification.
+type NotificationType string
+
+const (
+	// NotificationStatus is a unit status change observed by Watch.
+	NotificationStatus NotificationType = "unit.status"
+	// NotificationResync is the current status of a unit reported by Watch
+	// after reconnecting to systemd, when changes may have been missed.
+	NotificationResync NotificationType = "unit.resync"
+	// NotificationFailure is a unit failure observed by WatchFailures.
+	NotificationFailure NotificationType = "unit.failure"
+	// NotificationOOM is an OOM kill observed by WatchFailures, with the
+	// Failure field set.
+	NotificationOOM NotificationType = "unit.oom"
+	// NotificationSupervisor is a restart attempt by a Supervisor.
+	NotificationSupervisor NotificationType = "supervisor.restart"
+)
+
+// Notification is an event published to event sinks. Only the field
+// matching Type is set.
+type Notification struct {
+	Type       NotificationType `json:"type" yaml:"type"`
+	Status     *Event           `json:"status,omitempty" yaml:"status,omitempty"`
+	Failure    *FailureEvent    `json:"failure,omitempty" yaml:"failure,omitempty"`
+	Supervisor *SupervisorEvent `json:"supervisor,omitempty" yaml:"supervisor,omitempty"`
+}
+
+// EventSink receives notifications published by Watch, WatchFailures, and
+// supervisors. Publishing blocks watching, so slow sinks should hand
+// notifications off.
+type EventSink interface {
+	Publish(ctx context.Context, n Notification) error
+}
+
+// WithEventSink publishes the events observed by Watch and WatchFailures to
+// sink. It may be given more than once. Failing to publish doesn't stop
+// watching, but is recorded in the watch span.
+func WithEventSink(sink EventSink) Option {
+	return func(o *options) {
+		o.eventSinks = append(o.eventSinks, sink)
+	}
+}
+
+// publish sends n to the event sinks.
+func (m *manager) publish(ctx context.Context, n Notification) {
+	for _, sink := range m.options.eventSinks {
+		if err := sink.Publish(ctx, n); err != nil {
+			trace.SpanFromContext(ctx).RecordError(fmt.Errorf("failed to publish %s notification: %w", n.Type, err))
+		}
+	}
+}
+
+// publishStatus publishes a status observed by Watch, as a notification of
+// type NotificationStatus or NotificationResync.
+func (m *manager) publishStatus(ctx context.Context, typ NotificationType, unit string, status *dbus.UnitStatus) {
+	if len(m.options.eventSinks) == 0 {
+		return
+	}
+	event := NewEvent(unit, status)
+	m.publish(ctx, Notification{Type: typ, Status: &event})
+}
+
+// SupervisorNotifier returns a notify function for NewSupervisor publishing
+// supervisor events to sink. Failing to publish is ignored.
+func SupervisorNotifier(ctx context.Context, sink EventSink) func(SupervisorEvent) {
+	return func(e SupervisorEvent) {
+		_ = sink.Publish(ctx, Notification{Type: NotificationSupervisor, Supervisor: &e})
+	}
+}
+
+// ChannelSink is an EventSink sending notifications to a channel.
+type ChannelSink chan<- Notification
+
+// Assert ChannelSink fulfills the EventSink interface.
+var _ EventSink = ChannelSink(nil)
+
+// Publish sends n to the channel, blocking until it's received or ctx is
+// done.
+func (s ChannelSink) Publish(ctx context.Context, n Notification) error {
+	select {
+	case s <- n:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// webhookSignatureHeader holds the HMAC-SHA256 signature of webhook bodies.
+const webhookSignatureHeader = "X-Signature-256"
+
+// defaultWebhookRetryPolicy retries failed webhook deliveries a few times.
+var defaultWebhookRetryPolicy = RetryPolicy{
+	Attempts: 3,
+	Backoff:  Backoff{Initial: time.Second, Max: 10 * time.Second},
+}
+
+// WebhookSink is an EventSink posting notifications as JSON to a URL.
+type WebhookSink struct {
+	url         string
+	client      *http.Client
+	secret      []byte
+	retryPolicy RetryPolicy
+}
+
+// Assert WebhookSink fulfills the EventSink interface.
+var _ EventSink = (*WebhookSink)(nil)
+
+// WebhookOption configures a WebhookSink.
+type WebhookOption func(*WebhookSink)
+
+// WithWebhookClient sets the HTTP client used to post notifications.
+// Defaults to http.DefaultClient.
+func WithWebhookClient(c *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.client = c
+	}
+}
+
+// WithWebhookSecret signs notifications with an HMAC-SHA256 of the body
+// keyed by secret, hex encoded in the X-Signature-256 header as
+// "sha256=<signature>".
+func WithWebhookSecret(secret []byte) WebhookOption {
+	return func(s *WebhookSink) {
+		s.secret = secret
+	}
+}
+
+// WithWebhookRetryPolicy sets how failed deliveries are retried. By
+// default, deliveries are attempted three times. Network errors, server
+// errors, and rate limiting are retried, unless the policy classifies
+// errors itself.
+func WithWebhookRetryPolicy(p RetryPolicy) WebhookOption {
+	return func(s *WebhookSink) {
+		s.retryPolicy = p
+	}
+}
+
+// NewWebhookSink returns a WebhookSink posting to url.
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:         url,
+		client:      http.DefaultClient,
+		retryPolicy: defaultWebhookRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.retryPolicy.Retryable == nil {
+		s.retryPolicy.Retryable = isRetryableWebhookError
+	}
+
+	return s
+}
+
+// webhookStatusError is the unexpected HTTP status of a webhook response.
+type webhookStatusError struct {
+	code int
+}
+
+func (e webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.code)
+}
+
+// isRetryableWebhookError returns whether a delivery may succeed if
+// retried.
+func isRetryableWebhookError(err error) bool {
+	var statusErr webhookStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500 || statusErr.code == http.StatusTooManyRequests
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Publish posts n to the webhook, retrying according to the retry policy.
+func (s *WebhookSink) Publish(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	return s.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return s.post(ctx, body)
+	})
+}
+
+// post posts body once.
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != nil {
+		req.Header.Set(webhookSignatureHeader, signWebhook(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return webhookStatusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// signWebhook returns the signature header value of body.
+func signWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package systemdmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_WebhookSink(t *testing.T) {
+	n := Notification{
+		Type:    NotificationFailure,
+		Failure: &FailureEvent{Unit: "a.service", Result: "exit-code"},
+	}
+	fastRetries := RetryPolicy{Attempts: 3, Backoff: Backoff{Initial: time.Millisecond}}
+
+	t.Run("Posts signed JSON", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, signWebhook([]byte("secret"), body), r.Header.Get(webhookSignatureHeader))
+
+			var got Notification
+			require.NoError(t, json.Unmarshal(body, &got))
+			require.Equal(t, n, got)
+		}))
+		defer srv.Close()
+
+		sink := NewWebhookSink(srv.URL, WithWebhookSecret([]byte("secret")))
+		require.NoError(t, sink.Publish(t.Context(), n))
+	})
+
+	t.Run("Retries server errors", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+
+		sink := NewWebhookSink(srv.URL, WithWebhookRetryPolicy(fastRetries))
+		require.NoError(t, sink.Publish(t.Context(), n))
+		require.EqualValues(t, 3, attempts.Load())
+	})
+
+	t.Run("Doesn't retry client errors", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		sink := NewWebhookSink(srv.URL, WithWebhookRetryPolicy(fastRetries))
+		err := sink.Publish(t.Context(), n)
+		require.ErrorAs(t, err, &webhookStatusError{})
+		require.EqualValues(t, 1, attempts.Load())
+	})
+}
+
+func Test_Unit_signWebhook(t *testing.T) {
+	// Known HMAC-SHA256 test vector, RFC 4231 test case 2.
+	require.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		signWebhook([]byte("Jefe"), []byte("what do ya want for nothing?")))
+}
+
+func Test_Unit_ChannelSink(t *testing.T) {
+	ch := make(chan Notification, 1)
+	n := Notification{Type: NotificationSupervisor, Supervisor: &SupervisorEvent{Unit: "a.service", Attempt: 1}}
+	require.NoError(t, ChannelSink(ch).Publish(t.Context(), n))
+	require.Equal(t, n, <-ch)
+
+	// Publishing gives up once ctx is done.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	require.ErrorIs(t, ChannelSink(make(chan Notification)).Publish(ctx, n), context.Canceled)
+}
+
+// failingSink is an EventSink failing to publish.
+type failingSink struct{}
+
+func (failingSink) Publish(context.Context, Notification) error {
+	return errors.New("unavailable")
+}
+
+func Test_Unit_manager_publishStatus(t *testing.T) {
+	ch := make(chan Notification, 1)
+	m := &manager{options: newOptions([]Option{WithEventSink(failingSink{}), WithEventSink(ChannelSink(ch))})}
+
+	// Failing sinks don't prevent publishing to others.
+	m.publishStatus(t.Context(), NotificationStatus, "a.service", &dbus.UnitStatus{Name: "a.service", ActiveState: "active"})
+	n := <-ch
+	require.Equal(t, NotificationStatus, n.Type)
+	require.Equal(t, "a.service", n.Status.Unit)
+	require.Equal(t, "active", n.Status.Status.ActiveState)
+}
@@ -0,0 +1,181 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitSnapshot is the runtime state of a unit at the time of a snapshot.
+type UnitSnapshot struct {
+	Name          string        `json:"name" yaml:"name"`
+	ActiveState   string        `json:"active_state" yaml:"active_state"`
+	SubState      string        `json:"sub_state" yaml:"sub_state"`
+	UnitFileState UnitFileState `json:"unit_file_state" yaml:"unit_file_state"`
+}
+
+// StateSnapshot is the runtime state of a set of units, as captured by
+// Snapshot.
+type StateSnapshot struct {
+	Units   []UnitSnapshot `json:"units" yaml:"units"`
+	TakenAt time.Time      `json:"taken_at" yaml:"taken_at"`
+}
+
+// Snapshot captures whether the named units are active and enabled, so that
+// state can be restored with Restore after maintenance.
+func (m *manager) Snapshot(parentCtx context.Context, units []string) (StateSnapshot, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Snapshot")
+	span.SetAttributes(otelattr.StringSlice("units", units))
+	defer span.End()
+
+	snap := StateSnapshot{
+		Units:   make([]UnitSnapshot, 0, len(units)),
+		TakenAt: time.Now(),
+	}
+	for _, unit := range units {
+		u, err := m.snapshotUnit(ctx, unit)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+
+			return StateSnapshot{}, err
+		}
+		snap.Units = append(snap.Units, u)
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("captured state of %d units", len(units)))
+
+	return snap, nil
+}
+
+// snapshotUnit captures the runtime state of a single unit.
+func (m *manager) snapshotUnit(ctx context.Context, unit string) (UnitSnapshot, error) {
+	status, err := m.Status(ctx, unit)
+	if err != nil {
+		return UnitSnapshot{}, err
+	}
+	state, err := m.unitFileState(ctx, unit)
+	if err != nil {
+		return UnitSnapshot{}, err
+	}
+
+	return UnitSnapshot{
+		Name:          unit,
+		ActiveState:   status.ActiveState,
+		SubState:      status.SubState,
+		UnitFileState: state,
+	}, nil
+}
+
+// Restore brings units back to the state captured by Snapshot: units that
+// were active are started and the others stopped, and units that were
+// enabled, disabled, or masked are made so again. Enablement states that
+// can't be set directly, such as static, are left alone. Restore carries on
+// after a unit fails to be restored, and reports all failures.
+func (m *manager) Restore(parentCtx context.Context, snap StateSnapshot) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Restore")
+	defer span.End()
+
+	var (
+		errs   []error
+		reload bool
+	)
+	for _, u := range snap.Units {
+		changed, err := m.restoreUnitFileState(ctx, u.Name, u.UnitFileState)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		reload = reload || changed
+	}
+	// Like systemctl, reload systemd once unit files changed.
+	if reload {
+		if err := m.daemonReload(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Stop units before starting others, in reverse order, so units started
+	// last are stopped first.
+	for _, u := range slices.Backward(snap.Units) {
+		if !isRunning(u.ActiveState) {
+			if err := m.Stop(ctx, u.Name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, u := range snap.Units {
+		if isRunning(u.ActiveState) {
+			if err := m.Start(ctx, u.Name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		err = fmt.Errorf("failed to restore state of units: %w", err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("restored state of %d units", len(snap.Units)))
+
+	return nil
+}
+
+// restoreUnitFileState enables, disables, masks or unmasks the named unit
+// file so it's back to the given state. It returns whether it changed
+// anything.
+func (m *manager) restoreUnitFileState(ctx context.Context, unit string, want UnitFileState) (bool, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return false, err
+	}
+	if err := m.admitChange(ctx, unit); err != nil {
+		return false, err
+	}
+	current, err := m.unitFileState(ctx, unit)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case current == want:
+		return false, nil
+	case !want.Enabled() && !want.Masked() && want != UnitFileDisabled:
+		// Other states follow from the unit file content or location.
+		return false, nil
+	}
+
+	err = m.options.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		files := []string{unit}
+		if current.Masked() && !want.Masked() {
+			if _, err := m.dbusConn.Load().UnmaskUnitFilesContext(ctx, files, current == UnitFileMaskedRuntime); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		switch want {
+		case UnitFileEnabled, UnitFileEnabledRuntime:
+			_, _, err = m.dbusConn.Load().EnableUnitFilesContext(ctx, files, want == UnitFileEnabledRuntime, true)
+		case UnitFileDisabled:
+			if current.Enabled() {
+				_, err = m.dbusConn.Load().DisableUnitFilesContext(ctx, files, current == UnitFileEnabledRuntime)
+			}
+		case UnitFileMasked, UnitFileMaskedRuntime:
+			_, err = m.dbusConn.Load().MaskUnitFilesContext(ctx, files, want == UnitFileMaskedRuntime, true)
+		}
+
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to restore unit file state of %q to %q: %w", unit, want, err)
+	}
+
+	return true, nil
+}
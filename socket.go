@@ -0,0 +1,77 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitSocketStats are the connection counters of a socket unit, which systemd
+// resets when the socket is stopped.
+type UnitSocketStats struct {
+	// Accepted is how many connections were accepted, for Accept=yes
+	// sockets.
+	Accepted uint32 `json:"accepted" yaml:"accepted"`
+	// Connections is how many accepted connections are open, for Accept=yes
+	// sockets.
+	Connections uint32 `json:"connections" yaml:"connections"`
+	// Refused is how many connections were refused, e.g. due to
+	// MaxConnections=. It's zero on systemd versions older than 239.
+	Refused uint32 `json:"refused" yaml:"refused"`
+}
+
+// SocketStats returns the connection counters of the named socket unit,
+// e.g. for capacity dashboards of socket-activated services.
+func (m *manager) SocketStats(parentCtx context.Context, unit string) (UnitSocketStats, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SocketStats")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	props, err := m.unitProperties(ctx, unit, "Socket")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitSocketStats{}, err
+	}
+	stats, err := decodeSocketStats(props)
+	if err != nil {
+		err = fmt.Errorf("failed to decode socket stats of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitSocketStats{}, err
+	}
+	span.SetAttributes(
+		otelattr.Int("accepted", int(stats.Accepted)),
+		otelattr.Int("connections", int(stats.Connections)),
+		otelattr.Int("refused", int(stats.Refused)),
+	)
+	span.SetStatus(otelcodes.Ok, "retrieved socket stats")
+
+	return stats, nil
+}
+
+// decodeSocketStats decodes the connection counters of a socket unit.
+// NRefused is left zero if missing.
+func decodeSocketStats(props map[string]godbus.Variant) (UnitSocketStats, error) {
+	var (
+		stats UnitSocketStats
+		errs  = make([]error, 2)
+	)
+	stats.Accepted, errs[0] = decodeProperty[uint32](props["NAccepted"])
+	stats.Connections, errs[1] = decodeProperty[uint32](props["NConnections"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitSocketStats{}, err
+		}
+	}
+	stats.Refused, _ = props["NRefused"].Value().(uint32)
+
+	return stats, nil
+}
@@ -0,0 +1,35 @@
+package systemdmanager
+
+import (
+	"context"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_decodeSocketStats(t *testing.T) {
+	stats, err := decodeSocketStats(map[string]godbus.Variant{
+		"NAccepted":    godbus.MakeVariant(uint32(42)),
+		"NConnections": godbus.MakeVariant(uint32(3)),
+		"NRefused":     godbus.MakeVariant(uint32(1)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitSocketStats{Accepted: 42, Connections: 3, Refused: 1}, stats)
+
+	// Older systemd versions don't count refused connections.
+	stats, err = decodeSocketStats(map[string]godbus.Variant{
+		"NAccepted":    godbus.MakeVariant(uint32(42)),
+		"NConnections": godbus.MakeVariant(uint32(3)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitSocketStats{Accepted: 42, Connections: 3}, stats)
+
+	_, err = decodeSocketStats(map[string]godbus.Variant{"NAccepted": godbus.MakeVariant("42")})
+	require.Error(t, err)
+}
+
+func Test_Unit_SocketStats_WrongUnitType(t *testing.T) {
+	_, err := (&manager{}).SocketStats(context.Background(), "foo.service")
+	require.ErrorIs(t, err, ErrWrongUnitType)
+}
@@ -0,0 +1,120 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// Status returns the current status of the named unit. Units that aren't
+// installed are reported with a "not-found" load state.
+func (m *manager) Status(parentCtx context.Context, unit string) (*dbus.UnitStatus, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Status")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	// Fail early rather than with a confusing D-Bus error.
+	if err := m.validateUnit(unit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		span.RecordError(ErrDisconnected)
+		span.SetStatus(otelcodes.Error, ErrDisconnected.Error())
+
+		return nil, ErrDisconnected
+	}
+	key := cacheKey{unit: unit}
+	if cached, ok := m.cache.get(key); ok {
+		// Callers may modify the status they get.
+		status := cached.(dbus.UnitStatus)
+		span.SetStatus(otelcodes.Ok, "retrieved cached unit status")
+
+		return &status, nil
+	}
+
+	var statuses []dbus.UnitStatus
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		statuses, err = m.dbusConn.Load().ListUnitsByNamesContext(ctx, []string{unit})
+		return err
+	})
+	if err == nil && len(statuses) != 1 {
+		err = fmt.Errorf("expected one status, got %d", len(statuses))
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve status of unit %q: %w", unit, err)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	m.cache.put(key, statuses[0], m.options.cacheTTL)
+	span.SetStatus(otelcodes.Ok, "retrieved unit status")
+
+	return &statuses[0], nil
+}
+
+// StatusAll returns the statuses of the named units, keyed by name, in a
+// single D-Bus round-trip. Units which aren't loaded have a "not-found" load
+// state, as with Status.
+func (m *manager) StatusAll(parentCtx context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "StatusAll")
+	span.SetAttributes(otelattr.StringSlice("units", units))
+	defer span.End()
+
+	statuses, err := m.statusAll(ctx, units)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved unit statuses")
+
+	return statuses, nil
+}
+
+// statusAll implements StatusAll.
+func (m *manager) statusAll(ctx context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	// Fail early rather than with a confusing D-Bus error.
+	for _, unit := range units {
+		if err := m.validateUnit(unit); err != nil {
+			return nil, err
+		}
+	}
+	if len(units) == 0 {
+		return map[string]*dbus.UnitStatus{}, nil
+	}
+
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return nil, ErrDisconnected
+	}
+
+	var statuses []dbus.UnitStatus
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		statuses, err = m.dbusConn.Load().ListUnitsByNamesContext(ctx, units)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of units: %w", err)
+	}
+
+	result := make(map[string]*dbus.UnitStatus, len(statuses))
+	for i := range statuses {
+		m.cache.put(cacheKey{unit: statuses[i].Name}, statuses[i], m.options.cacheTTL)
+		result[statuses[i].Name] = &statuses[i]
+	}
+
+	return result, nil
+}
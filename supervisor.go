@@ -0,0 +1,161 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// Backoff computes exponentially increasing delays between attempts.
+type Backoff struct {
+	// Initial is the delay before the first retry. Defaults to one second.
+	Initial time.Duration
+	// Max caps the delay. Defaults to one minute.
+	Max time.Duration
+	// Multiplier is the factor applied to the delay after every attempt.
+	// Defaults to 2.
+	Multiplier float64
+}
+
+// Delay returns how long to wait before the given attempt, starting at 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	initial, maxDelay, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = time.Minute
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(initial)
+	for i := 1; i < attempt && delay < float64(maxDelay); i++ {
+		delay *= multiplier
+	}
+
+	return min(time.Duration(delay), maxDelay)
+}
+
+// RestartPolicy configures how a Supervisor recovers failed units.
+type RestartPolicy struct {
+	// Backoff is the delay between consecutive restart attempts.
+	Backoff Backoff
+	// MaxAttempts is how many consecutive restarts are attempted before
+	// giving up on a unit. Zero means no limit. Attempts are reset once the
+	// unit is observed active again.
+	MaxAttempts int
+}
+
+// SupervisorEvent describes a restart attempt by a Supervisor.
+type SupervisorEvent struct {
+	Unit    string
+	Attempt int
+	// Err is the restart error, if any.
+	Err error
+	// GaveUp is set when the policy allows no more attempts. The unit is
+	// left alone until it is observed active again.
+	GaveUp bool
+}
+
+// Supervisor watches a set of units and restarts them when they fail,
+// according to a RestartPolicy.
+type Supervisor struct {
+	mgr    Manager
+	units  []string
+	policy RestartPolicy
+	notify func(SupervisorEvent)
+}
+
+// NewSupervisor returns a Supervisor for the named units. notify, if not nil,
+// is called for every restart attempt and when giving up on a unit.
+func NewSupervisor(mgr Manager, policy RestartPolicy, notify func(SupervisorEvent), units ...string) *Supervisor {
+	if notify == nil {
+		notify = func(SupervisorEvent) {}
+	}
+
+	return &Supervisor{
+		mgr:    mgr,
+		units:  slices.Clone(units),
+		policy: policy,
+		notify: notify,
+	}
+}
+
+// Run supervises units until ctx is done. This is a blocking function.
+func (s *Supervisor) Run(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(s.units))
+	)
+	for i, unit := range s.units {
+		wg.Go(func() {
+			errs[i] = s.supervise(ctx, unit)
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil && !errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("failed to supervise units: %w", err)
+	}
+
+	return ctx.Err()
+}
+
+// supervise watches a single unit and restarts it whenever it fails.
+func (s *Supervisor) supervise(ctx context.Context, unit string) error {
+	updatesChan := make(chan *dbus.UnitStatus)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- s.mgr.Watch(ctx, unit, updatesChan)
+	}()
+
+	attempts, gaveUp := 0, false
+	for {
+		select {
+		case err := <-watchDone:
+			return err
+		case status := <-updatesChan:
+			switch {
+			case status == nil:
+				continue
+			case status.ActiveState == "active":
+				attempts, gaveUp = 0, false
+			case status.ActiveState == "failed" && !gaveUp:
+				gaveUp = s.recover(ctx, unit, &attempts)
+			}
+		}
+	}
+}
+
+// recover restarts a failed unit, retrying with backoff until a restart
+// succeeds, ctx is done, or the policy allows no more attempts. It returns
+// whether it gave up.
+func (s *Supervisor) recover(ctx context.Context, unit string, attempts *int) bool {
+	for {
+		*attempts++
+		if s.policy.MaxAttempts > 0 && *attempts > s.policy.MaxAttempts {
+			s.notify(SupervisorEvent{Unit: unit, Attempt: *attempts - 1, GaveUp: true})
+
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(s.policy.Backoff.Delay(*attempts)):
+		}
+
+		err := s.mgr.Restart(ctx, unit)
+		s.notify(SupervisorEvent{Unit: unit, Attempt: *attempts, Err: err})
+		if err == nil || ctx.Err() != nil {
+			return false
+		}
+	}
+}
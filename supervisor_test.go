@@ -0,0 +1,88 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeManager is a Manager whose methods are provided by the test. Calling a
+// method that isn't provided panics.
+type fakeManager struct {
+	Manager
+
+	restart func(ctx context.Context, unit string) error
+	watch   func(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error
+}
+
+func (f *fakeManager) Restart(ctx context.Context, unit string, _ ...CallOption) error {
+	return f.restart(ctx, unit)
+}
+
+func (f *fakeManager) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, _ ...WatchOption) error {
+	return f.watch(ctx, unit, updatesChan)
+}
+
+func Test_Unit_Backoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	require.Equal(t, time.Second, b.Delay(1))
+	require.Equal(t, 2*time.Second, b.Delay(2))
+	require.Equal(t, 4*time.Second, b.Delay(3))
+	require.Equal(t, 5*time.Second, b.Delay(4))
+	require.Equal(t, 5*time.Second, b.Delay(100))
+
+	// Defaults.
+	require.Equal(t, time.Second, Backoff{}.Delay(1))
+	require.Equal(t, time.Minute, Backoff{}.Delay(100))
+}
+
+func Test_Unit_Supervisor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		mutex    sync.Mutex
+		restarts int
+		events   = make(chan SupervisorEvent, 10)
+	)
+	mgr := &fakeManager{
+		restart: func(context.Context, string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			restarts++
+			return errors.New("failed to restart")
+		},
+		watch: func(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus) error {
+			updatesChan <- &dbus.UnitStatus{Name: unit, ActiveState: "failed"}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	policy := RestartPolicy{
+		Backoff:     Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+		MaxAttempts: 3,
+	}
+	s := NewSupervisor(mgr, policy, func(e SupervisorEvent) { events <- e }, "a.service")
+
+	runDone := make(chan error)
+	go func() { runDone <- s.Run(ctx) }()
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		e := <-events
+		require.Equal(t, attempt, e.Attempt)
+		require.Error(t, e.Err)
+		require.False(t, e.GaveUp)
+	}
+	e := <-events
+	require.True(t, e.GaveUp)
+	require.Equal(t, 3, e.Attempt)
+
+	cancel()
+	require.ErrorIs(t, <-runDone, context.Canceled)
+	require.Equal(t, 3, restarts)
+}
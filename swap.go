@@ -0,0 +1,287 @@
+package systemdmanager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// procSwaps lists the active swap areas, with their size and usage.
+const procSwaps = "/proc/swaps"
+
+// UnitSwap is the state of a swap unit.
+type UnitSwap struct {
+	// Unit is the name of the swap unit, e.g. "dev-sda2.swap".
+	Unit string `json:"unit" yaml:"unit"`
+	// What is the swap device or file.
+	What     string `json:"what" yaml:"what"`
+	Priority int32  `json:"priority" yaml:"priority"`
+	Options  string `json:"options" yaml:"options"`
+	// ActiveState is "active" while swapping to the device.
+	ActiveState string `json:"active_state" yaml:"active_state"`
+	// Result is "success", or why swapon or swapoff failed, e.g.
+	// "exit-code".
+	Result string `json:"result" yaml:"result"`
+	// Size and Used are in bytes, and zero while the swap unit isn't active.
+	Size uint64 `json:"size" yaml:"size"`
+	Used uint64 `json:"used" yaml:"used"`
+}
+
+// SwapUnitName returns the name of the swap unit of the swap device or file
+// what, e.g. "dev-sda2.swap" for "/dev/sda2".
+func SwapUnitName(what string) string {
+	return EscapePath(what) + ".swap"
+}
+
+// ListSwaps returns the state of the loaded swap units. Aliases of a swap
+// device, such as its /dev/disk/by-uuid link, are only listed once.
+func (m *manager) ListSwaps(parentCtx context.Context) ([]UnitSwap, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ListSwaps")
+	defer span.End()
+
+	var units []string
+	_, err := m.eachUnit(ctx, UnitFilter{Patterns: []string{"*.swap"}, States: []string{"loaded"}}, func(status dbus.UnitStatus) error {
+		if status.Followed == "" {
+			units = append(units, status.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	areas, err := readSwapAreas()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return nil, err
+	}
+	swaps := make([]UnitSwap, 0, len(units))
+	for _, unit := range units {
+		swap, err := m.swapStatus(ctx, unit, areas)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+
+			return nil, err
+		}
+		swaps = append(swaps, swap)
+	}
+	span.SetAttributes(otelattr.Int("swaps", len(swaps)))
+	span.SetStatus(otelcodes.Ok, "listed swaps")
+
+	return swaps, nil
+}
+
+// SwapStatus returns the state of the swap unit of the swap device or file
+// what.
+func (m *manager) SwapStatus(parentCtx context.Context, what string) (UnitSwap, error) {
+	unit := SwapUnitName(what)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "SwapStatus")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("what", what))
+	defer span.End()
+
+	areas, err := readSwapAreas()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitSwap{}, err
+	}
+	swap, err := m.swapStatus(ctx, unit, areas)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitSwap{}, err
+	}
+	span.SetAttributes(otelattr.String("active_state", swap.ActiveState))
+	span.SetStatus(otelcodes.Ok, "retrieved swap status")
+
+	return swap, nil
+}
+
+// swapStatus returns the state of the named swap unit, sized from the active
+// swap areas.
+func (m *manager) swapStatus(ctx context.Context, unit string, areas map[string]swapArea) (UnitSwap, error) {
+	props, err := m.unitProperties(ctx, unit, "Swap")
+	if err != nil {
+		return UnitSwap{}, err
+	}
+	swap, err := decodeSwap(props)
+	if err != nil {
+		return UnitSwap{}, fmt.Errorf("failed to decode swap properties of unit %q: %w", unit, err)
+	}
+	swap.Unit = unit
+	swap.ActiveState, err = m.activeState(ctx, unit)
+	if err != nil {
+		return UnitSwap{}, fmt.Errorf("failed to retrieve state of unit %q: %w", unit, err)
+	}
+	if swap.ActiveState == "active" {
+		// The kernel lists devices by their canonical path.
+		area, ok := areas[swap.What]
+		if !ok {
+			if resolved, err := filepath.EvalSymlinks(swap.What); err == nil {
+				area = areas[resolved]
+			}
+		}
+		swap.Size, swap.Used = area.size, area.used
+	}
+
+	return swap, nil
+}
+
+// decodeSwap decodes the properties of a swap unit.
+func decodeSwap(props map[string]godbus.Variant) (UnitSwap, error) {
+	var (
+		swap UnitSwap
+		errs = make([]error, 4)
+	)
+	swap.What, errs[0] = decodeProperty[string](props["What"])
+	swap.Priority, errs[1] = decodeProperty[int32](props["Priority"])
+	swap.Options, errs[2] = decodeProperty[string](props["Options"])
+	swap.Result, errs[3] = decodeProperty[string](props["Result"])
+	for _, err := range errs {
+		if err != nil {
+			return UnitSwap{}, err
+		}
+	}
+
+	return swap, nil
+}
+
+// swapArea is the size and usage, in bytes, of an active swap area.
+type swapArea struct {
+	size, used uint64
+}
+
+// readSwapAreas returns the active swap areas, by device or file.
+func readSwapAreas() (map[string]swapArea, error) {
+	f, err := os.Open(procSwaps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap areas: %w", err)
+	}
+	defer f.Close()
+
+	areas, err := parseSwapAreas(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap areas: %w", err)
+	}
+
+	return areas, nil
+}
+
+// parseSwapAreas parses the format of /proc/swaps, which has a header line,
+// then a line per swap area with its file name, type, size and usage in KiB,
+// and priority.
+func parseSwapAreas(r io.Reader) (map[string]swapArea, error) {
+	areas := make(map[string]swapArea)
+	scanner := bufio.NewScanner(r)
+	for i := 0; scanner.Scan(); i++ {
+		fields := strings.Fields(scanner.Text())
+		if i == 0 || len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("unexpected swap area %q", scanner.Text())
+		}
+		size, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected size of swap area %q: %w", fields[0], err)
+		}
+		used, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected usage of swap area %q: %w", fields[0], err)
+		}
+		// Spaces in file names are escaped.
+		areas[strings.ReplaceAll(fields[0], `\040`, " ")] = swapArea{size: size * 1024, used: used * 1024}
+	}
+
+	return areas, scanner.Err()
+}
+
+// ActivateSwap synchronously starts swapping to the device or file what. Its
+// swap unit is started if loaded, e.g. from /etc/fstab, and a transient swap
+// unit is created otherwise.
+func (m *manager) ActivateSwap(parentCtx context.Context, what string, opts ...CallOption) error {
+	unit := SwapUnitName(what)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "ActivateSwap")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("what", what))
+	defer span.End()
+
+	err := m.activateSwap(ctx, unit, what, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully activated swap %q", what))
+
+	return nil
+}
+
+// activateSwap implements ActivateSwap.
+func (m *manager) activateSwap(ctx context.Context, unit, what string, opts []CallOption) error {
+	if !path.IsAbs(what) {
+		return fmt.Errorf("failed to activate swap %q: path must be absolute", what)
+	}
+	exists, err := m.Exists(ctx, unit)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return m.Start(ctx, unit, opts...)
+	}
+
+	o := newCallOptions(opts)
+	props := []dbus.Property{
+		dbus.PropDescription("Swap on " + path.Clean(what)),
+		{Name: "What", Value: godbus.MakeVariant(path.Clean(what))},
+	}
+
+	return m.runJob(ctx, OperationStart, unit, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartTransientUnitContext(ctx, unit, string(o.jobMode), props, resultChan)
+	})
+}
+
+// DeactivateSwap synchronously stops swapping to the device or file what,
+// by stopping its swap unit.
+func (m *manager) DeactivateSwap(parentCtx context.Context, what string, opts ...CallOption) error {
+	unit := SwapUnitName(what)
+
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "DeactivateSwap")
+	span.SetAttributes(otelattr.String("unit", unit), otelattr.String("what", what))
+	defer span.End()
+
+	if err := m.Stop(ctx, unit, opts...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully deactivated swap %q", what))
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package systemdmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_SwapUnitName(t *testing.T) {
+	require.Equal(t, "dev-sda2.swap", SwapUnitName("/dev/sda2"))
+	require.Equal(t, "swapfile.swap", SwapUnitName("/swapfile"))
+	require.Equal(t, `dev-disk-by\x2duuid-1234.swap`, SwapUnitName("/dev/disk/by-uuid/1234"))
+}
+
+func Test_Unit_parseSwapAreas(t *testing.T) {
+	areas, err := parseSwapAreas(strings.NewReader(`Filename				Type		Size		Used		Priority
+/dev/dm-1                               partition	8388604		1024		-2
+/var/swap\040file                       file		1048572		0		10
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]swapArea{
+		"/dev/dm-1":      {size: 8388604 * 1024, used: 1024 * 1024},
+		"/var/swap file": {size: 1048572 * 1024},
+	}, areas)
+
+	// No swap areas.
+	areas, err = parseSwapAreas(strings.NewReader("Filename\tType\tSize\tUsed\tPriority\n"))
+	require.NoError(t, err)
+	require.Empty(t, areas)
+
+	_, err = parseSwapAreas(strings.NewReader("Filename\tType\tSize\tUsed\tPriority\n/dev/dm-1 partition lots 0 -2\n"))
+	require.Error(t, err)
+	_, err = parseSwapAreas(strings.NewReader("Filename\tType\tSize\tUsed\tPriority\n/dev/dm-1 partition\n"))
+	require.Error(t, err)
+}
+
+func Test_Unit_decodeSwap(t *testing.T) {
+	swap, err := decodeSwap(map[string]godbus.Variant{
+		"What":     godbus.MakeVariant("/dev/sda2"),
+		"Priority": godbus.MakeVariant(int32(-2)),
+		"Options":  godbus.MakeVariant("discard"),
+		"Result":   godbus.MakeVariant("success"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, UnitSwap{What: "/dev/sda2", Priority: -2, Options: "discard", Result: "success"}, swap)
+
+	_, err = decodeSwap(map[string]godbus.Variant{"What": godbus.MakeVariant("/dev/sda2")})
+	require.Error(t, err)
+}
+
+func Test_Unit_ActivateSwap_Relative(t *testing.T) {
+	err := (&manager{}).ActivateSwap(context.Background(), "swapfile")
+	require.ErrorContains(t, err, "must be absolute")
+}
@@ -0,0 +1,151 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrRolledBack means a switchover was rolled back, leaving the old instance
+// running.
+var ErrRolledBack = errors.New("switchover rolled back")
+
+const (
+	// defaultHealthInterval is how often the health check of a switchover
+	// is run by default, until it passes.
+	defaultHealthInterval = time.Second
+	// rollbackTimeout bounds rolling back a switchover, which happens even
+	// if its context is done.
+	rollbackTimeout = time.Minute
+)
+
+// SwitchoverOptions configures Switchover.
+type SwitchoverOptions struct {
+	// HealthTimeout bounds how long the new instance may take to become
+	// active and pass the health check. Zero means no bound other than ctx.
+	HealthTimeout time.Duration
+	// HealthInterval is how often the health check is run until it passes.
+	// Defaults to one second.
+	HealthInterval time.Duration
+}
+
+// Switchover replaces a running instance of a templated unit with another,
+// e.g. "app@blue.service" with "app@green.service": it starts the new
+// instance, waits for it to be active and to pass health, then stops the old
+// instance. health is run repeatedly until it passes. If the new instance
+// fails to start or to become healthy, it is stopped, leaving the old
+// instance untouched, and the returned error wraps ErrRolledBack.
+func (m *manager) Switchover(parentCtx context.Context, oldInstance, newInstance string, health func(ctx context.Context) error, opts SwitchoverOptions) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Switchover")
+	span.SetAttributes(otelattr.String("old_unit", oldInstance), otelattr.String("new_unit", newInstance))
+	defer span.End()
+
+	err := m.switchover(ctx, oldInstance, newInstance, health, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully switched over from %q to %q", oldInstance, newInstance))
+
+	return nil
+}
+
+// switchover implements Switchover.
+func (m *manager) switchover(ctx context.Context, oldInstance, newInstance string, health func(ctx context.Context) error, opts SwitchoverOptions) error {
+	if err := sameTemplate(oldInstance, newInstance); err != nil {
+		return err
+	}
+
+	if err := m.startAndWaitHealthy(ctx, newInstance, health, opts); err != nil {
+		// Roll back even if ctx is done, so both instances don't keep
+		// running.
+		rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+		defer cancel()
+		if stopErr := m.Stop(rollbackCtx, newInstance); stopErr != nil {
+			return fmt.Errorf("failed to roll back switchover to %q: %w", newInstance, errors.Join(err, stopErr))
+		}
+
+		return fmt.Errorf("%w to %q: %w", ErrRolledBack, newInstance, err)
+	}
+
+	if err := m.Stop(ctx, oldInstance); err != nil {
+		return fmt.Errorf("switched over to %q, but failed to stop %q: %w", newInstance, oldInstance, err)
+	}
+
+	return nil
+}
+
+// startAndWaitHealthy starts a unit and waits for it to be active and to
+// pass health.
+func (m *manager) startAndWaitHealthy(ctx context.Context, unit string, health func(ctx context.Context) error, opts SwitchoverOptions) error {
+	if err := m.Start(ctx, unit); err != nil {
+		return err
+	}
+
+	if opts.HealthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.HealthTimeout)
+		defer cancel()
+	}
+	if err := m.waitActive(ctx, unit); err != nil {
+		return err
+	}
+	if err := waitHealthy(ctx, health, opts.HealthInterval); err != nil {
+		return fmt.Errorf("unit %q didn't pass health: %w", unit, err)
+	}
+
+	return nil
+}
+
+// waitHealthy runs health every interval until it passes. On timeout, the
+// last health error is returned along with the cause.
+func waitHealthy(ctx context.Context, health func(ctx context.Context) error, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := health(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, context.Cause(ctx))
+		case <-ticker.C:
+		}
+	}
+}
+
+// sameTemplate checks that two units are distinct instances of the same
+// template.
+func sameTemplate(a, b string) error {
+	for _, unit := range []string{a, b} {
+		if err := ValidateUnitName(unit); err != nil {
+			return err
+		}
+	}
+	prefixA, suffixA, okA := strings.Cut(a, "@")
+	prefixB, suffixB, okB := strings.Cut(b, "@")
+	if !okA || !okB || prefixA != prefixB ||
+		suffixA[strings.LastIndexByte(suffixA, '.'):] != suffixB[strings.LastIndexByte(suffixB, '.'):] {
+		return fmt.Errorf("%w: %q and %q aren't instances of the same template", ErrInvalidUnitName, a, b)
+	}
+	if a == b {
+		return fmt.Errorf("%w: can't switch over %q to itself", ErrInvalidUnitName, a)
+	}
+
+	return nil
+}
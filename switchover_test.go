@@ -0,0 +1,43 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_sameTemplate(t *testing.T) {
+	require.NoError(t, sameTemplate("app@blue.service", "app@green.service"))
+	require.ErrorIs(t, sameTemplate("app@blue.service", "app@blue.service"), ErrInvalidUnitName)
+	require.ErrorIs(t, sameTemplate("app@blue.service", "other@green.service"), ErrInvalidUnitName)
+	require.ErrorIs(t, sameTemplate("app@blue.service", "app@green.socket"), ErrInvalidUnitName)
+	require.ErrorIs(t, sameTemplate("app.service", "app@green.service"), ErrInvalidUnitName)
+	require.ErrorIs(t, sameTemplate("app@blue.service", "app@green"), ErrInvalidUnitName)
+}
+
+func Test_Unit_waitHealthy(t *testing.T) {
+	t.Run("Passes eventually", func(t *testing.T) {
+		var calls int
+		err := waitHealthy(t.Context(), func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("not ready")
+			}
+			return nil
+		}, time.Millisecond)
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Never passes", func(t *testing.T) {
+		notReady := errors.New("not ready")
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		err := waitHealthy(ctx, func(context.Context) error { return notReady }, time.Millisecond)
+		require.ErrorIs(t, err, notReady)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
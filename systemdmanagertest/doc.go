@@ -0,0 +1,4 @@
+// Package systemdmanagertest provides utilities for testing code built on
+// systemdmanager, such as injecting faults into a Manager, and recording
+// its calls to replay them without systemd.
+package systemdmanagertest
@@ -0,0 +1,243 @@
+package systemdmanagertest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+)
+
+// Fault is injected into the calls of a Manager method.
+type Fault struct {
+	// Method is the name of the Manager method to inject the fault into,
+	// e.g. "Start".
+	Method string
+	// Unit restricts the fault to calls on the named unit. Empty matches
+	// calls on any unit.
+	Unit string
+	// Latency delays matching calls. Calls fail with the context error if
+	// it is done first.
+	Latency time.Duration
+	// Err fails matching calls once delayed, e.g. with
+	// systemdmanager.ErrTimeout or systemdmanager.ErrDisconnected.
+	Err error
+	// Result fails matching calls of the methods running jobs, i.e. Start,
+	// Stop, Restart, StopWithDrain, and StartTransient, as if their job
+	// completed with this result, e.g. "failed", "timeout", or "dependency".
+	Result string
+	// Times limits the fault to the first matching calls. Zero means all
+	// calls.
+	Times int
+}
+
+// FaultyManager is a Manager injecting faults into the calls of Start, Stop,
+// Restart, StopWithDrain, StartTransient, Status, StatusAll, Uptime, Watch,
+// WaitAllActive, and Ping. Calls which aren't failed are delegated to the
+// wrapped Manager, as are calls of other methods.
+type FaultyManager struct {
+	systemdmanager.Manager
+
+	mutex  sync.Mutex
+	faults []*injectedFault
+}
+
+// injectedFault is a fault and how many times it was injected.
+type injectedFault struct {
+	Fault
+	injected int
+}
+
+// Assert FaultyManager fulfills the Manager interface.
+var _ systemdmanager.Manager = (*FaultyManager)(nil)
+
+// WithFaults returns a FaultyManager wrapping mgr, which may be a real or a
+// fake Manager, and injecting faults. When several faults match a call, they
+// are all injected in order: latencies add up, and the first error wins.
+func WithFaults(mgr systemdmanager.Manager, faults ...Fault) *FaultyManager {
+	f := &FaultyManager{Manager: mgr}
+	for _, fault := range faults {
+		f.Inject(fault)
+	}
+
+	return f
+}
+
+// Inject adds a fault to inject into later calls.
+func (f *FaultyManager) Inject(fault Fault) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults = append(f.faults, &injectedFault{Fault: fault})
+}
+
+// Reset removes all faults.
+func (f *FaultyManager) Reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults = nil
+}
+
+// inject injects the faults matching a call of method on units, and returns
+// the error to fail the call with, if any.
+func (f *FaultyManager) inject(ctx context.Context, method string, units ...string) error {
+	var (
+		latency time.Duration
+		err     error
+	)
+	f.mutex.Lock()
+	for _, fault := range f.faults {
+		if fault.Method != method || (fault.Unit != "" && !slices.Contains(units, fault.Unit)) {
+			continue
+		}
+		if fault.Times > 0 && fault.injected >= fault.Times {
+			continue
+		}
+		fault.injected++
+		latency += fault.Latency
+		if err == nil {
+			err = fault.err(method, units)
+		}
+	}
+	f.mutex.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
+
+// jobOperations are the operations of the methods running jobs.
+var jobOperations = map[string]systemdmanager.Operation{
+	"Start":          systemdmanager.OperationStart,
+	"StartTransient": systemdmanager.OperationStart,
+	"Stop":           systemdmanager.OperationStop,
+	"StopWithDrain":  systemdmanager.OperationStop,
+	"Restart":        systemdmanager.OperationRestart,
+}
+
+// err returns the error of a fault injected into a call of method on units.
+func (fault Fault) err(method string, units []string) error {
+	if fault.Err != nil {
+		return fault.Err
+	}
+	if op, ok := jobOperations[method]; ok && fault.Result != "" {
+		// Mirror how the manager reports failed jobs.
+		return &systemdmanager.JobError{Op: op, Unit: strings.Join(units, ", "), Result: fault.Result}
+	}
+
+	return nil
+}
+
+// Start injects faults, then starts the unit unless failed.
+func (f *FaultyManager) Start(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	if err := f.inject(ctx, "Start", unit); err != nil {
+		return err
+	}
+
+	return f.Manager.Start(ctx, unit, opts...)
+}
+
+// Stop injects faults, then stops the unit unless failed.
+func (f *FaultyManager) Stop(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	if err := f.inject(ctx, "Stop", unit); err != nil {
+		return err
+	}
+
+	return f.Manager.Stop(ctx, unit, opts...)
+}
+
+// Restart injects faults, then restarts the unit unless failed.
+func (f *FaultyManager) Restart(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	if err := f.inject(ctx, "Restart", unit); err != nil {
+		return err
+	}
+
+	return f.Manager.Restart(ctx, unit, opts...)
+}
+
+// StopWithDrain injects faults, then drains and stops the unit unless
+// failed.
+func (f *FaultyManager) StopWithDrain(ctx context.Context, unit string, drain systemdmanager.DrainFunc, opts ...systemdmanager.CallOption) error {
+	if err := f.inject(ctx, "StopWithDrain", unit); err != nil {
+		return err
+	}
+
+	return f.Manager.StopWithDrain(ctx, unit, drain, opts...)
+}
+
+// StartTransient injects faults, then starts the transient unit unless
+// failed.
+func (f *FaultyManager) StartTransient(ctx context.Context, spec systemdmanager.TransientSpec, opts ...systemdmanager.CallOption) error {
+	if err := f.inject(ctx, "StartTransient", spec.Name); err != nil {
+		return err
+	}
+
+	return f.Manager.StartTransient(ctx, spec, opts...)
+}
+
+// Status injects faults, then returns the status of the unit unless failed.
+func (f *FaultyManager) Status(ctx context.Context, unit string) (*dbus.UnitStatus, error) {
+	if err := f.inject(ctx, "Status", unit); err != nil {
+		return nil, err
+	}
+
+	return f.Manager.Status(ctx, unit)
+}
+
+// StatusAll injects faults, then returns the statuses of the units unless
+// failed.
+func (f *FaultyManager) StatusAll(ctx context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	if err := f.inject(ctx, "StatusAll", units...); err != nil {
+		return nil, err
+	}
+
+	return f.Manager.StatusAll(ctx, units)
+}
+
+// Uptime injects faults, then returns the uptime of the unit unless failed.
+func (f *FaultyManager) Uptime(ctx context.Context, unit string) (time.Duration, error) {
+	if err := f.inject(ctx, "Uptime", unit); err != nil {
+		return -1, err
+	}
+
+	return f.Manager.Uptime(ctx, unit)
+}
+
+// Watch injects faults, then watches the unit unless failed.
+func (f *FaultyManager) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, opts ...systemdmanager.WatchOption) error {
+	if err := f.inject(ctx, "Watch", unit); err != nil {
+		return err
+	}
+
+	return f.Manager.Watch(ctx, unit, updatesChan, opts...)
+}
+
+// WaitAllActive injects faults, then waits for the units unless failed.
+func (f *FaultyManager) WaitAllActive(ctx context.Context, units []string) error {
+	if err := f.inject(ctx, "WaitAllActive", units...); err != nil {
+		return err
+	}
+
+	return f.Manager.WaitAllActive(ctx, units)
+}
+
+// Ping injects faults, then pings systemd unless failed.
+func (f *FaultyManager) Ping(ctx context.Context) error {
+	if err := f.inject(ctx, "Ping"); err != nil {
+		return err
+	}
+
+	return f.Manager.Ping(ctx)
+}
@@ -0,0 +1,82 @@
+package systemdmanagertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeManager is a Manager which operations always succeed.
+type fakeManager struct {
+	systemdmanager.Manager
+	started []string
+}
+
+func (f *fakeManager) Start(_ context.Context, unit string, _ ...systemdmanager.CallOption) error {
+	f.started = append(f.started, unit)
+	return nil
+}
+
+func (f *fakeManager) Status(_ context.Context, unit string) (*dbus.UnitStatus, error) {
+	return &dbus.UnitStatus{Name: unit, ActiveState: "active"}, nil
+}
+
+func Test_Unit_WithFaults(t *testing.T) {
+	t.Run("Fails matching calls", func(t *testing.T) {
+		fake := &fakeManager{}
+		mgr := WithFaults(fake, Fault{Method: "Start", Unit: "a.service", Err: systemdmanager.ErrDisconnected})
+
+		require.ErrorIs(t, mgr.Start(t.Context(), "a.service"), systemdmanager.ErrDisconnected)
+		require.NoError(t, mgr.Start(t.Context(), "b.service"))
+		require.Equal(t, []string{"b.service"}, fake.started)
+
+		status, err := mgr.Status(t.Context(), "a.service")
+		require.NoError(t, err)
+		require.Equal(t, "active", status.ActiveState)
+	})
+
+	t.Run("Fails with job results", func(t *testing.T) {
+		mgr := WithFaults(&fakeManager{}, Fault{Method: "Start", Result: "dependency"})
+		err := mgr.Start(t.Context(), "a.service")
+		require.EqualError(t, err, `failed to start unit "a.service" with result "dependency"`)
+		require.ErrorIs(t, err, systemdmanager.ErrJobDependency)
+	})
+
+	t.Run("Fails a number of times", func(t *testing.T) {
+		mgr := WithFaults(&fakeManager{}, Fault{Method: "Status", Err: systemdmanager.ErrTimeout, Times: 2})
+		for range 2 {
+			_, err := mgr.Status(t.Context(), "a.service")
+			require.ErrorIs(t, err, systemdmanager.ErrTimeout)
+		}
+		_, err := mgr.Status(t.Context(), "a.service")
+		require.NoError(t, err)
+	})
+
+	t.Run("Delays calls", func(t *testing.T) {
+		mgr := WithFaults(&fakeManager{}, Fault{Method: "Start", Latency: 10 * time.Millisecond})
+		started := time.Now()
+		require.NoError(t, mgr.Start(t.Context(), "a.service"))
+		require.GreaterOrEqual(t, time.Since(started), 10*time.Millisecond)
+
+		// Delays are bound by ctx.
+		mgr.Reset()
+		mgr.Inject(Fault{Method: "Start", Latency: time.Hour})
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, mgr.Start(ctx, "a.service"), context.DeadlineExceeded)
+	})
+
+	t.Run("First error wins", func(t *testing.T) {
+		first := errors.New("first")
+		mgr := WithFaults(&fakeManager{},
+			Fault{Method: "Start", Err: first},
+			Fault{Method: "Start", Err: errors.New("second")},
+		)
+		require.ErrorIs(t, mgr.Start(t.Context(), "a.service"), first)
+	})
+}
@@ -0,0 +1,432 @@
+package systemdmanagertest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+)
+
+// ErrNotRecorded means a Replayer has no recording of a call.
+var ErrNotRecorded = errors.New("call not recorded")
+
+// Interaction is a recorded call of a Manager method.
+type Interaction struct {
+	Method string `json:"method"`
+	// Args are the arguments of the call, other than the context and
+	// options, which calls are matched on.
+	Args json.RawMessage `json:"args,omitempty"`
+	// Result is the value returned by the call, if any.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the message of the error returned by the call, if any.
+	Error string `json:"error,omitempty"`
+	// Sentinels name the well-known errors the returned error wraps, if
+	// any, e.g. "ErrTimeout" and "DeadlineExceeded".
+	Sentinels []string `json:"sentinels,omitempty"`
+	// Job is the job error the returned error wraps, if any.
+	Job *systemdmanager.JobError `json:"job,omitempty"`
+	// Events are the statuses sent by Watch.
+	Events []*dbus.UnitStatus `json:"events,omitempty"`
+}
+
+// sentinels are the well-known errors preserved by recordings, so replayed
+// errors match them with errors.Is, most specific first. They include every
+// exported error of the systemdmanager package.
+var sentinels = []struct {
+	name string
+	err  error
+}{
+	{"ErrOutsideMaintenanceWindow", systemdmanager.ErrOutsideMaintenanceWindow},
+	{"ErrCanaryFailed", systemdmanager.ErrCanaryFailed},
+	{"ErrRolledBack", systemdmanager.ErrRolledBack},
+	{"ErrJobCanceled", systemdmanager.ErrJobCanceled},
+	{"ErrJobTimeout", systemdmanager.ErrJobTimeout},
+	{"ErrJobFailed", systemdmanager.ErrJobFailed},
+	{"ErrJobDependency", systemdmanager.ErrJobDependency},
+	{"ErrJobSkipped", systemdmanager.ErrJobSkipped},
+	{"ErrFailedStart", systemdmanager.ErrFailedStart},
+	{"ErrUnitNotFound", systemdmanager.ErrUnitNotFound},
+	{"ErrUnitFailed", systemdmanager.ErrUnitFailed},
+	{"ErrNotReady", systemdmanager.ErrNotReady},
+	{"ErrUnitClaimed", systemdmanager.ErrUnitClaimed},
+	{"ErrUnitNotAllowed", systemdmanager.ErrUnitNotAllowed},
+	{"ErrInvalidUnitName", systemdmanager.ErrInvalidUnitName},
+	{"ErrWrongUnitType", systemdmanager.ErrWrongUnitType},
+	{"ErrInvalidJobMode", systemdmanager.ErrInvalidJobMode},
+	{"ErrUnsupportedSetting", systemdmanager.ErrUnsupportedSetting},
+	{"ErrUnsupported", systemdmanager.ErrUnsupported},
+	{"ErrDependencyCycle", systemdmanager.ErrDependencyCycle},
+	{"ErrNoControlGroup", systemdmanager.ErrNoControlGroup},
+	{"ErrNotDelegated", systemdmanager.ErrNotDelegated},
+	{"ErrPermissionDenied", systemdmanager.ErrPermissionDenied},
+	{"ErrRateLimited", systemdmanager.ErrRateLimited},
+	{"ErrTimeout", systemdmanager.ErrTimeout},
+	{"ErrDisconnected", systemdmanager.ErrDisconnected},
+	{"Canceled", context.Canceled},
+	{"DeadlineExceeded", context.DeadlineExceeded},
+}
+
+// sentinel returns the well-known error named name, if any.
+func sentinel(name string) error {
+	for _, s := range sentinels {
+		if s.name == name {
+			return s.err
+		}
+	}
+
+	return nil
+}
+
+// replayedError is a recorded error, wrapping its sentinels and job error.
+type replayedError struct {
+	message string
+	wrapped []error
+}
+
+func (e replayedError) Error() string {
+	return e.message
+}
+
+func (e replayedError) Unwrap() []error {
+	return e.wrapped
+}
+
+// Recorder is a Manager recording the calls of Start, Stop, Restart,
+// StartTransient, Status, StatusAll, Uptime, Watch, WaitAllActive, and Ping
+// to a Replayer, as JSON lines. Calls are delegated to the wrapped Manager,
+// as are calls of other methods, which aren't recorded.
+type Recorder struct {
+	systemdmanager.Manager
+
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+// Assert Recorder fulfills the Manager interface.
+var _ systemdmanager.Manager = (*Recorder)(nil)
+
+// Record returns a Recorder wrapping mgr, which is usually a real Manager,
+// and writing interactions to w.
+func Record(mgr systemdmanager.Manager, w io.Writer) *Recorder {
+	return &Recorder{Manager: mgr, encoder: json.NewEncoder(w)}
+}
+
+// Err returns the first error writing interactions, if any.
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+// write writes a recorded call.
+func (r *Recorder) write(i Interaction) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.encoder.Encode(i); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// newInteraction returns the recording of a call of method.
+func newInteraction(method string, args, result any, err error) Interaction {
+	i := Interaction{Method: method, Args: marshal(args)}
+	if err != nil {
+		i.Error = err.Error()
+		for _, s := range sentinels {
+			if errors.Is(err, s.err) {
+				i.Sentinels = append(i.Sentinels, s.name)
+			}
+		}
+		var jobErr *systemdmanager.JobError
+		if errors.As(err, &jobErr) {
+			i.Job = jobErr
+		}
+	} else if result != nil {
+		i.Result = marshal(result)
+	}
+
+	return i
+}
+
+// marshal encodes v, which holds plain values that can't fail to encode.
+func marshal(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, _ := json.Marshal(v)
+
+	return b
+}
+
+// record records a call of method returning a value.
+func record[T any](r *Recorder, method string, args any, call func() (T, error)) (T, error) {
+	v, err := call()
+	r.write(newInteraction(method, args, v, err))
+
+	return v, err
+}
+
+// recordErr records a call of method returning an error only.
+func recordErr(r *Recorder, method string, args any, call func() error) error {
+	err := call()
+	r.write(newInteraction(method, args, nil, err))
+
+	return err
+}
+
+// Start starts the unit and records the call.
+func (r *Recorder) Start(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	return recordErr(r, "Start", unit, func() error { return r.Manager.Start(ctx, unit, opts...) })
+}
+
+// Stop stops the unit and records the call.
+func (r *Recorder) Stop(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	return recordErr(r, "Stop", unit, func() error { return r.Manager.Stop(ctx, unit, opts...) })
+}
+
+// Restart restarts the unit and records the call.
+func (r *Recorder) Restart(ctx context.Context, unit string, opts ...systemdmanager.CallOption) error {
+	return recordErr(r, "Restart", unit, func() error { return r.Manager.Restart(ctx, unit, opts...) })
+}
+
+// StartTransient starts the transient unit and records the call.
+func (r *Recorder) StartTransient(ctx context.Context, spec systemdmanager.TransientSpec, opts ...systemdmanager.CallOption) error {
+	return recordErr(r, "StartTransient", spec, func() error { return r.Manager.StartTransient(ctx, spec, opts...) })
+}
+
+// Status returns the status of the unit and records the call.
+func (r *Recorder) Status(ctx context.Context, unit string) (*dbus.UnitStatus, error) {
+	return record(r, "Status", unit, func() (*dbus.UnitStatus, error) { return r.Manager.Status(ctx, unit) })
+}
+
+// StatusAll returns the statuses of the units and records the call.
+func (r *Recorder) StatusAll(ctx context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	return record(r, "StatusAll", units, func() (map[string]*dbus.UnitStatus, error) { return r.Manager.StatusAll(ctx, units) })
+}
+
+// Uptime returns the uptime of the unit and records the call.
+func (r *Recorder) Uptime(ctx context.Context, unit string) (time.Duration, error) {
+	return record(r, "Uptime", unit, func() (time.Duration, error) { return r.Manager.Uptime(ctx, unit) })
+}
+
+// WaitAllActive waits for the units and records the call.
+func (r *Recorder) WaitAllActive(ctx context.Context, units []string) error {
+	return recordErr(r, "WaitAllActive", units, func() error { return r.Manager.WaitAllActive(ctx, units) })
+}
+
+// Ping pings systemd and records the call.
+func (r *Recorder) Ping(ctx context.Context) error {
+	return recordErr(r, "Ping", nil, func() error { return r.Manager.Ping(ctx) })
+}
+
+// Watch watches the unit and records the call, along with the statuses sent
+// to updatesChan, once it returns.
+func (r *Recorder) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, opts ...systemdmanager.WatchOption) error {
+	var (
+		events    []*dbus.UnitStatus
+		forwarded = make(chan struct{})
+		statuses  = make(chan *dbus.UnitStatus)
+	)
+	go func() {
+		defer close(forwarded)
+		for status := range statuses {
+			events = append(events, status)
+			select {
+			case updatesChan <- status:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	err := r.Manager.Watch(ctx, unit, statuses, opts...)
+	close(statuses)
+	<-forwarded
+	i := newInteraction("Watch", unit, nil, err)
+	i.Events = events
+	r.write(i)
+
+	return err
+}
+
+// Replayer is a Manager serving the calls recorded by a Recorder, without
+// systemd. Calls are matched on their method and arguments, and served in
+// recording order, the last recording of a call being served repeatedly.
+// Calls which weren't recorded fail with ErrNotRecorded. Methods a Recorder
+// doesn't record aren't supported, and panic.
+type Replayer struct {
+	systemdmanager.Manager
+
+	mutex        sync.Mutex
+	interactions map[string][]Interaction
+}
+
+// Assert Replayer fulfills the Manager interface.
+var _ systemdmanager.Manager = (*Replayer)(nil)
+
+// NewReplayer returns a Replayer serving the interactions recorded to r.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	p := &Replayer{interactions: make(map[string][]Interaction)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("invalid recording at line %d: %w", line, err)
+		}
+		key := interactionKey(i.Method, i.Args)
+		p.interactions[key] = append(p.interactions[key], i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	return p, nil
+}
+
+// interactionKey identifies the calls of method with args.
+func interactionKey(method string, args json.RawMessage) string {
+	return method + " " + string(args)
+}
+
+// next returns the next recording of a call of method with args.
+func (p *Replayer) next(method string, args any) (Interaction, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := interactionKey(method, marshal(args))
+	recorded := p.interactions[key]
+	if len(recorded) == 0 {
+		return Interaction{}, fmt.Errorf("%w: %s(%s)", ErrNotRecorded, method, marshal(args))
+	}
+	i := recorded[0]
+	if len(recorded) > 1 {
+		p.interactions[key] = recorded[1:]
+	}
+
+	return i, nil
+}
+
+// err returns the recorded error, if any.
+func (i Interaction) err() error {
+	if i.Error == "" {
+		return nil
+	}
+
+	replayed := replayedError{message: i.Error}
+	for _, name := range i.Sentinels {
+		if err := sentinel(name); err != nil {
+			replayed.wrapped = append(replayed.wrapped, err)
+		}
+	}
+	if i.Job != nil {
+		replayed.wrapped = append(replayed.wrapped, i.Job)
+	}
+
+	return replayed
+}
+
+// replay serves a call of method returning a value.
+func replay[T any](p *Replayer, method string, args any) (T, error) {
+	var v T
+	i, err := p.next(method, args)
+	if err != nil {
+		return v, err
+	}
+	if err := i.err(); err != nil {
+		return v, err
+	}
+	if i.Result != nil {
+		if err := json.Unmarshal(i.Result, &v); err != nil {
+			return v, fmt.Errorf("invalid recorded result of %s: %w", method, err)
+		}
+	}
+
+	return v, nil
+}
+
+// replayErr serves a call of method returning an error only.
+func replayErr(p *Replayer, method string, args any) error {
+	i, err := p.next(method, args)
+	if err != nil {
+		return err
+	}
+
+	return i.err()
+}
+
+// Start serves a recorded call.
+func (p *Replayer) Start(_ context.Context, unit string, _ ...systemdmanager.CallOption) error {
+	return replayErr(p, "Start", unit)
+}
+
+// Stop serves a recorded call.
+func (p *Replayer) Stop(_ context.Context, unit string, _ ...systemdmanager.CallOption) error {
+	return replayErr(p, "Stop", unit)
+}
+
+// Restart serves a recorded call.
+func (p *Replayer) Restart(_ context.Context, unit string, _ ...systemdmanager.CallOption) error {
+	return replayErr(p, "Restart", unit)
+}
+
+// StartTransient serves a recorded call.
+func (p *Replayer) StartTransient(_ context.Context, spec systemdmanager.TransientSpec, _ ...systemdmanager.CallOption) error {
+	return replayErr(p, "StartTransient", spec)
+}
+
+// Status serves a recorded call.
+func (p *Replayer) Status(_ context.Context, unit string) (*dbus.UnitStatus, error) {
+	return replay[*dbus.UnitStatus](p, "Status", unit)
+}
+
+// StatusAll serves a recorded call.
+func (p *Replayer) StatusAll(_ context.Context, units []string) (map[string]*dbus.UnitStatus, error) {
+	return replay[map[string]*dbus.UnitStatus](p, "StatusAll", units)
+}
+
+// Uptime serves a recorded call.
+func (p *Replayer) Uptime(_ context.Context, unit string) (time.Duration, error) {
+	return replay[time.Duration](p, "Uptime", unit)
+}
+
+// WaitAllActive serves a recorded call.
+func (p *Replayer) WaitAllActive(_ context.Context, units []string) error {
+	return replayErr(p, "WaitAllActive", units)
+}
+
+// Ping serves a recorded call.
+func (p *Replayer) Ping(context.Context) error {
+	return replayErr(p, "Ping", nil)
+}
+
+// Watch serves a recorded call, sending the recorded statuses to
+// updatesChan. A watch which was cancelled blocks until ctx is done too.
+func (p *Replayer) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, _ ...systemdmanager.WatchOption) error {
+	i, err := p.next("Watch", unit)
+	if err != nil {
+		return err
+	}
+	for _, status := range i.Events {
+		select {
+		case updatesChan <- status:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := i.err(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	<-ctx.Done()
+
+	return ctx.Err()
+}
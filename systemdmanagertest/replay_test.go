@@ -0,0 +1,157 @@
+package systemdmanagertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pires/go-systemdmanager"
+	"github.com/stretchr/testify/require"
+)
+
+// watchingManager is a fakeManager which watches report a fixed sequence of
+// statuses.
+type watchingManager struct {
+	fakeManager
+}
+
+func (m *watchingManager) Watch(ctx context.Context, unit string, updatesChan chan<- *dbus.UnitStatus, _ ...systemdmanager.WatchOption) error {
+	for _, state := range []string{"activating", "active"} {
+		updatesChan <- &dbus.UnitStatus{Name: unit, ActiveState: state}
+	}
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func Test_Unit_RecordReplay(t *testing.T) {
+	var recording bytes.Buffer
+	fake := &watchingManager{}
+	faulty := WithFaults(fake, Fault{Method: "Start", Unit: "b.service", Err: fmt.Errorf("failed to start unit: %w", systemdmanager.ErrTimeout)})
+	rec := Record(faulty, &recording)
+
+	// Record.
+	require.NoError(t, rec.Start(t.Context(), "a.service"))
+	require.ErrorIs(t, rec.Start(t.Context(), "b.service"), systemdmanager.ErrTimeout)
+	status, err := rec.Status(t.Context(), "a.service")
+	require.NoError(t, err)
+	watch := func(mgr systemdmanager.Manager) ([]*dbus.UnitStatus, error) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		updatesChan := make(chan *dbus.UnitStatus)
+		watchDone := make(chan error, 1)
+		go func() {
+			watchDone <- mgr.Watch(ctx, "a.service", updatesChan)
+		}()
+		events := []*dbus.UnitStatus{<-updatesChan, <-updatesChan}
+		cancel()
+
+		return events, <-watchDone
+	}
+	recordedEvents, err := watch(rec)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, rec.Err())
+
+	// Replay.
+	replayer, err := NewReplayer(&recording)
+	require.NoError(t, err)
+	require.NoError(t, replayer.Start(t.Context(), "a.service"))
+	err = replayer.Start(t.Context(), "b.service")
+	require.ErrorIs(t, err, systemdmanager.ErrTimeout)
+	require.EqualError(t, err, "failed to start unit: unit operation timed out")
+	replayedStatus, err := replayer.Status(t.Context(), "a.service")
+	require.NoError(t, err)
+	require.Equal(t, status, replayedStatus)
+	replayedEvents, err := watch(replayer)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, recordedEvents, replayedEvents)
+
+	// The last recording is served repeatedly.
+	require.NoError(t, replayer.Start(t.Context(), "a.service"))
+
+	// Calls which weren't recorded fail.
+	_, err = replayer.Uptime(t.Context(), "a.service")
+	require.ErrorIs(t, err, ErrNotRecorded)
+	require.ErrorIs(t, replayer.Stop(t.Context(), "a.service"), ErrNotRecorded)
+}
+
+func Test_Unit_NewReplayer(t *testing.T) {
+	_, err := NewReplayer(bytes.NewBufferString(`{"method":"Start"}` + "\n" + `{"method":`))
+	require.ErrorContains(t, err, "line 2")
+
+	replayer, err := NewReplayer(bytes.NewBufferString(`{"method":"Uptime","args":"a.service","result":1000000000}`))
+	require.NoError(t, err)
+	uptime, err := replayer.Uptime(t.Context(), "a.service")
+	require.NoError(t, err)
+	require.Equal(t, time.Second, uptime)
+}
+
+func Test_Unit_RecordReplay_Errors(t *testing.T) {
+	var recording bytes.Buffer
+	timeout := fmt.Errorf("failed to start unit: %w after 1s: %w", systemdmanager.ErrTimeout, context.DeadlineExceeded)
+	faulty := WithFaults(&fakeManager{},
+		Fault{Method: "Start", Unit: "a.service", Err: timeout},
+		Fault{Method: "Start", Unit: "b.service", Result: "dependency"},
+	)
+	rec := Record(faulty, &recording)
+	require.Error(t, rec.Start(t.Context(), "a.service"))
+	require.Error(t, rec.Start(t.Context(), "b.service"))
+	require.NoError(t, rec.Err())
+
+	replayer, err := NewReplayer(&recording)
+	require.NoError(t, err)
+
+	// Every sentinel a timeout wraps is preserved.
+	err = replayer.Start(t.Context(), "a.service")
+	require.ErrorIs(t, err, systemdmanager.ErrTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, timeout.Error())
+
+	// Job errors keep their type and fields.
+	err = replayer.Start(t.Context(), "b.service")
+	require.ErrorIs(t, err, systemdmanager.ErrJobDependency)
+	var jobErr *systemdmanager.JobError
+	require.ErrorAs(t, err, &jobErr)
+	require.Equal(t, &systemdmanager.JobError{Op: systemdmanager.OperationStart, Unit: "b.service", Result: "dependency"}, jobErr)
+}
+
+func Test_Unit_sentinels(t *testing.T) {
+	// Every exported error of the systemdmanager package is preserved.
+	files, err := filepath.Glob("../*.go")
+	require.NoError(t, err)
+	var want []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		require.NoError(t, err)
+		for _, decl := range f.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+				for _, spec := range decl.Specs {
+					for _, name := range spec.(*ast.ValueSpec).Names {
+						if name.IsExported() && strings.HasPrefix(name.Name, "Err") {
+							want = append(want, name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, want)
+	for _, name := range want {
+		require.NotNil(t, sentinel(name), name)
+	}
+
+	// Names match errors.
+	require.ErrorIs(t, sentinel("ErrUnitClaimed"), systemdmanager.ErrUnitClaimed)
+	require.ErrorIs(t, sentinel("DeadlineExceeded"), context.DeadlineExceeded)
+}
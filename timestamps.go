@@ -0,0 +1,88 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// UnitTimestamps are the times of a unit's latest state transitions. Zero
+// times mean the transition didn't happen since systemd started.
+type UnitTimestamps struct {
+	StateChange   time.Time `json:"state_change" yaml:"state_change"`
+	ActiveEnter   time.Time `json:"active_enter" yaml:"active_enter"`
+	ActiveExit    time.Time `json:"active_exit" yaml:"active_exit"`
+	InactiveEnter time.Time `json:"inactive_enter" yaml:"inactive_enter"`
+	InactiveExit  time.Time `json:"inactive_exit" yaml:"inactive_exit"`
+	// ExecMainStart and ExecMainExit are only set for services.
+	ExecMainStart time.Time `json:"exec_main_start" yaml:"exec_main_start"`
+	ExecMainExit  time.Time `json:"exec_main_exit" yaml:"exec_main_exit"`
+}
+
+// Timestamps returns the times of the named unit's latest state
+// transitions, retrieved at once.
+func (m *manager) Timestamps(parentCtx context.Context, unit string) (UnitTimestamps, error) {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "Timestamps")
+	span.SetAttributes(otelattr.String("unit", unit))
+	defer span.End()
+
+	ts, err := m.timestamps(ctx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return UnitTimestamps{}, err
+	}
+	span.SetStatus(otelcodes.Ok, "retrieved unit timestamps")
+
+	return ts, nil
+}
+
+// timestamps implements Timestamps.
+func (m *manager) timestamps(ctx context.Context, unit string) (UnitTimestamps, error) {
+	if err := m.validateUnit(unit); err != nil {
+		return UnitTimestamps{}, err
+	}
+	// Ensure connection to D-Bus API.
+	if !m.dbusConn.Load().Connected() {
+		return UnitTimestamps{}, ErrDisconnected
+	}
+
+	var props, serviceProps map[string]any
+	err := m.options.retryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		props, err = m.dbusConn.Load().GetUnitPropertiesContext(ctx, unit)
+		if err != nil || !strings.HasSuffix(unit, ".service") {
+			return err
+		}
+		serviceProps, err = m.dbusConn.Load().GetUnitTypePropertiesContext(ctx, unit, "Service")
+
+		return err
+	})
+	if err != nil {
+		return UnitTimestamps{}, fmt.Errorf("failed to retrieve timestamps of unit %q: %w", unit, err)
+	}
+
+	return UnitTimestamps{
+		StateChange:   timestampProperty(props, "StateChangeTimestamp"),
+		ActiveEnter:   timestampProperty(props, "ActiveEnterTimestamp"),
+		ActiveExit:    timestampProperty(props, "ActiveExitTimestamp"),
+		InactiveEnter: timestampProperty(props, "InactiveEnterTimestamp"),
+		InactiveExit:  timestampProperty(props, "InactiveExitTimestamp"),
+		ExecMainStart: timestampProperty(serviceProps, "ExecMainStartTimestamp"),
+		ExecMainExit:  timestampProperty(serviceProps, "ExecMainExitTimestamp"),
+	}, nil
+}
+
+// timestampProperty returns a timestamp property from a set of properties,
+// or the zero time if it's missing.
+func timestampProperty(props map[string]any, key string) time.Time {
+	usec, _ := props[key].(uint64)
+
+	return usecToTime(usec)
+}
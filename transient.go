@@ -0,0 +1,319 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"go.opentelemetry.io/otel"
+	otelattr "go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+// ErrUnsupportedSetting means a setting can't be applied to a transient
+// unit.
+var ErrUnsupportedSetting = errors.New("setting not supported for transient units")
+
+// TransientSpec describes a transient service, which only exists until it
+// stops.
+type TransientSpec struct {
+	// Name is the unit name, e.g. "job-42.service".
+	Name        string
+	Description string
+	// Command is the command line of the service, starting with the absolute
+	// path of the executable.
+	Command []string
+	// Settings are additional [Service] settings, in unit file syntax, e.g.
+	// {Key: "Type", Value: "oneshot"}. Keys may be repeated. Only the
+	// settings listed in transientSettings are supported.
+	Settings []unitfile.Entry
+}
+
+// ApplyProfile adds the settings of p, replacing any previous assignments of
+// the same keys.
+func (s *TransientSpec) ApplyProfile(p unitfile.Profile) {
+	s.Settings = slices.DeleteFunc(s.Settings, func(e unitfile.Entry) bool {
+		return slices.ContainsFunc(p.Settings, func(pe unitfile.Entry) bool { return pe.Key == e.Key })
+	})
+	s.Settings = append(s.Settings, p.Settings...)
+}
+
+// StartTransient creates and starts a transient service.
+func (m *manager) StartTransient(parentCtx context.Context, spec TransientSpec, opts ...CallOption) error {
+	// Set-up tracing context.
+	ctx, span := otel.Tracer(name).Start(parentCtx, "StartTransient")
+	span.SetAttributes(otelattr.String("unit", spec.Name))
+	defer span.End()
+
+	o := newCallOptions(opts)
+	span.SetAttributes(otelattr.String("job_mode", string(o.jobMode)))
+	props, err := transientProperties(spec)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	err = m.runJob(ctx, OperationStart, spec.Name, o, func(ctx context.Context, resultChan chan<- string) (int, error) {
+		return m.dbusConn.Load().StartTransientUnitContext(ctx, spec.Name, string(o.jobMode), props, resultChan)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+
+		return err
+	}
+	span.SetStatus(otelcodes.Ok, fmt.Sprintf("successfully started transient unit %q", spec.Name))
+
+	return nil
+}
+
+// transientProperties converts a spec into the D-Bus properties of a
+// transient unit.
+func transientProperties(spec TransientSpec) ([]dbus.Property, error) {
+	if !strings.HasSuffix(spec.Name, ".service") {
+		return nil, fmt.Errorf("%w %q: transient units must be services", ErrInvalidUnitName, spec.Name)
+	}
+	if len(spec.Command) == 0 || !path.IsAbs(spec.Command[0]) {
+		return nil, fmt.Errorf("transient unit %q needs a command with an absolute path", spec.Name)
+	}
+
+	props := []dbus.Property{dbus.PropExecStart(spec.Command, false)}
+	if spec.Description != "" {
+		props = append(props, dbus.PropDescription(spec.Description))
+	}
+	for _, e := range spec.Settings {
+		p, err := transientProperty(e.Key, e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid setting %s=%s of transient unit %q: %w", e.Key, e.Value, spec.Name, err)
+		}
+		props = append(props, p)
+	}
+
+	return props, nil
+}
+
+// settingKind is how a unit file setting is encoded as a D-Bus property.
+type settingKind int
+
+const (
+	settingBool settingKind = iota
+	settingString
+	settingStrings
+	settingDirectories
+	settingFilter
+	settingTimespan
+	settingMode
+	settingCapabilities
+	settingNamespaces
+)
+
+// transientSettings are the settings supported by transient units, and how
+// they are encoded.
+var transientSettings = map[string]settingKind{
+	"Type":                       settingString,
+	"User":                       settingString,
+	"Group":                      settingString,
+	"WorkingDirectory":           settingString,
+	"Restart":                    settingString,
+	"KillMode":                   settingString,
+	"StandardInput":              settingString,
+	"StandardOutput":             settingString,
+	"StandardError":              settingString,
+	"ProtectSystem":              settingString,
+	"ProtectHome":                settingString,
+	"Slice":                      settingString,
+	"RemainAfterExit":            settingBool,
+	"DynamicUser":                settingBool,
+	"PrivateUsers":               settingBool,
+	"PrivateMounts":              settingBool,
+	"RemoveIPC":                  settingBool,
+	"NoNewPrivileges":            settingBool,
+	"PrivateTmp":                 settingBool,
+	"PrivateDevices":             settingBool,
+	"PrivateNetwork":             settingBool,
+	"ProtectKernelTunables":      settingBool,
+	"ProtectKernelModules":       settingBool,
+	"ProtectKernelLogs":          settingBool,
+	"ProtectControlGroups":       settingBool,
+	"ProtectClock":               settingBool,
+	"ProtectHostname":            settingBool,
+	"RestrictRealtime":           settingBool,
+	"RestrictSUIDSGID":           settingBool,
+	"LockPersonality":            settingBool,
+	"MemoryDenyWriteExecute":     settingBool,
+	"Environment":                settingStrings,
+	"SystemCallArchitectures":    settingStrings,
+	"ReadWritePaths":             settingStrings,
+	"ReadOnlyPaths":              settingStrings,
+	"InaccessiblePaths":          settingStrings,
+	"SupplementaryGroups":        settingStrings,
+	"StateDirectory":             settingDirectories,
+	"RuntimeDirectory":           settingDirectories,
+	"CacheDirectory":             settingDirectories,
+	"LogsDirectory":              settingDirectories,
+	"ConfigurationDirectory":     settingDirectories,
+	"SystemCallFilter":           settingFilter,
+	"RestrictAddressFamilies":    settingFilter,
+	"RuntimeMaxSec":              settingTimespan,
+	"TimeoutStartSec":            settingTimespan,
+	"TimeoutStopSec":             settingTimespan,
+	"RestartSec":                 settingTimespan,
+	"UMask":                      settingMode,
+	"StateDirectoryMode":         settingMode,
+	"RuntimeDirectoryMode":       settingMode,
+	"CacheDirectoryMode":         settingMode,
+	"LogsDirectoryMode":          settingMode,
+	"ConfigurationDirectoryMode": settingMode,
+	"CapabilityBoundingSet":      settingCapabilities,
+	"AmbientCapabilities":        settingCapabilities,
+	"RestrictNamespaces":         settingNamespaces,
+}
+
+// transientProperty converts a unit file setting into a D-Bus property.
+func transientProperty(key, value string) (dbus.Property, error) {
+	kind, ok := transientSettings[key]
+	if !ok {
+		return dbus.Property{}, ErrUnsupportedSetting
+	}
+
+	var v any
+	switch kind {
+	case settingBool:
+		b, err := unitfile.ParseBool(value)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		v = b
+	case settingString:
+		v = value
+	case settingStrings:
+		words, err := unitfile.SplitWords(value)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		v = words
+	case settingDirectories:
+		// Directories are relative to /var/lib, /run, etc., and owned by the
+		// service user, which may be dynamic.
+		dirs, err := unitfile.SplitWords(value)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		for _, d := range dirs {
+			if !fs.ValidPath(d) || d == "." {
+				return dbus.Property{}, fmt.Errorf("invalid directory %q: must be a relative path without \".\" or \"..\" elements", d)
+			}
+		}
+		v = dirs
+	case settingFilter:
+		// A leading "~" turns an allow list into a deny list.
+		allow := !strings.HasPrefix(value, "~")
+		v = struct {
+			Allow bool
+			List  []string
+		}{allow, strings.Fields(strings.TrimPrefix(value, "~"))}
+	case settingTimespan:
+		d, err := unitfile.ParseTimespan(value)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		key = strings.TrimSuffix(key, "Sec") + "USec"
+		v = uint64(d.Microseconds())
+	case settingMode:
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		v = uint32(mode)
+	case settingCapabilities:
+		caps, err := parseCapabilities(value)
+		if err != nil {
+			return dbus.Property{}, err
+		}
+		v = caps
+	case settingNamespaces:
+		restrict, err := unitfile.ParseBool(value)
+		if err != nil {
+			return dbus.Property{}, fmt.Errorf("only booleans are supported: %w", err)
+		}
+		// The property holds the namespaces that are allowed.
+		v = uint64(0)
+		if !restrict {
+			v = uint64(allNamespaces)
+		}
+	}
+
+	return dbus.Property{Name: key, Value: godbus.MakeVariant(v)}, nil
+}
+
+// allNamespaces are the clone flags of all namespace types.
+const allNamespaces = 0x00000080 | // CLONE_NEWTIME
+	0x00020000 | // CLONE_NEWNS
+	0x02000000 | // CLONE_NEWCGROUP
+	0x04000000 | // CLONE_NEWUTS
+	0x08000000 | // CLONE_NEWIPC
+	0x10000000 | // CLONE_NEWUSER
+	0x20000000 | // CLONE_NEWPID
+	0x40000000 // CLONE_NEWNET
+
+// capabilities are the Linux capabilities, indexed by number.
+var capabilities = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER",
+	"CAP_FSETID", "CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_IPC_LOCK", "CAP_IPC_OWNER",
+	"CAP_SYS_MODULE", "CAP_SYS_RAWIO", "CAP_SYS_CHROOT", "CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE", "CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_MKNOD",
+	"CAP_LEASE", "CAP_AUDIT_WRITE", "CAP_AUDIT_CONTROL", "CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE", "CAP_MAC_ADMIN", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND", "CAP_AUDIT_READ", "CAP_PERFMON", "CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// parseCapabilities converts a list of capability names into a mask. A
+// leading "~" inverts the list, and an empty list means no capabilities.
+func parseCapabilities(value string) (uint64, error) {
+	invert := strings.HasPrefix(value, "~")
+	var mask uint64
+	for _, name := range strings.Fields(strings.TrimPrefix(value, "~")) {
+		i := slices.Index(capabilities, strings.ToUpper(name))
+		if i < 0 {
+			return 0, fmt.Errorf("unknown capability %q", name)
+		}
+		mask |= 1 << i
+	}
+	if invert {
+		mask = ^mask & (1<<len(capabilities) - 1)
+	}
+
+	return mask, nil
+}
+
+// formatCapabilities converts a mask into a list of capability names,
+// reversing parseCapabilities. Capabilities unknown by name are formatted
+// by number.
+func formatCapabilities(mask uint64) []string {
+	names := []string{}
+	for mask != 0 {
+		i := bits.TrailingZeros64(mask)
+		mask &^= 1 << i
+		if i < len(capabilities) {
+			names = append(names, capabilities[i])
+		} else {
+			names = append(names, fmt.Sprintf("CAP_%d", i))
+		}
+	}
+
+	return names
+}
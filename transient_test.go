@@ -0,0 +1,138 @@
+package systemdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/pires/go-systemdmanager/unitfile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_transientProperties(t *testing.T) {
+	spec := TransientSpec{
+		Name:        "job.service",
+		Description: "A job",
+		Command:     []string{"/bin/echo", "hello"},
+		Settings: []unitfile.Entry{
+			{Key: "Type", Value: "oneshot"},
+			{Key: "RuntimeMaxSec", Value: "1min"},
+			{Key: "Environment", Value: `FOO=bar "BAZ=qux quux"`},
+		},
+	}
+	props, err := transientProperties(spec)
+	require.NoError(t, err)
+	require.Equal(t, []dbus.Property{
+		dbus.PropExecStart([]string{"/bin/echo", "hello"}, false),
+		dbus.PropDescription("A job"),
+		{Name: "Type", Value: godbus.MakeVariant("oneshot")},
+		{Name: "RuntimeMaxUSec", Value: godbus.MakeVariant(uint64(time.Minute.Microseconds()))},
+		{Name: "Environment", Value: godbus.MakeVariant([]string{"FOO=bar", "BAZ=qux quux"})},
+	}, props)
+
+	invalid := []TransientSpec{
+		{Name: "job.timer", Command: []string{"/bin/true"}},
+		{Name: "job.service"},
+		{Name: "job.service", Command: []string{"true"}},
+		{Name: "job.service", Command: []string{"/bin/true"}, Settings: []unitfile.Entry{{Key: "Unknown", Value: "x"}}},
+		{Name: "job.service", Command: []string{"/bin/true"}, Settings: []unitfile.Entry{{Key: "PrivateTmp", Value: "maybe"}}},
+	}
+	for _, spec := range invalid {
+		_, err := transientProperties(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func Test_Unit_TransientSpec_ApplyProfile(t *testing.T) {
+	spec := TransientSpec{
+		Name:     "job.service",
+		Command:  []string{"/bin/true"},
+		Settings: []unitfile.Entry{{Key: "Type", Value: "oneshot"}, {Key: "ProtectSystem", Value: "full"}},
+	}
+	spec.ApplyProfile(unitfile.ProfileStrict)
+	require.Equal(t, unitfile.Entry{Key: "Type", Value: "oneshot"}, spec.Settings[0])
+	require.Equal(t, append([]unitfile.Entry{{Key: "Type", Value: "oneshot"}}, unitfile.ProfileStrict.Settings...), spec.Settings)
+
+	// All built-in profiles apply to transient units.
+	for _, p := range []unitfile.Profile{unitfile.ProfileStrict, unitfile.ProfileNetworkService} {
+		spec := TransientSpec{Name: "job.service", Command: []string{"/bin/true"}}
+		spec.ApplyProfile(p)
+		_, err := transientProperties(spec)
+		require.NoError(t, err, p.Name)
+	}
+}
+
+func Test_Unit_transientProperty(t *testing.T) {
+	p, err := transientProperty("SystemCallFilter", "~@privileged @resources")
+	require.NoError(t, err)
+	require.Equal(t, godbus.MakeVariant(struct {
+		Allow bool
+		List  []string
+	}{false, []string{"@privileged", "@resources"}}), p.Value)
+
+	p, err = transientProperty("CapabilityBoundingSet", "CAP_NET_BIND_SERVICE cap_chown")
+	require.NoError(t, err)
+	require.Equal(t, godbus.MakeVariant(uint64(1<<10|1)), p.Value)
+
+	p, err = transientProperty("CapabilityBoundingSet", "")
+	require.NoError(t, err)
+	require.Equal(t, godbus.MakeVariant(uint64(0)), p.Value)
+
+	p, err = transientProperty("RestrictNamespaces", "yes")
+	require.NoError(t, err)
+	require.Equal(t, godbus.MakeVariant(uint64(0)), p.Value)
+
+	p, err = transientProperty("UMask", "0027")
+	require.NoError(t, err)
+	require.Equal(t, godbus.MakeVariant(uint32(0o027)), p.Value)
+
+	_, err = transientProperty("CapabilityBoundingSet", "CAP_UNKNOWN")
+	require.Error(t, err)
+	_, err = transientProperty("ExecStartPre", "/bin/true")
+	require.ErrorIs(t, err, ErrUnsupportedSetting)
+}
+
+func Test_Unit_parseCapabilities(t *testing.T) {
+	all, err := parseCapabilities("~")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<41-1), all)
+
+	allButAdmin, err := parseCapabilities("~CAP_SYS_ADMIN")
+	require.NoError(t, err)
+	require.Equal(t, all&^(1<<21), allButAdmin)
+}
+
+func Test_Unit_formatCapabilities(t *testing.T) {
+	require.Empty(t, formatCapabilities(0))
+	require.Equal(t, []string{"CAP_CHOWN", "CAP_NET_BIND_SERVICE", "CAP_63"}, formatCapabilities(1|1<<10|1<<63))
+
+	mask, err := parseCapabilities("CAP_NET_ADMIN CAP_BPF")
+	require.NoError(t, err)
+	require.Equal(t, []string{"CAP_NET_ADMIN", "CAP_BPF"}, formatCapabilities(mask))
+}
+
+func Test_Unit_transientProperty_DynamicUser(t *testing.T) {
+	spec := TransientSpec{
+		Name:    "job.service",
+		Command: []string{"/bin/true"},
+		Settings: []unitfile.Entry{
+			{Key: "DynamicUser", Value: "yes"},
+			{Key: "StateDirectory", Value: "jobs/42 jobs-cache"},
+			{Key: "StateDirectoryMode", Value: "0700"},
+		},
+	}
+	props, err := transientProperties(spec)
+	require.NoError(t, err)
+	require.Equal(t, []dbus.Property{
+		dbus.PropExecStart([]string{"/bin/true"}, false),
+		{Name: "DynamicUser", Value: godbus.MakeVariant(true)},
+		{Name: "StateDirectory", Value: godbus.MakeVariant([]string{"jobs/42", "jobs-cache"})},
+		{Name: "StateDirectoryMode", Value: godbus.MakeVariant(uint32(0o700))},
+	}, props)
+
+	for _, dir := range []string{"/var/lib/jobs", "../jobs", "jobs/../other", "."} {
+		_, err := transientProperty("StateDirectory", dir)
+		require.Error(t, err, dir)
+	}
+}
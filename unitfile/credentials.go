@@ -0,0 +1,56 @@
+package unitfile
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// validateCredentialID checks a credential name, which becomes a file name
+// in the credentials directory of the service.
+func validateCredentialID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, "/:") {
+		return fmt.Errorf("invalid credential name %q", id)
+	}
+
+	return nil
+}
+
+// LoadCredential makes the service load the credential id from path, which
+// may be a file, a directory of credentials, or an AF_UNIX socket. The
+// service reads it from $CREDENTIALS_DIRECTORY/id.
+func (f *File) LoadCredential(id, path string) error {
+	if err := validateCredentialID(id); err != nil {
+		return err
+	}
+	f.Add("Service", "LoadCredential", id+":"+path)
+
+	return nil
+}
+
+// LoadCredentialEncrypted is like LoadCredential for a credential encrypted
+// with systemd-creds, which systemd decrypts for the service.
+func (f *File) LoadCredentialEncrypted(id, path string) error {
+	if err := validateCredentialID(id); err != nil {
+		return err
+	}
+	f.Add("Service", "LoadCredentialEncrypted", id+":"+path)
+
+	return nil
+}
+
+// SetCredentialEncrypted embeds the credential id, encrypted with
+// systemd-creds, in the unit, which systemd decrypts for the service.
+// encrypted is the output of systemd-creds encrypt, which is Base64.
+func (f *File) SetCredentialEncrypted(id string, encrypted []byte) error {
+	if err := validateCredentialID(id); err != nil {
+		return err
+	}
+	value := strings.Join(strings.Fields(string(encrypted)), "")
+	if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+		return fmt.Errorf("invalid encrypted credential %q: %w", id, err)
+	}
+	f.Add("Service", "SetCredentialEncrypted", id+":"+value)
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package unitfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_File_Credentials(t *testing.T) {
+	f := &File{}
+	require.NoError(t, f.LoadCredential("token", "/etc/app/token"))
+	require.NoError(t, f.LoadCredentialEncrypted("key", "/etc/credstore.encrypted/key"))
+	require.NoError(t, f.SetCredentialEncrypted("password", []byte("AQID\nBAUG\n")))
+	require.Equal(t, `[Service]
+LoadCredential=token:/etc/app/token
+LoadCredentialEncrypted=key:/etc/credstore.encrypted/key
+SetCredentialEncrypted=password:AQIDBAUG
+`, f.String())
+
+	require.Error(t, f.SetCredentialEncrypted("password", []byte("not base64!")))
+	for _, id := range []string{"", ".", "..", "a/b", "a:b"} {
+		require.Error(t, f.LoadCredential(id, "/path"), id)
+	}
+}
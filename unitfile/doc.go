@@ -0,0 +1,2 @@
+// Package unitfile parses, inspects, and writes systemd unit files.
+package unitfile
@@ -0,0 +1,258 @@
+package unitfile
+
+import (
+	"slices"
+	"strings"
+)
+
+// unitKeys are the settings of the [Unit] section.
+var unitKeys = []string{
+	"Description", "Documentation", "Wants", "Requires", "Requisite", "BindsTo",
+	"PartOf", "Upholds", "Conflicts", "Before", "After", "OnFailure", "OnSuccess",
+	"PropagatesReloadTo", "ReloadPropagatedFrom", "PropagatesStopTo",
+	"StopPropagatedFrom", "JoinsNamespaceOf", "RequiresMountsFor",
+	"WantsMountsFor", "OnFailureJobMode", "OnSuccessJobMode", "IgnoreOnIsolate",
+	"StopWhenUnneeded", "RefuseManualStart", "RefuseManualStop",
+	"AllowIsolate", "DefaultDependencies", "SurviveFinalKillSignal",
+	"CollectMode", "FailureAction", "SuccessAction", "FailureActionExitStatus",
+	"SuccessActionExitStatus", "JobTimeoutSec", "JobRunningTimeoutSec",
+	"JobTimeoutAction", "JobTimeoutRebootArgument", "StartLimitIntervalSec",
+	"StartLimitBurst", "StartLimitAction", "RebootArgument", "SourcePath",
+	"ConditionArchitecture", "ConditionFirmware", "ConditionVirtualization",
+	"ConditionHost", "ConditionKernelCommandLine", "ConditionKernelVersion",
+	"ConditionCredential", "ConditionEnvironment", "ConditionSecurity",
+	"ConditionCapability", "ConditionACPower", "ConditionNeedsUpdate",
+	"ConditionFirstBoot", "ConditionPathExists", "ConditionPathExistsGlob",
+	"ConditionPathIsDirectory", "ConditionPathIsSymbolicLink",
+	"ConditionPathIsMountPoint", "ConditionPathIsReadWrite",
+	"ConditionPathIsEncrypted", "ConditionDirectoryNotEmpty",
+	"ConditionFileNotEmpty", "ConditionFileIsExecutable", "ConditionUser",
+	"ConditionGroup", "ConditionControlGroupController", "ConditionMemory",
+	"ConditionCPUs", "ConditionCPUFeature", "ConditionOSRelease",
+	"ConditionMemoryPressure", "ConditionCPUPressure", "ConditionIOPressure",
+}
+
+// installKeys are the settings of the [Install] section.
+var installKeys = []string{
+	"Alias", "WantedBy", "RequiredBy", "UpheldBy", "Also", "DefaultInstance",
+}
+
+// execKeys are the settings shared by units that spawn processes, which
+// configure the execution environment, killing, and resource control.
+var execKeys = []string{
+	// Paths and credentials.
+	"ExecSearchPath", "WorkingDirectory", "RootDirectory", "RootImage",
+	"RootImageOptions", "RootEphemeral", "RootHash", "RootHashSignature",
+	"RootVerity", "RootImagePolicy", "MountImagePolicy", "ExtensionImagePolicy",
+	"MountAPIVFS", "ProtectProc", "ProcSubset", "BindPaths", "BindReadOnlyPaths",
+	"MountImages", "ExtensionImages", "ExtensionDirectories", "User", "Group",
+	"DynamicUser", "SupplementaryGroups", "SetLoginEnvironment", "PAMName",
+	// Capabilities and security.
+	"CapabilityBoundingSet", "AmbientCapabilities", "NoNewPrivileges",
+	"SecureBits", "SELinuxContext", "AppArmorProfile", "SmackProcessLabel",
+	// Process properties.
+	"LimitCPU", "LimitFSIZE", "LimitDATA", "LimitSTACK", "LimitCORE", "LimitRSS",
+	"LimitNOFILE", "LimitAS", "LimitNPROC", "LimitMEMLOCK", "LimitLOCKS",
+	"LimitSIGPENDING", "LimitMSGQUEUE", "LimitNICE", "LimitRTPRIO", "LimitRTTIME",
+	"UMask", "CoredumpFilter", "KeyringMode", "OOMScoreAdjust", "TimerSlackNSec",
+	"Personality", "IgnoreSIGPIPE", "Nice", "CPUSchedulingPolicy",
+	"CPUSchedulingPriority", "CPUSchedulingResetOnFork", "CPUAffinity",
+	"NUMAPolicy", "NUMAMask", "IOSchedulingClass", "IOSchedulingPriority",
+	// Sandboxing.
+	"ProtectSystem", "ProtectHome", "RuntimeDirectory", "StateDirectory",
+	"CacheDirectory", "LogsDirectory", "ConfigurationDirectory",
+	"RuntimeDirectoryMode", "StateDirectoryMode", "CacheDirectoryMode",
+	"LogsDirectoryMode", "ConfigurationDirectoryMode",
+	"RuntimeDirectoryPreserve", "TimeoutCleanSec", "ReadWritePaths",
+	"ReadOnlyPaths", "InaccessiblePaths", "ExecPaths", "NoExecPaths",
+	"TemporaryFileSystem", "PrivateTmp", "PrivateDevices", "PrivateNetwork",
+	"NetworkNamespacePath", "PrivateIPC", "IPCNamespacePath", "MemoryKSM",
+	"PrivateUsers", "ProtectHostname", "ProtectClock", "ProtectKernelTunables",
+	"ProtectKernelModules", "ProtectKernelLogs", "ProtectControlGroups",
+	"RestrictAddressFamilies", "RestrictFileSystems", "RestrictNamespaces",
+	"LockPersonality", "MemoryDenyWriteExecute", "RestrictRealtime",
+	"RestrictSUIDSGID", "RemoveIPC", "PrivateMounts", "MountFlags",
+	"SystemCallFilter", "SystemCallErrorNumber", "SystemCallArchitectures",
+	"SystemCallLog",
+	// Environment and logging.
+	"Environment", "EnvironmentFile", "PassEnvironment", "UnsetEnvironment",
+	"StandardInput", "StandardOutput", "StandardError", "StandardInputText",
+	"StandardInputData", "LogLevelMax", "LogExtraFields", "LogRateLimitIntervalSec",
+	"LogRateLimitBurst", "LogFilterPatterns", "LogNamespace", "SyslogIdentifier",
+	"SyslogFacility", "SyslogLevel", "SyslogLevelPrefix", "TTYPath", "TTYReset",
+	"TTYVHangup", "TTYRows", "TTYColumns", "TTYVTDisallocate",
+	// Credentials.
+	"LoadCredential", "LoadCredentialEncrypted", "ImportCredential",
+	"SetCredential", "SetCredentialEncrypted",
+	// System V compatibility and miscellaneous.
+	"UtmpIdentifier", "UtmpMode",
+	// Killing.
+	"KillMode", "KillSignal", "RestartKillSignal", "SendSIGHUP", "SendSIGKILL",
+	"FinalKillSignal", "WatchdogSignal",
+	// Resource control.
+	"Slice", "Delegate", "DelegateSubgroup", "CPUAccounting", "CPUWeight",
+	"StartupCPUWeight", "CPUQuota", "CPUQuotaPeriodSec", "AllowedCPUs",
+	"StartupAllowedCPUs", "AllowedMemoryNodes", "StartupAllowedMemoryNodes",
+	"MemoryAccounting", "MemoryMin", "MemoryLow", "StartupMemoryLow",
+	"DefaultStartupMemoryLow", "MemoryHigh", "StartupMemoryHigh", "MemoryMax",
+	"StartupMemoryMax", "MemorySwapMax", "StartupMemorySwapMax", "MemoryZSwapMax",
+	"StartupMemoryZSwapMax", "MemoryZSwapWriteback", "TasksAccounting", "TasksMax",
+	"IOAccounting", "IOWeight", "StartupIOWeight", "IODeviceWeight",
+	"IOReadBandwidthMax", "IOWriteBandwidthMax", "IOReadIOPSMax", "IOWriteIOPSMax",
+	"IODeviceLatencyTargetSec", "IPAccounting", "IPAddressAllow", "IPAddressDeny",
+	"SocketBindAllow", "SocketBindDeny", "RestrictNetworkInterfaces",
+	"NFTSet", "IPIngressFilterPath", "IPEgressFilterPath", "BPFProgram",
+	"DeviceAllow", "DevicePolicy", "ManagedOOMSwap", "ManagedOOMMemoryPressure",
+	"ManagedOOMMemoryPressureLimit", "ManagedOOMPreference", "MemoryPressureWatch",
+	"MemoryPressureThresholdSec", "CoredumpReceive", "DisableControllers",
+	"CPUShares", "StartupCPUShares", "MemoryLimit", "BlockIOAccounting",
+	"BlockIOWeight",
+}
+
+// serviceKeys are the settings specific to the [Service] section.
+var serviceKeys = []string{
+	"Type", "ExitType", "RemainAfterExit", "GuessMainPID", "PIDFile", "BusName",
+	"ExecStart", "ExecStartPre", "ExecStartPost", "ExecCondition", "ExecReload",
+	"ExecStop", "ExecStopPost", "RestartSec", "RestartSteps", "RestartMaxDelaySec",
+	"TimeoutStartSec", "TimeoutStopSec", "TimeoutAbortSec", "TimeoutSec",
+	"TimeoutStartFailureMode", "TimeoutStopFailureMode", "RuntimeMaxSec",
+	"RuntimeRandomizedExtraSec", "WatchdogSec", "Restart", "RestartMode",
+	"SuccessExitStatus", "RestartPreventExitStatus", "RestartForceExitStatus",
+	"RootDirectoryStartOnly", "NonBlocking", "NotifyAccess", "Sockets",
+	"FileDescriptorStoreMax", "FileDescriptorStorePreserve", "USBFunctionDescriptors",
+	"USBFunctionStrings", "OOMPolicy", "OpenFile", "ReloadSignal",
+}
+
+// socketKeys are the settings specific to the [Socket] section.
+var socketKeys = []string{
+	"ListenStream", "ListenDatagram", "ListenSequentialPacket", "ListenFIFO",
+	"ListenSpecial", "ListenNetlink", "ListenMessageQueue", "ListenUSBFunction",
+	"SocketProtocol", "BindIPv6Only", "Backlog", "BindToDevice", "SocketUser",
+	"SocketGroup", "SocketMode", "DirectoryMode", "Accept", "Writable",
+	"FlushPending", "MaxConnections", "MaxConnectionsPerSource", "KeepAlive",
+	"KeepAliveTimeSec", "KeepAliveIntervalSec", "KeepAliveProbes", "NoDelay",
+	"Priority", "DeferAcceptSec", "ReceiveBuffer", "SendBuffer", "IPTOS", "IPTTL",
+	"Mark", "ReusePort", "SmackLabel", "SmackLabelIPIn", "SmackLabelIPOut",
+	"SELinuxContextFromNet", "PipeSize", "MessageQueueMaxMessages",
+	"MessageQueueMessageSize", "FreeBind", "Transparent", "Broadcast",
+	"PassCredentials", "PassSecurity", "PassPacketInfo", "Timestamping",
+	"TCPCongestion", "ExecStartPre", "ExecStartPost", "ExecStopPre",
+	"ExecStopPost", "TimeoutSec", "Service", "RemoveOnStop", "Symlinks",
+	"FileDescriptorName", "TriggerLimitIntervalSec", "TriggerLimitBurst",
+	"PollLimitIntervalSec", "PollLimitBurst",
+}
+
+// timerKeys are the settings of the [Timer] section.
+var timerKeys = []string{
+	"OnActiveSec", "OnBootSec", "OnStartupSec", "OnUnitActiveSec",
+	"OnUnitInactiveSec", "OnCalendar", "AccuracySec", "RandomizedDelaySec",
+	"RandomizedOffsetSec", "FixedRandomDelay", "OnClockChange",
+	"OnTimezoneChange", "Unit", "Persistent", "WakeSystem", "RemainAfterElapse",
+}
+
+// pathKeys are the settings of the [Path] section.
+var pathKeys = []string{
+	"PathExists", "PathExistsGlob", "PathChanged", "PathModified",
+	"DirectoryNotEmpty", "Unit", "MakeDirectory", "DirectoryMode",
+	"TriggerLimitIntervalSec", "TriggerLimitBurst",
+}
+
+// booleanKeys are settings taking a boolean.
+var booleanKeys = []string{
+	"StopWhenUnneeded", "RefuseManualStart", "RefuseManualStop", "AllowIsolate",
+	"DefaultDependencies", "IgnoreOnIsolate", "RemainAfterExit", "GuessMainPID",
+	"RootDirectoryStartOnly", "NonBlocking", "DynamicUser", "NoNewPrivileges",
+	"PrivateTmp", "PrivateDevices", "PrivateNetwork", "PrivateIPC",
+	"PrivateMounts", "ProtectHostname", "ProtectClock", "ProtectKernelTunables",
+	"ProtectKernelModules", "ProtectKernelLogs", "ProtectControlGroups",
+	"LockPersonality", "MemoryDenyWriteExecute", "RestrictRealtime",
+	"RestrictSUIDSGID", "RemoveIPC", "CPUAccounting", "MemoryAccounting",
+	"TasksAccounting", "IOAccounting", "IPAccounting", "SendSIGHUP",
+	"SendSIGKILL", "Accept", "Writable", "FlushPending", "KeepAlive", "NoDelay",
+	"ReusePort", "FreeBind", "Transparent", "Broadcast", "PassCredentials",
+	"PassSecurity", "PassPacketInfo", "RemoveOnStop", "Persistent", "WakeSystem",
+	"RemainAfterElapse", "FixedRandomDelay", "OnClockChange", "OnTimezoneChange",
+	"MakeDirectory", "IgnoreSIGPIPE", "TTYReset", "TTYVHangup",
+	"TTYVTDisallocate", "SyslogLevelPrefix", "MountAPIVFS",
+}
+
+// timespanKeys are settings taking a time span.
+var timespanKeys = []string{
+	"RestartSec", "RestartMaxDelaySec", "TimeoutStartSec", "TimeoutStopSec",
+	"TimeoutAbortSec", "TimeoutSec", "RuntimeMaxSec", "RuntimeRandomizedExtraSec",
+	"WatchdogSec", "JobTimeoutSec", "JobRunningTimeoutSec", "StartLimitIntervalSec",
+	"TimeoutCleanSec", "OnActiveSec", "OnBootSec", "OnStartupSec",
+	"OnUnitActiveSec", "OnUnitInactiveSec", "AccuracySec", "RandomizedDelaySec",
+	"RandomizedOffsetSec", "KeepAliveTimeSec", "KeepAliveIntervalSec",
+	"DeferAcceptSec", "TriggerLimitIntervalSec", "PollLimitIntervalSec",
+	"LogRateLimitIntervalSec", "CPUQuotaPeriodSec", "MemoryPressureThresholdSec",
+}
+
+// enumValues are the accepted values of settings taking one of a fixed set.
+var enumValues = map[string][]string{
+	"Type": {"simple", "exec", "forking", "oneshot", "dbus", "notify", "notify-reload", "idle"},
+	"Restart": {
+		"no", "on-success", "on-failure", "on-abnormal", "on-watchdog",
+		"on-abort", "always",
+	},
+	"RestartMode":  {"normal", "direct", "debug"},
+	"ExitType":     {"main", "cgroup"},
+	"NotifyAccess": {"none", "main", "exec", "all"},
+	"KillMode":     {"control-group", "mixed", "process", "none"},
+	"OOMPolicy":    {"continue", "stop", "kill"},
+	"CollectMode":  {"inactive", "inactive-or-failed"},
+	"ProtectSystem": {
+		"yes", "true", "on", "1", "no", "false", "off", "0", "full", "strict",
+	},
+	"ProtectHome": {
+		"yes", "true", "on", "1", "no", "false", "off", "0", "read-only", "tmpfs",
+	},
+	"DevicePolicy": {"auto", "closed", "strict"},
+}
+
+// sectionKeys maps type-specific sections to the unit type they belong to
+// and their settings. Sections without a key list aren't checked for
+// unknown keys.
+var sectionKeys = map[string]struct {
+	unitType string
+	keys     []string
+}{
+	"Service":   {"service", slices.Concat(serviceKeys, execKeys)},
+	"Socket":    {"socket", slices.Concat(socketKeys, execKeys)},
+	"Timer":     {"timer", timerKeys},
+	"Path":      {"path", pathKeys},
+	"Mount":     {"mount", nil},
+	"Automount": {"automount", nil},
+	"Swap":      {"swap", nil},
+	"Slice":     {"slice", nil},
+	"Scope":     {"scope", nil},
+}
+
+// knownKeys returns the settings of a section in a unit of the given type,
+// and whether the section is valid at all. A nil set means any key is
+// accepted.
+func knownKeys(section, unitType string) (map[string]bool, bool) {
+	var keys []string
+	switch section {
+	case "Unit":
+		keys = unitKeys
+	case "Install":
+		keys = installKeys
+	default:
+		s, ok := sectionKeys[section]
+		if !ok || (unitType != "" && !strings.EqualFold(s.unitType, unitType)) {
+			return nil, false
+		}
+		if s.keys == nil {
+			return nil, true
+		}
+		keys = s.keys
+	}
+
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+
+	return set, true
+}
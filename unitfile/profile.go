@@ -0,0 +1,67 @@
+package unitfile
+
+// Profile is a reusable set of [Service] settings, such as a sandboxing
+// preset. Keys may be repeated, as with SystemCallFilter=.
+type Profile struct {
+	Name     string
+	Settings []Entry
+}
+
+// ProfileStrict sandboxes services that need neither the network nor
+// privileges: the file system is read-only, and kernel, devices, namespaces
+// and privileged system calls are off-limits. State can still be written to
+// StateDirectory= and the like.
+var ProfileStrict = Profile{
+	Name: "strict",
+	Settings: append(hardening(),
+		Entry{Key: "PrivateNetwork", Value: "yes"},
+		Entry{Key: "RestrictAddressFamilies", Value: "AF_UNIX"},
+		Entry{Key: "CapabilityBoundingSet", Value: ""},
+	),
+}
+
+// ProfileNetworkService is ProfileStrict for services talking over IP,
+// which may also bind privileged ports.
+var ProfileNetworkService = Profile{
+	Name: "network-service",
+	Settings: append(hardening(),
+		Entry{Key: "RestrictAddressFamilies", Value: "AF_UNIX AF_INET AF_INET6"},
+		Entry{Key: "CapabilityBoundingSet", Value: "CAP_NET_BIND_SERVICE"},
+	),
+}
+
+// hardening returns the settings shared by the built-in profiles.
+func hardening() []Entry {
+	return []Entry{
+		{Key: "NoNewPrivileges", Value: "yes"},
+		{Key: "ProtectSystem", Value: "strict"},
+		{Key: "ProtectHome", Value: "yes"},
+		{Key: "PrivateTmp", Value: "yes"},
+		{Key: "PrivateDevices", Value: "yes"},
+		{Key: "ProtectKernelTunables", Value: "yes"},
+		{Key: "ProtectKernelModules", Value: "yes"},
+		{Key: "ProtectKernelLogs", Value: "yes"},
+		{Key: "ProtectControlGroups", Value: "yes"},
+		{Key: "ProtectClock", Value: "yes"},
+		{Key: "ProtectHostname", Value: "yes"},
+		{Key: "RestrictNamespaces", Value: "yes"},
+		{Key: "RestrictRealtime", Value: "yes"},
+		{Key: "RestrictSUIDSGID", Value: "yes"},
+		{Key: "LockPersonality", Value: "yes"},
+		{Key: "MemoryDenyWriteExecute", Value: "yes"},
+		{Key: "SystemCallArchitectures", Value: "native"},
+		{Key: "SystemCallFilter", Value: "@system-service"},
+		{Key: "SystemCallFilter", Value: "~@privileged @resources"},
+	}
+}
+
+// ApplyProfile adds the settings of p to the [Service] section, replacing
+// any previous assignments of the same keys.
+func (f *File) ApplyProfile(p Profile) {
+	for _, e := range p.Settings {
+		f.Remove("Service", e.Key)
+	}
+	for _, e := range p.Settings {
+		f.Add("Service", e.Key, e.Value)
+	}
+}
@@ -0,0 +1,33 @@
+package unitfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_File_ApplyProfile(t *testing.T) {
+	f, err := Parse(strings.NewReader("[Service]\nExecStart=/usr/bin/app\nProtectSystem=full\n"))
+	require.NoError(t, err)
+
+	f.ApplyProfile(ProfileNetworkService)
+	v, ok := f.Get("Service", "ProtectSystem")
+	require.True(t, ok)
+	require.Equal(t, "strict", v)
+	require.Equal(t, []string{"@system-service", "~@privileged @resources"}, f.Values("Service", "SystemCallFilter"))
+	require.Equal(t, []string{"CAP_NET_BIND_SERVICE"}, f.Values("Service", "CapabilityBoundingSet"))
+	_, ok = f.Get("Service", "PrivateNetwork")
+	require.False(t, ok)
+
+	// The result passes verification.
+	require.Empty(t, Check(f, "service"))
+
+	// Applying another profile replaces the previous settings.
+	f.ApplyProfile(ProfileStrict)
+	require.Equal(t, []string{"@system-service", "~@privileged @resources"}, f.Values("Service", "SystemCallFilter"))
+	v, ok = f.Get("Service", "CapabilityBoundingSet")
+	require.True(t, ok)
+	require.Empty(t, v)
+	require.Empty(t, Check(f, "service"))
+}
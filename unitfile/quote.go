@@ -0,0 +1,86 @@
+package unitfile
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SplitWords splits a value into words the way systemd does for settings
+// such as ExecStart= and Environment=: words are separated by whitespace,
+// may be enclosed in single or double quotes, and support C-style escapes.
+func SplitWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   byte
+		escaped bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+			r, n, err := unescape(s[i-1:])
+			if err != nil {
+				return nil, err
+			}
+			word.WriteString(r)
+			i += n - 2
+		case c == '\\':
+			escaped, inWord = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// unescape decodes the C-style escape sequence at the start of s, returning
+// the decoded string and the length of the sequence.
+func unescape(s string) (string, int, error) {
+	if len(s) > 1 && strings.IndexByte(` "'\\`, s[1]) >= 0 {
+		return s[1:2], 2, nil
+	}
+	value, _, tail, err := strconv.UnquoteChar(s, 0)
+	if err != nil {
+		return "", 0, errors.New("invalid escape sequence in " + strconv.Quote(s))
+	}
+
+	return string(value), len(s) - len(tail), nil
+}
+
+// QuoteWord quotes a word, if needed, so that SplitWords returns it intact.
+func QuoteWord(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\n\"'\\") {
+		return word
+	}
+
+	return strconv.Quote(word)
+}
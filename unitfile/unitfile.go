@@ -0,0 +1,203 @@
+package unitfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// File is a parsed unit file. Comments and blank lines aren't kept.
+type File struct {
+	Sections []*Section
+}
+
+// Section is a section of a unit file, such as [Service].
+type Section struct {
+	Name    string
+	Entries []*Entry
+}
+
+// Entry is a single Key=Value assignment. Keys may be repeated.
+type Entry struct {
+	Key   string
+	Value string
+	// Line is where the entry starts in the parsed input, or zero for
+	// entries that weren't parsed.
+	Line int
+}
+
+// Parse parses systemd unit file syntax.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	var section *Section
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Join continuation lines, which end in a backslash, skipping
+		// comments interleaved with them.
+		start := lineNumber
+		for strings.HasSuffix(line, `\`) && !isComment(line) {
+			line = strings.TrimSuffix(line, `\`) + " "
+			next := ""
+			for next == "" && scanner.Scan() {
+				lineNumber++
+				next = strings.TrimSpace(scanner.Text())
+				if isComment(next) {
+					next = ""
+				}
+			}
+			line += next
+		}
+
+		switch {
+		case line == "" || isComment(line):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return nil, fmt.Errorf("line %d: invalid section header %q", start, line)
+			}
+			section = &Section{Name: line[1 : len(line)-1]}
+			f.Sections = append(f.Sections, section)
+		default:
+			if section == nil {
+				return nil, fmt.Errorf("line %d: assignment outside of a section", start)
+			}
+			key, value, ok := strings.Cut(line, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("line %d: missing '=' in %q", start, line)
+			}
+			section.Entries = append(section.Entries, &Entry{Key: key, Value: strings.TrimSpace(value), Line: start})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// isComment returns whether a trimmed line is a comment.
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")
+}
+
+// Section returns the first section with the given name, or nil.
+func (f *File) Section(name string) *Section {
+	for _, s := range f.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Get returns the last value assigned to key in section, which is the one
+// in effect for single-valued settings.
+func (f *File) Get(section, key string) (string, bool) {
+	values := f.all(section, key)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	return values[len(values)-1], true
+}
+
+// Values returns all values assigned to key in section, for list settings
+// such as ExecStartPre=. As with systemd, an empty assignment resets the
+// list.
+func (f *File) Values(section, key string) []string {
+	var values []string
+	for _, v := range f.all(section, key) {
+		if v == "" {
+			values = nil
+			continue
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// all returns all values assigned to key across sections with the given
+// name, in order.
+func (f *File) all(section, key string) []string {
+	var values []string
+	for _, s := range f.Sections {
+		if s.Name != section {
+			continue
+		}
+		for _, e := range s.Entries {
+			if e.Key == key {
+				values = append(values, e.Value)
+			}
+		}
+	}
+
+	return values
+}
+
+// Set replaces all assignments of key in section with a single one, adding
+// the section if needed.
+func (f *File) Set(section, key, value string) {
+	f.Remove(section, key)
+	f.Add(section, key, value)
+}
+
+// Add appends an assignment of key to the first section with the given name,
+// adding the section if needed.
+func (f *File) Add(section, key, value string) {
+	s := f.Section(section)
+	if s == nil {
+		s = &Section{Name: section}
+		f.Sections = append(f.Sections, s)
+	}
+	s.Entries = append(s.Entries, &Entry{Key: key, Value: value})
+}
+
+// Remove removes all assignments of key in section.
+func (f *File) Remove(section, key string) {
+	for _, s := range f.Sections {
+		if s.Name != section {
+			continue
+		}
+		entries := s.Entries[:0]
+		for _, e := range s.Entries {
+			if e.Key != key {
+				entries = append(entries, e)
+			}
+		}
+		s.Entries = entries
+	}
+}
+
+// WriteTo writes f in unit file syntax.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for i, s := range f.Sections {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "[%s]\n", s.Name)
+		for _, e := range s.Entries {
+			fmt.Fprintf(&buf, "%s=%s\n", e.Key, e.Value)
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// String returns f in unit file syntax.
+func (f *File) String() string {
+	var b strings.Builder
+	_, _ = f.WriteTo(&b)
+
+	return b.String()
+}
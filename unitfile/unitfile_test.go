@@ -0,0 +1,130 @@
+package unitfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testUnit = `# A comment
+; Another comment
+[Unit]
+Description=Test unit
+After=network.target
+After=local-fs.target
+
+[Service]
+ExecStartPre=/bin/true
+ExecStartPre=
+ExecStartPre=/bin/echo pre
+ExecStart=/bin/sh -c \
+  # interleaved comment
+  "echo hello"
+Environment = FOO=bar  "BAZ=qux quux"
+`
+
+func Test_Unit_Parse(t *testing.T) {
+	f, err := Parse(strings.NewReader(testUnit))
+	require.NoError(t, err)
+	require.Len(t, f.Sections, 2)
+	require.Equal(t, "Unit", f.Sections[0].Name)
+	require.NotNil(t, f.Section("Service"))
+	require.Nil(t, f.Section("Install"))
+
+	description, ok := f.Get("Unit", "Description")
+	require.True(t, ok)
+	require.Equal(t, "Test unit", description)
+
+	require.Equal(t, []string{"network.target", "local-fs.target"}, f.Values("Unit", "After"))
+	require.Equal(t, []string{"/bin/echo pre"}, f.Values("Service", "ExecStartPre"))
+
+	execStart, ok := f.Get("Service", "ExecStart")
+	require.True(t, ok)
+	require.Equal(t, `/bin/sh -c  "echo hello"`, execStart)
+
+	environment, ok := f.Get("Service", "Environment")
+	require.True(t, ok)
+	require.Equal(t, `FOO=bar  "BAZ=qux quux"`, environment)
+
+	_, ok = f.Get("Service", "Missing")
+	require.False(t, ok)
+}
+
+func Test_Unit_Parse_Invalid(t *testing.T) {
+	invalid := []string{
+		"Description=outside of a section",
+		"[Unit\nDescription=foo",
+		"[]",
+		"[Unit]\nDescription",
+		"[Unit]\n=foo",
+	}
+	for _, s := range invalid {
+		_, err := Parse(strings.NewReader(s))
+		require.Error(t, err, s)
+	}
+}
+
+func Test_Unit_File_Modify(t *testing.T) {
+	f, err := Parse(strings.NewReader(testUnit))
+	require.NoError(t, err)
+
+	f.Set("Unit", "After", "multi-user.target")
+	f.Add("Install", "WantedBy", "multi-user.target")
+	f.Remove("Service", "ExecStartPre")
+
+	require.Equal(t, []string{"multi-user.target"}, f.Values("Unit", "After"))
+	require.Nil(t, f.Values("Service", "ExecStartPre"))
+
+	expected := `[Unit]
+Description=Test unit
+After=multi-user.target
+
+[Service]
+ExecStart=/bin/sh -c  "echo hello"
+Environment=FOO=bar  "BAZ=qux quux"
+
+[Install]
+WantedBy=multi-user.target
+`
+	require.Equal(t, expected, f.String())
+
+	// Written files parse back to the same content.
+	parsed, err := Parse(strings.NewReader(f.String()))
+	require.NoError(t, err)
+	require.Equal(t, f.String(), parsed.String())
+}
+
+func Test_Unit_SplitWords(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected []string
+	}{
+		{"", nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}},
+		{`FOO=bar "BAZ=qux quux"`, []string{"FOO=bar", "BAZ=qux quux"}},
+		{`'single "quoted"' x`, []string{`single "quoted"`, "x"}},
+		{`a\ b \"c\" d\x41\n`, []string{"a b", `"c"`, "dA\n"}},
+		{`""`, []string{""}},
+		{`pre"fix"ed`, []string{"prefixed"}},
+	}
+	for _, tt := range tests {
+		words, err := SplitWords(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.expected, words, tt.in)
+	}
+
+	for _, s := range []string{`"unterminated`, `trailing\`, `\q`} {
+		_, err := SplitWords(s)
+		require.Error(t, err, s)
+	}
+}
+
+func Test_Unit_QuoteWord(t *testing.T) {
+	for _, word := range []string{"plain", "", "with space", `"quoted"`, `back\slash`, "new\nline"} {
+		words, err := SplitWords(QuoteWord(word))
+		require.NoError(t, err)
+		require.Equal(t, []string{word}, words, word)
+	}
+	require.Equal(t, "plain", QuoteWord("plain"))
+}
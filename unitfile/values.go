@@ -0,0 +1,199 @@
+package unitfile
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBool parses a systemd boolean, e.g. "yes", "off" or "1".
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "yes", "y", "true", "t", "on":
+		return true, nil
+	case "0", "no", "n", "false", "f", "off":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+// timespanUnits maps systemd time span units to durations.
+var timespanUnits = map[string]time.Duration{
+	"us": time.Microsecond, "usec": time.Microsecond, "µs": time.Microsecond,
+	"ms": time.Millisecond, "msec": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"M": 2629800 * time.Second, "month": 2629800 * time.Second, "months": 2629800 * time.Second,
+	"y": 31557600 * time.Second, "year": 31557600 * time.Second, "years": 31557600 * time.Second,
+}
+
+// timespanComponent matches a number followed by an optional unit.
+var timespanComponent = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*([a-zA-Zµ]*)`)
+
+// ParseTimespan parses a systemd time span, e.g. "90", "1min 30s" or
+// "2h30m". A number without unit is in seconds, and "infinity" returns the
+// maximum duration.
+func ParseTimespan(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "infinity" {
+		return time.Duration(math.MaxInt64), nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid time span %q", s)
+	}
+
+	var total time.Duration
+	for rest := s; strings.TrimSpace(rest) != ""; {
+		m := timespanComponent.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("invalid time span %q", s)
+		}
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time span %q: %w", s, err)
+		}
+		unit := time.Second
+		if m[2] != "" {
+			var ok bool
+			if unit, ok = timespanUnits[m[2]]; !ok {
+				return 0, fmt.Errorf("invalid time span %q: unknown unit %q", s, m[2])
+			}
+		}
+		total += time.Duration(value * float64(unit))
+		rest = rest[len(m[0]):]
+	}
+
+	return total, nil
+}
+
+// calendarShorthands are the calendar specifications systemd accepts as
+// shorthands.
+var calendarShorthands = []string{
+	"minutely", "hourly", "daily", "monthly", "weekly", "yearly", "annually",
+	"quarterly", "semiannually",
+}
+
+var (
+	calendarWeekdays = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*$`)
+	calendarValue    = regexp.MustCompile(`^(\*|\d+(\.\.\d+)?)(/\d+)?$`)
+	calendarSeconds  = regexp.MustCompile(`^(\*|\d+(\.\d+)?(\.\.\d+(\.\d+)?)?)(/\d+(\.\d+)?)?$`)
+)
+
+// ValidateCalendar checks a systemd calendar event specification, e.g.
+// "Mon..Fri *-*-* 09:00:00" or "daily", as used by OnCalendar=.
+func ValidateCalendar(s string) error {
+	if ok := validCalendar(strings.TrimSpace(s)); !ok {
+		return fmt.Errorf("invalid calendar specification %q", s)
+	}
+
+	return nil
+}
+
+// validCalendar implements ValidateCalendar.
+func validCalendar(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, shorthand := range calendarShorthands {
+		if strings.EqualFold(s, shorthand) {
+			return true
+		}
+	}
+	if strings.HasPrefix(s, "@") {
+		_, err := strconv.ParseUint(s[1:], 10, 64)
+		return err == nil
+	}
+
+	tokens := strings.Fields(s)
+	// A trailing time zone, such as "UTC" or "Europe/Lisbon".
+	if last := tokens[len(tokens)-1]; len(tokens) > 1 && (last == "UTC" || strings.Contains(last, "/")) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	// A leading list of weekdays or weekday ranges.
+	if c := tokens[0][0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		if !validWeekdays(tokens[0]) {
+			return false
+		}
+		tokens = tokens[1:]
+	}
+
+	var date, clock string
+	for _, token := range tokens {
+		switch {
+		case strings.Contains(token, ":") && clock == "":
+			clock = token
+		case strings.Contains(token, "-") && date == "" && clock == "":
+			date = token
+		default:
+			return false
+		}
+	}
+
+	return (date == "" || validDate(date)) && (clock == "" || validClock(clock))
+}
+
+// validWeekdays checks a comma-separated list of weekdays or weekday ranges.
+func validWeekdays(s string) bool {
+	for part := range strings.SplitSeq(s, ",") {
+		from, to, isRange := strings.Cut(part, "..")
+		if !calendarWeekdays.MatchString(from) || (isRange && !calendarWeekdays.MatchString(to)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validDate checks a date in the form "[year-]month-day", where "~" may
+// replace the last "-" to count days from the end of the month.
+func validDate(s string) bool {
+	s = strings.Replace(s, "~", "-", 1)
+	components := strings.Split(s, "-")
+	if len(components) < 2 || len(components) > 3 {
+		return false
+	}
+	for _, component := range components {
+		if !validCalendarValues(component, calendarValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validClock checks a time in the form "hour:minute[:second]".
+func validClock(s string) bool {
+	components := strings.Split(s, ":")
+	if len(components) < 2 || len(components) > 3 {
+		return false
+	}
+	for i, component := range components {
+		re := calendarValue
+		if i == 2 {
+			re = calendarSeconds
+		}
+		if !validCalendarValues(component, re) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validCalendarValues checks a comma-separated list of calendar values.
+func validCalendarValues(s string, re *regexp.Regexp) bool {
+	for value := range strings.SplitSeq(s, ",") {
+		if !re.MatchString(value) {
+			return false
+		}
+	}
+
+	return true
+}
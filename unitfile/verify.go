@@ -0,0 +1,225 @@
+package unitfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Issue is a problem found in a unit file.
+type Issue struct {
+	// Line is where the problem is, or zero if it isn't tied to a line.
+	Line    int
+	Section string
+	Key     string
+	Message string
+}
+
+// String returns a human-readable description of the issue.
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	switch {
+	case i.Section != "" && i.Key != "":
+		fmt.Fprintf(&b, "[%s] %s=: ", i.Section, i.Key)
+	case i.Section != "":
+		fmt.Fprintf(&b, "[%s]: ", i.Section)
+	}
+	b.WriteString(i.Message)
+
+	return b.String()
+}
+
+// VerifyOption configures Verify.
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	systemdAnalyze bool
+}
+
+// WithSystemdAnalyze additionally runs "systemd-analyze verify" on the unit
+// file, reporting its findings as issues. This catches problems the built-in
+// checker doesn't know about, at the cost of requiring systemd on the host.
+func WithSystemdAnalyze() VerifyOption {
+	return func(o *verifyOptions) {
+		o.systemdAnalyze = true
+	}
+}
+
+// Verify validates the unit file at path before it's installed. It reports
+// unknown keys, invalid values for known settings, services without
+// ExecStart=, timers without triggers, and invalid calendar specifications.
+// The returned error is only set when the file can't be checked at all.
+func Verify(ctx context.Context, path string, opts ...VerifyOption) ([]Issue, error) {
+	var o verifyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open unit file %q: %w", path, err)
+	}
+	defer file.Close()
+
+	f, err := Parse(file)
+	if err != nil {
+		return []Issue{{Message: err.Error()}}, nil
+	}
+	issues := Check(f, strings.TrimPrefix(filepath.Ext(path), "."))
+
+	if o.systemdAnalyze {
+		analyzed, err := systemdAnalyze(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, analyzed...)
+	}
+
+	return issues, nil
+}
+
+// systemdAnalyzeLine matches "<path>:<line>: <message>" lines output by
+// systemd-analyze verify.
+var systemdAnalyzeLine = regexp.MustCompile(`^.+?:(\d+): (.+)$`)
+
+// systemdAnalyze runs "systemd-analyze verify" on the unit file at path.
+func systemdAnalyze(ctx context.Context, path string) ([]Issue, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "systemd-analyze", "verify", "--man=no", path)
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var issues []Issue
+	scanner := bufio.NewScanner(&stderr)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		issue := Issue{Message: line}
+		if m := systemdAnalyzeLine.FindStringSubmatch(line); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Message = m[2]
+		}
+		issues = append(issues, issue)
+	}
+
+	// systemd-analyze fails on errors it has already reported.
+	if runErr != nil && len(issues) == 0 {
+		return nil, fmt.Errorf("failed to run systemd-analyze verify on %q: %w", path, runErr)
+	}
+
+	return issues, nil
+}
+
+// Check validates a parsed unit file of the given type, e.g. "service", with
+// the built-in checker.
+func Check(f *File, unitType string) []Issue {
+	var issues []Issue
+	for _, s := range f.Sections {
+		keys, known := knownKeys(s.Name, unitType)
+		if !known && !strings.HasPrefix(s.Name, "X-") {
+			issues = append(issues, Issue{Section: s.Name, Message: "unknown section"})
+			continue
+		}
+		for _, e := range s.Entries {
+			if keys != nil && !keys[e.Key] && !strings.HasPrefix(e.Key, "X-") {
+				issues = append(issues, Issue{Line: e.Line, Section: s.Name, Key: e.Key, Message: "unknown key"})
+				continue
+			}
+			if e.Value == "" {
+				continue
+			}
+			if err := checkValue(s.Name, e.Key, e.Value); err != nil {
+				issues = append(issues, Issue{Line: e.Line, Section: s.Name, Key: e.Key, Message: err.Error()})
+			}
+		}
+	}
+
+	switch unitType {
+	case "service":
+		issues = append(issues, checkService(f)...)
+	case "timer":
+		issues = append(issues, checkTimer(f)...)
+	}
+
+	return issues
+}
+
+// checkService checks that a service has something to run.
+func checkService(f *File) []Issue {
+	serviceType, _ := f.Get("Service", "Type")
+	hasStart := len(f.Values("Service", "ExecStart")) > 0
+	hasStop := len(f.Values("Service", "ExecStop")) > 0
+	_, hasSuccessAction := f.Get("Service", "SuccessAction")
+
+	switch {
+	case !hasStart && !hasStop && !hasSuccessAction:
+		return []Issue{{Section: "Service", Message: "service has no ExecStart=, ExecStop=, or SuccessAction="}}
+	case !hasStart && serviceType != "oneshot":
+		return []Issue{{Section: "Service", Message: "service has no ExecStart=, which is only allowed for Type=oneshot services"}}
+	case len(f.Values("Service", "ExecStart")) > 1 && serviceType != "oneshot":
+		return []Issue{{Section: "Service", Key: "ExecStart", Message: "multiple ExecStart= are only allowed for Type=oneshot services"}}
+	}
+
+	return nil
+}
+
+// timerTriggers are the settings that make a timer elapse.
+var timerTriggers = []string{
+	"OnActiveSec", "OnBootSec", "OnStartupSec", "OnUnitActiveSec", "OnUnitInactiveSec",
+	"OnCalendar", "OnClockChange", "OnTimezoneChange",
+}
+
+// checkTimer checks that a timer has a trigger.
+func checkTimer(f *File) []Issue {
+	for _, key := range timerTriggers {
+		if len(f.Values("Timer", key)) > 0 {
+			return nil
+		}
+	}
+
+	return []Issue{{Section: "Timer", Message: "timer has no trigger setting"}}
+}
+
+// checkValue validates the value of a known setting. Settings whose syntax
+// isn't known are accepted.
+func checkValue(section, key, value string) error {
+	if slices.Contains(booleanKeys, key) {
+		if _, err := ParseBool(value); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(timespanKeys, key) {
+		if _, err := ParseTimespan(value); err != nil {
+			return err
+		}
+	}
+	// Type= of mounts and swaps is a file system type, not a service type.
+	if allowed, ok := enumValues[key]; ok && (key != "Type" || section == "Service") && !slices.Contains(allowed, value) {
+		return fmt.Errorf("invalid value %q, expected one of %s", value, strings.Join(allowed, ", "))
+	}
+	if key == "OnCalendar" {
+		if err := ValidateCalendar(value); err != nil {
+			return err
+		}
+	}
+	if strings.HasPrefix(key, "Exec") && !slices.Contains([]string{"ExecSearchPath", "ExecPaths", "NoExecPaths"}, key) {
+		if _, err := SplitWords(value); err != nil {
+			return fmt.Errorf("invalid command line: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package unitfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		unitType string
+		content  string
+		issues   []Issue
+	}{
+		{
+			name:     "valid service",
+			unitType: "service",
+			content: `[Unit]
+Description=Valid
+[Service]
+Type=notify
+ExecStart=/usr/bin/app --flag "quoted arg"
+Restart=on-failure
+RestartSec=1min 30s
+PrivateTmp=yes
+X-Custom=ignored
+[Install]
+WantedBy=multi-user.target
+`,
+		},
+		{
+			name:     "unknown key and invalid values",
+			unitType: "service",
+			content: `[Service]
+ExecStart=/bin/true
+Foo=bar
+Type=bogus
+PrivateTmp=maybe
+TimeoutSec=5 lightyears
+`,
+			issues: []Issue{
+				{Line: 3, Section: "Service", Key: "Foo", Message: "unknown key"},
+				{Line: 4, Section: "Service", Key: "Type", Message: `invalid value "bogus", expected one of simple, exec, forking, oneshot, dbus, notify, notify-reload, idle`},
+				{Line: 5, Section: "Service", Key: "PrivateTmp", Message: `invalid boolean "maybe"`},
+				{Line: 6, Section: "Service", Key: "TimeoutSec", Message: `invalid time span "5 lightyears": unknown unit "lightyears"`},
+			},
+		},
+		{
+			name:     "missing ExecStart",
+			unitType: "service",
+			content:  "[Service]\nType=simple\nExecStop=/bin/true\n",
+			issues: []Issue{
+				{Section: "Service", Message: "service has no ExecStart=, which is only allowed for Type=oneshot services"},
+			},
+		},
+		{
+			name:     "nothing to run",
+			unitType: "service",
+			content:  "[Service]\nType=oneshot\n",
+			issues: []Issue{
+				{Section: "Service", Message: "service has no ExecStart=, ExecStop=, or SuccessAction="},
+			},
+		},
+		{
+			name:     "section of another unit type",
+			unitType: "service",
+			content:  "[Service]\nExecStart=/bin/true\n[Timer]\nOnCalendar=daily\n",
+			issues: []Issue{
+				{Section: "Timer", Message: "unknown section"},
+			},
+		},
+		{
+			name:     "bad calendar",
+			unitType: "timer",
+			content:  "[Timer]\nOnCalendar=daily\nOnCalendar=garbage\n",
+			issues: []Issue{
+				{Line: 3, Section: "Timer", Key: "OnCalendar", Message: `invalid calendar specification "garbage"`},
+			},
+		},
+		{
+			name:     "timer without trigger",
+			unitType: "timer",
+			content:  "[Timer]\nPersistent=true\n",
+			issues: []Issue{
+				{Section: "Timer", Message: "timer has no trigger setting"},
+			},
+		},
+		{
+			name:     "mount type isn't a service type",
+			unitType: "mount",
+			content:  "[Mount]\nWhat=/dev/sda1\nType=ext4\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(strings.NewReader(tt.content))
+			require.NoError(t, err)
+			require.Equal(t, tt.issues, Check(f, tt.unitType))
+		})
+	}
+}
+
+func Test_Unit_Verify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.service")
+	require.NoError(t, os.WriteFile(path, []byte("[Service]\nFoo=bar\nExecStart=/bin/true\n"), 0o644))
+
+	issues, err := Verify(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, []Issue{{Line: 2, Section: "Service", Key: "Foo", Message: "unknown key"}}, issues)
+	require.Equal(t, "line 2: [Service] Foo=: unknown key", issues[0].String())
+
+	// Syntax errors are issues too.
+	invalid := filepath.Join(dir, "invalid.service")
+	require.NoError(t, os.WriteFile(invalid, []byte("ExecStart=/bin/true\n"), 0o644))
+	issues, err = Verify(context.Background(), invalid)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	_, err = Verify(context.Background(), filepath.Join(dir, "missing.service"))
+	require.Error(t, err)
+}
+
+func Test_Unit_ParseTimespan(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90":        90 * time.Second,
+		"1min 30s":  90 * time.Second,
+		"2h30m":     150 * time.Minute,
+		"500ms":     500 * time.Millisecond,
+		"1.5s":      1500 * time.Millisecond,
+		" 1d ":      24 * time.Hour,
+		"1w 1d 1us": 8*24*time.Hour + time.Microsecond,
+	}
+	for s, expected := range tests {
+		d, err := ParseTimespan(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, d, s)
+	}
+
+	infinity, err := ParseTimespan("infinity")
+	require.NoError(t, err)
+	require.Positive(t, infinity)
+
+	for _, s := range []string{"", "abc", "5 parsecs", "-1s", "1s garbage"} {
+		_, err := ParseTimespan(s)
+		require.Error(t, err, s)
+	}
+}
+
+func Test_Unit_ValidateCalendar(t *testing.T) {
+	valid := []string{
+		"daily",
+		"Weekly",
+		"*-*-* 00:00:00",
+		"Mon..Fri *-*-* 09:00",
+		"Sat,Sun 10:00",
+		"Mon",
+		"2024-01-01",
+		"*-*~03",
+		"*-*-* *:00/15:00",
+		"*:0/5",
+		"12:00 UTC",
+		"*-*-* 04:00:00 Europe/Lisbon",
+		"@1700000000",
+		"*-01,07-01 12:30:15.5",
+	}
+	for _, s := range valid {
+		require.NoError(t, ValidateCalendar(s), s)
+	}
+
+	invalid := []string{
+		"",
+		"garbage",
+		"Mon..Garbage",
+		"*-*-*-* 00:00",
+		"00:00:00:00",
+		"*-*-* 00:00 00:00",
+		"@abc",
+		"1-2-x",
+	}
+	for _, s := range invalid {
+		require.Error(t, ValidateCalendar(s), s)
+	}
+}
+
+func Test_Unit_ParseBool(t *testing.T) {
+	for _, s := range []string{"yes", "True", "on", "1"} {
+		b, err := ParseBool(s)
+		require.NoError(t, err)
+		require.True(t, b, s)
+	}
+	for _, s := range []string{"no", "FALSE", "off", "0"} {
+		b, err := ParseBool(s)
+		require.NoError(t, err)
+		require.False(t, b, s)
+	}
+	_, err := ParseBool("maybe")
+	require.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package systemdmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// checkWatchable fails if the named unit can't be watched, because its name
+// is invalid or, unless waiting for it, it doesn't exist.
+func (m *manager) checkWatchable(ctx context.Context, unit string, o watchOptions) error {
+	if err := m.validateUnit(unit); err != nil {
+		return err
+	}
+	if o.waitForUnit {
+		return nil
+	}
+
+	status, err := m.Status(ctx, unit)
+	if err != nil {
+		return err
+	}
+	if status.LoadState == "not-found" {
+		return fmt.Errorf("%w: %q", ErrUnitNotFound, unit)
+	}
+
+	return nil
+}
+
+// LastStatusError means watching or waiting for a unit ended because the
+// context was done. It holds the last status observed, so callers can tell,
+// e.g., that the unit was still activating.
+type LastStatusError struct {
+	Unit string
+	// Status is the last status observed, nil if none was, such as while
+	// the unit wasn't loaded.
+	Status *dbus.UnitStatus
+	// Err is the context error.
+	Err error
+}
+
+func (e *LastStatusError) Error() string {
+	if e.Status == nil {
+		return fmt.Sprintf("unit %q wasn't observed: %v", e.Unit, e.Err)
+	}
+
+	return fmt.Sprintf("unit %q was last %s (%s): %v", e.Unit, e.Status.ActiveState, e.Status.SubState, e.Err)
+}
+
+func (e *LastStatusError) Unwrap() error {
+	return e.Err
+}
+
+// unitWatcher tracks the status of a unit across polls, to report changes
+// as go-systemd subscriptions do.
+type unitWatcher struct {
+	unit    string
+	changed func(previous, current *dbus.UnitStatus) bool
+	// last is the last reported status, nil while the unit isn't loaded.
+	last *dbus.UnitStatus
+}
+
+// update returns the status of the unit among the polled statuses of loaded
+// units, and whether it changed since the last poll. The status is nil when
+// the unit was unloaded.
+func (w *unitWatcher) update(statuses []dbus.UnitStatus) (*dbus.UnitStatus, bool) {
+	var current *dbus.UnitStatus
+	for i := range statuses {
+		if statuses[i].Name == w.unit {
+			current = &statuses[i]
+			break
+		}
+	}
+
+	previous := w.last
+	w.last = current
+	switch {
+	case current == nil:
+		return nil, previous != nil
+	case previous == nil:
+		return current, true
+	default:
+		return current, w.changed(previous, current)
+	}
+}
+
+// unitPoll is the result of polling the status of a unit.
+type unitPoll struct {
+	statuses []dbus.UnitStatus
+	// resync is set on the first poll after reconnecting, when changes may
+	// have been missed.
+	resync bool
+}
+
+// unitPoller polls the status of a unit, reconnecting as needed.
+type unitPoller struct {
+	interval time.Duration
+	// list returns the statuses of the loaded units matching a pattern.
+	list      func(ctx context.Context, pattern string) ([]dbus.UnitStatus, error)
+	connected func() bool
+	reconnect func(ctx context.Context) error
+}
+
+// newUnitPoller returns a poller using the manager connection.
+func (m *manager) newUnitPoller(interval time.Duration) unitPoller {
+	return unitPoller{
+		interval: interval,
+		list: func(ctx context.Context, pattern string) ([]dbus.UnitStatus, error) {
+			return m.dbusConn.Load().ListUnitsByPatternsContext(ctx, nil, []string{pattern})
+		},
+		connected: func() bool { return m.dbusConn.Load().Connected() },
+		reconnect: m.reconnect,
+	}
+}
+
+// run sends the status of the named unit to pollChan every interval, until
+// ctx is done or the connection is lost and reconnecting fails. Polls
+// failing otherwise are skipped.
+func (p unitPoller) run(ctx context.Context, unit string, pollChan chan<- unitPoll) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	// Unit names can't hold glob characters, but may hold escapes.
+	pattern := strings.ReplaceAll(unit, `\`, `\\`)
+
+	resync := false
+	for {
+		if !p.connected() {
+			if err := p.reconnect(ctx); err != nil {
+				return err
+			}
+			resync = true
+		}
+		// TODO understand if other errors are critical and handle
+		// them if it turns out to be the case.
+		statuses, err := p.list(ctx, pattern)
+		if err == nil {
+			select {
+			case pollChan <- unitPoll{statuses: statuses, resync: resync}:
+				resync = false
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package systemdmanager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Unit_unitWatcher(t *testing.T) {
+	w := unitWatcher{unit: "a.service", changed: ActiveStateChanged}
+	status := func(active, sub, description string) []dbus.UnitStatus {
+		return []dbus.UnitStatus{
+			{Name: "b.service", ActiveState: "active"},
+			{Name: "a.service", ActiveState: active, SubState: sub, Description: description},
+		}
+	}
+
+	// Not loaded yet.
+	_, ok := w.update(nil)
+	require.False(t, ok)
+
+	// Loaded units are reported first.
+	got, ok := w.update(status("inactive", "dead", "A"))
+	require.True(t, ok)
+	require.Equal(t, "inactive", got.ActiveState)
+
+	// Changes are filtered.
+	_, ok = w.update(status("inactive", "dead", "B"))
+	require.False(t, ok)
+	got, ok = w.update(status("active", "running", "B"))
+	require.True(t, ok)
+	require.Equal(t, "running", got.SubState)
+
+	// Unloading is reported once.
+	got, ok = w.update(status("", "", "")[:1])
+	require.True(t, ok)
+	require.Nil(t, got)
+	_, ok = w.update(nil)
+	require.False(t, ok)
+}
+
+func Test_Unit_unitPoller(t *testing.T) {
+	var connected atomic.Bool
+	connected.Store(true)
+	reconnectErr := errors.New("no bus")
+	var failReconnect atomic.Bool
+	p := unitPoller{
+		interval: time.Millisecond,
+		list: func(_ context.Context, pattern string) ([]dbus.UnitStatus, error) {
+			return []dbus.UnitStatus{{Name: pattern}}, nil
+		},
+		connected: connected.Load,
+		reconnect: func(context.Context) error {
+			if failReconnect.Load() {
+				return reconnectErr
+			}
+			connected.Store(true)
+			return nil
+		},
+	}
+
+	pollChan := make(chan unitPoll)
+	done := make(chan error, 1)
+	go func() {
+		done <- p.run(t.Context(), `a\x2db.service`, pollChan)
+	}()
+
+	// Escapes are matched literally.
+	poll := <-pollChan
+	require.Equal(t, `a\\x2db.service`, poll.statuses[0].Name)
+	require.False(t, poll.resync)
+
+	// Once reconnected, the next poll is a resync.
+	connected.Store(false)
+	for poll = <-pollChan; !poll.resync; poll = <-pollChan {
+	}
+	require.False(t, (<-pollChan).resync)
+
+	// Watching fails if reconnecting does.
+	failReconnect.Store(true)
+	connected.Store(false)
+	for {
+		select {
+		case <-pollChan:
+			continue
+		case err := <-done:
+			require.ErrorIs(t, err, reconnectErr)
+			return
+		}
+	}
+}
+
+func Test_Unit_LastStatusError(t *testing.T) {
+	err := error(&LastStatusError{
+		Unit:   "a.service",
+		Status: &dbus.UnitStatus{Name: "a.service", ActiveState: "activating", SubState: "start"},
+		Err:    context.DeadlineExceeded,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, `unit "a.service" was last activating (start): context deadline exceeded`)
+
+	err = &LastStatusError{Unit: "a.service", Err: context.Canceled}
+	require.ErrorIs(t, err, context.Canceled)
+	require.EqualError(t, err, `unit "a.service" wasn't observed: context canceled`)
+}